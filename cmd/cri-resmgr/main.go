@@ -15,6 +15,7 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
@@ -23,6 +24,7 @@ import (
 	"time"
 
 	"github.com/intel/goresctrl/pkg/rdt"
+	"sigs.k8s.io/yaml"
 
 	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager"
 	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/policy"
@@ -43,6 +45,10 @@ func main() {
 
 	printConfig := flag.Bool("print-config", false, "Print configuration and exit.")
 	listPolicies := flag.Bool("list-policies", false, "List available policies.")
+	simulateJSON := flag.Bool(resmgr.SimulateJSONFlag, false, "Print simulation results in JSON.")
+	simulateTopology := flag.String(resmgr.SimulateTopologyFlag, "", "Simulate against the topology archived in the given file.")
+	validateConfig := flag.String(resmgr.ValidateConfigFlag, "", "Validate the configuration in the given file and exit.")
+	validateJSON := flag.Bool(resmgr.ValidateJSONFlag, false, "Print validation results in JSON.")
 	flag.Parse()
 
 	switch {
@@ -50,6 +56,9 @@ func main() {
 		config.Print(nil)
 		os.Exit(0)
 
+	case *validateConfig != "":
+		os.Exit(validate(*validateConfig, *validateJSON))
+
 	case *listPolicies:
 		fmt.Printf("Available policies:\n")
 		for _, available := range policy.AvailablePolicies() {
@@ -63,6 +72,10 @@ func main() {
 			case "config-help", "help":
 				config.Describe(args[1:]...)
 				os.Exit(0)
+			case "config-schema":
+				os.Exit(printSchema(args[1:]))
+			case resmgr.SimulateCommand:
+				os.Exit(simulate(args[1:], *simulateJSON, *simulateTopology))
 			default:
 				log.Error("unknown command line arguments: %s", strings.Join(flag.Args(), ","))
 				flag.Usage()
@@ -93,3 +106,99 @@ func main() {
 		time.Sleep(15 * time.Second)
 	}
 }
+
+// simulate runs a simulation of a configuration file against a cache file.
+func simulate(args []string, asJSON bool, topologyArchive string) int {
+	defer logger.Flush()
+
+	if len(args) < 1 || len(args) > 2 {
+		log.Error("usage: %s [--%s] [--%s <topology-archive>] %s <config-file> [cache-file]",
+			os.Args[0], resmgr.SimulateJSONFlag, resmgr.SimulateTopologyFlag, resmgr.SimulateCommand)
+		return 1
+	}
+
+	configFile, cacheFile := args[0], ""
+	if len(args) > 1 {
+		cacheFile = args[1]
+	}
+
+	sim, err := resmgr.SimulateFromFile(configFile, cacheFile, topologyArchive)
+	if err != nil {
+		log.Error("simulation failed: %v", err)
+		return 1
+	}
+
+	if !asJSON {
+		fmt.Print(sim.String())
+		return 0
+	}
+
+	data, err := json.Marshal(sim)
+	if err != nil {
+		log.Error("failed to marshal simulation results: %v", err)
+		return 1
+	}
+	fmt.Println(string(data))
+
+	return 0
+}
+
+// validate checks the configuration in the given file.
+func validate(configFile string, asJSON bool) int {
+	defer logger.Flush()
+
+	errs := resmgr.ValidateConfigFromFile(configFile)
+
+	status := 0
+	if len(errs) > 0 {
+		status = 1
+	}
+
+	if !asJSON {
+		if status == 0 {
+			fmt.Printf("%s: configuration is valid\n", configFile)
+			return status
+		}
+		fmt.Printf("%s: configuration is invalid:\n", configFile)
+		for _, err := range errs {
+			module := err.Module
+			if module == "" {
+				module = "-"
+			}
+			fmt.Printf("  %s: %s\n", module, err.Reason)
+		}
+		return status
+	}
+
+	if errs == nil {
+		errs = config.ValidationErrors{}
+	}
+	data, err := json.Marshal(errs)
+	if err != nil {
+		log.Error("failed to marshal validation results: %v", err)
+		return 2
+	}
+	fmt.Println(string(data))
+
+	return status
+}
+
+// printSchema prints the OpenAPI schema for the configuration of the given modules.
+func printSchema(modules []string) int {
+	defer logger.Flush()
+
+	schema, err := config.GetSchema(modules...)
+	if err != nil {
+		log.Error("%v", err)
+		return 1
+	}
+
+	data, err := yaml.Marshal(schema)
+	if err != nil {
+		log.Error("failed to marshal configuration schema: %v", err)
+		return 1
+	}
+	fmt.Print(string(data))
+
+	return 0
+}
@@ -35,10 +35,15 @@ type resmgrConfig map[string]string
 // resmgrAdjustment represents external adjustments for the resource-manager
 type resmgrAdjustment map[string]*resmgr.Adjustment
 
-// resmgrStatus represents the status of an external adjustment update
+// resmgrConfigCRD represents configuration custom resources
+type resmgrConfigCRD map[string]*resmgr.ResourceManagerConfig
+
+// resmgrStatus represents the status of an external adjustment or configuration update
 type resmgrStatus struct {
-	request error
-	errors  map[string]string
+	config     bool // true for configuration, false for adjustment updates
+	request    error
+	errors     map[string]string
+	validation []resmgr.ConfigValidationError // problems found in rejected configuration
 }
 
 // ResourceManagerAgent is the interface exposed for the CRI Resource Manager Congig Agent
@@ -113,7 +118,7 @@ func (a *agent) Run() error {
 			if ok {
 				a.Info("got status %v", status)
 				if err := a.watcher.UpdateStatus(status); err != nil {
-					a.Error("failed to update adjustment node status: %v", err)
+					a.Error("failed to update node status: %v", err)
 				}
 			}
 		}
@@ -101,9 +101,18 @@ func (u *updater) Start() error {
 						u.Error("failed to send configuration update: %v", err)
 						ratelimit = time.After(retryTimeout)
 					} else {
+						var validation []resmgr.ConfigValidationError
 						if mgrErr != nil {
 							u.Error("cri-resmgr configuration error: %v", mgrErr)
+							validation = u.validateConfig(pendingConfig, mgrErr)
 						}
+
+						u.newStatus <- &resmgrStatus{
+							config:     true,
+							request:    mgrErr,
+							validation: validation,
+						}
+
 						pendingConfig = nil
 						ratelimit = nil
 					}
@@ -167,6 +176,41 @@ func (u *updater) setConfig(cfg *resmgrConfig) (error, error) {
 	}
 }
 
+// validateConfig collects the problems found in a rejected configuration.
+// If validation does not pinpoint any problems, the error of rejecting the
+// configuration is returned as the only problem.
+func (u *updater) validateConfig(cfg *resmgrConfig, mgrErr error) []resmgr.ConfigValidationError {
+	rejected := []resmgr.ConfigValidationError{{Error: mgrErr.Error()}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), setConfigTimeout)
+	defer cancel()
+
+	req := &resmgr_v1.ValidateConfigRequest{Config: *cfg}
+	u.Debug("sending ValidateConfig request to cri-resmgr")
+
+	reply, err := u.resmgrCli.ValidateConfig(ctx, req, []grpc.CallOption{grpc.FailFast(false)}...)
+
+	switch {
+	case err != nil:
+		u.Error("failed to validate rejected configuration: %v", err)
+		return rejected
+	case reply.Error != "":
+		u.Error("failed to validate rejected configuration: %s", reply.Error)
+		return rejected
+	case len(reply.Errors) == 0:
+		return rejected
+	}
+
+	validation := make([]resmgr.ConfigValidationError, 0, len(reply.Errors))
+	for _, e := range reply.Errors {
+		validation = append(validation, resmgr.ConfigValidationError{
+			Module: e.Module,
+			Error:  e.Error,
+		})
+	}
+	return validation
+}
+
 func (u *updater) setAdjustment(adjust *resmgrAdjustment) (map[string]string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), setConfigTimeout)
 	defer cancel()
@@ -178,6 +222,7 @@ func (u *updater) setAdjustment(adjust *resmgrAdjustment) (map[string]string, er
 			Resources:    p.Spec.Resources,
 			Classes:      p.Spec.Classes,
 			ToptierLimit: p.Spec.ToptierLimit,
+			MemoryQoS:    p.Spec.MemoryQoS,
 		}
 	}
 	encoded, err := json.Marshal(specs)
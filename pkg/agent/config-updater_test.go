@@ -0,0 +1,91 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	resmgr "github.com/intel/cri-resource-manager/pkg/apis/resmgr/v1alpha1"
+	resmgr_v1 "github.com/intel/cri-resource-manager/pkg/cri/resource-manager/config/api/v1"
+	"github.com/intel/cri-resource-manager/pkg/log"
+)
+
+// fakeConfigClient is a cri-resmgr config client with a canned ValidateConfig reply.
+type fakeConfigClient struct {
+	resmgr_v1.ConfigClient
+	reply *resmgr_v1.ValidateConfigReply
+	err   error
+}
+
+func (c *fakeConfigClient) ValidateConfig(ctx context.Context, req *resmgr_v1.ValidateConfigRequest, opts ...grpc.CallOption) (*resmgr_v1.ValidateConfigReply, error) {
+	return c.reply, c.err
+}
+
+func TestValidateRejectedConfig(t *testing.T) {
+	rejected := fmt.Errorf("failed to apply configuration")
+
+	tcases := []struct {
+		name     string
+		reply    *resmgr_v1.ValidateConfigReply
+		err      error
+		expected []resmgr.ConfigValidationError
+	}{
+		{
+			name: "validation errors",
+			reply: &resmgr_v1.ValidateConfigReply{
+				Errors: []*resmgr_v1.ValidationError{
+					{Module: "policy.balloons", Error: "invalid balloon definition"},
+					{Error: "failed to parse configuration"},
+				},
+			},
+			expected: []resmgr.ConfigValidationError{
+				{Module: "policy.balloons", Error: "invalid balloon definition"},
+				{Error: "failed to parse configuration"},
+			},
+		},
+		{
+			name:     "no validation errors",
+			reply:    &resmgr_v1.ValidateConfigReply{},
+			expected: []resmgr.ConfigValidationError{{Error: rejected.Error()}},
+		},
+		{
+			name:     "validation failure",
+			reply:    &resmgr_v1.ValidateConfigReply{Error: "validation is not supported"},
+			expected: []resmgr.ConfigValidationError{{Error: rejected.Error()}},
+		},
+		{
+			name:     "request failure",
+			err:      fmt.Errorf("connection refused"),
+			expected: []resmgr.ConfigValidationError{{Error: rejected.Error()}},
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			u := &updater{
+				Logger:    log.NewLogger("config-updater"),
+				resmgrCli: &fakeConfigClient{reply: tc.reply, err: tc.err},
+			}
+			validation := u.validateConfig(&resmgrConfig{"policy": "Active: balloons"}, rejected)
+			if !reflect.DeepEqual(validation, tc.expected) {
+				t.Errorf("expected validation errors %+v, got %+v", tc.expected, validation)
+			}
+		})
+	}
+}
@@ -220,6 +220,30 @@ func newAdjustmentCRDWatch(parent *watcher, ns namespace) *watch {
 	return w
 }
 
+// newConfigCRDWatch creates a watch for k8s ResourceManagerConfig CRDs
+func newConfigCRDWatch(parent *watcher, ns namespace) *watch {
+	w := newWatch(parent, "ConfigCRD", ns,
+		func(ns namespace, name string) (k8swatch.Interface, error) {
+			k8w, err := parent.resmgrCli.ResourceManagerConfigs(string(ns)).Watch(meta_v1.ListOptions{})
+			if err != nil {
+				return nil, err
+			}
+			return k8w, nil
+		},
+		func(ns namespace, name string) (interface{}, error) {
+			crds, err := parent.resmgrCli.ResourceManagerConfigs(string(ns)).List(meta_v1.ListOptions{})
+			if err != nil {
+				return nil, err
+			}
+			if crds == nil || len(crds.Items) == 0 {
+				crds = nil
+			}
+			return crds, nil
+		})
+	w.Start("ConfigCRD")
+	return w
+}
+
 func (w *watch) Name() string {
 	ns, name := w.ns, w.name
 	if ns != "" {
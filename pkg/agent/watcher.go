@@ -24,6 +24,8 @@ import (
 	"time"
 
 	"encoding/json"
+	"sort"
+
 	patch "github.com/evanphx/json-patch"
 	pkgtypes "k8s.io/apimachinery/pkg/types"
 
@@ -35,12 +37,14 @@ import (
 
 type cachedConfig struct {
 	sync.RWMutex
-	nodeCfg  *resmgrConfig    // node-specific configuration
-	groupCfg *resmgrConfig    // group-specific configuration
-	group    string           // group name, "" for default
-	inscope  resmgrAdjustment // external adjustments that apply to this node
-	ignored  resmgrAdjustment // external adjustments that do not apply to this node
-	status   *resmgrStatus    // latest adjustment update status
+	nodeCfg  *resmgrConfig                 // node-specific configuration
+	groupCfg *resmgrConfig                 // group-specific configuration
+	group    string                        // group name, "" for default
+	crds     resmgrConfigCRD               // configuration custom resources
+	sent     *resmgr.ResourceManagerConfig // custom resource of the last configuration sent
+	inscope  resmgrAdjustment              // external adjustments that apply to this node
+	ignored  resmgrAdjustment              // external adjustments that do not apply to this node
+	status   *resmgrStatus                 // latest adjustment update status
 }
 
 // k8sWatcher is our interface to K8s control plane watcher
@@ -55,23 +59,23 @@ type k8sWatcher interface {
 	GetConfig() resmgrConfig
 	// Get a chan through which to receive adjustment updates
 	AdjustmentChan() <-chan resmgrAdjustment
-	// Update the node Status for adjustment updates.
+	// Update the node Status for adjustment or configuration updates.
 	UpdateStatus(*resmgrStatus) error
 }
 
 // watcher implements k8sWatcher
 type watcher struct {
 	log.Logger
-	stop           chan struct{}                      // channel to stop watcher goroutine
-	k8sCli         *k8sclient.Clientset               // k8s client interface
-	resmgrCli      *resmgrcli.CriresmgrV1alpha1Client // adjustment CRD interface
-	currentConfig  cachedConfig                       // current configuration, cached
-	configChan     chan resmgrConfig                  // channel for config updates
-	adjustmentChan chan resmgrAdjustment              // channel for adjustment updates
+	stop           chan struct{}                        // channel to stop watcher goroutine
+	k8sCli         *k8sclient.Clientset                 // k8s client interface
+	resmgrCli      resmgrcli.CriresmgrV1alpha1Interface // adjustment and configuration CRD interface
+	currentConfig  cachedConfig                         // current configuration, cached
+	configChan     chan resmgrConfig                    // channel for config updates
+	adjustmentChan chan resmgrAdjustment                // channel for adjustment updates
 }
 
 // newK8sWatcher creates a new K8sWatcher instance
-func newK8sWatcher(k8sCli *k8sclient.Clientset, resmgrCli *resmgrcli.CriresmgrV1alpha1Client) (k8sWatcher, error) {
+func newK8sWatcher(k8sCli *k8sclient.Clientset, resmgrCli resmgrcli.CriresmgrV1alpha1Interface) (k8sWatcher, error) {
 	w := &watcher{
 		Logger:         log.NewLogger("watcher"),
 		k8sCli:         k8sCli,
@@ -124,12 +128,94 @@ func (w *watcher) GetConfig() resmgrConfig {
 	return cfg
 }
 
-// UpdateStatus updates the node status for adjustment updates.
+// UpdateStatus updates the node status for adjustment or configuration updates.
 func (w *watcher) UpdateStatus(status *resmgrStatus) error {
+	if status.config {
+		return w.PatchConfigStatus(status)
+	}
 	w.currentConfig.setStatus(status)
 	return w.PatchAdjustmentStatus(status)
 }
 
+// PatchConfigStatus updates the node status for configuration updates.
+func (w *watcher) PatchConfigStatus(status *resmgrStatus) error {
+	sent, crds := w.currentConfig.getConfigCRDs()
+
+	errCnt := 0
+	for _, crd := range crds {
+		var nodeStatus *resmgr.ConfigNodeStatus
+		if sent != nil && sent.Name == crd.Name {
+			nodeStatus = &resmgr.ConfigNodeStatus{
+				Accepted: status.request == nil,
+				Revision: sent.Generation,
+			}
+			if status.request != nil {
+				nodeStatus.Errors = status.validation
+				if len(nodeStatus.Errors) == 0 {
+					nodeStatus.Errors = []resmgr.ConfigValidationError{{Error: status.request.Error()}}
+				}
+			}
+		}
+		if err := w.patchConfigCRD(crd, nodeStatus); err != nil {
+			w.Error("%v", err)
+			errCnt++
+		}
+	}
+	if errCnt > 0 {
+		return agentError("some configuration status updates failed")
+	}
+
+	return nil
+}
+
+// patchConfigCRD patches the node status of the given configuration custom resource.
+func (w *watcher) patchConfigCRD(crd *resmgr.ResourceManagerConfig, status *resmgr.ConfigNodeStatus) error {
+	old, ok := crd.Status.Nodes[nodeName]
+	if status == nil && !ok {
+		w.Debug("configuration %s does not need status patching...", crd.Name)
+		return nil
+	}
+
+	current := &resmgr.ResourceManagerConfig{
+		Status: resmgr.ResourceManagerConfigStatus{
+			Nodes: map[string]resmgr.ConfigNodeStatus{},
+		},
+	}
+	if ok {
+		current.Status.Nodes[nodeName] = old
+	}
+	if status != nil && len(status.Errors) == 0 {
+		// Our cached status might be stale, make sure any old errors get cleared.
+		stale := current.Status.Nodes[nodeName]
+		stale.Errors = []resmgr.ConfigValidationError{{}}
+		current.Status.Nodes[nodeName] = stale
+	}
+	updated := &resmgr.ResourceManagerConfig{
+		Status: resmgr.ResourceManagerConfigStatus{
+			Nodes: map[string]resmgr.ConfigNodeStatus{},
+		},
+	}
+	if status != nil {
+		updated.Status.Nodes[nodeName] = *status
+	}
+
+	oldData, _ := json.Marshal(current)
+	newData, _ := json.Marshal(updated)
+	pdata, err := patch.CreateMergePatch(oldData, newData)
+	if err != nil {
+		return agentError("failed to create configuration status patch: %v", err)
+	}
+
+	w.Debug("patching status of configuration %s with %v...", crd.Name, string(pdata))
+
+	ptype := pkgtypes.MergePatchType
+	if _, err := w.resmgrCli.ResourceManagerConfigs(opts.configNs).Patch(crd.Name, ptype, pdata, "status"); err != nil {
+		return agentError("failed to patch ResourceManagerConfig CRD %q: %v", crd.Name, err)
+	}
+
+	return nil
+}
+
 // PatchAdjustmentStatus updates the node status for adjustment updates.
 func (w *watcher) PatchAdjustmentStatus(status *resmgrStatus) error {
 	errors := status.errors
@@ -241,7 +327,7 @@ func (w *watcher) patchAdjustment(adjust *resmgr.Adjustment, inscope bool, error
 
 // sendConfig sends the current configuration.
 func (w *watcher) sendConfig() {
-	cfg, kind := w.currentConfig.getConfig()
+	cfg, kind := w.currentConfig.markSent()
 	w.Info("pushing %s configuration to client", kind)
 	w.configChan <- cfg
 }
@@ -268,6 +354,7 @@ func (w *watcher) watch() error {
 	cfgw := newConfigMapWatch(w, opts.configMapName+".node."+nodeName, namespace(opts.configNs))
 	grpw := newConfigMapWatch(w, groupMapName(group), namespace(opts.configNs))
 	crdw := newAdjustmentCRDWatch(w, namespace(opts.configNs))
+	cfgcrdw := newConfigCRDWatch(w, namespace(opts.configNs))
 
 	w.Info("watcher running")
 	w.sendConfig()
@@ -280,6 +367,7 @@ func (w *watcher) watch() error {
 			cfgw.Stop()
 			grpw.Stop()
 			crdw.Stop()
+			cfgcrdw.Stop()
 			return nil
 
 		case e, ok := <-nodew.ResultChan():
@@ -356,6 +444,29 @@ func (w *watcher) watch() error {
 				}
 				continue
 			}
+
+		case e, ok := <-cfgcrdw.ResultChan():
+			if ok {
+				switch e.Type {
+				case k8swatch.Added, k8swatch.Modified:
+					crd := e.Object.(*resmgr.ResourceManagerConfig)
+					w.Info("ResourceManagerConfig CRD %s updated", crd.Name)
+					if w.currentConfig.setConfigCRD(crd) {
+						w.sendConfig()
+					}
+
+				case k8swatch.Deleted:
+					crd := e.Object.(*resmgr.ResourceManagerConfig)
+					w.Info("ResourceManagerConfig CRD %s deleted", crd.Name)
+					if w.currentConfig.deleteConfigCRD(crd) {
+						w.sendConfig()
+					}
+
+				case SyntheticMissing:
+					w.Info("No ResourceManagerConfig CRD(s)")
+				}
+				continue
+			}
 		}
 
 		// shouln't be necessary, but just in case avoid spinning on a closed channel
@@ -374,6 +485,7 @@ func groupMapName(group string) string {
 // newCacheConfig creates a new cachedConfig instance.
 func newCachedConfig() cachedConfig {
 	return cachedConfig{
+		crds:    resmgrConfigCRD{},
 		inscope: resmgrAdjustment{},
 		ignored: resmgrAdjustment{},
 	}
@@ -384,6 +496,59 @@ func (c *cachedConfig) getConfig() (resmgrConfig, string) {
 	c.RLock()
 	defer c.RUnlock()
 
+	cfg, kind, _ := c.selectConfig()
+	return cfg, kind
+}
+
+// markSent is a helper method for getting the config data to send and remembering it.
+func (c *cachedConfig) markSent() (resmgrConfig, string) {
+	c.Lock()
+	defer c.Unlock()
+
+	cfg, kind, crd := c.selectConfig()
+	c.sent = crd
+	return cfg, kind
+}
+
+// selectConfig picks the configuration to use with the lock held.
+//
+// Configuration custom resources take precedence over ConfigMaps. Among
+// custom resources, ones selecting this node by name take precedence over
+// ones selecting our group, which in turn take precedence over default ones.
+// If multiple custom resources qualify, the first one by name is used.
+func (c *cachedConfig) selectConfig() (resmgrConfig, string, *resmgr.ResourceManagerConfig) {
+	var node, group, dflt *resmgr.ResourceManagerConfig
+
+	names := make([]string, 0, len(c.crds))
+	for name := range c.crds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		crd := c.crds[name]
+		switch {
+		case crd.Spec.IsNodeSelected(nodeName):
+			if node == nil {
+				node = crd
+			}
+		case crd.Spec.IsGroupSelected(c.group):
+			if group == nil {
+				group = crd
+			}
+		case crd.Spec.IsDefault():
+			if dflt == nil {
+				dflt = crd
+			}
+		}
+	}
+
+	for _, crd := range []*resmgr.ResourceManagerConfig{node, group, dflt} {
+		if crd != nil {
+			return resmgrConfig(crd.Spec.ConfigData()), "ResourceManagerConfig " + crd.Name, crd
+		}
+	}
+
 	var cfg *resmgrConfig
 	var kind string
 
@@ -406,7 +571,7 @@ func (c *cachedConfig) getConfig() (resmgrConfig, string) {
 		cfg = &resmgrConfig{}
 	}
 
-	return *cfg, kind
+	return *cfg, kind, nil
 }
 
 // getAdjustment is a helper method for getting a copy of external adjustments
@@ -442,7 +607,43 @@ func (c *cachedConfig) setGroup(group string, data *map[string]string) bool {
 
 	c.groupCfg = (*resmgrConfig)(data)
 	c.group = group
-	return c.nodeCfg == nil
+	return c.nodeCfg == nil || len(c.crds) > 0
+}
+
+// setConfigCRD is a helper method for updating configuration custom resources
+func (c *cachedConfig) setConfigCRD(crd *resmgr.ResourceManagerConfig) bool {
+	c.Lock()
+	defer c.Unlock()
+
+	// filter out updates without any Spec changes (Status updates)
+	old, ok := c.crds[crd.Name]
+	c.crds[crd.Name] = crd
+
+	return !ok || old.Generation != crd.Generation
+}
+
+// deleteConfigCRD is a helper method for deleting configuration custom resources
+func (c *cachedConfig) deleteConfigCRD(crd *resmgr.ResourceManagerConfig) bool {
+	c.Lock()
+	defer c.Unlock()
+
+	if _, ok := c.crds[crd.Name]; !ok {
+		return false
+	}
+	delete(c.crds, crd.Name)
+	return true
+}
+
+// getConfigCRDs returns the custom resource of the last sent configuration and all custom resources.
+func (c *cachedConfig) getConfigCRDs() (*resmgr.ResourceManagerConfig, []*resmgr.ResourceManagerConfig) {
+	c.RLock()
+	defer c.RUnlock()
+
+	crds := make([]*resmgr.ResourceManagerConfig, 0, len(c.crds))
+	for _, crd := range c.crds {
+		crds = append(crds, crd)
+	}
+	return c.sent, crds
 }
 
 // setAdjustment is a helper method for updating external adjustments
@@ -0,0 +1,270 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/intel/cri-resource-manager/pkg/apis/resmgr/generated/clientset/versioned/fake"
+	resmgr "github.com/intel/cri-resource-manager/pkg/apis/resmgr/v1alpha1"
+	"github.com/intel/cri-resource-manager/pkg/log"
+)
+
+// setNodeName sets the name of the node for the duration of a test.
+func setNodeName(t *testing.T, name string) {
+	saved := nodeName
+	t.Cleanup(func() { nodeName = saved })
+	nodeName = name
+}
+
+// newConfigCRD creates a configuration custom resource with the given selectors and policy.
+func newConfigCRD(name string, generation int64, nodes, groups []string, policy string) *resmgr.ResourceManagerConfig {
+	return &resmgr.ResourceManagerConfig{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:       name,
+			Namespace:  opts.configNs,
+			Generation: generation,
+		},
+		Spec: resmgr.ResourceManagerConfigSpec{
+			Nodes:  nodes,
+			Groups: groups,
+			Config: map[string]runtime.RawExtension{
+				"policy": {Raw: []byte(`{"Active":"` + policy + `"}`)},
+			},
+		},
+	}
+}
+
+func TestSelectConfig(t *testing.T) {
+	setNodeName(t, "node0")
+
+	nodeMap := &resmgrConfig{"policy": "Active: node-configmap"}
+	groupMap := &resmgrConfig{"policy": "Active: group-configmap"}
+
+	tcases := []struct {
+		name     string
+		nodeMap  *resmgrConfig
+		groupMap *resmgrConfig
+		group    string
+		crds     []*resmgr.ResourceManagerConfig
+		expected string
+	}{
+		{
+			name:     "node ConfigMap without custom resources",
+			nodeMap:  nodeMap,
+			groupMap: groupMap,
+			group:    "group0",
+			expected: "Active: node-configmap",
+		},
+		{
+			name:     "group ConfigMap without custom resources",
+			groupMap: groupMap,
+			group:    "group0",
+			expected: "Active: group-configmap",
+		},
+		{
+			name:    "default custom resource over ConfigMaps",
+			nodeMap: nodeMap,
+			group:   "group0",
+			crds: []*resmgr.ResourceManagerConfig{
+				newConfigCRD("default", 1, nil, nil, "default"),
+			},
+			expected: `{"Active":"default"}`,
+		},
+		{
+			name:  "group custom resource over default one",
+			group: "group0",
+			crds: []*resmgr.ResourceManagerConfig{
+				newConfigCRD("default", 1, nil, nil, "default"),
+				newConfigCRD("group", 1, nil, []string{"group1", "group0"}, "group"),
+			},
+			expected: `{"Active":"group"}`,
+		},
+		{
+			name:  "custom resource for another group ignored",
+			group: "group0",
+			crds: []*resmgr.ResourceManagerConfig{
+				newConfigCRD("default", 1, nil, nil, "default"),
+				newConfigCRD("group", 1, nil, []string{"group1"}, "group"),
+			},
+			expected: `{"Active":"default"}`,
+		},
+		{
+			name:  "node custom resource over group one",
+			group: "group0",
+			crds: []*resmgr.ResourceManagerConfig{
+				newConfigCRD("default", 1, nil, nil, "default"),
+				newConfigCRD("group", 1, nil, []string{"group0"}, "group"),
+				newConfigCRD("node", 1, []string{"node0"}, []string{"group1"}, "node"),
+			},
+			expected: `{"Active":"node"}`,
+		},
+		{
+			name: "first of several node custom resources by name",
+			crds: []*resmgr.ResourceManagerConfig{
+				newConfigCRD("node-b", 1, []string{"node0"}, nil, "node-b"),
+				newConfigCRD("node-a", 1, []string{"node1", "node0"}, nil, "node-a"),
+			},
+			expected: `{"Active":"node-a"}`,
+		},
+		{
+			name:     "no configuration",
+			expected: "",
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newCachedConfig()
+			c.nodeCfg = tc.nodeMap
+			c.groupCfg = tc.groupMap
+			c.group = tc.group
+			for _, crd := range tc.crds {
+				c.setConfigCRD(crd)
+			}
+			cfg, _ := c.getConfig()
+			if policy := cfg["policy"]; policy != tc.expected {
+				t.Errorf("expected policy configuration %q, got %q", tc.expected, policy)
+			}
+		})
+	}
+}
+
+func TestSetConfigCRD(t *testing.T) {
+	c := newCachedConfig()
+	crd := newConfigCRD("default", 1, nil, nil, "default")
+
+	if !c.setConfigCRD(crd) {
+		t.Errorf("expected new custom resource to trigger an update")
+	}
+	status := crd.DeepCopy()
+	status.Status.Nodes = map[string]resmgr.ConfigNodeStatus{"node0": {Accepted: true, Revision: 1}}
+	if c.setConfigCRD(status) {
+		t.Errorf("expected status-only change not to trigger an update")
+	}
+	if !c.setConfigCRD(newConfigCRD("default", 2, nil, nil, "static")) {
+		t.Errorf("expected spec change to trigger an update")
+	}
+	if !c.deleteConfigCRD(crd) || c.deleteConfigCRD(crd) {
+		t.Errorf("expected only deleting a known custom resource to trigger an update")
+	}
+}
+
+func TestPatchConfigStatus(t *testing.T) {
+	setNodeName(t, "node0")
+
+	stale := newConfigCRD("stale", 1, []string{"node1", "node0"}, nil, "stale")
+	stale.Status.Nodes = map[string]resmgr.ConfigNodeStatus{
+		"node0": {Accepted: true, Revision: 1},
+		"node1": {Accepted: true, Revision: 1},
+	}
+	crds := []*resmgr.ResourceManagerConfig{
+		newConfigCRD("default", 1, nil, nil, "default"),
+		newConfigCRD("node", 3, []string{"node0"}, nil, "node"),
+		stale,
+	}
+	objects := []runtime.Object{}
+	for _, crd := range crds {
+		objects = append(objects, crd)
+	}
+	cli := fake.NewSimpleClientset(objects...).CriresmgrV1alpha1()
+
+	w := &watcher{
+		Logger:        log.NewLogger("watcher"),
+		resmgrCli:     cli,
+		currentConfig: newCachedConfig(),
+	}
+	for _, crd := range crds {
+		w.currentConfig.setConfigCRD(crd)
+	}
+	w.currentConfig.markSent()
+
+	// getStatus returns the status of the given custom resource on all nodes.
+	getStatus := func(name string) map[string]resmgr.ConfigNodeStatus {
+		crd, err := cli.ResourceManagerConfigs(opts.configNs).Get(name, meta_v1.GetOptions{})
+		if err != nil {
+			t.Fatalf("failed to get custom resource %s: %v", name, err)
+		}
+		return crd.Status.Nodes
+	}
+
+	tcases := []struct {
+		name     string
+		status   *resmgrStatus
+		expected resmgr.ConfigNodeStatus
+	}{
+		{
+			name: "rejected configuration with validation errors",
+			status: &resmgrStatus{
+				config:  true,
+				request: fmt.Errorf("failed to apply configuration"),
+				validation: []resmgr.ConfigValidationError{
+					{Module: "policy", Error: "unknown policy"},
+					{Error: "invalid configuration"},
+				},
+			},
+			expected: resmgr.ConfigNodeStatus{
+				Revision: 3,
+				Errors: []resmgr.ConfigValidationError{
+					{Module: "policy", Error: "unknown policy"},
+					{Error: "invalid configuration"},
+				},
+			},
+		},
+		{
+			name: "rejected configuration without validation errors",
+			status: &resmgrStatus{
+				config:  true,
+				request: fmt.Errorf("failed to apply configuration"),
+			},
+			expected: resmgr.ConfigNodeStatus{
+				Revision: 3,
+				Errors: []resmgr.ConfigValidationError{
+					{Error: "failed to apply configuration"},
+				},
+			},
+		},
+		{
+			name:   "accepted configuration",
+			status: &resmgrStatus{config: true},
+			expected: resmgr.ConfigNodeStatus{
+				Accepted: true,
+				Revision: 3,
+			},
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := w.UpdateStatus(tc.status); err != nil {
+				t.Fatalf("failed to update status: %v", err)
+			}
+			expected := map[string]resmgr.ConfigNodeStatus{"node0": tc.expected}
+			if status := getStatus("node"); !reflect.DeepEqual(status, expected) {
+				t.Errorf("expected status %+v, got %+v", expected, status)
+			}
+			if status := getStatus("default"); len(status) != 0 {
+				t.Errorf("expected no status for unused configuration, got %+v", status)
+			}
+			expected = map[string]resmgr.ConfigNodeStatus{"node1": {Accepted: true, Revision: 1}}
+			if status := getStatus("stale"); !reflect.DeepEqual(status, expected) {
+				t.Errorf("expected stale status of the node removed, got %+v", status)
+			}
+		})
+	}
+}
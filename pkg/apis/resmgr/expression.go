@@ -44,6 +44,7 @@ const (
 	KeyQOSClass  = "qosclass"
 	KeyLabels    = "labels"
 	KeyTags      = "tags"
+	KeyImage     = "image"
 )
 
 // Operator defines the possible operators for an Expression.
@@ -30,6 +30,10 @@ func (c *FakeCriresmgrV1alpha1) Adjustments(namespace string) v1alpha1.Adjustmen
 	return &FakeAdjustments{c, namespace}
 }
 
+func (c *FakeCriresmgrV1alpha1) ResourceManagerConfigs(namespace string) v1alpha1.ResourceManagerConfigInterface {
+	return &FakeResourceManagerConfigs{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeCriresmgrV1alpha1) RESTClient() rest.Interface {
@@ -25,6 +25,7 @@ import (
 type CriresmgrV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	AdjustmentsGetter
+	ResourceManagerConfigsGetter
 }
 
 // CriresmgrV1alpha1Client is used to interact with features provided by the criresmgr.intel.com group.
@@ -36,6 +37,10 @@ func (c *CriresmgrV1alpha1Client) Adjustments(namespace string) AdjustmentInterf
 	return newAdjustments(c, namespace)
 }
 
+func (c *CriresmgrV1alpha1Client) ResourceManagerConfigs(namespace string) ResourceManagerConfigInterface {
+	return newResourceManagerConfigs(c, namespace)
+}
+
 // NewForConfig creates a new CriresmgrV1alpha1Client for the given config.
 func NewForConfig(c *rest.Config) (*CriresmgrV1alpha1Client, error) {
 	config := *c
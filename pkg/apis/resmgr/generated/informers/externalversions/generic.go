@@ -53,6 +53,8 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	// Group=criresmgr.intel.com, Version=v1alpha1
 	case v1alpha1.SchemeGroupVersion.WithResource("adjustments"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Criresmgr().V1alpha1().Adjustments().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("resourcemanagerconfigs"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Criresmgr().V1alpha1().ResourceManagerConfigs().Informer()}, nil
 
 	}
 
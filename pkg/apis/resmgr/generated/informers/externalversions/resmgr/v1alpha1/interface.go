@@ -24,6 +24,8 @@ import (
 type Interface interface {
 	// Adjustments returns a AdjustmentInformer.
 	Adjustments() AdjustmentInformer
+	// ResourceManagerConfigs returns a ResourceManagerConfigInformer.
+	ResourceManagerConfigs() ResourceManagerConfigInformer
 }
 
 type version struct {
@@ -41,3 +43,8 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 func (v *version) Adjustments() AdjustmentInformer {
 	return &adjustmentInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// ResourceManagerConfigs returns a ResourceManagerConfigInformer.
+func (v *version) ResourceManagerConfigs() ResourceManagerConfigInformer {
+	return &resourceManagerConfigInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
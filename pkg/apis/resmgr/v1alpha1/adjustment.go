@@ -20,6 +20,7 @@ import (
 
 	resmgr "github.com/intel/cri-resource-manager/pkg/apis/resmgr"
 	corev1 "k8s.io/api/core/v1"
+	resapi "k8s.io/apimachinery/pkg/api/resource"
 )
 
 // HasSameVersion checks if the policy has the same version as the other.
@@ -109,6 +110,8 @@ func (spec *AdjustmentSpec) Compare(other *AdjustmentSpec) bool {
 		return false
 	case spec.ToptierLimit != nil && spec.ToptierLimit.Value() != other.ToptierLimit.Value():
 		return false
+	case !spec.MemoryQoS.Compare(other.MemoryQoS):
+		return false
 	}
 	return true
 }
@@ -121,6 +124,9 @@ func (spec *AdjustmentSpec) Verify() error {
 	if err := spec.verifyToptierLimit(); err != nil {
 		return err
 	}
+	if err := spec.verifyMemoryQoS(); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -236,6 +242,25 @@ func (spec *AdjustmentSpec) verifyToptierLimit() error {
 	return nil
 }
 
+// verifyMemoryQoS verifies the memory QoS settings of this spec.
+func (spec *AdjustmentSpec) verifyMemoryQoS() error {
+	if spec.MemoryQoS == nil {
+		return nil
+	}
+
+	for name, qty := range map[string]*resapi.Quantity{
+		"min":  spec.MemoryQoS.Min,
+		"low":  spec.MemoryQoS.Low,
+		"high": spec.MemoryQoS.High,
+	} {
+		if qty != nil && qty.Value() < 0 {
+			return apiError("invalid MemoryQoS %s %v", name, qty.Value())
+		}
+	}
+
+	return nil
+}
+
 // IsNodeInScope tests if the node is within this scope.
 func (scope *AdjustmentScope) IsNodeInScope(node string) bool {
 	if len(scope.Nodes) == 0 {
@@ -322,6 +347,26 @@ func (c *Classes) Compare(o *Classes) bool {
 	return *c.RDT == *o.RDT && *c.BlockIO == *o.BlockIO
 }
 
+// Compare checks if the memory QoS settings are identical to another one.
+func (q *MemoryQoS) Compare(o *MemoryQoS) bool {
+	switch {
+	case q == nil && o == nil:
+		return true
+	case q != nil && o == nil, q == nil && o != nil:
+		return false
+	}
+	return compareQuantities(q.Min, o.Min) && compareQuantities(q.Low, o.Low) &&
+		compareQuantities(q.High, o.High)
+}
+
+// compareQuantities checks if two optional quantities are identical.
+func compareQuantities(a, b *resapi.Quantity) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Value() == b.Value()
+}
+
 // apiError returns a format error specific to this API.
 func apiError(format string, args ...interface{}) error {
 	return fmt.Errorf("adjustment API error: "+format, args...)
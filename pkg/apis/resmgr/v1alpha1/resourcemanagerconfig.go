@@ -0,0 +1,64 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+// HasSameVersion checks if the configuration has the same version as the other.
+func (c *ResourceManagerConfig) HasSameVersion(o *ResourceManagerConfig) bool {
+	if c.ResourceVersion != o.ResourceVersion {
+		return false
+	}
+	if c.Generation != o.Generation {
+		return false
+	}
+	return true
+}
+
+// IsDefault tests if this spec is a default one, not selecting nodes or groups.
+func (spec *ResourceManagerConfigSpec) IsDefault() bool {
+	return len(spec.Nodes) == 0 && len(spec.Groups) == 0
+}
+
+// IsNodeSelected tests if this spec explicitly selects the given node.
+func (spec *ResourceManagerConfigSpec) IsNodeSelected(node string) bool {
+	for _, n := range spec.Nodes {
+		if n == node {
+			return true
+		}
+	}
+	return false
+}
+
+// IsGroupSelected tests if this spec explicitly selects the given group.
+func (spec *ResourceManagerConfigSpec) IsGroupSelected(group string) bool {
+	if group == "" {
+		return false
+	}
+	for _, g := range spec.Groups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+// ConfigData returns the configuration in the form expected by cri-resmgr.
+func (spec *ResourceManagerConfigSpec) ConfigData() map[string]string {
+	data := make(map[string]string, len(spec.Config))
+	for key, value := range spec.Config {
+		// JSON is valid YAML, so we can pass the raw data as such.
+		data[key] = string(value.Raw)
+	}
+	return data
+}
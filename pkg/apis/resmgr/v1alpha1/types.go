@@ -18,6 +18,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	resapi "k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 
 	resmgr "github.com/intel/cri-resource-manager/pkg/apis/resmgr"
 )
@@ -29,6 +30,11 @@ const (
 	Plural    string = "adjustments"            // Plural is Kind in plural form.
 	Singular  string = "adjustment"             // Singular is Kind in singular form.
 	Name      string = Plural + "." + GroupName // Name is the full name of our CRD.
+
+	ConfigKind     string = "ResourceManagerConfig"        // ConfigKind is the object kind of our config CRD.
+	ConfigPlural   string = "resourcemanagerconfigs"       // ConfigPlural is ConfigKind in plural form.
+	ConfigSingular string = "resourcemanagerconfig"        // ConfigSingular is ConfigKind in singular form.
+	ConfigName     string = ConfigPlural + "." + GroupName // ConfigName is the full name of our config CRD.
 )
 
 // +genclient
@@ -49,6 +55,7 @@ type AdjustmentSpec struct {
 	Resources    *corev1.ResourceRequirements `json:"resources"`
 	Classes      *Classes                     `json:"classes"`
 	ToptierLimit *resapi.Quantity             `json:"toptierLimit"`
+	MemoryQoS    *MemoryQoS                   `json:"memoryQoS"`
 }
 
 // AdjustmentStatus represents the status of applying an adjustment.
@@ -67,6 +74,13 @@ type AdjustmentScope struct {
 	Containers []*resmgr.Expression `json:"containers"`
 }
 
+// MemoryQoS defines cgroup v2 memory protection and throttling overrides.
+type MemoryQoS struct {
+	Min  *resapi.Quantity `json:"min"`
+	Low  *resapi.Quantity `json:"low"`
+	High *resapi.Quantity `json:"high"`
+}
+
 // Classes defines RDT and BlockIO class assignments.
 type Classes struct {
 	BlockIO *string `json:"blockio"`
@@ -82,3 +96,58 @@ type AdjustmentList struct {
 
 	Items []Adjustment `json:"items"`
 }
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ResourceManagerConfig is a CRD used to configure cri-resmgr on a set of nodes.
+type ResourceManagerConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ResourceManagerConfigSpec   `json:"spec"`
+	Status ResourceManagerConfigStatus `json:"status"`
+}
+
+// ResourceManagerConfigSpec specifies the configuration and the nodes it applies to.
+type ResourceManagerConfigSpec struct {
+	// Nodes lists the names of the nodes this configuration applies to.
+	Nodes []string `json:"nodes,omitempty"`
+	// Groups lists the configuration groups this configuration applies to.
+	Groups []string `json:"groups,omitempty"`
+	// Config is the configuration data, one entry per top-level module.
+	Config map[string]runtime.RawExtension `json:"config"`
+}
+
+// ResourceManagerConfigStatus represents the status of applying a configuration.
+type ResourceManagerConfigStatus struct {
+	Nodes map[string]ConfigNodeStatus `json:"nodes"`
+}
+
+// ConfigNodeStatus represents the status of a configuration on a node.
+type ConfigNodeStatus struct {
+	// Accepted is true if the node accepted the configuration.
+	Accepted bool `json:"accepted"`
+	// Revision is the generation of the configuration the status is for.
+	Revision int64 `json:"revision"`
+	// Errors lists the problems, if any, found in the configuration.
+	Errors []ConfigValidationError `json:"errors,omitempty"`
+}
+
+// ConfigValidationError describes a problem found in a configuration.
+type ConfigValidationError struct {
+	// Module is the configuration module the problem was found in, if known.
+	Module string `json:"module,omitempty"`
+	// Error describes the problem.
+	Error string `json:"error"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ResourceManagerConfigList is a list of ResourceManagerConfigs.
+type ResourceManagerConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ResourceManagerConfig `json:"items"`
+}
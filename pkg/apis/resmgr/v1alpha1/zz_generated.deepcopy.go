@@ -165,6 +165,11 @@ func (in *AdjustmentSpec) DeepCopyInto(out *AdjustmentSpec) {
 		x := (*in).DeepCopy()
 		*out = &x
 	}
+	if in.MemoryQoS != nil {
+		in, out := &in.MemoryQoS, &out.MemoryQoS
+		*out = new(MemoryQoS)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -226,3 +231,188 @@ func (in *Classes) DeepCopy() *Classes {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigNodeStatus) DeepCopyInto(out *ConfigNodeStatus) {
+	*out = *in
+	if in.Errors != nil {
+		in, out := &in.Errors, &out.Errors
+		*out = make([]ConfigValidationError, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigNodeStatus.
+func (in *ConfigNodeStatus) DeepCopy() *ConfigNodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigNodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigValidationError) DeepCopyInto(out *ConfigValidationError) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigValidationError.
+func (in *ConfigValidationError) DeepCopy() *ConfigValidationError {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigValidationError)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MemoryQoS) DeepCopyInto(out *MemoryQoS) {
+	*out = *in
+	if in.Min != nil {
+		in, out := &in.Min, &out.Min
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Low != nil {
+		in, out := &in.Low, &out.Low
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.High != nil {
+		in, out := &in.High, &out.High
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MemoryQoS.
+func (in *MemoryQoS) DeepCopy() *MemoryQoS {
+	if in == nil {
+		return nil
+	}
+	out := new(MemoryQoS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceManagerConfig) DeepCopyInto(out *ResourceManagerConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceManagerConfig.
+func (in *ResourceManagerConfig) DeepCopy() *ResourceManagerConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceManagerConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ResourceManagerConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceManagerConfigList) DeepCopyInto(out *ResourceManagerConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ResourceManagerConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceManagerConfigList.
+func (in *ResourceManagerConfigList) DeepCopy() *ResourceManagerConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceManagerConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ResourceManagerConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceManagerConfigSpec) DeepCopyInto(out *ResourceManagerConfigSpec) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(map[string]runtime.RawExtension, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceManagerConfigSpec.
+func (in *ResourceManagerConfigSpec) DeepCopy() *ResourceManagerConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceManagerConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceManagerConfigStatus) DeepCopyInto(out *ResourceManagerConfigStatus) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make(map[string]ConfigNodeStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceManagerConfigStatus.
+func (in *ResourceManagerConfigStatus) DeepCopy() *ResourceManagerConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceManagerConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -24,6 +24,7 @@ import (
 	"sort"
 	"strings"
 	"syscall"
+	"time"
 
 	"golang.org/x/sys/unix"
 
@@ -119,6 +120,34 @@ func UpdateOciConfig(ignoreErrors bool) error {
 		// yet.
 		staticOciBlockIO[class] = ociBlockIO
 	}
+	if err := applyCostQoS(); err != nil {
+		if ignoreErrors {
+			log.Error("ignoring: %v", err)
+		} else {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyCostQoS writes device cost QoS parameters of all classes to the cgroup v2 root.
+func applyCostQoS() error {
+	costQoS := cgroups.OciDeviceCostQoSs{}
+	for _, class := range GetClasses() {
+		for _, devQoS := range class.Parameters.CostQoSDevice {
+			costQoS.Update(devQoS.Major, devQoS.Minor, devQoS.Params)
+		}
+	}
+	if len(costQoS) == 0 {
+		return nil
+	}
+	if cgroups.DetectSystemCgroupVersion() != 2 {
+		log.Warn("ignoring CostQoS: cgroup v2 required")
+		return nil
+	}
+	if err := cgroups.SetBlkioCostQoS(cgroups.GetMountDir(), costQoS); err != nil {
+		return blockioError("failed to configure io.cost.qos: %w", err)
+	}
 	return nil
 }
 
@@ -196,6 +225,8 @@ func devicesParametersToOci(dps []DevicesParameters, currentIOSchedulers map[str
 		errors = multierror.Append(errors, err)
 		throttleWriteIOPS, err = parseAndValidateInt64("ThrottleWriteIOPS", dp.ThrottleWriteIOPS, -1, 0, -1)
 		errors = multierror.Append(errors, err)
+		latencyTarget, err := parseAndValidateLatency("LatencyTarget", dp.LatencyTarget)
+		errors = multierror.Append(errors, err)
 		if dp.Devices == nil {
 			if weight > -1 {
 				oci.Weight = weight
@@ -204,6 +235,10 @@ func devicesParametersToOci(dps []DevicesParameters, currentIOSchedulers map[str
 				errors = multierror.Append(errors, fmt.Errorf("ignoring throttling (rbps=%#v wbps=%#v riops=%#v wiops=%#v): Devices not listed",
 					dp.ThrottleReadBps, dp.ThrottleWriteBps, dp.ThrottleReadIOPS, dp.ThrottleWriteIOPS))
 			}
+			if latencyTarget > -1 || dp.CostQoS != "" {
+				errors = multierror.Append(errors, fmt.Errorf("ignoring latency target %#v and cost QoS %#v: Devices not listed",
+					dp.LatencyTarget, dp.CostQoS))
+			}
 		} else {
 			blockDevices, err := currentPlatform.configurableBlockDevices(dp.Devices)
 			if err != nil {
@@ -236,6 +271,12 @@ func devicesParametersToOci(dps []DevicesParameters, currentIOSchedulers map[str
 				if throttleWriteIOPS != -1 {
 					oci.ThrottleWriteIOPSDevice.Update(blockDeviceInfo.Major, blockDeviceInfo.Minor, throttleWriteIOPS)
 				}
+				if latencyTarget != -1 {
+					oci.LatencyTargetDevice.Update(blockDeviceInfo.Major, blockDeviceInfo.Minor, latencyTarget)
+				}
+				if dp.CostQoS != "" {
+					oci.CostQoSDevice.Update(blockDeviceInfo.Major, blockDeviceInfo.Minor, dp.CostQoS)
+				}
 			}
 		}
 	}
@@ -263,6 +304,21 @@ func parseAndValidateInt64(fieldName string, fieldContent string,
 	return value, nil
 }
 
+// parseAndValidateLatency parses a latency target, like "10ms", into microseconds.
+func parseAndValidateLatency(fieldName string, fieldContent string) (int64, error) {
+	if fieldContent == "" {
+		return -1, nil
+	}
+	latency, err := time.ParseDuration(fieldContent)
+	if err != nil {
+		return -1, fmt.Errorf("syntax error in %#v (%#v)", fieldName, fieldContent)
+	}
+	if latency < 0 {
+		return -1, fmt.Errorf("value of %#v (%#v) smaller than minimum (0)", fieldName, fieldContent)
+	}
+	return latency.Microseconds(), nil
+}
+
 // platformInterface includes functions that access the system. Enables mocking the system.
 type platformInterface interface {
 	configurableBlockDevices(devWildcards []string) ([]BlockDeviceInfo, error)
@@ -237,6 +237,48 @@ func TestDevicesParametersToOci(t *testing.T) {
 				"(-2) smaller than minimum",
 			},
 		},
+		{
+			name: "cgroup v2 parameters",
+			dps: []DevicesParameters{
+				{
+					Devices:       []string{"/dev/sda", "/dev/sdb"},
+					LatencyTarget: "10ms",
+					CostQoS:       "enable=1 ctrl=auto",
+				},
+				{
+					Devices:       []string{"/dev/sdb"},
+					LatencyTarget: "500us",
+				},
+			},
+			expectedOci: &cgroups.OciBlockIOParameters{
+				Weight: -1,
+				LatencyTargetDevice: cgroups.OciDeviceRates{
+					{Major: 11, Minor: 12, Rate: 10000},
+					{Major: 21, Minor: 22, Rate: 500},
+				},
+				CostQoSDevice: cgroups.OciDeviceCostQoSs{
+					{Major: 11, Minor: 12, Params: "enable=1 ctrl=auto"},
+					{Major: 21, Minor: 22, Params: "enable=1 ctrl=auto"},
+				},
+			},
+		},
+		{
+			name: "invalid latency target, cgroup v2 parameters without Devices",
+			dps: []DevicesParameters{
+				{
+					Devices:       []string{"/dev/sda"},
+					LatencyTarget: "10",
+				},
+				{
+					LatencyTarget: "1ms",
+				},
+			},
+			expectedErrorCount: 2,
+			expectedErrorSubstrings: []string{
+				"syntax error in \"LatencyTarget\"",
+				"Devices not listed",
+			},
+		},
 		{
 			name: "throttling without listing Devices",
 			dps: []DevicesParameters{
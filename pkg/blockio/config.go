@@ -27,6 +27,7 @@ type options struct {
 }
 
 // DevicesParameters defines Block IO parameters for a set of devices.
+// LatencyTarget and CostQoS are only effective on cgroup v2.
 type DevicesParameters struct {
 	Devices           []string `json:",omitempty"`
 	ThrottleReadBps   string   `json:",omitempty"`
@@ -34,6 +35,11 @@ type DevicesParameters struct {
 	ThrottleReadIOPS  string   `json:",omitempty"`
 	ThrottleWriteIOPS string   `json:",omitempty"`
 	Weight            string   `json:",omitempty"`
+	// LatencyTarget is the io.latency target of the devices, for instance "10ms".
+	LatencyTarget string `json:",omitempty"`
+	// CostQoS is written as such to io.cost.qos of the devices in the cgroup v2
+	// root, for instance "enable=1 ctrl=auto". It affects all classes.
+	CostQoS string `json:",omitempty"`
 }
 
 // Currently active set of "raw" options
@@ -42,6 +42,12 @@ var blkioThrottleWriteBpsFiles = []string{"blkio.throttle.write_bps_device"}
 var blkioThrottleReadIOPSFiles = []string{"blkio.throttle.read_iops_device"}
 var blkioThrottleWriteIOPSFiles = []string{"blkio.throttle.write_iops_device"}
 
+// cgroups v2 io parameter filenames.
+var ioWeightFiles = []string{"io.bfq.weight", "io.weight"}
+var ioMaxFiles = []string{"io.max"}
+var ioLatencyFiles = []string{"io.latency"}
+var ioCostQoSFiles = []string{"io.cost.qos"}
+
 // OciBlockIOParameters contains OCI standard configuration of cgroups blkio parameters.
 //
 // Effects of Weight and Rate values in SetBlkioParameters():
@@ -51,6 +57,11 @@ var blkioThrottleWriteIOPSFiles = []string{"blkio.throttle.write_iops_device"}
 //	  -1  |  Do not write to cgroups, value is missing
 //	   0  |  Write to cgroups, will remove the setting as specified in cgroups blkio interface
 //	other |  Write to cgroups, sets the value
+//
+// LatencyTargetDevice and CostQoSDevice are cgroup v2 only parameters.
+// Latency targets are given in microseconds and written to io.latency.
+// Cost QoS parameters are device-global and written to io.cost.qos in
+// the cgroup v2 root by SetBlkioCostQoS().
 type OciBlockIOParameters struct {
 	Weight                  int64
 	WeightDevice            OciDeviceWeights
@@ -58,6 +69,8 @@ type OciBlockIOParameters struct {
 	ThrottleWriteBpsDevice  OciDeviceRates
 	ThrottleReadIOPSDevice  OciDeviceRates
 	ThrottleWriteIOPSDevice OciDeviceRates
+	LatencyTargetDevice     OciDeviceRates
+	CostQoSDevice           OciDeviceCostQoSs
 }
 
 // OciDeviceWeight contains values for
@@ -79,6 +92,28 @@ type OciDeviceRate struct {
 	Rate  int64
 }
 
+// OciDeviceCostQoS contains io.cost.qos parameters for a device,
+// for instance "enable=1 ctrl=auto rpct=95.00 rlat=5000".
+type OciDeviceCostQoS struct {
+	Major  int64
+	Minor  int64
+	Params string
+}
+
+// OciDeviceCostQoSs contains io.cost.qos parameters for devices
+type OciDeviceCostQoSs []OciDeviceCostQoS
+
+// Update updates device cost QoS parameters, or appends them if not found.
+func (q *OciDeviceCostQoSs) Update(maj, min int64, params string) {
+	for index, devQoS := range *q {
+		if devQoS.Major == maj && devQoS.Minor == min {
+			(*q)[index].Params = params
+			return
+		}
+	}
+	*q = append(*q, OciDeviceCostQoS{Major: maj, Minor: min, Params: params})
+}
+
 // OciDeviceWeights contains weights for devices
 type OciDeviceWeights []OciDeviceWeight
 
@@ -181,6 +216,7 @@ func ResetBlkioParameters(cgroupsDir string, blockIO OciBlockIOParameters) error
 	newBlockIO.ThrottleWriteBpsDevice = resetDevRates(oldBlockIO.ThrottleWriteBpsDevice, blockIO.ThrottleWriteBpsDevice)
 	newBlockIO.ThrottleReadIOPSDevice = resetDevRates(oldBlockIO.ThrottleReadIOPSDevice, blockIO.ThrottleReadIOPSDevice)
 	newBlockIO.ThrottleWriteIOPSDevice = resetDevRates(oldBlockIO.ThrottleWriteIOPSDevice, blockIO.ThrottleWriteIOPSDevice)
+	newBlockIO.LatencyTargetDevice = resetDevRates(oldBlockIO.LatencyTargetDevice, blockIO.LatencyTargetDevice)
 	errors = multierror.Append(errors, SetBlkioParameters(cgroupsDir, newBlockIO))
 	return errors.ErrorOrNil()
 }
@@ -203,6 +239,9 @@ func resetDevRates(old, wanted []OciDeviceRate) []OciDeviceRate {
 
 // GetBlkioParameters returns OCI BlockIO parameters from files in cgroups blkio controller directory.
 func GetBlkioParameters(cgroupsDir string) (OciBlockIOParameters, error) {
+	if currentPlatform.isUnifiedDir(cgroupsDir) {
+		return getIOParameters(cgroupsDir)
+	}
 	var errors *multierror.Error
 	blockIO := NewOciBlockIOParameters()
 	content, err := readFromFileInDir(cgroupsDir, blkioWeightFiles)
@@ -281,6 +320,12 @@ func readFromFileInDir(baseDir string, filenames []string) (string, error) {
 // SetBlkioParameters writes OCI BlockIO parameters to files in cgroups blkio contoller directory.
 func SetBlkioParameters(cgroupsDir string, blockIO OciBlockIOParameters) error {
 	log.Debug("configuring cgroups blkio controller in directory %#v with parameters %+v", cgroupsDir, blockIO)
+	if currentPlatform.isUnifiedDir(cgroupsDir) {
+		return setIOParameters(cgroupsDir, blockIO)
+	}
+	if len(blockIO.LatencyTargetDevice) > 0 {
+		log.Warn("ignoring I/O latency targets in %#v: cgroup v2 required", cgroupsDir)
+	}
 	var errors *multierror.Error
 	if blockIO.Weight >= 0 {
 		errors = multierror.Append(errors, writeToFileInDir(cgroupsDir, blkioWeightFiles, strconv.FormatInt(blockIO.Weight, 10)))
@@ -332,6 +377,7 @@ func writeToFileInDir(baseDir string, filenames []string, content string) error
 type platformInterface interface {
 	readFromFile(filename string) (string, error)
 	writeToFile(filename string, content string) error
+	isUnifiedDir(dirname string) bool
 }
 
 // defaultPlatform versions of platformInterface functions access the underlying system.
@@ -356,3 +402,9 @@ func (dpm defaultPlatform) writeToFile(filename string, content string) error {
 	_, err = fmt.Fprintf(f, content)
 	return err
 }
+
+// isUnifiedDir returns true if the directory belongs to a cgroup v2 (unified) hierarchy.
+func (dpm defaultPlatform) isUnifiedDir(dirname string) bool {
+	_, err := os.Stat(filepath.Join(dirname, Controllers))
+	return err == nil
+}
@@ -0,0 +1,241 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cgroups
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+const (
+	// ioDefaultWeight is the kernel default for both io.weight and io.bfq.weight.
+	ioDefaultWeight = 100
+	// ioMaxValue is written to io.max to remove a throttling limit.
+	ioMaxValue = "max"
+)
+
+// blkioWeightToIOWeight converts a cgroup v1 blkio weight [10, 1000]
+// to a cgroup v2 io.weight [1, 10000].
+func blkioWeightToIOWeight(weight int64) int64 {
+	return 1 + (weight-10)*9999/990
+}
+
+// ioWeightToBlkioWeight converts a cgroup v2 io.weight [1, 10000]
+// to a cgroup v1 blkio weight [10, 1000].
+func ioWeightToBlkioWeight(weight int64) int64 {
+	return 10 + ((weight-1)*990+9999/2)/9999
+}
+
+// getIOParameters returns OCI BlockIO parameters from files in a cgroup v2 directory.
+func getIOParameters(cgroupsDir string) (OciBlockIOParameters, error) {
+	var errors *multierror.Error
+	blockIO := NewOciBlockIOParameters()
+	errors = multierror.Append(errors, readIOWeights(cgroupsDir, &blockIO))
+	content, err := readFromFileInDir(cgroupsDir, ioMaxFiles)
+	if err == nil {
+		errors = multierror.Append(errors, parseIOKeyedLines(content, map[string]OciDeviceParameters{
+			"rbps":  &blockIO.ThrottleReadBpsDevice,
+			"wbps":  &blockIO.ThrottleWriteBpsDevice,
+			"riops": &blockIO.ThrottleReadIOPSDevice,
+			"wiops": &blockIO.ThrottleWriteIOPSDevice,
+		}))
+	} else {
+		errors = multierror.Append(errors, err)
+	}
+	// io.latency is present only if the kernel supports it, treat it as optional.
+	if content, err = readFromFileInDir(cgroupsDir, ioLatencyFiles); err == nil {
+		errors = multierror.Append(errors, parseIOKeyedLines(content, map[string]OciDeviceParameters{
+			"target": &blockIO.LatencyTargetDevice,
+		}))
+	}
+	return blockIO, errors.ErrorOrNil()
+}
+
+// readIOWeights parses the default and device weights from io.bfq.weight or io.weight.
+func readIOWeights(cgroupsDir string, blockIO *OciBlockIOParameters) error {
+	var readErrors *multierror.Error
+	for _, filename := range ioWeightFiles {
+		content, err := currentPlatform.readFromFile(filepath.Join(cgroupsDir, filename))
+		if err != nil {
+			readErrors = multierror.Append(readErrors, err)
+			continue
+		}
+		// io.bfq.weight uses the same scale as cgroup v1, io.weight needs to be converted.
+		convert := func(w int64) int64 { return w }
+		if filename == "io.weight" {
+			convert = ioWeightToBlkioWeight
+		}
+		var errors *multierror.Error
+		for _, line := range strings.Split(content, "\n") {
+			if line == "" {
+				continue
+			}
+			fields := strings.Fields(line)
+			if len(fields) != 2 {
+				errors = multierror.Append(errors, fmt.Errorf("invalid line %q in %q, two fields expected", line, filename))
+				continue
+			}
+			weight, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				errors = multierror.Append(errors, fmt.Errorf("parsing weight from %q in %q failed: %w", line, filename, err))
+				continue
+			}
+			if fields[0] == "default" {
+				blockIO.Weight = convert(weight)
+				continue
+			}
+			major, minor, err := parseMajMin(fields[0])
+			if err != nil {
+				errors = multierror.Append(errors, err)
+				continue
+			}
+			blockIO.WeightDevice.Append(major, minor, convert(weight))
+		}
+		return errors.ErrorOrNil()
+	}
+	return fmt.Errorf("could not read any of files %q: %w", ioWeightFiles, readErrors.ErrorOrNil())
+}
+
+// parseIOKeyedLines parses "MAJOR:MINOR key=value..." lines, as found in
+// io.max and io.latency, appending values of known keys to params.
+// Unlimited ("max") values are skipped.
+func parseIOKeyedLines(content string, params map[string]OciDeviceParameters) error {
+	var errors *multierror.Error
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		major, minor, err := parseMajMin(fields[0])
+		if err != nil {
+			errors = multierror.Append(errors, err)
+			continue
+		}
+		for _, field := range fields[1:] {
+			keyValue := strings.SplitN(field, "=", 2)
+			if len(keyValue) != 2 {
+				errors = multierror.Append(errors, fmt.Errorf("invalid field %q in line %q, key=value expected", field, line))
+				continue
+			}
+			param, ok := params[keyValue[0]]
+			if !ok || keyValue[1] == ioMaxValue {
+				continue
+			}
+			value, err := strconv.ParseInt(keyValue[1], 10, 64)
+			if err != nil {
+				errors = multierror.Append(errors, fmt.Errorf("invalid number in %q of line %q: %w", field, line, err))
+				continue
+			}
+			param.Append(major, minor, value)
+		}
+	}
+	return errors.ErrorOrNil()
+}
+
+// parseMajMin parses device numbers from "MAJOR:MINOR".
+func parseMajMin(majMin string) (int64, int64, error) {
+	fields := strings.Split(majMin, ":")
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("invalid device %q, single colon expected", majMin)
+	}
+	major, majErr := strconv.ParseInt(fields[0], 10, 64)
+	minor, minErr := strconv.ParseInt(fields[1], 10, 64)
+	if majErr != nil || minErr != nil {
+		return 0, 0, fmt.Errorf("invalid number when parsing \"major:minor\" from %q", majMin)
+	}
+	return major, minor, nil
+}
+
+// setIOParameters writes OCI BlockIO parameters to files in a cgroup v2 directory.
+func setIOParameters(cgroupsDir string, blockIO OciBlockIOParameters) error {
+	var errors *multierror.Error
+	if blockIO.Weight >= 0 {
+		errors = multierror.Append(errors, writeIOWeight(cgroupsDir, "default", blockIO.Weight))
+	}
+	for _, weightDevice := range blockIO.WeightDevice {
+		dev := fmt.Sprintf("%d:%d", weightDevice.Major, weightDevice.Minor)
+		errors = multierror.Append(errors, writeIOWeight(cgroupsDir, dev, weightDevice.Weight))
+	}
+	for _, limit := range []struct {
+		key   string
+		rates OciDeviceRates
+	}{
+		{"rbps", blockIO.ThrottleReadBpsDevice},
+		{"wbps", blockIO.ThrottleWriteBpsDevice},
+		{"riops", blockIO.ThrottleReadIOPSDevice},
+		{"wiops", blockIO.ThrottleWriteIOPSDevice},
+	} {
+		for _, rateDevice := range limit.rates {
+			value := ioMaxValue
+			if rateDevice.Rate > 0 {
+				value = strconv.FormatInt(rateDevice.Rate, 10)
+			}
+			content := fmt.Sprintf("%d:%d %s=%s", rateDevice.Major, rateDevice.Minor, limit.key, value)
+			errors = multierror.Append(errors, writeToFileInDir(cgroupsDir, ioMaxFiles, content))
+		}
+	}
+	for _, latencyDevice := range blockIO.LatencyTargetDevice {
+		content := fmt.Sprintf("%d:%d target=%d", latencyDevice.Major, latencyDevice.Minor, latencyDevice.Rate)
+		errors = multierror.Append(errors, writeToFileInDir(cgroupsDir, ioLatencyFiles, content))
+	}
+	return errors.ErrorOrNil()
+}
+
+// writeIOWeight writes the default or a device weight, given in cgroup v1
+// scale, to both io.bfq.weight and io.weight. The former is effective for
+// devices using the BFQ scheduler, the latter for other devices, so the
+// weight is only considered failed if neither file can be written.
+// Zero weight resets the weight to the kernel default.
+func writeIOWeight(cgroupsDir, dev string, weight int64) error {
+	var errors *multierror.Error
+	written := false
+	for _, filename := range ioWeightFiles {
+		var value string
+		switch {
+		case weight == 0 && dev == "default":
+			value = strconv.Itoa(ioDefaultWeight)
+		case weight == 0:
+			value = "default"
+		case filename == "io.weight":
+			value = strconv.FormatInt(blkioWeightToIOWeight(weight), 10)
+		default:
+			value = strconv.FormatInt(weight, 10)
+		}
+		if err := currentPlatform.writeToFile(filepath.Join(cgroupsDir, filename), dev+" "+value); err != nil {
+			errors = multierror.Append(errors, err)
+			continue
+		}
+		written = true
+	}
+	if written {
+		return nil
+	}
+	return fmt.Errorf("could not write %s weight %d to any of files %q: %w", dev, weight, ioWeightFiles, errors.ErrorOrNil())
+}
+
+// SetBlkioCostQoS writes device cost QoS parameters to io.cost.qos in
+// the root of a cgroup v2 hierarchy.
+func SetBlkioCostQoS(rootDir string, costQoS OciDeviceCostQoSs) error {
+	var errors *multierror.Error
+	for _, devQoS := range costQoS {
+		content := fmt.Sprintf("%d:%d %s", devQoS.Major, devQoS.Minor, devQoS.Params)
+		errors = multierror.Append(errors, writeToFileInDir(rootDir, ioCostQoSFiles, content))
+	}
+	return errors.ErrorOrNil()
+}
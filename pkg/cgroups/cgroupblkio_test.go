@@ -381,12 +381,188 @@ func TestSetBlkioParameters(t *testing.T) {
 	}
 }
 
+// TestGetBlkioParametersV2: unit test for GetBlkioParameters() on cgroup v2
+func TestGetBlkioParametersV2(t *testing.T) {
+	tcases := []struct {
+		name                    string
+		fsContent               map[string]string
+		expectedBlockIO         *OciBlockIOParameters
+		expectedErrorCount      int
+		expectedErrorSubstrings []string
+	}{
+		{
+			name: "all parameters from bfq",
+			fsContent: map[string]string{
+				"/cg/io.bfq.weight": "default 80\n8:0 50\n",
+				"/cg/io.max":        "8:0 rbps=1000 wbps=max riops=max wiops=20\n8:16 rbps=max wbps=2000 riops=max wiops=max\n",
+				"/cg/io.latency":    "8:0 target=5000\n",
+			},
+			expectedBlockIO: &OciBlockIOParameters{
+				Weight:                  80,
+				WeightDevice:            OciDeviceWeights{{8, 0, 50}},
+				ThrottleReadBpsDevice:   OciDeviceRates{{8, 0, 1000}},
+				ThrottleWriteBpsDevice:  OciDeviceRates{{8, 16, 2000}},
+				ThrottleWriteIOPSDevice: OciDeviceRates{{8, 0, 20}},
+				LatencyTargetDevice:     OciDeviceRates{{8, 0, 5000}},
+			},
+		},
+		{
+			name: "io.weight is converted, io.latency is optional",
+			fsContent: map[string]string{
+				"/cg/io.weight": "default 100\n8:0 910\n",
+				"/cg/io.max":    "",
+			},
+			expectedBlockIO: &OciBlockIOParameters{
+				Weight:       20,
+				WeightDevice: OciDeviceWeights{{8, 0, 100}},
+			},
+		},
+		{
+			name: "bad content",
+			fsContent: map[string]string{
+				"/cg/io.weight": "default 100\n8:0\n",
+				"/cg/io.max":    "8:0 rbps=x\n8 wbps=1\n",
+			},
+			expectedErrorCount: 3,
+			expectedErrorSubstrings: []string{
+				"invalid line \"8:0\"",
+				"invalid number in \"rbps=x\"",
+				"invalid device \"8\"",
+			},
+		},
+		{
+			name:               "missing files",
+			expectedErrorCount: 2,
+			expectedErrorSubstrings: []string{
+				"\"io.bfq.weight\"",
+				"\"io.max\"",
+			},
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mpf := mockPlatform{
+				fsOrigContent: tc.fsContent,
+				unified:       true,
+			}
+			currentPlatform = &mpf
+			blockIO, err := GetBlkioParameters("/cg")
+			testutils.VerifyError(t, err, tc.expectedErrorCount, tc.expectedErrorSubstrings)
+			if tc.expectedBlockIO != nil {
+				testutils.VerifyDeepEqual(t, "blockio parameters", *tc.expectedBlockIO, blockIO)
+			}
+		})
+	}
+}
+
+// TestSetBlkioParametersV2: unit test for SetBlkioParameters() and ResetBlkioParameters() on cgroup v2
+func TestSetBlkioParametersV2(t *testing.T) {
+	tcases := []struct {
+		name                    string
+		fsContent               map[string]string
+		blockIO                 OciBlockIOParameters
+		reset                   bool
+		writesFail              int
+		expectedFsWrites        map[string]string
+		expectedErrorCount      int
+		expectedErrorSubstrings []string
+	}{
+		{
+			name: "write full OCI struct",
+			blockIO: OciBlockIOParameters{
+				Weight:                  10,
+				WeightDevice:            OciDeviceWeights{{1, 2, 20}, {3, 4, 0}},
+				ThrottleReadBpsDevice:   OciDeviceRates{{11, 12, 100}},
+				ThrottleWriteBpsDevice:  OciDeviceRates{{21, 22, 0}},
+				ThrottleReadIOPSDevice:  OciDeviceRates{{31, 32, 300}},
+				ThrottleWriteIOPSDevice: OciDeviceRates{{41, 42, 400}},
+				LatencyTargetDevice:     OciDeviceRates{{51, 52, 5000}},
+			},
+			expectedFsWrites: map[string]string{
+				"/cg/io.bfq.weight": "default 10+1:2 20+3:4 default",
+				"/cg/io.weight":     "default 1+1:2 102+3:4 default",
+				"/cg/io.max":        "11:12 rbps=100+21:22 wbps=max+31:32 riops=300+41:42 wiops=400",
+				"/cg/io.latency":    "51:52 target=5000",
+			},
+		},
+		{
+			name:    "write both weight files",
+			blockIO: OciBlockIOParameters{Weight: 0, WeightDevice: OciDeviceWeights{{1, 2, 1000}}},
+			expectedFsWrites: map[string]string{
+				"/cg/io.bfq.weight": "default 100+1:2 1000",
+				"/cg/io.weight":     "default 100+1:2 10000",
+			},
+		},
+		{
+			name:       "fall back to converted io.weight",
+			blockIO:    OciBlockIOParameters{Weight: -1, WeightDevice: OciDeviceWeights{{1, 2, 1000}}},
+			writesFail: 1,
+			expectedFsWrites: map[string]string{
+				"/cg/io.weight": "1:2 10000",
+			},
+		},
+		{
+			name:               "weight write fails",
+			blockIO:            OciBlockIOParameters{Weight: 100},
+			writesFail:         2,
+			expectedFsWrites:   map[string]string{},
+			expectedErrorCount: 1,
+			expectedErrorSubstrings: []string{
+				"could not write default weight 100 to any of files",
+			},
+		},
+		{
+			name: "reset removes old limits and latency targets",
+			fsContent: map[string]string{
+				"/cg/io.bfq.weight": "default 100\n",
+				"/cg/io.max":        "8:0 rbps=1000 wbps=max riops=max wiops=max\n",
+				"/cg/io.latency":    "8:0 target=5000\n",
+			},
+			blockIO: OciBlockIOParameters{
+				Weight:              200,
+				LatencyTargetDevice: OciDeviceRates{{8, 16, 100}},
+			},
+			reset: true,
+			expectedFsWrites: map[string]string{
+				"/cg/io.bfq.weight": "default 200",
+				"/cg/io.weight":     "default 1920",
+				"/cg/io.max":        "8:0 rbps=max",
+				"/cg/io.latency":    "8:16 target=100+8:0 target=0",
+			},
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mpf := mockPlatform{
+				fsOrigContent: tc.fsContent,
+				fsWrites:      make(map[string]string),
+				writesFail:    tc.writesFail,
+				unified:       true,
+			}
+			currentPlatform = &mpf
+			var err error
+			if tc.reset {
+				err = ResetBlkioParameters("/cg", tc.blockIO)
+			} else {
+				err = SetBlkioParameters("/cg", tc.blockIO)
+			}
+			testutils.VerifyError(t, err, tc.expectedErrorCount, tc.expectedErrorSubstrings)
+			testutils.VerifyDeepEqual(t, "filesystem writes", tc.expectedFsWrites, mpf.fsWrites)
+		})
+	}
+}
+
 // mockPlatform implements mock versions of platformInterface functions.
 type mockPlatform struct {
 	fsOrigContent map[string]string
 	fsWrites      map[string]string
 	readsFail     int
 	writesFail    int
+	unified       bool
+}
+
+func (mpf *mockPlatform) isUnifiedDir(dirname string) bool {
+	return mpf.unified
 }
 
 func (mpf *mockPlatform) readFromFile(filename string) (string, error) {
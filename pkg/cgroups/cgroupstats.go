@@ -45,6 +45,18 @@ type CPUAcctUsage struct {
 	System int64
 }
 
+// CPUStat has parsed CPU usage and CFS throttling statistics of a cgroup.
+type CPUStat struct {
+	// UsageNs is the total CPU time consumed by the cgroup.
+	UsageNs int64
+	// NrPeriods is the number of elapsed CFS enforcement periods.
+	NrPeriods int64
+	// NrThrottled is the number of periods the cgroup was throttled.
+	NrThrottled int64
+	// ThrottledNs is the total time the cgroup was throttled.
+	ThrottledNs int64
+}
+
 // HugetlbUsage has parsed contents of huge pages usage in bytes.
 type HugetlbUsage struct {
 	Size     string
@@ -77,6 +89,28 @@ type NumaStat struct {
 	HierarchicalUnevictable NumaLine
 }
 
+// IOStat has parsed statistics of one device from a cgroup v2 io.stat file.
+type IOStat struct {
+	Major int
+	Minor int
+	Stats map[string]int64
+}
+
+// PressureLine has parsed contents of one line of a PSI pressure file.
+type PressureLine struct {
+	Avg10  float64
+	Avg60  float64
+	Avg300 float64
+	Total  int64
+}
+
+// Pressure has parsed contents of a cgroup v2 PSI pressure file. Full is
+// nil if the kernel does not report full pressure for the resource.
+type Pressure struct {
+	Some *PressureLine
+	Full *PressureLine
+}
+
 // GlobalNumaStats has the statistics from one global NUMA nodestats file.
 type GlobalNumaStats struct {
 	NumaHit       int64
@@ -265,6 +299,69 @@ func GetCPUAcctStats(cgroupPath string) ([]CPUAcctUsage, error) {
 	return result, nil
 }
 
+// GetCPUStat retrieves CPU usage and throttling statistics for a cgroup
+// directory given relative to the controller mount points.
+func GetCPUStat(cgroupDir string) (CPUStat, error) {
+
+	// With cgroup v2 cpu.stat looks like this:
+	//
+	// usage_usec 6427395
+	// user_usec 4212345
+	// system_usec 2215050
+	// nr_periods 105
+	// nr_throttled 12
+	// throttled_usec 301232
+	//
+	// With cgroup v1 cpu.stat of the cpu controller looks like this:
+	//
+	// nr_periods 105
+	// nr_throttled 12
+	// throttled_time 301232987
+	//
+	// and the total usage is in cpuacct.usage of the cpuacct controller.
+
+	result := CPUStat{}
+	v2 := DetectSystemCgroupVersion() == 2
+
+	lines, err := readCgroupFileLines(path.Join(string(Cpu.Group(cgroupDir)), "cpu.stat"))
+	if err != nil {
+		return CPUStat{}, err
+	}
+
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		value, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return CPUStat{}, fmt.Errorf("error parsing cpu.stat entry %q: %v", line, err)
+		}
+		switch fields[0] {
+		case "usage_usec":
+			result.UsageNs = value * 1000
+		case "nr_periods":
+			result.NrPeriods = value
+		case "nr_throttled":
+			result.NrThrottled = value
+		case "throttled_usec":
+			result.ThrottledNs = value * 1000
+		case "throttled_time":
+			result.ThrottledNs = value
+		}
+	}
+
+	if !v2 {
+		usage, err := readCgroupSingleNumber(path.Join(string(Cpuacct.Group(cgroupDir)), "cpuacct.usage"))
+		if err != nil {
+			return CPUStat{}, err
+		}
+		result.UsageNs = usage
+	}
+
+	return result, nil
+}
+
 // GetCPUSetMemoryMigrate returns boolean indicating whether memory migration is enabled.
 func GetCPUSetMemoryMigrate(cgroupPath string) (bool, error) {
 
@@ -330,6 +427,199 @@ func GetHugetlbUsage(cgroupPath string) ([]HugetlbUsage, error) {
 	return result, nil
 }
 
+// GetHugetlbCurrent retrieves current cgroup v2 huge pages usage by page size.
+func GetHugetlbCurrent(cgroupPath string) (map[string]int64, error) {
+
+	// Files, for instance hugetlb.2MB.current, look like this:
+	//
+	// 4194304
+
+	files, err := filepath.Glob(path.Join(cgroupPath, "hugetlb.*.current"))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]int64, len(files))
+	for _, file := range files {
+		size := strings.SplitN(filepath.Base(file), ".", 3)[1]
+		bytes, err := readCgroupSingleNumber(file)
+		if err != nil {
+			return nil, err
+		}
+		result[size] = bytes
+	}
+
+	return result, nil
+}
+
+// GetKeyedStats returns the parsed contents of a flat keyed cgroup v2
+// statistics file, like cpu.stat or memory.stat.
+func GetKeyedStats(cgroupPath, entry string) (map[string]int64, error) {
+
+	// Files look like this:
+	//
+	// anon 7430144
+	// file 1654784
+	// ...
+
+	lines, err := readCgroupFileLines(path.Join(cgroupPath, entry))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]int64, len(lines))
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("error parsing %s entry %q", entry, line)
+		}
+		value, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s entry %q: %v", entry, line, err)
+		}
+		result[fields[0]] = value
+	}
+
+	return result, nil
+}
+
+// GetMemoryNumaStatsV2 returns parsed cgroup v2 per NUMA node memory
+// statistics, indexed by statistic type and NUMA node.
+func GetMemoryNumaStatsV2(cgroupPath string) (map[string]map[string]int64, error) {
+
+	// File looks like this:
+	//
+	// anon N0=7430144 N1=0
+	// file N0=1654784 N1=8192
+	// ...
+
+	lines, err := readCgroupFileLines(path.Join(cgroupPath, "memory.numa_stat"))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]map[string]int64, len(lines))
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("error parsing memory.numa_stat entry %q", line)
+		}
+		nodes := make(map[string]int64, len(fields)-1)
+		for _, field := range fields[1:] {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 || !strings.HasPrefix(kv[0], "N") {
+				return nil, fmt.Errorf("error parsing memory.numa_stat entry %q", line)
+			}
+			value, err := strconv.ParseInt(kv[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing memory.numa_stat entry %q: %v", line, err)
+			}
+			nodes[strings.TrimPrefix(kv[0], "N")] = value
+		}
+		result[fields[0]] = nodes
+	}
+
+	return result, nil
+}
+
+// GetIOStats returns parsed cgroup v2 per device I/O statistics.
+func GetIOStats(cgroupPath string) ([]IOStat, error) {
+
+	// File looks like this:
+	//
+	// 8:16 rbytes=1459200 wbytes=314773504 rios=192 wios=353 dbytes=0 dios=0
+	// 8:0 rbytes=90430464 wbytes=299008000 rios=8950 wios=1252 dbytes=50331648 dios=3021
+
+	lines, err := readCgroupFileLines(path.Join(cgroupPath, "io.stat"))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]IOStat, 0, len(lines))
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		devNums := strings.Split(fields[0], ":")
+		if len(devNums) != 2 {
+			return nil, fmt.Errorf("error parsing io.stat entry %q", line)
+		}
+		major, majErr := strconv.Atoi(devNums[0])
+		minor, minErr := strconv.Atoi(devNums[1])
+		if majErr != nil || minErr != nil {
+			return nil, fmt.Errorf("error parsing io.stat entry %q", line)
+		}
+		stat := IOStat{Major: major, Minor: minor, Stats: make(map[string]int64, len(fields)-1)}
+		for _, field := range fields[1:] {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("error parsing io.stat entry %q", line)
+			}
+			value, err := strconv.ParseInt(kv[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing io.stat entry %q: %v", line, err)
+			}
+			stat.Stats[kv[0]] = value
+		}
+		result = append(result, stat)
+	}
+
+	return result, nil
+}
+
+// GetPressure returns parsed cgroup v2 PSI pressure for a resource
+// ("cpu", "memory" or "io").
+func GetPressure(cgroupPath, resource string) (Pressure, error) {
+
+	// File looks like this:
+	//
+	// some avg10=0.00 avg60=0.12 avg300=0.03 total=152613
+	// full avg10=0.00 avg60=0.05 avg300=0.01 total=78734
+
+	entry := resource + ".pressure"
+	lines, err := readCgroupFileLines(path.Join(cgroupPath, entry))
+	if err != nil {
+		return Pressure{}, err
+	}
+
+	result := Pressure{}
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		pl := &PressureLine{}
+		for _, field := range fields[1:] {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 {
+				return Pressure{}, fmt.Errorf("error parsing %s entry %q", entry, line)
+			}
+			if kv[0] == "total" {
+				pl.Total, err = strconv.ParseInt(kv[1], 10, 64)
+			} else {
+				var avg float64
+				avg, err = strconv.ParseFloat(kv[1], 64)
+				switch kv[0] {
+				case "avg10":
+					pl.Avg10 = avg
+				case "avg60":
+					pl.Avg60 = avg
+				case "avg300":
+					pl.Avg300 = avg
+				}
+			}
+			if err != nil {
+				return Pressure{}, fmt.Errorf("error parsing %s entry %q: %v", entry, line, err)
+			}
+		}
+		switch fields[0] {
+		case "some":
+			result.Some = pl
+		case "full":
+			result.Full = pl
+		default:
+			return Pressure{}, fmt.Errorf("error parsing %s entry %q", entry, line)
+		}
+	}
+
+	return result, nil
+}
+
 // GetMemoryUsage retrieves cgroup memory usage.
 func GetMemoryUsage(cgroupPath string) (MemoryUsage, error) {
 
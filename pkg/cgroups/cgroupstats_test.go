@@ -0,0 +1,158 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cgroups
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/intel/cri-resource-manager/pkg/testutils"
+)
+
+// TestGetCPUStat: unit test for GetCPUStat()
+func TestGetCPUStat(t *testing.T) {
+	tcases := []struct {
+		name          string
+		cgroupVersion int
+		files         map[string]string
+		expectedStat  CPUStat
+		expectedError bool
+	}{
+		{
+			name:          "cgroup v2",
+			cgroupVersion: 2,
+			files: map[string]string{
+				"pod0/ctr0/cpu.stat": "usage_usec 6427395\nuser_usec 4212345\nsystem_usec 2215050\n" +
+					"nr_periods 105\nnr_throttled 12\nthrottled_usec 301232\n",
+			},
+			expectedStat: CPUStat{
+				UsageNs:     6427395000,
+				NrPeriods:   105,
+				NrThrottled: 12,
+				ThrottledNs: 301232000,
+			},
+		},
+		{
+			name:          "cgroup v1",
+			cgroupVersion: 1,
+			files: map[string]string{
+				"cpu/pod0/ctr0/cpu.stat":          "nr_periods 105\nnr_throttled 12\nthrottled_time 301232987\n",
+				"cpuacct/pod0/ctr0/cpuacct.usage": "6427395123\n",
+			},
+			expectedStat: CPUStat{
+				UsageNs:     6427395123,
+				NrPeriods:   105,
+				NrThrottled: 12,
+				ThrottledNs: 301232987,
+			},
+		},
+		{
+			name:          "cgroup v1, missing cpuacct",
+			cgroupVersion: 1,
+			files: map[string]string{
+				"cpu/pod0/ctr0/cpu.stat": "nr_periods 105\nnr_throttled 12\nthrottled_time 301232987\n",
+			},
+			expectedError: true,
+		},
+	}
+	savedMountDir, savedVersion := mountDir, systemCgroupVersion
+	defer func() {
+		mountDir, systemCgroupVersion = savedMountDir, savedVersion
+	}()
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mountDir = t.TempDir()
+			systemCgroupVersion = tc.cgroupVersion
+			for name, content := range tc.files {
+				file := filepath.Join(mountDir, name)
+				if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+					t.Fatalf("failed to create directory: %v", err)
+				}
+				if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+					t.Fatalf("failed to write file: %v", err)
+				}
+			}
+			stat, err := GetCPUStat("pod0/ctr0")
+			if tc.expectedError {
+				if err == nil {
+					t.Errorf("expected error, got %+v", stat)
+				}
+				return
+			}
+			testutils.VerifyError(t, err, 0, nil)
+			testutils.VerifyDeepEqual(t, "CPU statistics", tc.expectedStat, stat)
+		})
+	}
+}
+
+// TestCgroupV2Stats: unit test for cgroup v2 statistics parsers
+func TestCgroupV2Stats(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"memory.stat":         "anon 7430144\nfile 1654784\n",
+		"memory.numa_stat":    "anon N0=7430144 N1=0\nfile N0=1646592 N1=8192\n",
+		"io.stat":             "8:16 rbytes=1459200 wbytes=314773504 rios=192 wios=353\n",
+		"cpu.pressure":        "some avg10=1.50 avg60=0.12 avg300=0.03 total=152613\n",
+		"memory.pressure":     "some avg10=0.00 avg60=0.00 avg300=0.00 total=0\nfull avg10=0.00 avg60=0.00 avg300=0.00 total=7\n",
+		"io.pressure":         "bogus avg10=0.00\n",
+		"hugetlb.2MB.current": "4194304\n",
+		"hugetlb.1GB.current": "0\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+
+	stats, err := GetKeyedStats(dir, "memory.stat")
+	testutils.VerifyError(t, err, 0, nil)
+	testutils.VerifyDeepEqual(t, "memory.stat", map[string]int64{"anon": 7430144, "file": 1654784}, stats)
+
+	numa, err := GetMemoryNumaStatsV2(dir)
+	testutils.VerifyError(t, err, 0, nil)
+	testutils.VerifyDeepEqual(t, "memory.numa_stat", map[string]map[string]int64{
+		"anon": {"0": 7430144, "1": 0},
+		"file": {"0": 1646592, "1": 8192},
+	}, numa)
+
+	io, err := GetIOStats(dir)
+	testutils.VerifyError(t, err, 0, nil)
+	testutils.VerifyDeepEqual(t, "io.stat", []IOStat{{
+		Major: 8, Minor: 16,
+		Stats: map[string]int64{"rbytes": 1459200, "wbytes": 314773504, "rios": 192, "wios": 353},
+	}}, io)
+
+	cpu, err := GetPressure(dir, "cpu")
+	testutils.VerifyError(t, err, 0, nil)
+	testutils.VerifyDeepEqual(t, "cpu.pressure", Pressure{
+		Some: &PressureLine{Avg10: 1.5, Avg60: 0.12, Avg300: 0.03, Total: 152613},
+	}, cpu)
+
+	mem, err := GetPressure(dir, "memory")
+	testutils.VerifyError(t, err, 0, nil)
+	testutils.VerifyDeepEqual(t, "memory.pressure", Pressure{
+		Some: &PressureLine{},
+		Full: &PressureLine{Total: 7},
+	}, mem)
+
+	if _, err := GetPressure(dir, "io"); err == nil {
+		t.Errorf("expected error parsing invalid io.pressure")
+	}
+
+	hugetlb, err := GetHugetlbCurrent(dir)
+	testutils.VerifyError(t, err, 0, nil)
+	testutils.VerifyDeepEqual(t, "hugetlb usage", map[string]int64{"2MB": 4194304, "1GB": 0}, hugetlb)
+}
@@ -113,8 +113,11 @@ const (
 type collector struct {
 }
 
-// NewCollector creates new Prometheus collector
+// NewCollector creates new Prometheus collector for cgroup v1 or v2.
 func NewCollector() (prometheus.Collector, error) {
+	if _, err := os.Stat(filepath.Join(cgroupRoot, cgroups.Controllers)); err == nil {
+		return &collectorV2{}, nil
+	}
 	return &collector{}, nil
 }
 
@@ -278,12 +281,16 @@ func updateBlkioDeviceUsageMetric(ch chan<- prometheus.Metric, path string, metr
 }
 
 func walkCgroups() []string {
+	return walkCgroupsIn(filepath.Join(cgroupRoot, "cpuset"))
+}
+
+// walkCgroupsIn returns container cgroup directories relative to root.
+func walkCgroupsIn(root string) []string {
 	// XXX TODO: add support for kubelet cgroupfs cgroup driver.
 
 	containerDirs := []string{}
 
-	cpuset := filepath.Join(cgroupRoot, "cpuset")
-	filepath.Walk(filepath.Join(cpuset, kubepodsDir),
+	filepath.Walk(filepath.Join(root, kubepodsDir),
 		func(path string, info os.FileInfo, err error) error {
 			if err != nil {
 				if os.IsNotExist(err) {
@@ -311,7 +318,7 @@ func walkCgroups() []string {
 				return filepath.SkipDir
 			}
 
-			path = strings.TrimPrefix(path, cpuset+"/")
+			path = strings.TrimPrefix(path, root+"/")
 			containerDirs = append(containerDirs, path)
 
 			return nil
@@ -0,0 +1,274 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cgroupstats
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"sync"
+
+	"github.com/intel/cri-resource-manager/pkg/cgroups"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Prometheus Metric descriptor indices and descriptor table for cgroup v2
+const (
+	cpuStatV2Desc = iota
+	memoryStatV2Desc
+	memoryNumaStatV2Desc
+	ioStatV2Desc
+	pressureAvgV2Desc
+	pressureTotalV2Desc
+	hugeTlbCurrentV2Desc
+	numV2Descriptors
+)
+
+// containerLabels are the labels identifying the container of all cgroup v2 series.
+var containerLabels = []string{"namespace", "pod", "container", "container_id"}
+
+// withContainerLabels returns containerLabels followed by the given extra labels.
+func withContainerLabels(labels ...string) []string {
+	return append(append([]string{}, containerLabels...), labels...)
+}
+
+var v2Descriptors = [numV2Descriptors]*prometheus.Desc{
+	cpuStatV2Desc: prometheus.NewDesc(
+		"cgroup_cpu_stat",
+		"CPU usage and throttling statistics (cpu.stat) for a given container and pod.",
+		withContainerLabels("type"), nil,
+	),
+	memoryStatV2Desc: prometheus.NewDesc(
+		"cgroup_memory_stat",
+		"Memory statistics (memory.stat) for a given container and pod.",
+		withContainerLabels("type"), nil,
+	),
+	memoryNumaStatV2Desc: prometheus.NewDesc(
+		"cgroup_memory_numa_stat",
+		"Per NUMA node memory statistics (memory.numa_stat) for a given container and pod.",
+		withContainerLabels("numa_node_id", "type"), nil,
+	),
+	ioStatV2Desc: prometheus.NewDesc(
+		"cgroup_io_stat",
+		"Per device I/O statistics (io.stat) for a given container and pod.",
+		withContainerLabels("major", "minor", "type"), nil,
+	),
+	pressureAvgV2Desc: prometheus.NewDesc(
+		"cgroup_pressure_avg",
+		"Average share of time stalled on a resource (PSI) for a given container and pod.",
+		withContainerLabels("resource", "kind", "window"), nil,
+	),
+	pressureTotalV2Desc: prometheus.NewDesc(
+		"cgroup_pressure_total_usec",
+		"Total time stalled on a resource (PSI) for a given container and pod.",
+		withContainerLabels("resource", "kind"), nil,
+	),
+	hugeTlbCurrentV2Desc: prometheus.NewDesc(
+		"cgroup_hugetlb_current",
+		"Current hugepages usage for a given container and pod.",
+		withContainerLabels("size"), nil,
+	),
+}
+
+// ContainerInfo identifies the pod and container of a cgroup.
+type ContainerInfo struct {
+	Namespace string
+	Pod       string
+	Container string
+	ID        string
+	// CgroupDir is the container cgroup directory relative to the cgroup v2 mount point.
+	CgroupDir string
+}
+
+// ContainerLister returns the containers to collect cgroup v2 statistics for.
+type ContainerLister func() []ContainerInfo
+
+var (
+	listerLock      sync.RWMutex
+	containerLister ContainerLister
+)
+
+// SetContainerLister sets the function used to list containers and their
+// identities. Without one, container cgroups are discovered by walking the
+// cgroup filesystem and identified only by their container ID.
+func SetContainerLister(lister ContainerLister) {
+	listerLock.Lock()
+	defer listerLock.Unlock()
+	containerLister = lister
+}
+
+// listContainers returns the containers to collect statistics for.
+func listContainers() []ContainerInfo {
+	listerLock.RLock()
+	lister := containerLister
+	listerLock.RUnlock()
+
+	if lister != nil {
+		return lister()
+	}
+
+	containerIDRegexp := regexp.MustCompile(`[a-z0-9]{64}`)
+	containers := []ContainerInfo{}
+	for _, path := range walkCgroupsIn(cgroupRoot) {
+		containers = append(containers, ContainerInfo{
+			ID:        containerIDRegexp.FindString(filepath.Base(path)),
+			CgroupDir: path,
+		})
+	}
+	return containers
+}
+
+type collectorV2 struct {
+}
+
+// Describe implements prometheus.Collector interface
+func (c *collectorV2) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range v2Descriptors {
+		ch <- d
+	}
+}
+
+// Collect implements prometheus.Collector interface
+func (c *collectorV2) Collect(ch chan<- prometheus.Metric) {
+	var wg sync.WaitGroup
+
+	// As with cgroup v1, we don't bail out on errors, since containers can
+	// disappear while we are collecting. We just skip the missing values.
+
+	for _, ctr := range listContainers() {
+		wg.Add(1)
+		go func(ctr ContainerInfo) {
+			defer wg.Done()
+			collectContainerV2(ch, ctr)
+		}(ctr)
+	}
+
+	// We need to wait so that the response channel doesn't get closed.
+	wg.Wait()
+}
+
+// collectContainerV2 collects all cgroup v2 statistics of a single container.
+func collectContainerV2(ch chan<- prometheus.Metric, ctr ContainerInfo) {
+	dir := filepath.Join(cgroupRoot, ctr.CgroupDir)
+	labels := []string{ctr.Namespace, ctr.Pod, ctr.Container, ctr.ID}
+
+	if stats, err := cgroups.GetKeyedStats(dir, "cpu.stat"); err == nil {
+		for key, value := range stats {
+			ch <- prometheus.MustNewConstMetric(
+				v2Descriptors[cpuStatV2Desc],
+				prometheus.CounterValue,
+				float64(value),
+				append(labels, key)...,
+			)
+		}
+	} else {
+		logCollectError("CPU", ctr, err)
+	}
+
+	if stats, err := cgroups.GetKeyedStats(dir, "memory.stat"); err == nil {
+		for key, value := range stats {
+			ch <- prometheus.MustNewConstMetric(
+				v2Descriptors[memoryStatV2Desc],
+				prometheus.UntypedValue,
+				float64(value),
+				append(labels, key)...,
+			)
+		}
+	} else {
+		logCollectError("memory", ctr, err)
+	}
+
+	if numa, err := cgroups.GetMemoryNumaStatsV2(dir); err == nil {
+		for key, nodes := range numa {
+			for node, value := range nodes {
+				ch <- prometheus.MustNewConstMetric(
+					v2Descriptors[memoryNumaStatV2Desc],
+					prometheus.GaugeValue,
+					float64(value),
+					append(labels, node, key)...,
+				)
+			}
+		}
+	} else {
+		logCollectError("NUMA", ctr, err)
+	}
+
+	if devices, err := cgroups.GetIOStats(dir); err == nil {
+		for _, dev := range devices {
+			major, minor := strconv.Itoa(dev.Major), strconv.Itoa(dev.Minor)
+			for key, value := range dev.Stats {
+				ch <- prometheus.MustNewConstMetric(
+					v2Descriptors[ioStatV2Desc],
+					prometheus.CounterValue,
+					float64(value),
+					append(labels, major, minor, key)...,
+				)
+			}
+		}
+	} else {
+		logCollectError("I/O", ctr, err)
+	}
+
+	for _, resource := range []string{"cpu", "memory", "io"} {
+		pressure, err := cgroups.GetPressure(dir, resource)
+		if err != nil {
+			logCollectError(resource+" pressure", ctr, err)
+			continue
+		}
+		for kind, pl := range map[string]*cgroups.PressureLine{"some": pressure.Some, "full": pressure.Full} {
+			if pl == nil {
+				continue
+			}
+			for window, avg := range map[string]float64{"10s": pl.Avg10, "60s": pl.Avg60, "300s": pl.Avg300} {
+				ch <- prometheus.MustNewConstMetric(
+					v2Descriptors[pressureAvgV2Desc],
+					prometheus.GaugeValue,
+					avg,
+					append(labels, resource, kind, window)...,
+				)
+			}
+			ch <- prometheus.MustNewConstMetric(
+				v2Descriptors[pressureTotalV2Desc],
+				prometheus.CounterValue,
+				float64(pl.Total),
+				append(labels, resource, kind)...,
+			)
+		}
+	}
+
+	if hugetlb, err := cgroups.GetHugetlbCurrent(dir); err == nil {
+		for size, value := range hugetlb {
+			ch <- prometheus.MustNewConstMetric(
+				v2Descriptors[hugeTlbCurrentV2Desc],
+				prometheus.GaugeValue,
+				float64(value),
+				append(labels, size)...,
+			)
+		}
+	} else {
+		logCollectError("hugetlb", ctr, err)
+	}
+}
+
+// logCollectError logs a failure to collect statistics. Missing files are
+// only logged for debugging, since controllers or PSI may be disabled.
+func logCollectError(what string, ctr ContainerInfo, err error) {
+	if os.IsNotExist(err) {
+		log.Debug("no %s stats for %s/%s/%s: %v", what, ctr.Namespace, ctr.Pod, ctr.Container, err)
+		return
+	}
+	log.Error("failed to collect %s stats for %s/%s/%s: %v", what, ctr.Namespace, ctr.Pod, ctr.Container, err)
+}
@@ -0,0 +1,193 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cgroupstats
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	model "github.com/prometheus/client_model/go"
+
+	"github.com/intel/cri-resource-manager/pkg/cgroups"
+)
+
+const (
+	testContainerID = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	testCgroupDir   = "kubepods.slice/kubepods-pod1.slice/cri-containerd-" + testContainerID + ".scope"
+)
+
+// createFakeCgroupV2 creates a fake cgroup v2 tree with a single container.
+func createFakeCgroupV2(t *testing.T) string {
+	root := t.TempDir()
+	files := map[string]string{
+		cgroups.Controllers: "cpuset cpu io memory hugetlb pids\n",
+		filepath.Join(testCgroupDir, "cpu.stat"): "usage_usec 1000\n" +
+			"nr_throttled 2\n",
+		filepath.Join(testCgroupDir, "memory.stat"): "anon 4096\n" +
+			"file 8192\n",
+		filepath.Join(testCgroupDir, "memory.numa_stat"): "anon N0=4096 N1=0\n",
+		filepath.Join(testCgroupDir, "io.stat"):          "8:0 rbytes=512 wbytes=1024\n",
+		filepath.Join(testCgroupDir, "memory.pressure"): "some avg10=1.50 avg60=0.50 avg300=0.25 total=300\n" +
+			"full avg10=0.00 avg60=0.00 avg300=0.00 total=100\n",
+		filepath.Join(testCgroupDir, "cpu.pressure"):        "some avg10=0.00 avg60=0.00 avg300=0.00 total=42\n",
+		filepath.Join(testCgroupDir, "hugetlb.2MB.current"): "2097152\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+	return root
+}
+
+// collectV2 collects all metrics of the given collector into a map keyed by
+// metric name and non-container labels. The container labels of all metrics
+// are returned as namespace/pod/container/container_id.
+func collectV2(t *testing.T, c prometheus.Collector) (map[string]float64, map[string]struct{}) {
+	names := map[*prometheus.Desc]string{
+		v2Descriptors[cpuStatV2Desc]:        "cpu_stat",
+		v2Descriptors[memoryStatV2Desc]:     "memory_stat",
+		v2Descriptors[memoryNumaStatV2Desc]: "memory_numa_stat",
+		v2Descriptors[ioStatV2Desc]:         "io_stat",
+		v2Descriptors[pressureAvgV2Desc]:    "pressure_avg",
+		v2Descriptors[pressureTotalV2Desc]:  "pressure_total_usec",
+		v2Descriptors[hugeTlbCurrentV2Desc]: "hugetlb_current",
+	}
+
+	ch := make(chan prometheus.Metric, 1024)
+	c.Collect(ch)
+	close(ch)
+
+	metrics := map[string]float64{}
+	containers := map[string]struct{}{}
+	for m := range ch {
+		name, ok := names[m.Desc()]
+		if !ok {
+			t.Fatalf("unexpected metric %s", m.Desc())
+		}
+		pb := &model.Metric{}
+		if err := m.Write(pb); err != nil {
+			t.Fatalf("failed to write metric: %v", err)
+		}
+		labels := []string{name}
+		ctr := map[string]string{}
+		for _, l := range pb.GetLabel() {
+			switch l.GetName() {
+			case "namespace", "pod", "container", "container_id":
+				ctr[l.GetName()] = l.GetValue()
+			default:
+				labels = append(labels, l.GetName()+"="+l.GetValue())
+			}
+		}
+		containers[ctr["namespace"]+"/"+ctr["pod"]+"/"+ctr["container"]+"/"+ctr["container_id"]] = struct{}{}
+		var value float64
+		switch {
+		case pb.GetCounter() != nil:
+			value = pb.GetCounter().GetValue()
+		case pb.GetGauge() != nil:
+			value = pb.GetGauge().GetValue()
+		default:
+			value = pb.GetUntyped().GetValue()
+		}
+		metrics[strings.Join(labels, ",")] = value
+	}
+	return metrics, containers
+}
+
+func TestCollectorV2(t *testing.T) {
+	savedRoot := cgroupRoot
+	defer func() {
+		cgroupRoot = savedRoot
+		SetContainerLister(nil)
+	}()
+	cgroupRoot = createFakeCgroupV2(t)
+
+	c, err := NewCollector()
+	if err != nil {
+		t.Fatalf("failed to create collector: %v", err)
+	}
+	if _, ok := c.(*collectorV2); !ok {
+		t.Fatalf("expected a cgroup v2 collector, got %T", c)
+	}
+
+	tcases := []struct {
+		name      string
+		lister    ContainerLister
+		container string
+	}{
+		{
+			name:      "containers discovered from cgroupfs",
+			container: "///" + testContainerID,
+		},
+		{
+			name: "containers from lister",
+			lister: func() []ContainerInfo {
+				return []ContainerInfo{
+					{
+						Namespace: "default",
+						Pod:       "pod1",
+						Container: "ctr",
+						ID:        "ctr-id",
+						CgroupDir: testCgroupDir,
+					},
+				}
+			},
+			container: "default/pod1/ctr/ctr-id",
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			SetContainerLister(tc.lister)
+			expected := map[string]float64{
+				"cpu_stat,type=usage_usec":                           1000,
+				"cpu_stat,type=nr_throttled":                         2,
+				"memory_stat,type=anon":                              4096,
+				"memory_stat,type=file":                              8192,
+				"memory_numa_stat,numa_node_id=0,type=anon":          4096,
+				"memory_numa_stat,numa_node_id=1,type=anon":          0,
+				"io_stat,major=8,minor=0,type=rbytes":                512,
+				"io_stat,major=8,minor=0,type=wbytes":                1024,
+				"pressure_avg,kind=some,resource=cpu,window=10s":     0,
+				"pressure_avg,kind=some,resource=cpu,window=60s":     0,
+				"pressure_avg,kind=some,resource=cpu,window=300s":    0,
+				"pressure_total_usec,kind=some,resource=cpu":         42,
+				"pressure_avg,kind=some,resource=memory,window=10s":  1.5,
+				"pressure_avg,kind=some,resource=memory,window=60s":  0.5,
+				"pressure_avg,kind=some,resource=memory,window=300s": 0.25,
+				"pressure_total_usec,kind=some,resource=memory":      300,
+				"pressure_avg,kind=full,resource=memory,window=10s":  0,
+				"pressure_avg,kind=full,resource=memory,window=60s":  0,
+				"pressure_avg,kind=full,resource=memory,window=300s": 0,
+				"pressure_total_usec,kind=full,resource=memory":      100,
+				"hugetlb_current,size=2MB":                           2097152,
+			}
+			metrics, containers := collectV2(t, c)
+			if !reflect.DeepEqual(metrics, expected) {
+				t.Errorf("expected metrics %v, got %v", expected, metrics)
+			}
+			if _, ok := containers[tc.container]; !ok || len(containers) != 1 {
+				t.Errorf("expected metrics only for container %s, got %v", tc.container, containers)
+			}
+		})
+	}
+}
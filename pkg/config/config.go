@@ -97,6 +97,33 @@ func SetConfigFromFile(path string) error {
 	return setconfig(data, ConfigFile)
 }
 
+// RestoreConfig reverts the configuration to a snapshot taken by GetConfig,
+// notifying all modules about the rollback.
+func RestoreConfig(snapshot Data) error {
+	return revertconfig(snapshot, true)
+}
+
+// EvaluateConfig temporarily applies the given configuration without notifying
+// any modules, runs fn, then restores the previous configuration. Notifiers
+// registered by fn are dropped when the previous configuration is restored.
+func EvaluateConfig(cfg map[string]string, fn func() error) error {
+	data, err := DataFromStringMap(cfg)
+	if err != nil {
+		return configError("failed to evaluate configuration: %v", err)
+	}
+	return evaluateconfig(data, fn)
+}
+
+// EvaluateConfigFromFile temporarily applies configuration from the given file
+// and runs fn, as EvaluateConfig does.
+func EvaluateConfigFromFile(path string, fn func() error) error {
+	data, err := DataFromFile(path)
+	if err != nil {
+		return configError("failed to evaluate configuration from file: %v", err)
+	}
+	return evaluateconfig(data, fn)
+}
+
 // GetModule looks up the module for the given path, implicitly creating it if necessary.
 func GetModule(path string) *Module {
 	return lookup(path)
@@ -163,21 +190,47 @@ func setconfig(data Data, source Source) error {
 	return nil
 }
 
+// evaluateconfig applies the configuration without notifications, runs fn, and reverts.
+func evaluateconfig(data Data, fn func() error) error {
+	snapshot, err := main.getconfig()
+	if err != nil {
+		return configError("pre-evaluation configuration snapshot failed: %v", err)
+	}
+
+	if err = main.validate(data); err != nil {
+		return err
+	}
+
+	notifiers := main.saveNotifiers(nil)
+	defer main.restoreNotifiers(notifiers)
+
+	if err = main.configure(data, false); err != nil {
+		revertconfig(snapshot, false)
+		return err
+	}
+	defer revertconfig(snapshot, false)
+
+	return fn()
+}
+
 // revertconfig reverts configuration using a previously taken snapshot
-func revertconfig(snapshot Data, notify bool) {
+func revertconfig(snapshot Data, notify bool) error {
 	err := main.configure(snapshot, true)
 	if err != nil {
 		log.Error("failed to revert configuration: %v", err)
 	}
 
 	if !notify {
-		return
+		return err
 	}
 
-	err = main.notify(RevertEvent, ConfigBackup)
-	if err != nil {
-		log.Error("reverted configuration rejected: %v", err)
+	if nerr := main.notify(RevertEvent, ConfigBackup); nerr != nil {
+		log.Error("reverted configuration rejected: %v", nerr)
+		if err == nil {
+			err = nerr
+		}
 	}
+	return err
 }
 
 // getconfig returns the configuration for the given module and its submodules.
@@ -302,6 +355,30 @@ func (m *Module) notify(event Event, source Source) error {
 	return nil
 }
 
+// saveNotifiers records the number of notifiers of this module and its children.
+func (m *Module) saveNotifiers(saved map[*Module]int) map[*Module]int {
+	if saved == nil {
+		saved = make(map[*Module]int)
+	}
+	saved[m] = len(m.notifiers)
+	for _, child := range m.children {
+		child.saveNotifiers(saved)
+	}
+	return saved
+}
+
+// restoreNotifiers drops notifiers added since the corresponding saveNotifiers.
+func (m *Module) restoreNotifiers(saved map[*Module]int) {
+	if cnt, ok := saved[m]; ok && cnt < len(m.notifiers) {
+		m.notifiers = m.notifiers[:cnt]
+	} else if !ok {
+		m.notifiers = nil
+	}
+	for _, child := range m.children {
+		child.restoreNotifiers(saved)
+	}
+}
+
 // check performs basic sanity checks on the module.
 func (m *Module) check() {
 	ptrType := reflect.TypeOf(m.ptr)
@@ -361,7 +438,7 @@ func (m *Module) validate(data Data) error {
 				names = append(names, name)
 			}
 			if !m.noValidate {
-				return configError("implicit module %s: given configuration data %s",
+				return validationError(m.path, "implicit module %s: given configuration data %s",
 					m.path, strings.Join(names, ","))
 			}
 			log.Error("implicit module %s: given configuration date %s",
@@ -374,7 +451,7 @@ func (m *Module) validate(data Data) error {
 	for field := range modcfg {
 		if _, ok := fields[field]; !ok {
 			if !m.noValidate {
-				return configError("module %s: given unknown configuration data %s", m.path, field)
+				return validationError(m.path, "module %s: given unknown configuration data %s", m.path, field)
 			}
 			log.Error("module %s: given unknown configuration data %s", m.path, field)
 		}
@@ -398,7 +475,7 @@ func (m *Module) validate(data Data) error {
 		for name := range subcfg {
 			unconsumed = append(unconsumed, name)
 		}
-		return configError("module %s: no child corresponding to data %s",
+		return validationError(m.path, "module %s: no child corresponding to data %s",
 			m.path, strings.Join(unconsumed, ","))
 	}
 
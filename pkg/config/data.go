@@ -63,6 +63,20 @@ func DataFromFile(path string) (Data, error) {
 	return data, nil
 }
 
+// StringMap remarshals configuration data into a map of per-key YAML strings,
+// the same format DataFromStringMap (and SetConfig) expects.
+func (d Data) StringMap() (map[string]string, error) {
+	smap := make(map[string]string)
+	for key, val := range d {
+		raw, err := yaml.Marshal(val)
+		if err != nil {
+			return nil, configError("failed to marshal data for key %q: %v", key, err)
+		}
+		smap[key] = string(raw)
+	}
+	return smap, nil
+}
+
 // copy does a shallow copy of the given data.
 func (d Data) copy() Data {
 	data := make(Data)
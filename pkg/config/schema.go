@@ -0,0 +1,195 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// Schema is an OpenAPI v3 schema describing configuration data.
+type Schema struct {
+	Type                  string             `json:"type,omitempty"`
+	Description           string             `json:"description,omitempty"`
+	Nullable              bool               `json:"nullable,omitempty"`
+	Properties            map[string]*Schema `json:"properties,omitempty"`
+	Items                 *Schema            `json:"items,omitempty"`
+	AdditionalProperties  *Schema            `json:"additionalProperties,omitempty"`
+	PreserveUnknownFields bool               `json:"x-kubernetes-preserve-unknown-fields,omitempty"`
+}
+
+var (
+	jsonUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// GetSchema returns the schema for the configuration of the given top-level modules.
+// Without any names given, the schema for the full configuration is returned.
+func GetSchema(names ...string) (*Schema, error) {
+	if len(names) == 0 {
+		return main.schema(), nil
+	}
+
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for _, name := range names {
+		m, ok := main.children[name]
+		if !ok {
+			return nil, configError("can't generate schema, unknown module %q", name)
+		}
+		s.Properties[name] = m.schema()
+	}
+	return s, nil
+}
+
+// schema returns the schema for the module and its submodules.
+func (m *Module) schema() *Schema {
+	var s *Schema
+
+	if m.isImplicit() {
+		s = &Schema{Type: "object"}
+	} else {
+		s = moduleSchema(reflect.TypeOf(m.ptr))
+		s.Description = m.description
+	}
+
+	if len(m.children) == 0 {
+		return s
+	}
+
+	if s.Type != "object" || s.AdditionalProperties != nil {
+		log.Warning("module %s: can't merge non-struct data with sub-module schemas", m.path)
+		return s
+	}
+
+	if s.Properties == nil {
+		s.Properties = map[string]*Schema{}
+	}
+	for name, child := range m.children {
+		s.Properties[name] = child.schema()
+	}
+	s.PreserveUnknownFields = true
+
+	return s
+}
+
+// moduleSchema returns the schema for the data type of a module.
+func moduleSchema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	// Module data is always a YAML/JSON object. Unlike for other types,
+	// custom decoding of module data usually just fills in defaults.
+	if t.Kind() != reflect.Struct {
+		return typeSchema(t, map[reflect.Type]bool{})
+	}
+
+	s := &Schema{
+		Type:                  "object",
+		Properties:            map[string]*Schema{},
+		PreserveUnknownFields: true,
+	}
+	structFields(t, s.Properties, map[reflect.Type]bool{t: true})
+
+	return s
+}
+
+// typeSchema returns the schema for the given type.
+func typeSchema(t reflect.Type, seen map[reflect.Type]bool) *Schema {
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+
+	// types with custom decoding can take any form, we can't tell what
+	if t.Implements(jsonUnmarshaler) || reflect.PtrTo(t).Implements(jsonUnmarshaler) ||
+		t.Implements(textUnmarshaler) || reflect.PtrTo(t).Implements(textUnmarshaler) {
+		return &Schema{PreserveUnknownFields: true}
+	}
+
+	s := &Schema{Nullable: nullable}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		s.Type = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s.Type = "integer"
+	case reflect.Float32, reflect.Float64:
+		s.Type = "number"
+	case reflect.String:
+		s.Type = "string"
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			s.Type = "string"
+			break
+		}
+		s.Type = "array"
+		s.Nullable = true
+		s.Items = typeSchema(t.Elem(), seen)
+	case reflect.Map:
+		s.Type = "object"
+		s.Nullable = true
+		s.AdditionalProperties = typeSchema(t.Elem(), seen)
+	case reflect.Struct:
+		if seen[t] {
+			return &Schema{PreserveUnknownFields: true}
+		}
+		seen[t] = true
+		defer delete(seen, t)
+
+		s.Type = "object"
+		s.Properties = map[string]*Schema{}
+		s.PreserveUnknownFields = true
+		structFields(t, s.Properties, seen)
+	default:
+		return &Schema{PreserveUnknownFields: true}
+	}
+
+	return s
+}
+
+// structFields collects the schemas of the fields of the given struct.
+func structFields(t reflect.Type, properties map[string]*Schema, seen map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := strings.Split(f.Tag.Get("json"), ",")
+		name := tag[0]
+
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				structFields(ft, properties, seen)
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		properties[name] = typeSchema(f.Type, seen)
+	}
+}
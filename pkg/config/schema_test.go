@@ -0,0 +1,128 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+type schemaTestEmbedded struct {
+	Embedded string `json:"embedded"`
+}
+
+type schemaTestOptions struct {
+	schemaTestEmbedded
+	Flag     bool    `json:"flag"`
+	Count    int     `json:"count,omitempty"`
+	Ratio    float64 `json:"ratio"`
+	Untagged string
+	Names    []string          `json:"names"`
+	Labels   map[string]string `json:"labels"`
+	Period   Duration          `json:"period"`
+	Nested   *struct {
+		Value uint32 `json:"value"`
+	} `json:"nested"`
+	Skipped  string `json:"-"`
+	internal string
+}
+
+func TestTypeSchema(t *testing.T) {
+	s := typeSchema(reflect.TypeOf(&schemaTestOptions{}), map[reflect.Type]bool{})
+
+	if s.Type != "object" || !s.PreserveUnknownFields {
+		t.Fatalf("expected object with unknown fields preserved, got %+v", *s)
+	}
+
+	tcases := []struct {
+		name     string
+		typ      string
+		nullable bool
+		any      bool
+	}{
+		{name: "embedded", typ: "string"},
+		{name: "flag", typ: "boolean"},
+		{name: "count", typ: "integer"},
+		{name: "ratio", typ: "number"},
+		{name: "Untagged", typ: "string"},
+		{name: "names", typ: "array", nullable: true},
+		{name: "labels", typ: "object", nullable: true},
+		{name: "period", any: true},
+		{name: "nested", typ: "object", nullable: true},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, ok := s.Properties[tc.name]
+			if !ok {
+				t.Fatalf("property %q missing", tc.name)
+			}
+			if p.Type != tc.typ {
+				t.Errorf("expected type %q, got %q", tc.typ, p.Type)
+			}
+			if p.Nullable != tc.nullable {
+				t.Errorf("expected nullable %v, got %v", tc.nullable, p.Nullable)
+			}
+			if tc.any && !p.PreserveUnknownFields {
+				t.Errorf("expected any value to be accepted")
+			}
+		})
+	}
+
+	for _, name := range []string{"Skipped", "-", "internal", "schemaTestEmbedded"} {
+		if _, ok := s.Properties[name]; ok {
+			t.Errorf("unexpected property %q", name)
+		}
+	}
+
+	if s.Properties["names"].Items.Type != "string" {
+		t.Errorf("expected string array items")
+	}
+	if s.Properties["labels"].AdditionalProperties.Type != "string" {
+		t.Errorf("expected string map values")
+	}
+	if s.Properties["nested"].Properties["value"].Type != "integer" {
+		t.Errorf("expected integer nested value")
+	}
+}
+
+func TestGetSchema(t *testing.T) {
+	opts := &schemaTestOptions{}
+	Register("schema-test", "schema test module", opts, func() interface{} { return &schemaTestOptions{} })
+	Register("schema-test.child", "schema test child module", &schemaTestEmbedded{},
+		func() interface{} { return &schemaTestEmbedded{} })
+
+	s, err := GetSchema("schema-test")
+	if err != nil {
+		t.Fatalf("failed to get schema: %v", err)
+	}
+
+	m, ok := s.Properties["schema-test"]
+	if !ok {
+		t.Fatalf("module schema missing")
+	}
+	if m.Description != "schema test module" {
+		t.Errorf("unexpected module description %q", m.Description)
+	}
+	if _, ok := m.Properties["flag"]; !ok {
+		t.Errorf("module data property missing")
+	}
+	if c, ok := m.Properties["child"]; !ok || c.Properties["embedded"] == nil {
+		t.Errorf("child module schema missing")
+	}
+
+	if _, err := GetSchema("no-such-module"); err == nil {
+		t.Errorf("expected error for unknown module")
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"strings"
+)
+
+// ValidationError describes a problem found in configuration data.
+type ValidationError struct {
+	// Module is the path of the module the problem was found in, if known.
+	Module string `json:"module,omitempty"`
+	// Reason describes the problem.
+	Reason string `json:"error"`
+}
+
+// ValidationErrors is a list of problems found in configuration data.
+type ValidationErrors []*ValidationError
+
+// Error returns the problem as a string.
+func (e *ValidationError) Error() string {
+	return e.Reason
+}
+
+// Error returns all the problems as a single string.
+func (e ValidationErrors) Error() string {
+	reasons := make([]string, 0, len(e))
+	for _, err := range e {
+		reasons = append(reasons, err.Error())
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// AsValidationErrors converts an error to a list of validation errors. Errors
+// which are not validation errors are attributed to the given module.
+func AsValidationErrors(module string, err error) ValidationErrors {
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case ValidationErrors:
+		return e
+	case *ValidationError:
+		return ValidationErrors{e}
+	default:
+		return ValidationErrors{{Module: module, Reason: err.Error()}}
+	}
+}
+
+// ValidateConfig checks the given configuration without taking it into use.
+// Data is checked against the registered modules, then temporarily applied
+// to run fn for any further checks, as EvaluateConfig does. Any problems
+// found are returned as ValidationErrors.
+func ValidateConfig(cfg map[string]string, fn func() error) error {
+	data, err := DataFromStringMap(cfg)
+	if err != nil {
+		return AsValidationErrors("", configError("failed to parse configuration: %v", err))
+	}
+	return validateconfig(data, fn)
+}
+
+// ValidateConfigFromFile checks the configuration in the given file, as
+// ValidateConfig does.
+func ValidateConfigFromFile(path string, fn func() error) error {
+	data, err := DataFromFile(path)
+	if err != nil {
+		return AsValidationErrors("", configError("failed to read configuration: %v", err))
+	}
+	return validateconfig(data, fn)
+}
+
+// validateconfig checks the given configuration data.
+func validateconfig(data Data, fn func() error) error {
+	if err := evaluateconfig(data, fn); err != nil {
+		return AsValidationErrors("", err)
+	}
+	return nil
+}
+
+// validationError creates a validation error for the given module.
+func validationError(module, format string, args ...interface{}) error {
+	return &ValidationError{
+		Module: module,
+		Reason: configError(format, args...).Error(),
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"testing"
+)
+
+type validateTestOptions struct {
+	Count int `json:"count"`
+}
+
+func TestValidateConfig(t *testing.T) {
+	opts := &validateTestOptions{}
+	Register("validate-test", "validation test module", opts,
+		func() interface{} { return &validateTestOptions{} })
+
+	check := func() error {
+		if opts.Count > 2 {
+			return fmt.Errorf("count %d too large", opts.Count)
+		}
+		return nil
+	}
+
+	tcases := []struct {
+		name    string
+		config  map[string]string
+		invalid bool
+		module  string
+		reason  string
+	}{
+		{
+			name:   "valid",
+			config: map[string]string{"validate-test": "count: 1"},
+		},
+		{
+			name:    "unknown data",
+			config:  map[string]string{"validate-test": "bogus: 1"},
+			invalid: true,
+			module:  "validate-test",
+			reason:  "config error: module validate-test: given unknown configuration data bogus",
+		},
+		{
+			name:    "semantic error",
+			config:  map[string]string{"validate-test": "count: 3"},
+			invalid: true,
+			reason:  "count 3 too large",
+		},
+		{
+			name:    "unparsable data",
+			config:  map[string]string{"validate-test": "count: [1"},
+			invalid: true,
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateConfig(tc.config, check)
+			if !tc.invalid {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			errs, ok := err.(ValidationErrors)
+			if !ok || len(errs) != 1 {
+				t.Fatalf("expected a single validation error, got %T %v", err, err)
+			}
+			if errs[0].Module != tc.module {
+				t.Errorf("expected module %q, got %q", tc.module, errs[0].Module)
+			}
+			if tc.reason != "" && errs[0].Reason != tc.reason {
+				t.Errorf("expected reason %q, got %q", tc.reason, errs[0].Reason)
+			}
+		})
+	}
+
+	if opts.Count != 0 {
+		t.Errorf("validation left configuration applied (count %d)", opts.Count)
+	}
+}
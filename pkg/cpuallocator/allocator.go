@@ -37,12 +37,25 @@ const (
 	AllocIdleNodes
 	// AllocIdleCores requests allocation of full idle cores (all threads in core).
 	AllocIdleCores
+	// AllocIdleL3Domains requests allocation of full idle L3 cache domains,
+	// and colocating threads in L3 cache domains.
+	AllocIdleL3Domains
+	// AllocIdleClusters requests allocation of full idle L2 cache clusters,
+	// and colocating threads in L2 cache clusters.
+	AllocIdleClusters
 	// AllocDefault is the default allocation preferences.
 	AllocDefault = AllocIdlePackages | AllocIdleCores
 
 	logSource = "cpuallocator"
 )
 
+const (
+	// l2Cache is the cache level shared by the CPUs of a cluster.
+	l2Cache = 2
+	// l3Cache is the cache level shared by the CPUs of an L3 domain.
+	l3Cache = 3
+)
+
 // allocatorHelper encapsulates state for allocating CPUs.
 type allocatorHelper struct {
 	logger.Logger               // allocatorHelper logger instance
@@ -86,6 +99,8 @@ type topologyCache struct {
 	node map[idset.ID]cpuset.CPUSet
 	core map[idset.ID]cpuset.CPUSet
 
+	cache map[int]map[idset.ID]cpuset.CPUSet // CPUs sharing caches, by level and id
+
 	cpuPriorities cpuPriorities // CPU priority mapping
 }
 
@@ -179,6 +194,75 @@ func (a *allocatorHelper) takeIdlePackages() {
 	}
 }
 
+// Allocate full idle L3 cache domains or L2 cache clusters.
+func (a *allocatorHelper) takeIdleCaches(level int) {
+	a.Debug("* takeIdleCaches(L%d)...", level)
+
+	offline := a.sys.Offlined()
+	caches := a.topology.cache[level]
+
+	// pick idle caches
+	ids := pickIds(a.sys.CacheIDs(level),
+		func(id idset.ID) bool {
+			cset := caches[id].Difference(offline)
+			if cset.IsEmpty() {
+				return false
+			}
+			return cset.Intersection(a.from).Equals(cset)
+		})
+
+	// sorted by number of preferred cpus, then by size and then by id
+	sort.Slice(ids,
+		func(i, j int) bool {
+			iCset, jCset := caches[ids[i]], caches[ids[j]]
+			if res := a.topology.cpuPriorities.cmpCPUSet(iCset, jCset, a.prefer, -1); res != 0 {
+				return res > 0
+			}
+			if iCset.Size() != jCset.Size() {
+				return iCset.Size() > jCset.Size()
+			}
+			return ids[i] < ids[j]
+		})
+
+	a.Debug(" => idle L%d caches sorted by preference: %v", level, ids)
+
+	// take as many idle caches as we need/can
+	for _, id := range ids {
+		cset := caches[id].Difference(offline)
+		a.Debug(" => considering L%d cache %v (#%s)...", level, id, cset)
+		if a.cnt >= cset.Size() {
+			a.Debug(" => taking L%d cache %v...", level, id)
+			a.result = a.result.Union(cset)
+			a.from = a.from.Difference(cset)
+			a.cnt -= cset.Size()
+
+			if a.cnt == 0 {
+				break
+			}
+		}
+	}
+}
+
+// cacheLevels returns the cache levels to allocate by, L3 domains before L2 clusters.
+func (a *allocatorHelper) cacheLevels() []int {
+	levels := []int{}
+	if (a.flags & AllocIdleL3Domains) != 0 {
+		levels = append(levels, l3Cache)
+	}
+	if (a.flags & AllocIdleClusters) != 0 {
+		levels = append(levels, l2Cache)
+	}
+	return levels
+}
+
+// cacheCPUSet returns the CPUs sharing the cache at the given level with a CPU.
+func (a *allocatorHelper) cacheCPUSet(level int, id idset.ID) cpuset.CPUSet {
+	if cset, ok := a.topology.cache[level][a.sys.CPU(id).CacheID(level)]; ok {
+		return cset
+	}
+	return cpuset.NewCPUSet()
+}
+
 // Allocate full idle CPU cores.
 func (a *allocatorHelper) takeIdleCores() {
 	a.Debug("* takeIdleCores()...")
@@ -238,6 +322,8 @@ func (a *allocatorHelper) takeIdleThreads() {
 	// sorted for preference by id, mimicking cpus_assignment.go for now:
 	//   IOW, prefer CPUs
 	//     - from packages with higher number of CPUs/cores already in a.result
+	//     - from L3 domains with higher number of CPUs/cores already in a.result, if enabled
+	//     - from L2 clusters with higher number of CPUs/cores already in a.result, if enabled
 	//     - from packages having larger number of available cpus with preferred priority
 	//     - from a single package
 	//     - from the list of cpus with preferred priority
@@ -245,6 +331,7 @@ func (a *allocatorHelper) takeIdleThreads() {
 	//     - from cores with fewer remaining free CPUs/cores in a.from
 	//     - from packages with lower id
 	//     - with lower id
+	cacheLevels := a.cacheLevels()
 	sort.Slice(cores,
 		func(i, j int) bool {
 			iCore := cores[i]
@@ -263,6 +350,14 @@ func (a *allocatorHelper) takeIdleThreads() {
 				return iPkgColo > jPkgColo
 			}
 
+			for _, level := range cacheLevels {
+				iCacheColo := a.cacheCPUSet(level, iCore).Intersection(a.result).Size()
+				jCacheColo := a.cacheCPUSet(level, jCore).Intersection(a.result).Size()
+				if iCacheColo != jCacheColo {
+					return iCacheColo > jCacheColo
+				}
+			}
+
 			// Always sort cores in package order
 			if res := a.topology.cpuPriorities.cmpCPUSet(iPkgSet.Intersection(a.from), jPkgSet.Intersection(a.from), a.prefer, a.cnt); res != 0 {
 				return res > 0
@@ -329,6 +424,12 @@ func (a *allocatorHelper) allocate() cpuset.CPUSet {
 		if (a.flags & AllocIdlePackages) != 0 {
 			a.takeIdlePackages()
 		}
+		if a.cnt > 0 && (a.flags&AllocIdleL3Domains) != 0 {
+			a.takeIdleCaches(l3Cache)
+		}
+		if a.cnt > 0 && (a.flags&AllocIdleClusters) != 0 {
+			a.takeIdleCaches(l2Cache)
+		}
 		if a.cnt > 0 && (a.flags&AllocIdleCores) != 0 {
 			a.takeIdleCores()
 		}
@@ -387,9 +488,10 @@ func (ca *cpuAllocator) ReleaseCpus(from *cpuset.CPUSet, cnt int, prefer CPUPrio
 
 func newTopologyCache(sys sysfs.System) topologyCache {
 	c := topologyCache{
-		pkg:  make(map[idset.ID]cpuset.CPUSet),
-		node: make(map[idset.ID]cpuset.CPUSet),
-		core: make(map[idset.ID]cpuset.CPUSet)}
+		pkg:   make(map[idset.ID]cpuset.CPUSet),
+		node:  make(map[idset.ID]cpuset.CPUSet),
+		core:  make(map[idset.ID]cpuset.CPUSet),
+		cache: make(map[int]map[idset.ID]cpuset.CPUSet)}
 	if sys != nil {
 		for _, id := range sys.PackageIDs() {
 			c.pkg[id] = sys.Package(id).CPUSet()
@@ -400,6 +502,12 @@ func newTopologyCache(sys sysfs.System) topologyCache {
 		for _, id := range sys.CPUIDs() {
 			c.core[id] = sys.CPU(id).ThreadCPUSet()
 		}
+		for _, level := range []int{l2Cache, l3Cache} {
+			c.cache[level] = make(map[idset.ID]cpuset.CPUSet)
+			for _, id := range sys.CacheIDs(level) {
+				c.cache[level][id] = sys.CacheCPUSet(level, id)
+			}
+		}
 	}
 
 	c.discoverCPUPriorities(sys)
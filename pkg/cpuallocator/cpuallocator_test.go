@@ -99,3 +99,85 @@ func TestAllocatorHelper(t *testing.T) {
 		})
 	}
 }
+
+func TestIdleCacheAllocation(t *testing.T) {
+	// Create tmpdir and decompress testdata there
+	tmpdir, err := ioutil.TempDir("", "cri-resource-manager-test-")
+	if err != nil {
+		t.Fatalf("failed to create tmpdir: %v", err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	if err := utils.UncompressTbz2(path.Join("testdata", "sysfs.tar.bz2"), tmpdir); err != nil {
+		t.Fatalf("failed to decompress testdata: %v", err)
+	}
+
+	// Make cores #11 and #12 share their L2 cache, forming a cluster.
+	root := path.Join(tmpdir, "sysfs", "2-socket-4-node-40-core", "sys")
+	for _, cpu := range []string{"11", "12", "51", "52"} {
+		entry := path.Join(root, "devices/system/cpu", "cpu"+cpu, "cache/index2/shared_cpu_list")
+		if err := os.Chmod(entry, 0644); err != nil {
+			t.Fatalf("failed to make %s writable: %v", entry, err)
+		}
+		if err := ioutil.WriteFile(entry, []byte("11-12,51-52\n"), 0644); err != nil {
+			t.Fatalf("failed to override %s: %v", entry, err)
+		}
+	}
+
+	sys, err := sysfs.DiscoverSystemAt(root, sysfs.DiscoverCPUTopology, sysfs.DiscoverCache)
+	if err != nil {
+		t.Fatalf("failed to discover mock system: %v", err)
+	}
+	topoCache := newTopologyCache(sys)
+
+	tcs := []struct {
+		description string
+		flags       AllocFlag
+		from        cpuset.CPUSet
+		cnt         int
+		expected    cpuset.CPUSet
+	}{
+		{
+			description: "idle cores by default",
+			flags:       AllocDefault,
+			from:        cpuset.MustParse("10-13,50-53"),
+			cnt:         4,
+			expected:    cpuset.MustParse("10-11,50-51"),
+		},
+		{
+			description: "idle cluster",
+			flags:       AllocDefault | AllocIdleL3Domains | AllocIdleClusters,
+			from:        cpuset.MustParse("10-13,50-53"),
+			cnt:         4,
+			expected:    cpuset.MustParse("11-12,51-52"),
+		},
+		{
+			description: "threads not colocated by default",
+			flags:       AllocDefault,
+			from:        cpuset.MustParse("10-12,51"),
+			cnt:         3,
+			expected:    cpuset.MustParse("10-11,51"),
+		},
+		{
+			description: "threads colocated with cluster",
+			flags:       AllocDefault | AllocIdleL3Domains | AllocIdleClusters,
+			from:        cpuset.MustParse("10-12,51"),
+			cnt:         3,
+			expected:    cpuset.MustParse("11,12,51"),
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			a := newAllocatorHelper(sys, topoCache)
+			a.flags = tc.flags
+			a.from = tc.from
+			a.prefer = PriorityNone
+			a.cnt = tc.cnt
+			result := a.allocate()
+			if !result.Equals(tc.expected) {
+				t.Errorf("expected %q, result was %q", tc.expected, result)
+			}
+		})
+	}
+}
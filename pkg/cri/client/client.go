@@ -29,6 +29,7 @@ import (
 
 	"github.com/intel/cri-resource-manager/pkg/instrumentation"
 	logger "github.com/intel/cri-resource-manager/pkg/log"
+	"github.com/intel/cri-resource-manager/pkg/record"
 	"github.com/intel/cri-resource-manager/pkg/utils"
 
 	v1 "github.com/intel/cri-resource-manager/pkg/cri/client/v1"
@@ -206,6 +207,7 @@ func (c *client) connect(kind, socket string, options ConnectOptions) (*grpc.Cli
 
 	dialOpts := instrumentation.InjectGrpcClientTrace(
 		grpc.WithInsecure(),
+		grpc.WithUnaryInterceptor(record.UnaryClientInterceptor),
 		grpc.WithBlock(),
 		grpc.FailOnNonTempDialError(true),
 		grpc.WithDialer(func(socket string, timeout time.Duration) (net.Conn, error) {
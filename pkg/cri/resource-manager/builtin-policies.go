@@ -18,6 +18,7 @@ import (
 	// List of builtin policies
 	_ "github.com/intel/cri-resource-manager/pkg/cri/resource-manager/policy/builtin/balloons"
 	_ "github.com/intel/cri-resource-manager/pkg/cri/resource-manager/policy/builtin/dynamic-pools"
+	_ "github.com/intel/cri-resource-manager/pkg/cri/resource-manager/policy/builtin/external"
 	_ "github.com/intel/cri-resource-manager/pkg/cri/resource-manager/policy/builtin/none"
 	_ "github.com/intel/cri-resource-manager/pkg/cri/resource-manager/policy/builtin/podpools"
 	_ "github.com/intel/cri-resource-manager/pkg/cri/resource-manager/policy/builtin/static"
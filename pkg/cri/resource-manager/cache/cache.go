@@ -26,6 +26,7 @@ import (
 	"sync"
 
 	v1 "k8s.io/api/core/v1"
+	resapi "k8s.io/apimachinery/pkg/api/resource"
 	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
 	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
 
@@ -58,14 +59,20 @@ const (
 	RDTClassKey = "rdtclass" + "." + kubernetes.ResmgrKeyNamespace
 	// BlockIOClassKey is the pod annotation key for specifying a container Block I/O class.
 	BlockIOClassKey = "blockioclass" + "." + kubernetes.ResmgrKeyNamespace
+	// CPUClassKey is the pod annotation key for specifying a container CPU class.
+	CPUClassKey = "cpuclass" + "." + kubernetes.ResmgrKeyNamespace
 	// ToptierLimitKey is the pod annotation key for specifying container top tier memory limits.
 	ToptierLimitKey = "toptierlimit" + "." + kubernetes.ResmgrKeyNamespace
+	// MemoryQoSKey is the pod annotation key for overriding container memory QoS settings.
+	MemoryQoSKey = "memoryqos" + "." + kubernetes.ResmgrKeyNamespace
 
 	// RDTClassPodQoS denotes that the RDTClass should be taken from PodQosClass
 	RDTClassPodQoS = "/PodQos"
 
 	// ToptierLimitUnset is the reserved value for indicating unset top tier limits.
 	ToptierLimitUnset int64 = -1
+	// MemoryQoSUnset is the reserved value for indicating unset memory QoS settings.
+	MemoryQoSUnset int64 = -1
 
 	// TopologyHintsKey can be used to opt out from automatic topology hint generation.
 	TopologyHintsKey = "topologyhints" + "." + kubernetes.ResmgrKeyNamespace
@@ -365,11 +372,21 @@ type Container interface {
 	// GetBlockIOClass returns the BlockIO class for this container.
 	GetBlockIOClass() string
 
+	// SetCPUClass assigns the CPUs of this container to the given CPU class.
+	SetCPUClass(string)
+	// GetCPUClass returns the CPU class for this container.
+	GetCPUClass() string
+
 	// SetToptierLimit sets the tier memory limit for the container.
 	SetToptierLimit(int64)
 	// GetToptierLimit returns the top tier memory limit for the container.
 	GetToptierLimit() int64
 
+	// SetMemoryQoS sets memory QoS overrides for the container.
+	SetMemoryQoS(*MemoryQoS)
+	// GetMemoryQoS returns the effective memory QoS overrides for the container.
+	GetMemoryQoS() *MemoryQoS
+
 	// SetPageMigration sets the page migration policy/options for the container.
 	SetPageMigration(*PageMigrate)
 	// GetPageMigration returns the current page migration policy/options for the container.
@@ -437,7 +454,9 @@ type container struct {
 	CgroupDir    string       // cgroup directory relative to a(ny) controller.
 	RDTClass     string       // RDT class this container is assigned to.
 	BlockIOClass string       // Block I/O class this container is assigned to.
+	CPUClass     string       // CPU class of the CPUs of this container, if any.
 	ToptierLimit int64        // Top tier memory limit.
+	MemoryQoS    *MemoryQoS   // Memory QoS overrides, nil if none.
 	PageMigrate  *PageMigrate // Page migration policy/options for this container.
 
 	pending map[string]struct{} // controllers with pending changes for this container
@@ -502,6 +521,62 @@ func (pm *PageMigrate) Clone() *PageMigrate {
 	return c
 }
 
+// MemoryQoS contains cgroup v2 memory protection and throttling settings
+// (memory.min, memory.low and memory.high) in bytes. MemoryQoSUnset marks
+// a setting which is not overridden.
+type MemoryQoS struct {
+	Min  int64
+	Low  int64
+	High int64
+}
+
+// NewMemoryQoS creates memory QoS settings with nothing overridden.
+func NewMemoryQoS() *MemoryQoS {
+	return &MemoryQoS{Min: MemoryQoSUnset, Low: MemoryQoSUnset, High: MemoryQoSUnset}
+}
+
+// ParseMemoryQoS parses memory QoS settings of the form "min=1Gi,low=2Gi,high=4Gi".
+func ParseMemoryQoS(value string) (*MemoryQoS, error) {
+	qos := NewMemoryQoS()
+	for _, setting := range strings.Split(value, ",") {
+		setting = strings.TrimSpace(setting)
+		if setting == "" {
+			continue
+		}
+		kv := strings.SplitN(setting, "=", 2)
+		if len(kv) != 2 {
+			return nil, cacheError("invalid memory QoS setting %q, key=value expected", setting)
+		}
+		qty, err := resapi.ParseQuantity(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return nil, cacheError("invalid memory QoS setting %q: %v", setting, err)
+		}
+		if qty.Value() < 0 {
+			return nil, cacheError("invalid memory QoS setting %q: negative value", setting)
+		}
+		switch strings.TrimSpace(kv[0]) {
+		case "min":
+			qos.Min = qty.Value()
+		case "low":
+			qos.Low = qty.Value()
+		case "high":
+			qos.High = qty.Value()
+		default:
+			return nil, cacheError("invalid memory QoS setting %q, unknown key", setting)
+		}
+	}
+	return qos, nil
+}
+
+// Clone creates a copy of the memory QoS settings.
+func (q *MemoryQoS) Clone() *MemoryQoS {
+	if q == nil {
+		return nil
+	}
+	c := *q
+	return &c
+}
+
 // Cachable is an interface opaque cachable data must implement.
 type Cachable interface {
 	// Set value (via a pointer receiver) to the object.
@@ -573,12 +648,24 @@ type Cache interface {
 	GetConfig() *config.RawConfig
 	// ResetConfig clears any stored configuration from the cache.
 	ResetConfig() error
+	// AddConfigRevision records a configuration in the (bounded) configuration history.
+	AddConfigRevision(*config.ConfigRevision) error
+	// GetConfigHistory returns the recorded configuration history, oldest entry first.
+	GetConfigHistory() []*config.ConfigRevision
+	// GetConfigRevision returns the given revision from the history, or nil if it is not found.
+	GetConfigRevision(uint64) *config.ConfigRevision
 
 	// SetAdjustment updates external adjustments and containers based this.
 	SetAdjustment(*config.Adjustment) (bool, map[string]error)
 
 	// Save requests a cache save.
 	Save() error
+	// Snapshot takes a restorable snapshot of the current state of the cache.
+	Snapshot() ([]byte, error)
+	// CheckpointResources takes a checkpoint of the resource assignments of all containers.
+	CheckpointResources() *ResourceCheckpoint
+	// RestoreResources restores the resource assignments of containers from a checkpoint.
+	RestoreResources(*ResourceCheckpoint)
 
 	// RefreshPods purges/inserts stale/new pods/containers using a pod sandbox list response.
 	RefreshPods(*criv1.ListPodSandboxResponse, map[string]*PodStatus) ([]Pod, []Pod, []Container)
@@ -623,11 +710,14 @@ type cache struct {
 	Containers map[string]*container // known/cache containers
 	NextID     uint64                // next container cache id to use
 
-	Cfg        *config.RawConfig      // cached/current configuration
-	External   *config.Adjustment     // cached/current external adjustments
-	PolicyName string                 // name of the active policy
-	policyData map[string]interface{} // opaque policy data
-	PolicyJSON map[string]string      // ditto in raw, unmarshaled form
+	Cfg        *config.RawConfig        // cached/current configuration
+	History    []*config.ConfigRevision // history of applied configurations
+	Revision   uint64                   // last configuration revision used
+	historyLen int                      // max. number of history entries to keep
+	External   *config.Adjustment       // cached/current external adjustments
+	PolicyName string                   // name of the active policy
+	policyData map[string]interface{}   // opaque policy data
+	PolicyJSON map[string]string        // ditto in raw, unmarshaled form
 
 	pending map[string]struct{} // cache IDs of containers with pending changes
 
@@ -641,8 +731,15 @@ var _ Cache = &cache{}
 type Options struct {
 	// CacheDir is the directory the cache should save its state in.
 	CacheDir string
+	// ConfigHistory is the number of configuration history entries to keep.
+	ConfigHistory int
 }
 
+const (
+	// DefaultConfigHistory is the default number of configuration history entries.
+	DefaultConfigHistory = 16
+)
+
 // NewCache instantiates a new cache. Load it from the given path if it exists.
 func NewCache(options Options) (Cache, error) {
 	cch := &cache{
@@ -655,6 +752,11 @@ func NewCache(options Options) (Cache, error) {
 		policyData: make(map[string]interface{}),
 		PolicyJSON: make(map[string]string),
 		implicit:   make(map[string]ImplicitAffinity),
+		historyLen: options.ConfigHistory,
+	}
+
+	if cch.historyLen <= 0 {
+		cch.historyLen = DefaultConfigHistory
 	}
 
 	if _, err := cch.checkPerm("cache", cch.filePath, false, cacheFilePerm); err != nil {
@@ -1416,6 +1518,40 @@ func (cch *cache) checkPerm(what, path string, isDir bool, p *permissions) (bool
 }
 
 // mkdirAll creates a directory, checking permissions if it already exists.
+// AddConfigRevision records a configuration in the configuration history.
+func (cch *cache) AddConfigRevision(rev *config.ConfigRevision) error {
+	oldHistory, oldRevision := cch.History, cch.Revision
+
+	cch.Revision++
+	rev.Revision = cch.Revision
+	cch.History = append(cch.History, rev)
+	if extra := len(cch.History) - cch.historyLen; extra > 0 {
+		cch.History = append([]*config.ConfigRevision{}, cch.History[extra:]...)
+	}
+
+	if err := cch.Save(); err != nil {
+		cch.History, cch.Revision = oldHistory, oldRevision
+		return err
+	}
+
+	return nil
+}
+
+// GetConfigHistory returns the recorded configuration history.
+func (cch *cache) GetConfigHistory() []*config.ConfigRevision {
+	return append([]*config.ConfigRevision{}, cch.History...)
+}
+
+// GetConfigRevision returns the given revision from the configuration history.
+func (cch *cache) GetConfigRevision(revision uint64) *config.ConfigRevision {
+	for _, rev := range cch.History {
+		if rev.Revision == revision {
+			return rev
+		}
+	}
+	return nil
+}
+
 func (cch *cache) mkdirAll(what, path string, p *permissions) error {
 	exists, err := cch.checkPerm(what, path, true, p)
 	if err != nil {
@@ -1439,6 +1575,8 @@ type snapshot struct {
 	Containers map[string]*container
 	NextID     uint64
 	Cfg        *config.RawConfig
+	History    []*config.ConfigRevision `json:",omitempty"`
+	Revision   uint64                   `json:",omitempty"`
 	PolicyName string
 	PolicyJSON map[string]string
 }
@@ -1450,6 +1588,8 @@ func (cch *cache) Snapshot() ([]byte, error) {
 		Pods:       make(map[string]*pod),
 		Containers: make(map[string]*container),
 		Cfg:        cch.Cfg,
+		History:    cch.History,
+		Revision:   cch.Revision,
 		NextID:     cch.NextID,
 		PolicyName: cch.PolicyName,
 		PolicyJSON: cch.PolicyJSON,
@@ -1502,6 +1642,8 @@ func (cch *cache) Restore(data []byte) error {
 	cch.Pods = s.Pods
 	cch.Containers = s.Containers
 	cch.Cfg = s.Cfg
+	cch.History = s.History
+	cch.Revision = s.Revision
 	cch.NextID = s.NextID
 	cch.PolicyJSON = s.PolicyJSON
 	cch.PolicyName = s.PolicyName
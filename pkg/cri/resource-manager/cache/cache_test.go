@@ -20,12 +20,14 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
 	kubecm "k8s.io/kubernetes/pkg/kubelet/cm"
 	kubetypes "k8s.io/kubernetes/pkg/kubelet/types"
 
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/config"
 	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/kubernetes"
 )
 
@@ -418,3 +420,95 @@ func TestCPULimitCalculationAccuracy(t *testing.T) {
 		}
 	}
 }
+
+func TestConfigHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache-test")
+	if err != nil {
+		t.Fatalf("failed to create cache directory: %v", err)
+	}
+	defer removeTmpCache(dir)
+
+	const historyLen = 3
+	cch, err := NewCache(Options{CacheDir: dir, ConfigHistory: historyLen})
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+
+	for i := 1; i <= 5; i++ {
+		rev := &config.ConfigRevision{
+			Timestamp: time.Now(),
+			Source:    config.ConfigSourceAgent,
+			Data:      map[string]string{"policy": fmt.Sprintf("Active: rev%d", i)},
+		}
+		if i == 4 {
+			rev.Error = "rejected"
+		}
+		if err := cch.AddConfigRevision(rev); err != nil {
+			t.Fatalf("failed to add configuration revision %d: %v", i, err)
+		}
+		if rev.Revision != uint64(i) {
+			t.Errorf("expected revision %d, got %d", i, rev.Revision)
+		}
+	}
+
+	// reload the cache to check that the history is persisted
+	cch, err = NewCache(Options{CacheDir: dir, ConfigHistory: historyLen})
+	if err != nil {
+		t.Fatalf("failed to reload cache: %v", err)
+	}
+
+	history := cch.GetConfigHistory()
+	if len(history) != historyLen {
+		t.Fatalf("expected %d history entries, got %d", historyLen, len(history))
+	}
+	for i, rev := range history {
+		if expected := uint64(3 + i); rev.Revision != expected {
+			t.Errorf("history entry #%d: expected revision %d, got %d", i, expected, rev.Revision)
+		}
+	}
+
+	if rev := cch.GetConfigRevision(2); rev != nil {
+		t.Errorf("expected revision 2 to be dropped from the history, got %+v", rev)
+	}
+	if rev := cch.GetConfigRevision(4); rev == nil || rev.Applied() {
+		t.Errorf("expected revision 4 to be a rejected configuration, got %+v", rev)
+	}
+	if rev := cch.GetConfigRevision(5); rev == nil || rev.Data["policy"] != "Active: rev5" {
+		t.Errorf("unexpected data for revision 5: %+v", rev)
+	}
+
+	if err := cch.AddConfigRevision(&config.ConfigRevision{}); err != nil {
+		t.Fatalf("failed to add configuration revision: %v", err)
+	}
+	if last := cch.GetConfigHistory()[historyLen-1]; last.Revision != 6 {
+		t.Errorf("expected revision 6 after reload, got %d", last.Revision)
+	}
+}
+
+func TestEstimateHugePages(t *testing.T) {
+	lnx := &criv1.LinuxContainerResources{
+		HugepageLimits: []*criv1.HugepageLimit{
+			{PageSize: "2MB", Limit: 64 << 20},
+			{PageSize: "1GB", Limit: 2 << 30},
+			{PageSize: "64KB", Limit: 0},
+		},
+	}
+
+	resources := estimateComputeResources(lnx, "")
+
+	expected := map[v1.ResourceName]int64{
+		"hugepages-2Mi": 64 << 20,
+		"hugepages-1Gi": 2 << 30,
+	}
+	for name, value := range expected {
+		if qty, ok := resources.Limits[name]; !ok || qty.Value() != value {
+			t.Errorf("expected %s limit %d, got %v", name, value, resources.Limits[name])
+		}
+		if qty, ok := resources.Requests[name]; !ok || qty.Value() != value {
+			t.Errorf("expected %s request %d, got %v", name, value, resources.Requests[name])
+		}
+	}
+	if _, ok := resources.Limits["hugepages-64Ki"]; ok {
+		t.Errorf("unexpected limit for hugepages with no limit")
+	}
+}
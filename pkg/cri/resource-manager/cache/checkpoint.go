@@ -0,0 +1,151 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"reflect"
+
+	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+// ResourceCheckpoint is a checkpoint of the resource assignments of containers.
+type ResourceCheckpoint struct {
+	containers map[string]*resourceState // checkpointed containers by cache ID
+}
+
+// resourceState is the checkpointed resource assignment of a single container.
+type resourceState struct {
+	linuxReq     *criv1.LinuxContainerResources
+	rdtClass     string
+	blockIOClass string
+	cpuClass     string
+	toptierLimit int64
+	memoryQoS    *MemoryQoS
+	pageMigrate  *PageMigrate
+	tags         map[string]string
+	req          *interface{}
+	pending      map[string]struct{}
+}
+
+// CheckpointResources takes a checkpoint of the resource assignments of all containers.
+func (cch *cache) CheckpointResources() *ResourceCheckpoint {
+	cp := &ResourceCheckpoint{
+		containers: make(map[string]*resourceState),
+	}
+
+	for id, c := range cch.Containers {
+		if id != c.CacheID {
+			continue
+		}
+		cp.containers[id] = &resourceState{
+			linuxReq:     copyLinuxResources(c.LinuxReq),
+			rdtClass:     c.RDTClass,
+			blockIOClass: c.BlockIOClass,
+			cpuClass:     c.CPUClass,
+			toptierLimit: c.ToptierLimit,
+			memoryQoS:    c.MemoryQoS.Clone(),
+			pageMigrate:  c.PageMigrate.Clone(),
+			tags:         copyStringMap(c.Tags),
+			req:          c.req,
+			pending:      copyPending(c.pending),
+		}
+	}
+
+	return cp
+}
+
+// RestoreResources restores the resource assignments of containers from a
+// checkpoint. Containers with assignments changed since the checkpoint are
+// marked pending for all controllers, so that the restored assignments get
+// enforced again. Containers created since the checkpoint are left intact.
+func (cch *cache) RestoreResources(cp *ResourceCheckpoint) {
+	for id, s := range cp.containers {
+		c, ok := cch.Containers[id]
+		if !ok {
+			continue
+		}
+
+		changed := !reflect.DeepEqual(c.LinuxReq, s.linuxReq) ||
+			c.RDTClass != s.rdtClass || c.BlockIOClass != s.blockIOClass ||
+			c.CPUClass != s.cpuClass ||
+			c.ToptierLimit != s.toptierLimit ||
+			!reflect.DeepEqual(c.MemoryQoS, s.memoryQoS) ||
+			!reflect.DeepEqual(c.PageMigrate, s.pageMigrate) ||
+			!reflect.DeepEqual(c.Tags, s.tags)
+
+		c.LinuxReq = copyLinuxResources(s.linuxReq)
+		c.RDTClass = s.rdtClass
+		c.BlockIOClass = s.blockIOClass
+		c.CPUClass = s.cpuClass
+		c.ToptierLimit = s.toptierLimit
+		c.MemoryQoS = s.memoryQoS.Clone()
+		c.PageMigrate = s.pageMigrate.Clone()
+		c.Tags = copyStringMap(s.tags)
+		c.req = s.req
+
+		c.pending = copyPending(s.pending)
+		if len(c.pending) == 0 {
+			cch.clearPending(c)
+		} else {
+			cch.markPending(c)
+		}
+
+		if changed {
+			cch.Info("%s: restored resource assignments from checkpoint", c.PrettyName())
+			c.markPending(allControllers...)
+		}
+	}
+}
+
+// copyLinuxResources returns a copy of the given Linux container resources.
+func copyLinuxResources(r *criv1.LinuxContainerResources) *criv1.LinuxContainerResources {
+	if r == nil {
+		return nil
+	}
+	cp := *r
+	if r.HugepageLimits != nil {
+		cp.HugepageLimits = make([]*criv1.HugepageLimit, 0, len(r.HugepageLimits))
+		for _, l := range r.HugepageLimits {
+			limit := *l
+			cp.HugepageLimits = append(cp.HugepageLimits, &limit)
+		}
+	}
+	cp.Unified = copyStringMap(r.Unified)
+	return &cp
+}
+
+// copyStringMap returns a copy of the given string map.
+func copyStringMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	cp := make(map[string]string, len(m))
+	for k, v := range m {
+		cp[k] = v
+	}
+	return cp
+}
+
+// copyPending returns a copy of the given set of pending controllers.
+func copyPending(pending map[string]struct{}) map[string]struct{} {
+	if pending == nil {
+		return nil
+	}
+	cp := make(map[string]struct{}, len(pending))
+	for ctrl := range pending {
+		cp[ctrl] = struct{}{}
+	}
+	return cp
+}
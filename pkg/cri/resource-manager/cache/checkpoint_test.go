@@ -0,0 +1,108 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestCheckpointRestoreResources(t *testing.T) {
+	tcases := []struct {
+		name            string
+		modify          func(Container)
+		expectedPending bool
+	}{
+		{
+			name:   "no changes",
+			modify: func(Container) {},
+		},
+		{
+			name: "changed cpuset",
+			modify: func(c Container) {
+				c.SetCpusetCpus("2-3")
+			},
+			expectedPending: true,
+		},
+		{
+			name: "changed classes",
+			modify: func(c Container) {
+				c.SetRDTClass("newRDT")
+				c.SetBlockIOClass("newBlockIO")
+			},
+			expectedPending: true,
+		},
+		{
+			name: "changed tags",
+			modify: func(c Container) {
+				c.SetTag("key", "value")
+			},
+			expectedPending: true,
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cch, dir, err := createTmpCache()
+			if err != nil {
+				t.Fatalf("failed to create cache: %v", err)
+			}
+			defer removeTmpCache(dir)
+
+			fp := &fakePod{name: "pod", qos: v1.PodQOSBurstable}
+			if _, err := createFakePod(cch, fp); err != nil {
+				t.Fatalf("failed to create pod: %v", err)
+			}
+			c, err := createFakeContainer(cch, &fakeContainer{fakePod: fp, name: "ctr"})
+			if err != nil {
+				t.Fatalf("failed to create container: %v", err)
+			}
+			c.SetCpusetCpus("0-1")
+			c.SetRDTClass("RDT")
+			c.SetBlockIOClass("BlockIO")
+			for _, ctrl := range c.GetPending() {
+				c.ClearPending(ctrl)
+			}
+
+			cp := cch.CheckpointResources()
+			tc.modify(c)
+			for _, ctrl := range c.GetPending() {
+				c.ClearPending(ctrl)
+			}
+			cch.RestoreResources(cp)
+
+			if cpus := c.GetCpusetCpus(); cpus != "0-1" {
+				t.Errorf("expected restored cpuset %q, got %q", "0-1", cpus)
+			}
+			if class := c.GetRDTClass(); class != "RDT" {
+				t.Errorf("expected restored RDT class %q, got %q", "RDT", class)
+			}
+			if class := c.GetBlockIOClass(); class != "BlockIO" {
+				t.Errorf("expected restored block I/O class %q, got %q", "BlockIO", class)
+			}
+			if _, ok := c.GetTag("key"); ok {
+				t.Errorf("expected tag to be removed by restore")
+			}
+
+			pending := len(cch.GetPendingContainers()) > 0
+			if pending != tc.expectedPending {
+				t.Errorf("expected pending %v, got %v", tc.expectedPending, pending)
+			}
+			if tc.expectedPending && len(c.GetPending()) != len(allControllers) {
+				t.Errorf("expected pending controllers %v, got %v", allControllers, c.GetPending())
+			}
+		})
+	}
+}
@@ -196,6 +196,10 @@ func (c *container) setDefaults() error {
 	}
 	c.SetBlockIOClass(class)
 
+	if class, ok = c.GetEffectiveAnnotation(CPUClassKey); ok {
+		c.SetCPUClass(class)
+	}
+
 	limit, ok := c.GetEffectiveAnnotation(ToptierLimitKey)
 	if !ok {
 		c.ToptierLimit = ToptierLimitUnset
@@ -208,6 +212,17 @@ func (c *container) setDefaults() error {
 		c.SetToptierLimit(qty.Value())
 	}
 
+	qos := NewMemoryQoS()
+	if value, ok := c.GetEffectiveAnnotation(MemoryQoSKey); ok {
+		parsed, err := ParseMemoryQoS(value)
+		if err != nil {
+			return cacheError("%q: failed to parse memory QoS annotation %q (%q): %v",
+				c.PrettyName(), MemoryQoSKey, value, err)
+		}
+		qos = parsed
+	}
+	c.SetMemoryQoS(qos)
+
 	return nil
 }
 
@@ -691,6 +706,9 @@ func (c *container) SetCpusetCpus(value string) {
 	}
 	c.LinuxReq.CpusetCpus = value
 	c.markPending(CRI)
+	if c.CPUClass != "" {
+		c.markPending(CPU)
+	}
 }
 
 func (c *container) SetCpusetMems(value string) {
@@ -809,6 +827,15 @@ func (c *container) GetBlockIOClass() string {
 	return c.BlockIOClass
 }
 
+func (c *container) SetCPUClass(class string) {
+	c.CPUClass = class
+	c.markPending(CPU)
+}
+
+func (c *container) GetCPUClass() string {
+	return c.CPUClass
+}
+
 func (c *container) SetToptierLimit(limit int64) {
 	c.ToptierLimit = limit
 	c.markPending(Memory)
@@ -823,6 +850,30 @@ func (c *container) GetToptierLimit() int64 {
 	return c.ToptierLimit
 }
 
+func (c *container) SetMemoryQoS(qos *MemoryQoS) {
+	c.MemoryQoS = qos.Clone()
+	c.markPending(Memory)
+}
+
+func (c *container) GetMemoryQoS() *MemoryQoS {
+	qos := c.MemoryQoS.Clone()
+	if qos == nil {
+		qos = NewMemoryQoS()
+	}
+	if adjust, _ := c.getEffectiveAdjustment(); adjust != nil && adjust.MemoryQoS != nil {
+		if adjust.MemoryQoS.Min != nil {
+			qos.Min = adjust.MemoryQoS.Min.Value()
+		}
+		if adjust.MemoryQoS.Low != nil {
+			qos.Low = adjust.MemoryQoS.Low.Value()
+		}
+		if adjust.MemoryQoS.High != nil {
+			qos.High = adjust.MemoryQoS.High.Value()
+		}
+	}
+	return qos
+}
+
 func (c *container) SetPageMigration(p *PageMigrate) {
 	c.PageMigrate = p
 	c.markPending(PageMigration)
@@ -1013,6 +1064,8 @@ func (c *container) Eval(key string) interface{} {
 		return c.Tags
 	case resmgr.KeyID:
 		return c.ID
+	case resmgr.KeyImage:
+		return c.Image
 	default:
 		return cacheError("%s: Container cannot evaluate of %q", c.PrettyName(), key)
 	}
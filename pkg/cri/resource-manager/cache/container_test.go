@@ -164,3 +164,60 @@ func TestKeysInNamespace(t *testing.T) {
 		})
 	}
 }
+
+func TestParseMemoryQoS(t *testing.T) {
+	tcases := []struct {
+		name        string
+		value       string
+		expected    *MemoryQoS
+		expectError bool
+	}{
+		{
+			name:     "empty value overrides nothing",
+			expected: NewMemoryQoS(),
+		},
+		{
+			name:     "all settings",
+			value:    "min=1Ki, low=2Ki,high=4Ki",
+			expected: &MemoryQoS{Min: 1024, Low: 2048, High: 4096},
+		},
+		{
+			name:     "partial settings",
+			value:    "high=1M",
+			expected: &MemoryQoS{Min: MemoryQoSUnset, Low: MemoryQoSUnset, High: 1000000},
+		},
+		{
+			name:        "unknown key",
+			value:       "max=1G",
+			expectError: true,
+		},
+		{
+			name:        "missing value",
+			value:       "low",
+			expectError: true,
+		},
+		{
+			name:        "invalid quantity",
+			value:       "low=lots",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			qos, err := ParseMemoryQoS(tc.value)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected error, received %v", qos)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if !cmp.Equal(qos, tc.expected) {
+				t.Errorf("Expected %v, received %v", tc.expected, qos)
+			}
+		})
+	}
+}
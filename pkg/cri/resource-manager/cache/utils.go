@@ -70,6 +70,18 @@ func estimateComputeResources(lnx *criv1.LinuxContainerResources, cgroupParent s
 		resources.Limits[corev1.ResourceMemory] = *qty
 	}
 
+	// get hugepage limits, for hugepages requests are always equal to limits
+	for _, hp := range lnx.HugepageLimits {
+		if hp.Limit == 0 {
+			continue
+		}
+		if name, ok := hugePageResourceName(hp.PageSize); ok {
+			qty := resapi.NewQuantity(int64(hp.Limit), resapi.BinarySI)
+			resources.Requests[name] = *qty
+			resources.Limits[name] = *qty
+		}
+	}
+
 	// set or calculate CPU limit, set memory request if known
 	if qos == corev1.PodQOSGuaranteed {
 		resources.Limits[corev1.ResourceCPU] = resources.Requests[corev1.ResourceCPU]
@@ -84,6 +96,19 @@ func estimateComputeResources(lnx *criv1.LinuxContainerResources, cgroupParent s
 	return resources
 }
 
+// hugePageResourceName returns the resource name for a CRI hugepage size (for instance 2MB).
+func hugePageResourceName(pageSize string) (corev1.ResourceName, bool) {
+	units := map[string]string{"KB": "Ki", "MB": "Mi", "GB": "Gi", "TB": "Ti"}
+	if len(pageSize) < 3 {
+		return "", false
+	}
+	num, unit := pageSize[:len(pageSize)-2], units[pageSize[len(pageSize)-2:]]
+	if _, err := strconv.ParseUint(num, 10, 64); err != nil || unit == "" {
+		return "", false
+	}
+	return corev1.ResourceName(corev1.ResourceHugePagesPrefix + num + unit), true
+}
+
 // SharesToMilliCPU converts CFS CPU shares to milliCPU.
 func SharesToMilliCPU(shares int64) int64 {
 	return sharesToMilliCPU(shares)
@@ -24,6 +24,10 @@ const _ = grpc.SupportPackageIsVersion7
 type ConfigClient interface {
 	SetConfig(ctx context.Context, in *SetConfigRequest, opts ...grpc.CallOption) (*SetConfigReply, error)
 	SetAdjustment(ctx context.Context, in *SetAdjustmentRequest, opts ...grpc.CallOption) (*SetAdjustmentReply, error)
+	Simulate(ctx context.Context, in *SimulateRequest, opts ...grpc.CallOption) (*SimulateReply, error)
+	ValidateConfig(ctx context.Context, in *ValidateConfigRequest, opts ...grpc.CallOption) (*ValidateConfigReply, error)
+	GetConfigHistory(ctx context.Context, in *GetConfigHistoryRequest, opts ...grpc.CallOption) (*GetConfigHistoryReply, error)
+	RollbackConfig(ctx context.Context, in *RollbackConfigRequest, opts ...grpc.CallOption) (*RollbackConfigReply, error)
 }
 
 type configClient struct {
@@ -52,12 +56,52 @@ func (c *configClient) SetAdjustment(ctx context.Context, in *SetAdjustmentReque
 	return out, nil
 }
 
+func (c *configClient) Simulate(ctx context.Context, in *SimulateRequest, opts ...grpc.CallOption) (*SimulateReply, error) {
+	out := new(SimulateReply)
+	err := c.cc.Invoke(ctx, "/v1.Config/Simulate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *configClient) ValidateConfig(ctx context.Context, in *ValidateConfigRequest, opts ...grpc.CallOption) (*ValidateConfigReply, error) {
+	out := new(ValidateConfigReply)
+	err := c.cc.Invoke(ctx, "/v1.Config/ValidateConfig", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *configClient) GetConfigHistory(ctx context.Context, in *GetConfigHistoryRequest, opts ...grpc.CallOption) (*GetConfigHistoryReply, error) {
+	out := new(GetConfigHistoryReply)
+	err := c.cc.Invoke(ctx, "/v1.Config/GetConfigHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *configClient) RollbackConfig(ctx context.Context, in *RollbackConfigRequest, opts ...grpc.CallOption) (*RollbackConfigReply, error) {
+	out := new(RollbackConfigReply)
+	err := c.cc.Invoke(ctx, "/v1.Config/RollbackConfig", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ConfigServer is the server API for Config service.
 // All implementations must embed UnimplementedConfigServer
 // for forward compatibility
 type ConfigServer interface {
 	SetConfig(context.Context, *SetConfigRequest) (*SetConfigReply, error)
 	SetAdjustment(context.Context, *SetAdjustmentRequest) (*SetAdjustmentReply, error)
+	Simulate(context.Context, *SimulateRequest) (*SimulateReply, error)
+	ValidateConfig(context.Context, *ValidateConfigRequest) (*ValidateConfigReply, error)
+	GetConfigHistory(context.Context, *GetConfigHistoryRequest) (*GetConfigHistoryReply, error)
+	RollbackConfig(context.Context, *RollbackConfigRequest) (*RollbackConfigReply, error)
 	mustEmbedUnimplementedConfigServer()
 }
 
@@ -71,6 +115,18 @@ func (UnimplementedConfigServer) SetConfig(context.Context, *SetConfigRequest) (
 func (UnimplementedConfigServer) SetAdjustment(context.Context, *SetAdjustmentRequest) (*SetAdjustmentReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SetAdjustment not implemented")
 }
+func (UnimplementedConfigServer) Simulate(context.Context, *SimulateRequest) (*SimulateReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Simulate not implemented")
+}
+func (UnimplementedConfigServer) ValidateConfig(context.Context, *ValidateConfigRequest) (*ValidateConfigReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ValidateConfig not implemented")
+}
+func (UnimplementedConfigServer) GetConfigHistory(context.Context, *GetConfigHistoryRequest) (*GetConfigHistoryReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConfigHistory not implemented")
+}
+func (UnimplementedConfigServer) RollbackConfig(context.Context, *RollbackConfigRequest) (*RollbackConfigReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RollbackConfig not implemented")
+}
 func (UnimplementedConfigServer) mustEmbedUnimplementedConfigServer() {}
 
 // UnsafeConfigServer may be embedded to opt out of forward compatibility for this service.
@@ -120,6 +176,78 @@ func _Config_SetAdjustment_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Config_Simulate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SimulateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConfigServer).Simulate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.Config/Simulate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConfigServer).Simulate(ctx, req.(*SimulateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Config_ValidateConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConfigServer).ValidateConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.Config/ValidateConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConfigServer).ValidateConfig(ctx, req.(*ValidateConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Config_GetConfigHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetConfigHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConfigServer).GetConfigHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.Config/GetConfigHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConfigServer).GetConfigHistory(ctx, req.(*GetConfigHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Config_RollbackConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RollbackConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConfigServer).RollbackConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.Config/RollbackConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConfigServer).RollbackConfig(ctx, req.(*RollbackConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Config_ServiceDesc is the grpc.ServiceDesc for Config service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -135,6 +263,22 @@ var Config_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SetAdjustment",
 			Handler:    _Config_SetAdjustment_Handler,
 		},
+		{
+			MethodName: "Simulate",
+			Handler:    _Config_Simulate_Handler,
+		},
+		{
+			MethodName: "ValidateConfig",
+			Handler:    _Config_ValidateConfig_Handler,
+		},
+		{
+			MethodName: "GetConfigHistory",
+			Handler:    _Config_GetConfigHistory_Handler,
+		},
+		{
+			MethodName: "RollbackConfig",
+			Handler:    _Config_RollbackConfig_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "pkg/cri/resource-manager/config/api/v1/api.proto",
@@ -17,6 +17,8 @@ limitations under the License.
 package config
 
 import (
+	"time"
+
 	extapi "github.com/intel/cri-resource-manager/pkg/apis/resmgr/v1alpha1"
 )
 
@@ -29,6 +31,45 @@ type RawConfig struct {
 	Data map[string]string
 }
 
+// ConfigSource identifies where an applied configuration came from.
+type ConfigSource string
+
+const (
+	// ConfigSourceFile is a configuration loaded from a file during startup.
+	ConfigSourceFile ConfigSource = "file"
+	// ConfigSourceAgent is a configuration received from the agent.
+	ConfigSourceAgent ConfigSource = "agent"
+	// ConfigSourceSignal is a forced configuration file reloaded by a signal.
+	ConfigSourceSignal ConfigSource = "signal"
+	// ConfigSourceRollback is a configuration restored from the history.
+	ConfigSourceRollback ConfigSource = "rollback"
+)
+
+// ConfigRevision is an entry in the history of configurations we tried to apply.
+type ConfigRevision struct {
+	// Revision is the sequence number of this entry in the history.
+	Revision uint64
+	// Timestamp is the time the configuration was applied.
+	Timestamp time.Time
+	// Source is where the configuration came from.
+	Source ConfigSource
+	// Path is the configuration file for file and signal sources.
+	Path string `json:",omitempty"`
+	// NodeName is the node name the agent used to acquire configuration.
+	NodeName string `json:",omitempty"`
+	// RollbackOf is the revision restored by a rollback.
+	RollbackOf uint64 `json:",omitempty"`
+	// Data is the configuration data.
+	Data map[string]string
+	// Error is the reason the configuration was rejected, empty if it was applied.
+	Error string `json:",omitempty"`
+}
+
+// Applied returns true if the configuration was applied successfully.
+func (r *ConfigRevision) Applied() bool {
+	return r.Error == ""
+}
+
 // Adjustment represents external adjustments for this node.
 type Adjustment struct {
 	// Adjustments contains all adjustment CRDs for this node.
@@ -32,6 +32,7 @@ import (
 	"encoding/json"
 
 	extapi "github.com/intel/cri-resource-manager/pkg/apis/resmgr/v1alpha1"
+	pkgcfg "github.com/intel/cri-resource-manager/pkg/config"
 )
 
 const (
@@ -44,6 +45,18 @@ type SetConfigCb func(*RawConfig) error
 // SetAdjustmentCb is a callback function for a SetAdjustment request.
 type SetAdjustmentCb func(*Adjustment) map[string]error
 
+// SimulateCb is a callback function for a Simulate request.
+type SimulateCb func(*RawConfig) ([]byte, error)
+
+// ValidateCb is a callback function for a ValidateConfig request.
+type ValidateCb func(*RawConfig) (pkgcfg.ValidationErrors, error)
+
+// GetConfigHistoryCb is a callback function for a GetConfigHistory request.
+type GetConfigHistoryCb func() []*ConfigRevision
+
+// RollbackConfigCb is a callback function for a RollbackConfig request.
+type RollbackConfigCb func(uint64) error
+
 // Server is the interface for our gRPC server.
 type Server interface {
 	Start(string) error
@@ -54,19 +67,28 @@ type Server interface {
 type server struct {
 	v1.UnimplementedConfigServer
 	log.Logger
-	socket          string          // configured socket
-	sync.Mutex                      // lock for concurrent per-request goroutines.
-	server          *grpc.Server    // gRPC server instance
-	setConfigCb     SetConfigCb     // configuration update notification callback
-	setAdjustmentCb SetAdjustmentCb // extneral adjustment update notification callback
+	socket          string             // configured socket
+	sync.Mutex                         // lock for concurrent per-request goroutines.
+	server          *grpc.Server       // gRPC server instance
+	setConfigCb     SetConfigCb        // configuration update notification callback
+	setAdjustmentCb SetAdjustmentCb    // extneral adjustment update notification callback
+	simulateCb      SimulateCb         // configuration simulation callback
+	validateCb      ValidateCb         // configuration validation callback
+	historyCb       GetConfigHistoryCb // configuration history callback
+	rollbackCb      RollbackConfigCb   // configuration rollback callback
 }
 
 // NewConfigServer creates new Server instance.
-func NewConfigServer(configCb SetConfigCb, adjustmentCb SetAdjustmentCb) (Server, error) {
+func NewConfigServer(configCb SetConfigCb, adjustmentCb SetAdjustmentCb, simulateCb SimulateCb, validateCb ValidateCb,
+	historyCb GetConfigHistoryCb, rollbackCb RollbackConfigCb) (Server, error) {
 	s := &server{
 		Logger:          log.NewLogger("config-server"),
 		setConfigCb:     configCb,
 		setAdjustmentCb: adjustmentCb,
+		simulateCb:      simulateCb,
+		validateCb:      validateCb,
+		historyCb:       historyCb,
+		rollbackCb:      rollbackCb,
 	}
 	return s, nil
 }
@@ -166,6 +188,103 @@ func (s *server) SetAdjustment(ctx context.Context, req *v1.SetAdjustmentRequest
 	return reply, nil
 }
 
+// Simulate evaluates a candidate configuration without applying it.
+func (s *server) Simulate(ctx context.Context, req *v1.SimulateRequest) (*v1.SimulateReply, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.Debug("Simulate request: %+v", req)
+
+	reply := &v1.SimulateReply{}
+	if s.simulateCb == nil {
+		reply.Error = "simulation is not supported"
+		return reply, nil
+	}
+
+	simulation, err := s.simulateCb(&RawConfig{Data: req.Config})
+	if err != nil {
+		reply.Error = fmt.Sprintf("failed to simulate configuration: %v", err)
+	}
+	reply.Simulation = string(simulation)
+
+	return reply, nil
+}
+
+// ValidateConfig checks a candidate configuration without applying it.
+func (s *server) ValidateConfig(ctx context.Context, req *v1.ValidateConfigRequest) (*v1.ValidateConfigReply, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.Debug("ValidateConfig request: %+v", req)
+
+	reply := &v1.ValidateConfigReply{}
+	if s.validateCb == nil {
+		reply.Error = "validation is not supported"
+		return reply, nil
+	}
+
+	errs, err := s.validateCb(&RawConfig{Data: req.Config})
+	if err != nil {
+		reply.Error = fmt.Sprintf("failed to validate configuration: %v", err)
+	}
+	for _, e := range errs {
+		reply.Errors = append(reply.Errors, &v1.ValidationError{
+			Module: e.Module,
+			Error:  e.Reason,
+		})
+	}
+
+	return reply, nil
+}
+
+// GetConfigHistory returns the history of applied configurations.
+func (s *server) GetConfigHistory(ctx context.Context, req *v1.GetConfigHistoryRequest) (*v1.GetConfigHistoryReply, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.Debug("GetConfigHistory request: %+v", req)
+
+	reply := &v1.GetConfigHistoryReply{}
+	if s.historyCb == nil {
+		return reply, nil
+	}
+
+	for _, rev := range s.historyCb() {
+		reply.History = append(reply.History, &v1.ConfigRevision{
+			Revision:   rev.Revision,
+			Timestamp:  rev.Timestamp.UnixNano(),
+			Source:     string(rev.Source),
+			Path:       rev.Path,
+			NodeName:   rev.NodeName,
+			RollbackOf: rev.RollbackOf,
+			Config:     rev.Data,
+			Error:      rev.Error,
+		})
+	}
+
+	return reply, nil
+}
+
+// RollbackConfig reactivates a configuration from the history.
+func (s *server) RollbackConfig(ctx context.Context, req *v1.RollbackConfigRequest) (*v1.RollbackConfigReply, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.Debug("RollbackConfig request: %+v", req)
+
+	reply := &v1.RollbackConfigReply{}
+	if s.rollbackCb == nil {
+		reply.Error = "configuration rollback is not supported"
+		return reply, nil
+	}
+
+	if err := s.rollbackCb(req.Revision); err != nil {
+		reply.Error = fmt.Sprintf("failed to roll back configuration: %v", err)
+	}
+
+	return reply, nil
+}
+
 func serverError(format string, args ...interface{}) error {
 	return fmt.Errorf(format, args...)
 }
@@ -68,3 +68,20 @@ func Assign(c cache.Cache, class string, cpus ...int) error {
 
 	return nil
 }
+
+// GetCPUClass returns the class a cpu is assigned to, or an empty string if none.
+func GetCPUClass(c cache.Cache, cpu int) string {
+	for class, cpus := range *getClassAssignments(c) {
+		if cpus.Has(cpu) {
+			return class
+		}
+	}
+	return ""
+}
+
+// Release hands a set of cpus back to the controller. They are assigned to the
+// default class, or dropped from their class and restored to their original
+// settings if there is no default class.
+func Release(c cache.Cache, cpus ...int) {
+	getCPUController().releaseCPUs(c, cpus...)
+}
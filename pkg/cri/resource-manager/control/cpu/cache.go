@@ -20,7 +20,8 @@ import (
 )
 
 const (
-	cacheKeyCPUAssignments = "CPUClassAssignments"
+	cacheKeyCPUAssignments      = "CPUClassAssignments"
+	cacheKeyContainerCPUClasses = "ContainerCPUClasses"
 )
 
 // cpuClassAssignments contains the information about how cpus are assigned to
@@ -58,3 +59,46 @@ func (c *cpuClassAssignments) Set(value interface{}) {
 func (c *cpuClassAssignments) Get() interface{} {
 	return *c
 }
+
+// containerCPUClass contains the CPUs of a container assigned to the CPU
+// class of the container
+type containerCPUClass struct {
+	Class string      `json:"class"`
+	CPUs  utils.IDSet `json:"cpus"`
+}
+
+// containerCPUClasses contains the CPU class assignments of containers, by
+// cache ID
+type containerCPUClasses map[string]containerCPUClass
+
+// Get the state of container CPU class assignments from cache
+func getContainerCPUClasses(c cache.Cache) *containerCPUClasses {
+	a := &containerCPUClasses{}
+
+	if !c.GetPolicyEntry(cacheKeyContainerCPUClasses, a) {
+		log.Debug("no cached state of container CPU class assignments found")
+	}
+
+	return a
+}
+
+// Save the state of container CPU class assignments in cache
+func setContainerCPUClasses(c cache.Cache, a *containerCPUClasses) {
+	c.SetPolicyEntry(cacheKeyContainerCPUClasses, cache.Cachable(a))
+}
+
+// Set the value of cached containerCPUClasses
+func (c *containerCPUClasses) Set(value interface{}) {
+	switch value.(type) {
+	case containerCPUClasses:
+		*c = value.(containerCPUClasses)
+	case *containerCPUClasses:
+		cp := value.(*containerCPUClasses)
+		*c = *cp
+	}
+}
+
+// Get cached containerCPUClasses
+func (c *containerCPUClasses) Get() interface{} {
+	return *c
+}
@@ -42,10 +42,17 @@ type cpuctl struct {
 	system  sysfs.System // system topology
 	config  *config
 	started bool
+	// settings of CPUs before we first assigned them to a class
+	original map[int]cpuSettings
 }
 
 type config struct {
 	Classes map[string]Class `json:"classes"`
+	// DefaultClass is the class CPUs are assigned to once they are no
+	// longer exclusive to a container with a CPU class. If empty, such
+	// CPUs are dropped from their class and their cpufreq and cpuidle
+	// settings are restored to what they were before they got a class.
+	DefaultClass string `json:"defaultClass,omitempty"`
 
 	// Private field for storing info if we need to care about uncore
 	uncoreEnabled bool
@@ -57,6 +64,13 @@ type Class struct {
 	EnergyPerformancePreference uint `json:"energyPerformancePreference"`
 	UncoreMinFreq               uint `json:"uncoreMinFreq"`
 	UncoreMaxFreq               uint `json:"uncoreMaxFreq"`
+	// Governor is the cpufreq scaling governor, left untouched if empty.
+	Governor string `json:"governor,omitempty"`
+	// DisabledIdleStates lists the names of idle states (for instance C6) to
+	// disable. All other idle states are enabled. Left untouched if nil.
+	DisabledIdleStates []string `json:"disabledIdleStates,omitempty"`
+	// DisableTurbo caps the maximum frequency at the base frequency.
+	DisableTurbo bool `json:"disableTurbo,omitempty"`
 }
 
 var log logger.Logger = logger.NewLogger(CPUController)
@@ -115,35 +129,221 @@ func (ctl *cpuctl) PreStartHook(c cache.Container) error {
 
 // PostStartHook handler for the CPU controller.
 func (ctl *cpuctl) PostStartHook(c cache.Container) error {
-	return nil
+	return ctl.syncContainerClasses(c)
 }
 
 // PostUpdateHook handler for the CPU controller.
 func (ctl *cpuctl) PostUpdateHook(c cache.Container) error {
-	return nil
+	return ctl.syncContainerClasses(c)
 }
 
 // PostStopHook handler for the CPU controller.
 func (ctl *cpuctl) PostStopHook(c cache.Container) error {
-	return nil
+	return ctl.syncContainerClasses(c)
 }
 
-// enforceCpufreq enforces a class-specific cpufreq configuration to a cpuset
+// syncContainerClasses assigns the CPUs exclusive to a container with a CPU
+// class, CPUs no other active container is pinned to, to the class of the
+// container. CPUs no longer exclusive to such a container, because it has
+// stopped or its CPUs have changed, are given back to the default class.
+// Only errors related to the given container are returned.
+func (ctl *cpuctl) syncContainerClasses(c cache.Container) error {
+	c.ClearPending(CPUController)
+
+	cpusets := map[string]cpuset.CPUSet{}
+	users := map[int]int{}
+	for _, ctr := range ctl.cache.GetContainers() {
+		switch ctr.GetState() {
+		case cache.ContainerStateCreating, cache.ContainerStateCreated, cache.ContainerStateRunning:
+		default:
+			continue
+		}
+		cpus, err := cpuset.Parse(ctr.GetCpusetCpus())
+		if err != nil {
+			log.Warn("%s: failed to parse cpuset %q: %v", ctr.PrettyName(), ctr.GetCpusetCpus(), err)
+			continue
+		}
+		cpusets[ctr.GetCacheID()] = cpus
+		for _, cpu := range cpus.ToSliceNoSort() {
+			users[cpu]++
+		}
+	}
+
+	var err error
+	old := *getContainerCPUClasses(ctl.cache)
+	assigned := containerCPUClasses{}
+	for _, ctr := range ctl.cache.GetContainers() {
+		class := ctr.GetCPUClass()
+		cpus, ok := cpusets[ctr.GetCacheID()]
+		if class == "" || !ok {
+			continue
+		}
+		if _, ok := ctl.config.Classes[class]; !ok {
+			log.Error("%s: non-existent cpu class %q", ctr.PrettyName(), class)
+			if ctr.GetCacheID() == c.GetCacheID() {
+				err = fmt.Errorf("%s: non-existent cpu class %q", ctr.PrettyName(), class)
+			}
+			continue
+		}
+		exclusive := utils.NewIDSet()
+		for _, cpu := range cpus.ToSliceNoSort() {
+			if users[cpu] == 1 {
+				exclusive.Add(cpu)
+			}
+		}
+		if exclusive.Size() == 0 {
+			log.Debug("%s: no exclusive CPUs, can't assign cpu class %q", ctr.PrettyName(), class)
+			continue
+		}
+		assigned[ctr.GetCacheID()] = containerCPUClass{Class: class, CPUs: exclusive}
+	}
+
+	released := utils.NewIDSet()
+	for id, o := range old {
+		for cpu := range o.CPUs {
+			if a, ok := assigned[id]; !ok || !a.CPUs.Has(cpu) {
+				released.Add(cpu)
+			}
+		}
+	}
+	for _, a := range assigned {
+		released.Del(a.CPUs.Members()...)
+	}
+	if released.Size() > 0 {
+		ctl.releaseCPUs(ctl.cache, released.SortedMembers()...)
+	}
+
+	for id, a := range assigned {
+		cpus := a.CPUs.Clone()
+		if o, ok := old[id]; ok && o.Class == a.Class {
+			cpus.Del(o.CPUs.Members()...)
+		}
+		if cpus.Size() == 0 {
+			continue
+		}
+		log.Info("assigning CPUs %s to cpu class %q", cpus, a.Class)
+		if e := Assign(ctl.cache, a.Class, cpus.SortedMembers()...); e != nil && id == c.GetCacheID() {
+			err = e
+		}
+	}
+
+	setContainerCPUClasses(ctl.cache, &assigned)
+
+	return err
+}
+
+// releaseCPUs gives CPUs back to the default class, or drops them from
+// their current class and restores their original settings if there is
+// no default class.
+func (ctl *cpuctl) releaseCPUs(cch cache.Cache, cpus ...int) {
+	if class := ctl.config.DefaultClass; class != "" {
+		log.Info("releasing CPUs %v to default cpu class %q", cpus, class)
+		if err := Assign(cch, class, cpus...); err != nil {
+			log.Error("failed to release CPUs %v to default cpu class: %v", cpus, err)
+		}
+		return
+	}
+
+	log.Info("releasing CPUs %v from their cpu class", cpus)
+	assignments := *getClassAssignments(cch)
+	for class, assigned := range assignments {
+		assigned.Del(cpus...)
+		if assigned.Size() == 0 {
+			delete(assignments, class)
+		}
+	}
+	setClassAssignments(cch, &assignments)
+
+	for _, cpu := range cpus {
+		settings, ok := ctl.original[cpu]
+		if !ok {
+			log.Warn("original settings of cpu %d unknown, leaving it configured as it is", cpu)
+			continue
+		}
+		if err := restoreCPUSettings(cpu, settings); err != nil {
+			log.Warn("failed to restore original settings of cpu %d: %v", cpu, err)
+			continue
+		}
+		delete(ctl.original, cpu)
+	}
+}
+
+// saveCPUSettings saves the settings of CPUs not assigned to any class yet.
+func (ctl *cpuctl) saveCPUSettings(cpus ...int) {
+	if ctl.original == nil {
+		ctl.original = make(map[int]cpuSettings)
+	}
+	for _, cpu := range cpus {
+		if _, ok := ctl.original[cpu]; ok {
+			continue
+		}
+		settings, err := readCPUSettings(cpu)
+		if err != nil {
+			log.Warn("failed to save original settings of cpu %d: %v", cpu, err)
+			continue
+		}
+		ctl.original[cpu] = settings
+	}
+}
+
+// enforceCpufreq enforces a class-specific cpufreq and cpuidle configuration to a cpuset
 func (ctl *cpuctl) enforceCpufreq(class string, cpus ...int) error {
-	if _, ok := ctl.config.Classes[class]; !ok {
+	cls, ok := ctl.config.Classes[class]
+	if !ok {
 		return fmt.Errorf("non-existent cpu class %q", class)
 	}
 
-	min := int(ctl.config.Classes[class].MinFreq)
-	max := int(ctl.config.Classes[class].MaxFreq)
+	ctl.saveCPUSettings(cpus...)
+
+	if cls.Governor != "" {
+		log.Debug("enforcing scaling governor %q from class %q on %v", cls.Governor, class, cpus)
+		for _, cpu := range cpus {
+			if err := setScalingGovernor(cpu, cls.Governor); err != nil {
+				return err
+			}
+		}
+	}
+
+	min := int(cls.MinFreq)
+	max := int(cls.MaxFreq)
 	log.Debug("enforcing cpu frequency limits {%d, %d} from class %q on %v", min, max, class, cpus)
 
 	if err := utils.SetCPUsScalingMinFreq(cpus, min); err != nil {
 		return fmt.Errorf("Cannot set min freq %d: %w", min, err)
 	}
 
-	if err := utils.SetCPUsScalingMaxFreq(cpus, max); err != nil {
-		return fmt.Errorf("Cannot set max freq %d: %w", max, err)
+	if !cls.DisableTurbo {
+		if err := utils.SetCPUsScalingMaxFreq(cpus, max); err != nil {
+			return fmt.Errorf("Cannot set max freq %d: %w", max, err)
+		}
+	} else {
+		for _, cpu := range cpus {
+			base, err := getBaseFrequency(cpu)
+			if err != nil {
+				return fmt.Errorf("Cannot disable turbo: %w", err)
+			}
+			cpuMax := max
+			if cpuMax == 0 || cpuMax > int(base) {
+				cpuMax = int(base)
+			}
+			if err := utils.SetCPUScalingMaxFreq(cpu, cpuMax); err != nil {
+				return fmt.Errorf("Cannot set max freq %d: %w", cpuMax, err)
+			}
+		}
+	}
+
+	if cls.DisabledIdleStates != nil {
+		log.Debug("enforcing disabled idle states %v from class %q on %v",
+			cls.DisabledIdleStates, class, cpus)
+		for _, cpu := range cpus {
+			unknown, err := setIdleStates(cpu, cls.DisabledIdleStates)
+			if err != nil {
+				return err
+			}
+			if len(unknown) > 0 {
+				log.Warn("cpu class %q: unknown idle states %v on cpu %d", class, unknown, cpu)
+			}
+		}
 	}
 
 	return nil
@@ -240,6 +440,11 @@ func (ctl *cpuctl) configure() error {
 	log.Debug("applying cpu controller configuration:\n%s", utils.DumpJSON(ctl.config))
 
 	// Sanity check
+	if class := ctl.config.DefaultClass; class != "" {
+		if _, ok := ctl.config.Classes[class]; !ok {
+			return fmt.Errorf("default cpu class %q missing from the configuration", class)
+		}
+	}
 	uncoreAvailable := utils.UncoreFreqAvailable()
 	for name, conf := range ctl.config.Classes {
 		if conf.UncoreMinFreq != 0 || conf.UncoreMaxFreq != 0 {
@@ -0,0 +1,208 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cpu
+
+import (
+	"reflect"
+	"testing"
+
+	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/cache"
+)
+
+// setupController sets up the controller singleton with a fresh cache and the given config.
+func setupController(t *testing.T, cfg *config) *cpuctl {
+	cch, err := cache.NewCache(cache.Options{CacheDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	saved := singleton
+	t.Cleanup(func() { singleton = saved })
+	singleton = &cpuctl{cache: cch, config: cfg}
+	return singleton
+}
+
+// createContainer adds a running container pinned to the given CPUs to the cache.
+func createContainer(t *testing.T, cch cache.Cache, name, cpus, class string) cache.Container {
+	podCfg := &criv1.PodSandboxConfig{
+		Metadata: &criv1.PodSandboxMetadata{Name: name, Uid: name, Namespace: "default"},
+	}
+	if _, err := cch.InsertPod(name, &criv1.RunPodSandboxRequest{Config: podCfg}, nil); err != nil {
+		t.Fatalf("failed to create pod: %v", err)
+	}
+	c, err := cch.InsertContainer(&criv1.CreateContainerRequest{
+		PodSandboxId: name,
+		Config: &criv1.ContainerConfig{
+			Metadata: &criv1.ContainerMetadata{Name: name},
+			Linux:    &criv1.LinuxContainerConfig{Resources: &criv1.LinuxContainerResources{}},
+		},
+		SandboxConfig: podCfg,
+	})
+	if err != nil {
+		t.Fatalf("failed to create container: %v", err)
+	}
+	c.SetCpusetCpus(cpus)
+	if class != "" {
+		c.SetCPUClass(class)
+	}
+	c.UpdateState(cache.ContainerStateRunning)
+	return c
+}
+
+// classAssignments returns the current CPU class assignments as cpuset strings.
+func classAssignments(cch cache.Cache) map[string]string {
+	assignments := map[string]string{}
+	for class, cpus := range *getClassAssignments(cch) {
+		assignments[class] = cpus.String()
+	}
+	return assignments
+}
+
+func TestContainerCPUClasses(t *testing.T) {
+	tcases := []struct {
+		name         string
+		defaultClass string
+		steps        []func(fast, other cache.Container)
+		expected     []map[string]string
+	}{
+		{
+			name:         "only exclusive CPUs are assigned",
+			defaultClass: "idle",
+			steps: []func(fast, other cache.Container){
+				func(fast, other cache.Container) {},
+				func(fast, other cache.Container) {
+					fast.SetCpusetCpus("0-2")
+					other.SetCpusetCpus("3-5")
+				},
+			},
+			expected: []map[string]string{
+				{"fast": "0,1"},
+				{"fast": "0,1,2"},
+			},
+		},
+		{
+			name:         "CPUs are restored on cpuset change and stop",
+			defaultClass: "idle",
+			steps: []func(fast, other cache.Container){
+				func(fast, other cache.Container) {},
+				func(fast, other cache.Container) {
+					fast.SetCpusetCpus("1")
+				},
+				func(fast, other cache.Container) {
+					fast.UpdateState(cache.ContainerStateExited)
+				},
+			},
+			expected: []map[string]string{
+				{"fast": "0,1"},
+				{"fast": "1", "idle": "0"},
+				{"idle": "0,1"},
+			},
+		},
+		{
+			name: "CPUs are dropped without a default class",
+			steps: []func(fast, other cache.Container){
+				func(fast, other cache.Container) {},
+				func(fast, other cache.Container) {
+					fast.UpdateState(cache.ContainerStateExited)
+				},
+			},
+			expected: []map[string]string{
+				{"fast": "0,1"},
+				{},
+			},
+		},
+		{
+			name:         "CPUs are restored when they get shared",
+			defaultClass: "idle",
+			steps: []func(fast, other cache.Container){
+				func(fast, other cache.Container) {},
+				func(fast, other cache.Container) {
+					other.SetCpusetCpus("1-5")
+				},
+			},
+			expected: []map[string]string{
+				{"fast": "0,1"},
+				{"fast": "0", "idle": "1"},
+			},
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := setupController(t, &config{
+				Classes: map[string]Class{
+					"fast": {Governor: "performance"},
+					"idle": {Governor: "powersave"},
+				},
+				DefaultClass: tc.defaultClass,
+			})
+			fast := createContainer(t, ctl.cache, "fast", "0-3", "fast")
+			other := createContainer(t, ctl.cache, "other", "2-5", "")
+			for i, step := range tc.steps {
+				step(fast, other)
+				if err := ctl.PostUpdateHook(fast); err != nil {
+					t.Fatalf("step #%d: post-update hook failed: %v", i, err)
+				}
+				if fast.HasPending(CPUController) {
+					t.Errorf("step #%d: expected pending CPU controller to be cleared", i)
+				}
+				if assignments := classAssignments(ctl.cache); !reflect.DeepEqual(assignments, tc.expected[i]) {
+					t.Errorf("step #%d: expected cpu class assignments %v, got %v", i, tc.expected[i], assignments)
+				}
+			}
+		})
+	}
+}
+
+func TestUnknownContainerCPUClass(t *testing.T) {
+	ctl := setupController(t, &config{Classes: map[string]Class{"fast": {}}})
+	bad := createContainer(t, ctl.cache, "bad", "0-1", "missing")
+	good := createContainer(t, ctl.cache, "good", "2-3", "fast")
+
+	if err := ctl.PostStartHook(bad); err == nil {
+		t.Errorf("expected error for non-existent cpu class, got none")
+	}
+	if err := ctl.PostStartHook(good); err != nil {
+		t.Errorf("unexpected error for container with a valid cpu class: %v", err)
+	}
+	expected := map[string]string{"fast": "2,3"}
+	if assignments := classAssignments(ctl.cache); !reflect.DeepEqual(assignments, expected) {
+		t.Errorf("expected cpu class assignments %v, got %v", expected, assignments)
+	}
+}
+
+func TestReleaseCPUsWithoutDefaultClass(t *testing.T) {
+	setupSysfs(t)
+	ctl := setupController(t, &config{Classes: map[string]Class{"fast": {Governor: "performance"}}})
+
+	ctl.saveCPUSettings(0)
+	if err := setScalingGovernor(0, "performance"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := Assign(ctl.cache, "fast", 0); err != nil {
+		t.Fatalf("failed to assign cpu class: %v", err)
+	}
+
+	ctl.releaseCPUs(ctl.cache, 0)
+	if assignments := classAssignments(ctl.cache); len(assignments) != 0 {
+		t.Errorf("expected no cpu class assignments, got %v", assignments)
+	}
+	if governor := readEntry(t, "cpufreq", "scaling_governor"); governor != "powersave" {
+		t.Errorf("expected original governor powersave to be restored, got %q", governor)
+	}
+	if _, ok := ctl.original[0]; ok {
+		t.Errorf("expected original settings of released cpu to be forgotten")
+	}
+}
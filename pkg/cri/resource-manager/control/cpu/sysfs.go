@@ -0,0 +1,168 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cpu
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// sysfsCPUPath is the sysfs directory with per-CPU cpufreq and cpuidle controls.
+var sysfsCPUPath = "/sys/devices/system/cpu"
+
+// cpuPath returns the path of a sysfs entry of a CPU.
+func cpuPath(cpu int, entry ...string) string {
+	return filepath.Join(append([]string{sysfsCPUPath, "cpu" + strconv.Itoa(cpu)}, entry...)...)
+}
+
+// setScalingGovernor sets the cpufreq scaling governor of a CPU.
+func setScalingGovernor(cpu int, governor string) error {
+	path := cpuPath(cpu, "cpufreq", "scaling_governor")
+	if err := os.WriteFile(path, []byte(governor), 0644); err != nil {
+		return fmt.Errorf("failed to set scaling governor %q of cpu %d: %w", governor, cpu, err)
+	}
+	return nil
+}
+
+// getBaseFrequency returns the base (non-turbo) frequency of a CPU.
+func getBaseFrequency(cpu int) (uint, error) {
+	path := cpuPath(cpu, "cpufreq", "base_frequency")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read base frequency of cpu %d: %w", cpu, err)
+	}
+	freq, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse base frequency of cpu %d: %w", cpu, err)
+	}
+	return uint(freq), nil
+}
+
+// setIdleStates disables the named idle states of a CPU and enables all others.
+// It returns the names of disabled states which were not found for the CPU.
+func setIdleStates(cpu int, disabled []string) ([]string, error) {
+	dirs, err := filepath.Glob(cpuPath(cpu, "cpuidle", "state[0-9]*"))
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := map[string]bool{}
+	for _, name := range disabled {
+		wanted[name] = false
+	}
+
+	for _, dir := range dirs {
+		data, err := os.ReadFile(filepath.Join(dir, "name"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read idle state name of cpu %d: %w", cpu, err)
+		}
+		name := strings.TrimSpace(string(data))
+		value := "0"
+		if _, ok := wanted[name]; ok {
+			value = "1"
+			wanted[name] = true
+		}
+		if err := os.WriteFile(filepath.Join(dir, "disable"), []byte(value), 0644); err != nil {
+			return nil, fmt.Errorf("failed to set idle state %s (%s) of cpu %d: %w",
+				filepath.Base(dir), name, cpu, err)
+		}
+	}
+
+	unknown := []string{}
+	for _, name := range disabled {
+		if !wanted[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	return unknown, nil
+}
+
+// cpuSettings are the values of the cpufreq and cpuidle entries of a CPU
+// which a CPU class can change, by entry path relative to the CPU directory.
+type cpuSettings map[string]string
+
+// cpufreq entries of CPU settings, in the order they are restored in.
+var cpufreqEntries = []string{
+	"cpufreq/scaling_governor",
+	"cpufreq/scaling_max_freq",
+	"cpufreq/scaling_min_freq",
+}
+
+// readCPUSettings reads the current settings of a CPU. Entries missing
+// from sysfs are left out.
+func readCPUSettings(cpu int) (cpuSettings, error) {
+	entries := append([]string{}, cpufreqEntries...)
+	states, err := filepath.Glob(cpuPath(cpu, "cpuidle", "state[0-9]*", "disable"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range states {
+		rel, err := filepath.Rel(cpuPath(cpu), path)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, rel)
+	}
+
+	settings := cpuSettings{}
+	for _, entry := range entries {
+		data, err := os.ReadFile(cpuPath(cpu, entry))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read %s of cpu %d: %w", entry, cpu, err)
+		}
+		settings[entry] = strings.TrimSpace(string(data))
+	}
+	return settings, nil
+}
+
+// restoreCPUSettings restores previously read settings of a CPU.
+func restoreCPUSettings(cpu int, settings cpuSettings) error {
+	entries := []string{}
+	for _, entry := range cpufreqEntries {
+		if _, ok := settings[entry]; ok {
+			entries = append(entries, entry)
+		}
+	}
+	idle := []string{}
+	for entry := range settings {
+		if strings.HasPrefix(entry, "cpuidle/") {
+			idle = append(idle, entry)
+		}
+	}
+	sort.Strings(idle)
+	entries = append(entries, idle...)
+
+	// Setting the maximum frequency below the current minimum one fails,
+	// so retry failed writes once the other entries have been restored.
+	failed := []string{}
+	for _, entry := range entries {
+		if err := os.WriteFile(cpuPath(cpu, entry), []byte(settings[entry]), 0644); err != nil {
+			failed = append(failed, entry)
+		}
+	}
+	for _, entry := range failed {
+		if err := os.WriteFile(cpuPath(cpu, entry), []byte(settings[entry]), 0644); err != nil {
+			return fmt.Errorf("failed to restore %s of cpu %d: %w", entry, cpu, err)
+		}
+	}
+	return nil
+}
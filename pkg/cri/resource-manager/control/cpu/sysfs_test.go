@@ -0,0 +1,176 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cpu
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// setupSysfs creates a fake sysfs CPU tree with the given idle states for cpu0.
+func setupSysfs(t *testing.T, states ...string) {
+	root := t.TempDir()
+	saved := sysfsCPUPath
+	sysfsCPUPath = root
+	t.Cleanup(func() { sysfsCPUPath = saved })
+
+	files := map[string]string{
+		"cpu0/cpufreq/scaling_governor": "powersave",
+		"cpu0/cpufreq/base_frequency":   "2100000\n",
+	}
+	for i, name := range states {
+		dir := filepath.Join("cpu0/cpuidle", "state"+string(rune('0'+i)))
+		files[filepath.Join(dir, "name")] = name + "\n"
+		files[filepath.Join(dir, "disable")] = "0"
+	}
+	for file, content := range files {
+		path := filepath.Join(root, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create %s: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to create %s: %v", path, err)
+		}
+	}
+}
+
+func readEntry(t *testing.T, entry ...string) string {
+	data, err := os.ReadFile(cpuPath(0, entry...))
+	if err != nil {
+		t.Fatalf("failed to read %v: %v", entry, err)
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func TestSetIdleStates(t *testing.T) {
+	tcases := []struct {
+		name     string
+		disabled []string
+		expected []string
+		unknown  []string
+	}{
+		{
+			name:     "enable all",
+			disabled: []string{},
+			expected: []string{"0", "0", "0", "0"},
+			unknown:  []string{},
+		},
+		{
+			name:     "disable deep states",
+			disabled: []string{"C6", "C1E"},
+			expected: []string{"0", "0", "1", "1"},
+			unknown:  []string{},
+		},
+		{
+			name:     "unknown state",
+			disabled: []string{"C6", "C10"},
+			expected: []string{"0", "0", "0", "1"},
+			unknown:  []string{"C10"},
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			setupSysfs(t, "POLL", "C1", "C1E", "C6")
+			unknown, err := setIdleStates(0, tc.disabled)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(unknown, tc.unknown) {
+				t.Errorf("expected unknown states %v, got %v", tc.unknown, unknown)
+			}
+			for i, expected := range tc.expected {
+				state := "state" + string(rune('0'+i))
+				if value := readEntry(t, "cpuidle", state, "disable"); value != expected {
+					t.Errorf("expected %s disable %q, got %q", state, expected, value)
+				}
+			}
+		})
+	}
+}
+
+func TestGovernorAndBaseFrequency(t *testing.T) {
+	setupSysfs(t)
+
+	if err := setScalingGovernor(0, "performance"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if governor := readEntry(t, "cpufreq", "scaling_governor"); governor != "performance" {
+		t.Errorf("expected governor performance, got %q", governor)
+	}
+
+	base, err := getBaseFrequency(0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if base != 2100000 {
+		t.Errorf("expected base frequency 2100000, got %d", base)
+	}
+
+	if _, err := getBaseFrequency(1); err == nil {
+		t.Errorf("expected error for missing cpu, got none")
+	}
+}
+
+func TestRestoreCPUSettings(t *testing.T) {
+	setupSysfs(t, "POLL", "C1", "C6")
+	for entry, value := range map[string]string{
+		"scaling_min_freq": "800000",
+		"scaling_max_freq": "3500000",
+	} {
+		if err := os.WriteFile(cpuPath(0, "cpufreq", entry), []byte(value), 0644); err != nil {
+			t.Fatalf("failed to create %s: %v", entry, err)
+		}
+	}
+
+	settings, err := readCPUSettings(0)
+	if err != nil {
+		t.Fatalf("failed to read cpu settings: %v", err)
+	}
+	expected := cpuSettings{
+		"cpufreq/scaling_governor": "powersave",
+		"cpufreq/scaling_min_freq": "800000",
+		"cpufreq/scaling_max_freq": "3500000",
+		"cpuidle/state0/disable":   "0",
+		"cpuidle/state1/disable":   "0",
+		"cpuidle/state2/disable":   "0",
+	}
+	if !reflect.DeepEqual(settings, expected) {
+		t.Fatalf("expected cpu settings %v, got %v", expected, settings)
+	}
+
+	if err := setScalingGovernor(0, "performance"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := setIdleStates(0, []string{"C6"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := os.WriteFile(cpuPath(0, "cpufreq", "scaling_max_freq"), []byte("2100000"), 0644); err != nil {
+		t.Fatalf("failed to write max frequency: %v", err)
+	}
+
+	if err := restoreCPUSettings(0, settings); err != nil {
+		t.Fatalf("failed to restore cpu settings: %v", err)
+	}
+	restored, err := readCPUSettings(0)
+	if err != nil {
+		t.Fatalf("failed to read cpu settings: %v", err)
+	}
+	if !reflect.DeepEqual(restored, expected) {
+		t.Errorf("expected restored cpu settings %v, got %v", expected, restored)
+	}
+}
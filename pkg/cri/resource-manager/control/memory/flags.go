@@ -0,0 +1,47 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"github.com/intel/cri-resource-manager/pkg/config"
+)
+
+const (
+	// defaultThrottlingFactor is the default fraction of memory limit used for memory.high.
+	defaultThrottlingFactor = 0.8
+)
+
+// options captures our configurable controller parameters.
+type options struct {
+	// ThrottlingFactor is the fraction of the memory limit of Burstable
+	// containers set as their memory.high throttling threshold. Setting
+	// it to 0 leaves memory.high unlimited.
+	ThrottlingFactor float64
+}
+
+// Our runtime configuration.
+var opt = defaultOptions().(*options)
+
+// defaultOptions returns a new options instance, all initialized to defaults.
+func defaultOptions() interface{} {
+	return &options{
+		ThrottlingFactor: defaultThrottlingFactor,
+	}
+}
+
+// Register us for configuration handling.
+func init() {
+	config.Register(MemoryConfigPath, MemoryDescription, opt, defaultOptions)
+}
@@ -19,6 +19,8 @@ import (
 	"os"
 	"strconv"
 
+	v1 "k8s.io/api/core/v1"
+
 	"github.com/intel/cri-resource-manager/pkg/cgroups"
 	"github.com/intel/cri-resource-manager/pkg/cri/client"
 	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/cache"
@@ -29,17 +31,31 @@ import (
 const (
 	// MemoryController is the name of the memory controller.
 	MemoryController = cache.Memory
+	// MemoryConfigPath is the configuration path for the memory controller.
+	MemoryConfigPath = "resource-manager.control." + MemoryController
+	// MemoryDescription is the description for the memory controller.
+	MemoryDescription = "memory controller"
 
 	// memoryCgroupPath is the path to the root of the memory cgroup.
 	memoryCgroupPath = "/sys/fs/cgroup/memory"
 	// toptierSoftLimitControl is the memory cgroup entry to set top tier soft limit.
 	toptierSoftLimitControl = "memory.toptier_soft_limit_in_bytes"
+
+	// cgroup v2 memory protection and throttling entries.
+	memoryMinControl  = "memory.min"
+	memoryLowControl  = "memory.low"
+	memoryHighControl = "memory.high"
+	// memoryMaxValue is the cgroup v2 value for no limit.
+	memoryMaxValue = "max"
+	// memoryPageSize is the granularity we round memory.high to.
+	memoryPageSize = 4096
 )
 
 // memctl encapsulates the runtime state of our memory enforcement/controller.
 type memctl struct {
-	cache    cache.Cache // resource manager cache
-	disabled bool        // true, if kernel lacks the necessary cgroup controls
+	cache   cache.Cache // resource manager cache
+	toptier bool        // true, if kernel has top tier soft limit control
+	qos     bool        // true, if cgroup v2 memory QoS controls are available
 }
 
 // Our logger instance.
@@ -58,9 +74,10 @@ func getMemoryController() *memctl {
 
 // Start initializes the controller for enforcing decisions.
 func (ctl *memctl) Start(cache cache.Cache, client client.Client) error {
-	// Let's keep this off for now so we can exercise this without a patched kernel...
-	if !ctl.checkToptierLimitSupport() {
-		return memctlError("cgroup top tier memory limit control not available")
+	ctl.qos = cgroups.DetectSystemCgroupVersion() == 2
+	ctl.toptier = ctl.checkToptierLimitSupport()
+	if !ctl.qos && !ctl.toptier {
+		return memctlError("neither cgroup v2 memory QoS nor top tier memory limit control available")
 	}
 	ctl.cache = cache
 	return nil
@@ -86,7 +103,7 @@ func (ctl *memctl) PostStartHook(c cache.Container) error {
 		return nil
 	}
 
-	if err := ctl.setToptierLimit(c); err != nil {
+	if err := ctl.enforce(c); err != nil {
 		return err
 	}
 
@@ -101,7 +118,7 @@ func (ctl *memctl) PostUpdateHook(c cache.Container) error {
 		return nil
 	}
 
-	if err := ctl.setToptierLimit(c); err != nil {
+	if err := ctl.enforce(c); err != nil {
 		return err
 	}
 
@@ -115,14 +132,29 @@ func (ctl *memctl) PostStopHook(c cache.Container) error {
 	return nil
 }
 
+// enforce applies all supported memory controls to the container.
+func (ctl *memctl) enforce(c cache.Container) error {
+	if ctl.toptier {
+		if err := ctl.setToptierLimit(c); err != nil {
+			return err
+		}
+	}
+	if ctl.qos {
+		if err := ctl.setMemoryQoS(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Check if memory cgroup controller supports top tier soft limits.
 func (ctl *memctl) checkToptierLimitSupport() bool {
 	_, err := os.Stat(memoryCgroupPath + "/" + toptierSoftLimitControl)
 	if err != nil && os.IsNotExist(err) {
 		log.Warn("cgroup top tier memory limit control not available")
-		ctl.disabled = true
+		return false
 	}
-	return !ctl.disabled
+	return true
 }
 
 // setToptierLimit sets the top tier memory (soft) limit for the container.
@@ -146,6 +178,84 @@ func (ctl *memctl) setToptierLimit(c cache.Container) error {
 	return nil
 }
 
+// setMemoryQoS sets the cgroup v2 memory protection and throttling for the container.
+func (ctl *memctl) setMemoryQoS(c cache.Container) error {
+	dir := c.GetCgroupDir()
+	if dir == "" {
+		log.Debug("%q: no cgroup directory, skipping memory QoS", c.PrettyName())
+		return nil
+	}
+
+	res := c.GetResourceRequirements()
+	request, limit := int64(0), int64(0)
+	if qty, ok := res.Requests[v1.ResourceMemory]; ok {
+		request = qty.Value()
+	}
+	if qty, ok := res.Limits[v1.ResourceMemory]; ok {
+		limit = qty.Value()
+	}
+
+	min, low, high := memoryQoS(c.GetQOSClass(), request, limit, c.GetMemoryQoS(), opt.ThrottlingFactor)
+	group := cgroups.Memory.Group(dir)
+
+	for _, entry := range []struct {
+		name  string
+		value int64
+	}{
+		{memoryMinControl, min},
+		{memoryLowControl, low},
+		{memoryHighControl, high},
+	} {
+		value := memoryMaxValue
+		if entry.value >= 0 {
+			value = strconv.FormatInt(entry.value, 10)
+		}
+		if err := group.Write(entry.name, value+"\n"); err != nil {
+			return err
+		}
+	}
+
+	log.Info("%q: memory QoS set to min %d, low %d, high %d", c.PrettyName(), min, low, high)
+
+	return nil
+}
+
+// memoryQoS calculates memory.min, memory.low and memory.high for a container.
+// Guaranteed containers are protected by memory.min, Burstable ones by memory.low,
+// both up to their memory request. Burstable containers with a memory limit are
+// throttled at factor * limit, but never below their request. Overrides take
+// precedence. A negative memory.high stands for no limit.
+func memoryQoS(class v1.PodQOSClass, request, limit int64, override *cache.MemoryQoS, factor float64) (int64, int64, int64) {
+	min, low, high := int64(0), int64(0), int64(-1)
+
+	switch class {
+	case v1.PodQOSGuaranteed:
+		min = request
+	case v1.PodQOSBurstable:
+		low = request
+		if limit > 0 && factor > 0 {
+			high = int64(float64(limit)*factor) / memoryPageSize * memoryPageSize
+			if high < request {
+				high = request
+			}
+		}
+	}
+
+	if override != nil {
+		if override.Min != cache.MemoryQoSUnset {
+			min = override.Min
+		}
+		if override.Low != cache.MemoryQoSUnset {
+			low = override.Low
+		}
+		if override.High != cache.MemoryQoSUnset {
+			high = override.High
+		}
+	}
+
+	return min, low, high
+}
+
 // memctlError creates a memory I/O-controller-specific formatted error message.
 func memctlError(format string, args ...interface{}) error {
 	return fmt.Errorf("memory: "+format, args...)
@@ -153,5 +263,5 @@ func memctlError(format string, args ...interface{}) error {
 
 // init registers this controller.
 func init() {
-	control.Register(MemoryController, "memory toptier controller", getMemoryController())
+	control.Register(MemoryController, MemoryDescription, getMemoryController())
 }
@@ -0,0 +1,103 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/cache"
+)
+
+func TestMemoryQoS(t *testing.T) {
+	const (
+		Mi = int64(1024 * 1024)
+	)
+	tcases := []struct {
+		name     string
+		class    v1.PodQOSClass
+		request  int64
+		limit    int64
+		override *cache.MemoryQoS
+		factor   float64
+		min      int64
+		low      int64
+		high     int64
+	}{
+		{
+			name:    "guaranteed is protected by memory.min",
+			class:   v1.PodQOSGuaranteed,
+			request: 100 * Mi,
+			limit:   100 * Mi,
+			factor:  0.8,
+			min:     100 * Mi,
+			high:    -1,
+		},
+		{
+			name:    "burstable is protected by memory.low and throttled",
+			class:   v1.PodQOSBurstable,
+			request: 100 * Mi,
+			limit:   200 * Mi,
+			factor:  0.8,
+			low:     100 * Mi,
+			high:    160 * Mi,
+		},
+		{
+			name:    "burstable is never throttled below request",
+			class:   v1.PodQOSBurstable,
+			request: 190 * Mi,
+			limit:   200 * Mi,
+			factor:  0.8,
+			low:     190 * Mi,
+			high:    190 * Mi,
+		},
+		{
+			name:    "burstable without limit or factor is not throttled",
+			class:   v1.PodQOSBurstable,
+			request: 100 * Mi,
+			limit:   200 * Mi,
+			low:     100 * Mi,
+			high:    -1,
+		},
+		{
+			name:   "besteffort gets nothing",
+			class:  v1.PodQOSBestEffort,
+			factor: 0.8,
+			high:   -1,
+		},
+		{
+			name:     "overrides take precedence",
+			class:    v1.PodQOSBurstable,
+			request:  100 * Mi,
+			limit:    200 * Mi,
+			factor:   0.8,
+			override: &cache.MemoryQoS{Min: 10 * Mi, Low: cache.MemoryQoSUnset, High: 150 * Mi},
+			min:      10 * Mi,
+			low:      100 * Mi,
+			high:     150 * Mi,
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			min, low, high := memoryQoS(tc.class, tc.request, tc.limit, tc.override, tc.factor)
+			if min != tc.min || low != tc.low || high != tc.high {
+				t.Errorf("expected min/low/high %d/%d/%d, got %d/%d/%d",
+					tc.min, tc.low, tc.high, min, low, high)
+			}
+		})
+	}
+}
@@ -30,14 +30,14 @@ import (
 	idset "github.com/intel/goresctrl/pkg/utils"
 )
 
-// Support dynamic pushing of unused pages from DRAM to PMEM.
+// Support dynamic pushing of unused pages from DRAM to PMEM or CXL memory.
 //
 // The algorithm is be (roughly) this:
 //
 // Find out which processes belong to the container. For every process in the
 // container, find out which pages the process uses. Using move_pages(), push a
 // number of pages not in the working set, which are present in DRAM, from DRAM
-// to PMEM. This may need to be done for many times with a delay in between,
+// to PMEM (or CXL memory). This may need to be done for many times with a delay in between,
 // because the process will be "stuck" when the pages are moved. Repeat this
 // process.
 //
@@ -54,6 +54,7 @@ import (
 type page struct {
 	pid  int
 	addr uint64
+	pfn  uint64 // page frame number, if known
 }
 
 type addrRange struct {
@@ -465,13 +466,14 @@ func (d *demoter) getPagesForContainer(c *container, sourceNodes idset.IDSet) (p
 
 func pickClosestPMEMNode(currentNode idset.ID, targetNodes idset.IDSet) idset.ID {
 	// TODO: analyze the topology information (and possibly the amount of free memory) and choose the "best"
-	// PMEM node to demote the page to. The array targetNodes already contains only the subset of PMEM nodes
+	// PMEM or CXL node to demote the page to. The array targetNodes already contains only the subset of such nodes
 	// available in this topology subtree. Right now just pick a random controller.
 	nodes := targetNodes.Members()
 	return nodes[rand.Intn(len(nodes))]
 }
 
-func (d *demoter) movePagesForPid(p []page, count uint, pid int, targetNodes idset.IDSet) (uint, error) {
+// movePagesForPid moves at most count pages of a process to the given target nodes.
+func movePagesForPid(pageMover PageMover, p []page, count uint, pid int, targetNodes idset.IDSet) (uint, error) {
 	// We move at max count pages, but there might not be that much.
 	nPages := count
 	if uint(len(p)) < count {
@@ -492,7 +494,7 @@ func (d *demoter) movePagesForPid(p []page, count uint, pid int, targetNodes ids
 	flags := 1 << 1
 
 	// Call move_pages() first with nil nodes array to find out the current controllers.
-	_, currentStatus, err := d.pageMover.MovePagesSyscall(pid, nPages, pages, nil, flags)
+	_, currentStatus, err := pageMover.MovePagesSyscall(pid, nPages, pages, nil, flags)
 	if err != nil {
 		log.Error("Failed to find out the current status of the pages: %v.", err)
 		return 0, err
@@ -515,13 +517,18 @@ func (d *demoter) movePagesForPid(p []page, count uint, pid int, targetNodes ids
 	}
 
 	// Call move_pages() to actually move the pages.
-	_, _, err = d.pageMover.MovePagesSyscall(pid, uint(len(dramPages)), dramPages, nodes, flags)
+	_, _, err = pageMover.MovePagesSyscall(pid, uint(len(dramPages)), dramPages, nodes, flags)
 
 	// We processed (moved or ignored) at least nPages.
 	return nPages, err
 }
 
 func (d *demoter) movePages(p pagePool, count uint, targetNodes idset.IDSet) error {
+	return movePages(d.pageMover, p, count, targetNodes)
+}
+
+// movePages moves at most count pages from the page pool to the given target nodes.
+func movePages(pageMover PageMover, p pagePool, count uint, targetNodes idset.IDSet) error {
 	// Select pid for moving the pages so that the process with the largest number
 	// of non-dirty pages gets the pages moved first.
 	processedPids := make(map[int]bool, 0)
@@ -555,7 +562,7 @@ func (d *demoter) movePages(p pagePool, count uint, targetNodes idset.IDSet) err
 		}
 
 		log.Debug("moving %d pages for pid %d", nMovePages, mostPagesPid)
-		nPages, err := d.movePagesForPid(p.pages[mostPagesPid], nMovePages, mostPagesPid, targetNodes)
+		nPages, err := movePagesForPid(pageMover, p.pages[mostPagesPid], nMovePages, mostPagesPid, targetNodes)
 		if err != nil {
 			log.Error("Failed to move pages: %v", err)
 			return err
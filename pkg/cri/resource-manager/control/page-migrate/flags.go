@@ -26,14 +26,35 @@ type options struct {
 	PageMoveInterval config.Duration
 	// MaxPageMoveCount controls how many pages we can move in a single go.
 	MaxPageMoveCount uint
+	// PageTracker selects how page accesses are tracked: soft-dirty, idle-page, damon, or auto.
+	PageTracker string
+	// ColdPageScans controls how many scans a page needs to stay idle to get demoted.
+	ColdPageScans uint
+	// HotPageScans controls how many scans a page needs to get accessed in to get promoted.
+	HotPageScans uint
 }
 
+const (
+	// SoftDirtyTracker uses soft-dirty bits to find idle pages and only demotes them.
+	SoftDirtyTracker = "soft-dirty"
+	// IdlePageTracker uses idle page tracking for tiering.
+	IdlePageTracker = "idle-page"
+	// DamonTracker uses DAMON for tiering.
+	DamonTracker = "damon"
+	// AutoTracker uses DAMON or idle page tracking for tiering, whichever is available.
+	AutoTracker = "auto"
+)
+
 // Our runtime configuration.
 var opt = defaultOptions().(*options)
 
 // defaultOptions returns a new options instance, all initialized to defaults.
 func defaultOptions() interface{} {
-	return &options{}
+	return &options{
+		PageTracker:   SoftDirtyTracker,
+		ColdPageScans: 2,
+		HotPageScans:  2,
+	}
 }
 
 // Register us for configuration handling.
@@ -39,6 +39,7 @@ type migration struct {
 	sync.Mutex                       // protect access from multiple goroutines
 	containers map[string]*container // containers we migrate
 	demoter    *demoter              // demoter adopted from topology-aware policy
+	tierer     *tierer               // tiering engine using page access tracking
 }
 
 //
@@ -71,11 +72,12 @@ type migration struct {
 
 // container is the per container data we track locally.
 type container struct {
-	cacheID    string
-	id         string
-	prettyName string
-	cgroupDir  string
-	pm         *cache.PageMigrate
+	cacheID      string
+	id           string
+	prettyName   string
+	cgroupDir    string
+	pm           *cache.PageMigrate
+	toptierLimit int64
 }
 
 // Our logger instance.
@@ -91,6 +93,7 @@ func getMigrationController() *migration {
 			containers: make(map[string]*container),
 		}
 		singleton.demoter = newDemoter(singleton)
+		singleton.tierer = newTierer(singleton)
 	}
 	return singleton
 }
@@ -99,13 +102,26 @@ func getMigrationController() *migration {
 func (m *migration) Start(cache cache.Cache, client client.Client) error {
 	m.cache = cache
 	m.syncWithCache()
-	m.demoter.Reconfigure()
+	m.reconfigure()
 	return nil
 }
 
 // Stop shuts down the controller.
 func (m *migration) Stop() {
 	m.demoter.Stop()
+	m.tierer.Stop()
+}
+
+// reconfigure activates either the demoter or the tiering engine, depending on page tracking.
+func (m *migration) reconfigure() {
+	switch opt.PageTracker {
+	case SoftDirtyTracker, "":
+		m.tierer.Stop()
+		m.demoter.Reconfigure()
+	default:
+		m.demoter.Stop()
+		m.tierer.Reconfigure()
+	}
 }
 
 // PreCreateHook is the controller's pre-create hook.
@@ -162,11 +178,12 @@ func (m *migration) insertContainer(cc cache.Container) error {
 	}
 
 	c := &container{
-		cacheID:    cc.GetCacheID(),
-		id:         cc.GetID(),
-		prettyName: cc.PrettyName(),
-		cgroupDir:  cc.GetCgroupDir(),
-		pm:         pm.Clone(),
+		cacheID:      cc.GetCacheID(),
+		id:           cc.GetID(),
+		prettyName:   cc.PrettyName(),
+		cgroupDir:    cc.GetCgroupDir(),
+		pm:           pm.Clone(),
+		toptierLimit: cc.GetToptierLimit(),
 	}
 	if c.cgroupDir == "" {
 		return migrationError("can't find cgroup dir for container %s",
@@ -192,6 +209,7 @@ func (m *migration) updateContainer(cc cache.Container) error {
 	}
 
 	c.pm = pm.Clone()
+	c.toptierLimit = cc.GetToptierLimit()
 	return nil
 }
 
@@ -0,0 +1,629 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pagemigrate
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/intel/cri-resource-manager/pkg/cgroups"
+	"github.com/intel/cri-resource-manager/pkg/config"
+	idset "github.com/intel/goresctrl/pkg/utils"
+)
+
+// Support continuous tiering of container memory between DRAM and PMEM or CXL memory.
+//
+// Unlike the demoter, which only pushes pages without the soft-dirty bit from
+// DRAM to slower memory, the tiering engine keeps a short access history of
+// pages, using idle page tracking or DAMON to find out which pages have been
+// accessed between two scans:
+//   https://www.kernel.org/doc/html/latest/admin-guide/mm/idle_page_tracking.html
+//   https://www.kernel.org/doc/html/latest/admin-guide/mm/damon/usage.html
+//
+// Pages which stay idle for ColdPageScans scans in DRAM get demoted, and pages
+// which get accessed in HotPageScans scans in a row in slower memory get promoted
+// back to DRAM. If a container has a top tier limit set, cold DRAM pages get
+// demoted until the container fits its limit, and pages only get promoted as
+// long as the container stays within its limit.
+//
+// Pages are scanned on a snapshot of the tracked containers, without holding
+// the controller lock. The access history of a container is kept for at most
+// maxTrackedPages pages: if a container has more, a sample of its pages is
+// tracked, each sampled page standing for as many pages as the sampling skips.
+
+const (
+	// /proc/pid/pagemap entry bits
+	pagemapPFNMask      = (uint64(1) << 55) - 1
+	pagemapExclusiveBit = uint64(1) << 56
+	pagemapPresentBit   = uint64(1) << 63
+
+	// maximum number of pages tracked per container, 1 GiB of 4 KiB pages
+	maxTrackedPages = 1 << 18
+)
+
+type tierer struct {
+	migration *migration // controller backpointer
+
+	// Tracking pages
+	scanLock sync.Mutex                      // serialize page scanning with stopping it
+	tracker  pageTracker                     // page access tracker
+	history  map[string]map[pageKey]pageHeat // page access history per container
+	scanStop chan interface{}                // channel for stopping page scanning
+
+	// Moving pages
+	pageMover        PageMover
+	movers           map[string]chan *tieringPlan // channels for passing the latest tiering plans to movers
+	pageTracker      string                       // kind of page access tracking
+	pageScanInterval config.Duration              // how often should we scan pages
+	pageMoveInterval config.Duration              // how often should we move pages for a container
+	maxPageMoveCount uint                         // how many pages to move at once
+	coldPageScans    uint                         // scans a page needs to be idle to be cold
+	hotPageScans     uint                         // scans a page needs to be accessed to be hot
+}
+
+// pageKey identifies a page of a process.
+type pageKey struct {
+	pid  int
+	addr uint64
+}
+
+// pageHeat is the recent access history of a page.
+type pageHeat struct {
+	idle uint // number of scans in a row the page was idle
+	busy uint // number of scans in a row the page was accessed
+}
+
+// tieringPage is a page with its current NUMA node and access history.
+type tieringPage struct {
+	page
+	node idset.ID
+	heat pageHeat
+}
+
+// tieringPlan is the set of pages to move for a container.
+type tieringPlan struct {
+	demote    pagePool    // cold pages to move to slow memory
+	promote   pagePool    // hot pages to move to fast memory
+	fastNodes idset.IDSet // DRAM nodes
+	slowNodes idset.IDSet // PMEM or CXL nodes
+}
+
+func newTierer(m *migration) *tierer {
+	return &tierer{
+		migration: m,
+		history:   make(map[string]map[pageKey]pageHeat),
+		movers:    make(map[string]chan *tieringPlan),
+		pageMover: &linuxPageMover{},
+	}
+}
+
+func (t *tierer) start() {
+	if t.scanStop != nil {
+		return
+	}
+
+	if t.pageScanInterval <= 0 || t.pageMoveInterval <= 0 || t.maxPageMoveCount == 0 {
+		log.Info("memory tiering is disabled")
+		return
+	}
+
+	tracker, err := newPageTracker(t.pageTracker, time.Duration(t.pageScanInterval))
+	if err != nil {
+		log.Error("memory tiering is disabled: %v", err)
+		return
+	}
+	t.scanLock.Lock()
+	t.tracker = tracker
+	t.scanLock.Unlock()
+
+	log.Info("tiering memory using %s tracking: scanning pages every %s, moving max. %d pages every %s",
+		tracker.Name(), t.pageScanInterval.String(), t.maxPageMoveCount, t.pageMoveInterval.String())
+
+	stop := make(chan interface{})
+	go func() {
+		scanTimer := time.NewTicker(time.Duration(t.pageScanInterval))
+		for {
+			select {
+			case _ = <-stop:
+				scanTimer.Stop()
+				return
+			case _ = <-scanTimer.C:
+				t.scanPages()
+			}
+		}
+	}()
+	t.scanStop = stop
+}
+
+// Stop stops page scanning and tiering.
+func (t *tierer) Stop() {
+	if t.scanStop != nil {
+		close(t.scanStop)
+		t.scanStop = nil
+	}
+	t.scanLock.Lock()
+	defer t.scanLock.Unlock()
+	t.migration.Lock()
+	defer t.migration.Unlock()
+	t.stopMovers()
+	if t.tracker != nil {
+		t.tracker.Stop()
+		t.tracker = nil
+	}
+	t.history = make(map[string]map[pageKey]pageHeat)
+}
+
+// Reconfigure restarts, if necessary, page scanning and tiering with new options.
+func (t *tierer) Reconfigure() {
+	coldPageScans, hotPageScans := opt.ColdPageScans, opt.HotPageScans
+	if coldPageScans == 0 {
+		coldPageScans = 1
+	}
+	if hotPageScans == 0 {
+		hotPageScans = 1
+	}
+	if t.pageTracker != opt.PageTracker ||
+		t.pageScanInterval != opt.PageScanInterval ||
+		t.pageMoveInterval != opt.PageMoveInterval ||
+		t.maxPageMoveCount != opt.MaxPageMoveCount ||
+		t.coldPageScans != coldPageScans ||
+		t.hotPageScans != hotPageScans {
+		t.Stop()
+		t.pageTracker = opt.PageTracker
+		t.pageScanInterval = opt.PageScanInterval
+		t.pageMoveInterval = opt.PageMoveInterval
+		t.maxPageMoveCount = opt.MaxPageMoveCount
+		t.coldPageScans = coldPageScans
+		t.hotPageScans = hotPageScans
+	}
+	t.start()
+}
+
+// scanPages scans pages of tracked containers and updates their tiering plans.
+func (t *tierer) scanPages() {
+	t.scanLock.Lock()
+	defer t.scanLock.Unlock()
+
+	if t.tracker == nil {
+		return
+	}
+
+	containers := t.getScannedContainers()
+	pids := make(map[string][]int)
+	all := []int{}
+	for id, c := range containers {
+		cpids, err := getContainerPids(c)
+		if err != nil {
+			log.Error("%s: failed to get processes: %v", c.prettyName, err)
+			continue
+		}
+		pids[id] = cpids
+		all = append(all, cpids...)
+	}
+
+	if err := t.tracker.Track(all); err != nil {
+		log.Error("failed to track page accesses: %v", err)
+		return
+	}
+
+	plans := make(map[string]*tieringPlan)
+	for id, cpids := range pids {
+		c := containers[id]
+		plan, err := t.scanContainer(c, cpids)
+		if err != nil {
+			log.Error("%s: failed to scan pages: %v", c.prettyName, err)
+			continue
+		}
+		plans[id] = plan
+	}
+
+	for id := range t.history {
+		if _, ok := pids[id]; !ok {
+			delete(t.history, id)
+		}
+	}
+
+	t.migration.Lock()
+	defer t.migration.Unlock()
+
+	for id, plan := range plans {
+		if _, ok := t.migration.containers[id]; ok {
+			t.updateMover(id, plan)
+		}
+	}
+	for id := range t.movers {
+		if _, ok := pids[id]; !ok || t.migration.containers[id] == nil {
+			t.stopMover(id)
+		}
+	}
+}
+
+// getScannedContainers returns a snapshot of the containers with page migration set up.
+func (t *tierer) getScannedContainers() map[string]*container {
+	t.migration.Lock()
+	defer t.migration.Unlock()
+
+	containers := make(map[string]*container)
+	for id, c := range t.migration.containers {
+		pm := c.GetPageMigration()
+		if pm == nil || pm.SourceNodes.Size() == 0 || pm.TargetNodes.Size() == 0 {
+			continue
+		}
+		snapshot := *c
+		snapshot.pm = pm.Clone()
+		containers[id] = &snapshot
+	}
+	return containers
+}
+
+// scanContainer updates the access history of the pages of a container and plans their tiering.
+func (t *tierer) scanContainer(c *container, pids []int) (*tieringPlan, error) {
+	pm := c.GetPageMigration()
+	old := t.history[c.cacheID]
+	heat := make(map[pageKey]pageHeat)
+	pages := []tieringPage{}
+
+	candidates := make(map[int][]page)
+	total := 0
+	for _, pid := range pids {
+		ranges, err := readAnonRanges(pid)
+		if err != nil {
+			// Probably the process just died?
+			log.Debug("%s: failed to read memory ranges of process %d: %v", c.prettyName, pid, err)
+			continue
+		}
+		ppages, err := readPages(pid, ranges)
+		if err != nil {
+			log.Debug("%s: failed to read pages of process %d: %v", c.prettyName, pid, err)
+			continue
+		}
+		candidates[pid] = ppages
+		total += len(ppages)
+	}
+
+	pageSize := uint64(os.Getpagesize())
+	stride := uint64(1)
+	if total > maxTrackedPages {
+		stride = uint64((total + maxTrackedPages - 1) / maxTrackedPages)
+		log.Debug("%s: tracking every %d. of %d pages", c.prettyName, stride, total)
+	}
+
+	for _, pid := range pids {
+		sampled := samplePages(candidates[pid], stride, pageSize)
+		if len(sampled) == 0 {
+			continue
+		}
+
+		accessed, err := t.tracker.Accessed(pid, sampled)
+		if err != nil {
+			return nil, err
+		}
+		if accessed == nil {
+			continue
+		}
+
+		nodes, err := t.getPageNodes(pid, sampled)
+		if err != nil {
+			log.Debug("%s: failed to locate pages of process %d: %v", c.prettyName, pid, err)
+			continue
+		}
+
+		for i, p := range sampled {
+			key := pageKey{pid: pid, addr: p.addr}
+			h := old[key]
+			if accessed[i] {
+				h.busy++
+				h.idle = 0
+			} else {
+				h.idle++
+				h.busy = 0
+			}
+			heat[key] = h
+			if nodes[i] < 0 {
+				continue
+			}
+			pages = append(pages, tieringPage{page: p, node: idset.ID(nodes[i]), heat: h})
+		}
+	}
+	t.history[c.cacheID] = heat
+
+	// each sampled page stands for stride pages when checking the top tier limit
+	demote, promote := planTiering(pages, pm.SourceNodes, pm.TargetNodes,
+		c.toptierLimit, int64(pageSize*stride), t.coldPageScans, t.hotPageScans)
+
+	log.Debug("%s: %d pages tracked, %d to demote to %s, %d to promote to %s",
+		c.prettyName, len(pages), len(demote), pm.TargetNodes, len(promote), pm.SourceNodes)
+
+	return &tieringPlan{
+		demote:    newPagePool(demote),
+		promote:   newPagePool(promote),
+		fastNodes: pm.SourceNodes.Clone(),
+		slowNodes: pm.TargetNodes.Clone(),
+	}, nil
+}
+
+// samplePages picks every stride'th page by page number. The same pages get
+// picked in consecutive scans as long as the stride stays the same.
+func samplePages(pages []page, stride, pageSize uint64) []page {
+	if stride <= 1 {
+		return pages
+	}
+	sampled := make([]page, 0, uint64(len(pages))/stride+1)
+	for _, p := range pages {
+		if (p.addr/pageSize)%stride == 0 {
+			sampled = append(sampled, p)
+		}
+	}
+	return sampled
+}
+
+// getPageNodes returns the NUMA nodes the given pages of a process are currently on.
+func (t *tierer) getPageNodes(pid int, pages []page) ([]int, error) {
+	addrs := make([]uintptr, len(pages))
+	for i, p := range pages {
+		addrs[i] = uintptr(p.addr)
+	}
+	_, nodes, err := t.pageMover.MovePagesSyscall(pid, uint(len(addrs)), addrs, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+// planTiering picks the pages to demote and promote. Pages on fast nodes which
+// have been idle for coldScans scans are demoted, coldest first. Pages on slow
+// nodes which have been accessed in hotScans scans are promoted, hottest first.
+// With a non-negative limit, the amount of pages on fast nodes is kept within
+// limit bytes by demoting more and promoting fewer pages.
+func planTiering(pages []tieringPage, fast, slow idset.IDSet, limit, pageSize int64, coldScans, hotScans uint) ([]page, []page) {
+	onFast := []tieringPage{}
+	onSlow := []tieringPage{}
+	for _, p := range pages {
+		switch {
+		case fast.Has(p.node):
+			onFast = append(onFast, p)
+		case slow.Has(p.node):
+			onSlow = append(onSlow, p)
+		}
+	}
+
+	sort.SliceStable(onFast, func(i, j int) bool {
+		hi, hj := onFast[i].heat, onFast[j].heat
+		if hi.idle != hj.idle {
+			return hi.idle > hj.idle
+		}
+		return hi.busy < hj.busy
+	})
+	sort.SliceStable(onSlow, func(i, j int) bool {
+		return onSlow[i].heat.busy > onSlow[j].heat.busy
+	})
+
+	nDemote := 0
+	for nDemote < len(onFast) && onFast[nDemote].heat.idle >= coldScans {
+		nDemote++
+	}
+	nPromote := 0
+	for nPromote < len(onSlow) && onSlow[nPromote].heat.busy >= hotScans {
+		nPromote++
+	}
+
+	if limit >= 0 {
+		quota := int(limit / pageSize)
+		if excess := len(onFast) - nDemote - quota; excess > 0 {
+			nDemote += excess
+		}
+		if room := quota - (len(onFast) - nDemote); nPromote > room {
+			nPromote = room
+		}
+	}
+
+	demote := make([]page, 0, nDemote)
+	for _, p := range onFast[:nDemote] {
+		demote = append(demote, p.page)
+	}
+	promote := make([]page, 0, nPromote)
+	for _, p := range onSlow[:nPromote] {
+		promote = append(promote, p.page)
+	}
+
+	return demote, promote
+}
+
+// updateMover passes a new tiering plan to the mover of a container, starting one if necessary.
+// The channel of a mover holds only the latest plan: a plan the mover has not picked up yet is
+// replaced by the new one, so we never block waiting for a mover busy moving pages.
+func (t *tierer) updateMover(cid string, plan *tieringPlan) {
+	if channel, found := t.movers[cid]; found {
+		select {
+		case <-channel:
+		default:
+		}
+		channel <- plan
+		return
+	}
+
+	channel := make(chan *tieringPlan, 1)
+	go func() {
+		moveTimer := time.NewTicker(time.Duration(t.pageMoveInterval))
+		pageMover := t.pageMover
+		count := t.maxPageMoveCount
+		for {
+			select {
+			case p, ok := <-channel:
+				if !ok {
+					// A stop request.
+					moveTimer.Stop()
+					return
+				}
+				plan = p
+			case _ = <-moveTimer.C:
+				plan.move(pageMover, count)
+			}
+		}
+	}()
+	t.movers[cid] = channel
+}
+
+func (t *tierer) stopMover(cid string) {
+	if channel, found := t.movers[cid]; found {
+		close(channel)
+		delete(t.movers, cid)
+	}
+}
+
+func (t *tierer) stopMovers() {
+	for cid := range t.movers {
+		t.stopMover(cid)
+	}
+}
+
+// move moves at most count pages of the plan, demoting before promoting.
+func (p *tieringPlan) move(pageMover PageMover, count uint) {
+	demote := p.demote.count()
+	if demote > count {
+		demote = count
+	}
+	if demote > 0 {
+		if err := movePages(pageMover, p.demote, demote, p.slowNodes); err != nil {
+			log.Error("Error demoting pages: %s", err)
+		}
+	}
+
+	promote := p.promote.count()
+	if promote > count-demote {
+		promote = count - demote
+	}
+	if promote > 0 {
+		if err := movePages(pageMover, p.promote, promote, p.fastNodes); err != nil {
+			log.Error("Error promoting pages: %s", err)
+		}
+	}
+}
+
+// newPagePool creates a page pool of the given pages.
+func newPagePool(pages []page) pagePool {
+	pool := pagePool{
+		pages: make(map[int][]page),
+	}
+	for _, p := range pages {
+		pool.pages[p.pid] = append(pool.pages[p.pid], p)
+	}
+	return pool
+}
+
+// count returns the number of pages in the pool.
+func (p pagePool) count() uint {
+	count := uint(0)
+	for _, pages := range p.pages {
+		count += uint(len(pages))
+	}
+	return count
+}
+
+// getContainerPids returns the processes of a container.
+func getContainerPids(c *container) ([]int, error) {
+	group := cgroups.Memory.Group(c.cgroupDir)
+	procs, err := group.GetProcesses()
+	if err != nil {
+		return nil, err
+	}
+	pids := make([]int, 0, len(procs))
+	for _, proc := range procs {
+		pid, err := strconv.Atoi(proc)
+		if err != nil {
+			log.Error("%s: invalid pid %q", c.prettyName, proc)
+			continue
+		}
+		pids = append(pids, pid)
+	}
+	return pids, nil
+}
+
+// readAnonRanges reads the anonymous private memory ranges of a process.
+func readAnonRanges(pid int) ([]addrRange, error) {
+	maps, err := ioutil.ReadFile("/proc/" + strconv.Itoa(pid) + "/maps")
+	if err != nil {
+		return nil, err
+	}
+	return parseAnonRanges(string(maps), uint64(os.Getpagesize())), nil
+}
+
+// parseAnonRanges parses writable private memory ranges not backed by any file from /proc/pid/maps.
+func parseAnonRanges(maps string, pageSize uint64) []addrRange {
+	ranges := []addrRange{}
+	for _, line := range strings.Split(maps, "\n") {
+		// address perms offset dev inode [path]
+		fields := strings.Fields(line)
+		if len(fields) < 5 || fields[4] != "0" {
+			continue
+		}
+		if perms := fields[1]; len(perms) < 4 || perms[1] != 'w' || perms[3] != 'p' {
+			continue
+		}
+		addrs := strings.SplitN(fields[0], "-", 2)
+		if len(addrs) != 2 {
+			continue
+		}
+		start, err := strconv.ParseUint(addrs[0], 16, 64)
+		if err != nil {
+			continue
+		}
+		end, err := strconv.ParseUint(addrs[1], 16, 64)
+		if err != nil || end <= start {
+			continue
+		}
+		ranges = append(ranges, addrRange{addr: start, length: (end - start) / pageSize})
+	}
+	return ranges
+}
+
+// readPages reads the present, exclusively mapped pages of a process in the given ranges.
+func readPages(pid int, ranges []addrRange) ([]page, error) {
+	pageMap, err := os.Open("/proc/" + strconv.Itoa(pid) + "/pagemap")
+	if err != nil {
+		return nil, err
+	}
+	defer pageMap.Close()
+
+	pageSize := uint64(os.Getpagesize())
+	pages := []page{}
+	for _, r := range ranges {
+		buf := make([]byte, 8*r.length)
+		n, err := pageMap.ReadAt(buf, int64(r.addr/pageSize*8))
+		if err != nil && n == 0 {
+			// Maybe there was a race condition and the maps changed?
+			continue
+		}
+		for i := 0; i+8 <= n; i += 8 {
+			data := binary.LittleEndian.Uint64(buf[i : i+8])
+			if data&pagemapPresentBit == 0 || data&pagemapExclusiveBit == 0 {
+				continue
+			}
+			pages = append(pages, page{
+				pid:  pid,
+				addr: r.addr + uint64(i/8)*pageSize,
+				pfn:  data & pagemapPFNMask,
+			})
+		}
+	}
+
+	return pages, nil
+}
@@ -0,0 +1,419 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pagemigrate
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/intel/cri-resource-manager/pkg/config"
+	idset "github.com/intel/goresctrl/pkg/utils"
+)
+
+func TestPlanTiering(t *testing.T) {
+	const pageSize = 4096
+
+	fast := idset.NewIDSet(0)
+	slow := idset.NewIDSet(2)
+
+	pages := []tieringPage{
+		{page: page{pid: 1, addr: 0x1000}, node: 0, heat: pageHeat{idle: 3}},
+		{page: page{pid: 1, addr: 0x2000}, node: 0, heat: pageHeat{idle: 1}},
+		{page: page{pid: 1, addr: 0x3000}, node: 0, heat: pageHeat{busy: 4}},
+		{page: page{pid: 1, addr: 0x4000}, node: 0, heat: pageHeat{idle: 2}},
+		{page: page{pid: 1, addr: 0x5000}, node: 2, heat: pageHeat{busy: 1}},
+		{page: page{pid: 1, addr: 0x6000}, node: 2, heat: pageHeat{busy: 5}},
+		{page: page{pid: 1, addr: 0x7000}, node: 2, heat: pageHeat{busy: 2}},
+		{page: page{pid: 1, addr: 0x8000}, node: 2, heat: pageHeat{idle: 7}},
+		{page: page{pid: 1, addr: 0x9000}, node: 1, heat: pageHeat{idle: 7}},
+	}
+
+	tcases := []struct {
+		name            string
+		limit           int64
+		expectedDemote  []uint64
+		expectedPromote []uint64
+	}{
+		{
+			name:            "unlimited",
+			limit:           -1,
+			expectedDemote:  []uint64{0x1000, 0x4000},
+			expectedPromote: []uint64{0x6000, 0x7000},
+		},
+		{
+			name:            "plenty of room",
+			limit:           16 * pageSize,
+			expectedDemote:  []uint64{0x1000, 0x4000},
+			expectedPromote: []uint64{0x6000, 0x7000},
+		},
+		{
+			name:            "room for one promotion",
+			limit:           3 * pageSize,
+			expectedDemote:  []uint64{0x1000, 0x4000},
+			expectedPromote: []uint64{0x6000},
+		},
+		{
+			name:            "over the limit",
+			limit:           1 * pageSize,
+			expectedDemote:  []uint64{0x1000, 0x4000, 0x2000},
+			expectedPromote: []uint64{},
+		},
+		{
+			name:            "zero limit",
+			limit:           0,
+			expectedDemote:  []uint64{0x1000, 0x4000, 0x2000, 0x3000},
+			expectedPromote: []uint64{},
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			demote, promote := planTiering(pages, fast, slow, tc.limit, pageSize, 2, 2)
+			checkPageAddrs(t, "demote", demote, tc.expectedDemote)
+			checkPageAddrs(t, "promote", promote, tc.expectedPromote)
+		})
+	}
+}
+
+func checkPageAddrs(t *testing.T, what string, pages []page, expected []uint64) {
+	if len(pages) != len(expected) {
+		t.Errorf("expected %d pages to %s, got %d (%v)", len(expected), what, len(pages), pages)
+		return
+	}
+	for i, p := range pages {
+		if p.addr != expected[i] {
+			t.Errorf("expected page #%d to %s to be 0x%x, got 0x%x", i, what, expected[i], p.addr)
+		}
+	}
+}
+
+func TestParseAnonRanges(t *testing.T) {
+	maps := `55d0c4a00000-55d0c4a02000 r--p 00000000 fd:01 1234    /usr/bin/app
+55d0c4c02000-55d0c4c04000 rw-p 00002000 fd:01 1234    /usr/bin/app
+55d0c5000000-55d0c5021000 rw-p 00000000 00:00 0       [heap]
+7f0000000000-7f0000004000 rw-p 00000000 00:00 0
+7f0000004000-7f0000008000 ---p 00000000 00:00 0
+7f0000008000-7f000000a000 rw-s 00000000 00:01 0       /dev/zero (deleted)
+7ffd0000000-7ffd0001000 rw-p 00000000 00:00 0         [stack]
+`
+	expected := []addrRange{
+		{addr: 0x55d0c5000000, length: 0x21},
+		{addr: 0x7f0000000000, length: 4},
+		{addr: 0x7ffd0000000, length: 1},
+	}
+
+	ranges := parseAnonRanges(maps, 4096)
+	if len(ranges) != len(expected) {
+		t.Fatalf("expected %d ranges, got %d (%v)", len(expected), len(ranges), ranges)
+	}
+	for i, r := range ranges {
+		if r != expected[i] {
+			t.Errorf("expected range #%d to be %v, got %v", i, expected[i], r)
+		}
+	}
+}
+
+func TestIdlePageTracker(t *testing.T) {
+	bitmap := filepath.Join(t.TempDir(), "bitmap")
+	if err := os.WriteFile(bitmap, make([]byte, 8*4), 0644); err != nil {
+		t.Fatalf("failed to create bitmap: %v", err)
+	}
+	tracker, err := newIdlePageTracker(bitmap)
+	if err != nil {
+		t.Fatalf("failed to create tracker: %v", err)
+	}
+
+	pages := []page{
+		{pid: 1, addr: 0x1000, pfn: 3},
+		{pid: 1, addr: 0x2000, pfn: 70},
+		{pid: 1, addr: 0x3000, pfn: 130},
+	}
+
+	accessed, err := tracker.Accessed(1, pages)
+	if err != nil || accessed != nil {
+		t.Fatalf("expected no information on first scan, got %v, %v", accessed, err)
+	}
+
+	// the kernel clears the idle bit of accessed pages
+	data, err := os.ReadFile(bitmap)
+	if err != nil {
+		t.Fatalf("failed to read bitmap: %v", err)
+	}
+	word := binary.LittleEndian.Uint64(data[8:16])
+	binary.LittleEndian.PutUint64(data[8:16], word&^(uint64(1)<<(70%64)))
+	if err := os.WriteFile(bitmap, data, 0644); err != nil {
+		t.Fatalf("failed to update bitmap: %v", err)
+	}
+
+	accessed, err = tracker.Accessed(1, pages)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []bool{false, true, false}
+	for i := range expected {
+		if accessed[i] != expected[i] {
+			t.Errorf("expected page #%d accessed to be %v, got %v", i, expected[i], accessed[i])
+		}
+	}
+
+	tracker.Track([]int{2})
+	accessed, err = tracker.Accessed(1, pages)
+	if err != nil || accessed != nil {
+		t.Errorf("expected no information for untracked process, got %v, %v", accessed, err)
+	}
+
+	if _, err := tracker.Accessed(1, []page{{pid: 1, addr: 0x1000}}); err == nil {
+		t.Errorf("expected an error for pages without page frame numbers")
+	}
+}
+
+func TestDamonTracker(t *testing.T) {
+	root := t.TempDir()
+	kdamonds := filepath.Join(root, "kdamonds")
+	ctx := filepath.Join(kdamonds, "0", "contexts", "0")
+	for idx := 0; idx < 2; idx++ {
+		id := strconv.Itoa(idx)
+		for _, dir := range []string{
+			"monitoring_attrs/intervals",
+			"targets/" + id,
+			"schemes/" + id + "/access_pattern/sz",
+			"schemes/" + id + "/access_pattern/nr_accesses",
+			"schemes/" + id + "/access_pattern/age",
+			"schemes/" + id + "/filters/0",
+		} {
+			if err := os.MkdirAll(filepath.Join(ctx, dir), 0755); err != nil {
+				t.Fatalf("failed to create directory: %v", err)
+			}
+		}
+	}
+	setEntry := func(path, value string) {
+		if err := os.WriteFile(filepath.Join(kdamonds, path), []byte(value), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+	checkEntries := func(entries map[string]string) {
+		t.Helper()
+		for path, value := range entries {
+			data, err := os.ReadFile(filepath.Join(kdamonds, path))
+			if err != nil || string(data) != value {
+				t.Errorf("expected %s to be %q, got %q (%v)", path, value, string(data), err)
+			}
+		}
+	}
+
+	setEntry("nr_kdamonds", "1\n")
+	if _, err := newDamonTracker(root, 2*time.Second); err == nil {
+		t.Errorf("expected DAMON used by other kdamonds to be refused")
+	}
+	setEntry("nr_kdamonds", "0\n")
+
+	tracker, err := newDamonTracker(root, 2*time.Second)
+	if err != nil {
+		t.Fatalf("failed to create tracker: %v", err)
+	}
+
+	if err := tracker.Track([]int{200, 100}); err != nil {
+		t.Fatalf("failed to start tracking: %v", err)
+	}
+	checkEntries(map[string]string{
+		"nr_kdamonds":             "1",
+		"0/state":                 "on",
+		"0/contexts/0/operations": "vaddr",
+		"0/contexts/0/monitoring_attrs/intervals/aggr_us": "2000000",
+		"0/contexts/0/targets/nr_targets":                 "2",
+		"0/contexts/0/targets/0/pid_target":               "100",
+		"0/contexts/0/targets/1/pid_target":               "200",
+		"0/contexts/0/schemes/nr_schemes":                 "2",
+		"0/contexts/0/schemes/1/action":                   "stat",
+		"0/contexts/0/schemes/1/filters/0/type":           "target",
+		"0/contexts/0/schemes/1/filters/0/target_idx":     "1",
+		"0/contexts/0/schemes/1/filters/0/matching":       "N",
+	})
+
+	pages := []page{{pid: 100, addr: 0x1000}, {pid: 100, addr: 0x5000}, {pid: 100, addr: 0x9000}}
+	if accessed, _ := tracker.Accessed(100, pages); accessed != nil {
+		t.Errorf("expected no information before monitoring results, got %v", accessed)
+	}
+
+	tried := filepath.Join(ctx, "schemes", "0", "tried_regions")
+	for idx, r := range []damonRegion{{0x0, 0x4000, 3}, {0x4000, 0x8000, 0}} {
+		dir := filepath.Join(tried, strconv.Itoa(idx))
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		for entry, value := range map[string]uint64{"start": r.start, "end": r.end, "nr_accesses": r.nrAccesses} {
+			if err := os.WriteFile(filepath.Join(dir, entry), []byte(strconv.FormatUint(value, 10)+"\n"), 0644); err != nil {
+				t.Fatalf("failed to create %s: %v", entry, err)
+			}
+		}
+	}
+
+	checkAccessed := func(pid int, expected []bool) {
+		t.Helper()
+		accessed, err := tracker.Accessed(pid, pages)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(accessed) != len(expected) {
+			t.Fatalf("expected accesses %v for process %d, got %v", expected, pid, accessed)
+		}
+		for i := range expected {
+			if accessed[i] != expected[i] {
+				t.Errorf("expected page #%d accessed to be %v, got %v", i, expected[i], accessed[i])
+			}
+		}
+	}
+
+	if err := tracker.Track([]int{100, 200}); err != nil {
+		t.Fatalf("failed to update tracking: %v", err)
+	}
+	checkEntries(map[string]string{"0/state": "update_schemes_tried_regions"})
+	checkAccessed(100, []bool{true, false, false})
+
+	// a new process takes the target of a gone one, others keep being monitored
+	if err := tracker.Track([]int{300, 100}); err != nil {
+		t.Fatalf("failed to update tracking: %v", err)
+	}
+	checkEntries(map[string]string{
+		"nr_kdamonds":                       "1",
+		"0/contexts/0/targets/nr_targets":   "2",
+		"0/contexts/0/targets/0/pid_target": "100",
+		"0/contexts/0/targets/1/pid_target": "300",
+		"0/state":                           "update_schemes_tried_regions",
+	})
+	checkAccessed(100, []bool{true, false, false})
+	checkAccessed(300, nil)
+
+	tracker.Stop()
+	checkEntries(map[string]string{
+		"nr_kdamonds": "0",
+		"0/state":     "off",
+	})
+}
+
+func TestAssignTargets(t *testing.T) {
+	tcases := []struct {
+		name     string
+		old      []int
+		pids     []int
+		expected []int
+	}{
+		{
+			name:     "new processes",
+			pids:     []int{3, 1, 2},
+			expected: []int{1, 2, 3},
+		},
+		{
+			name:     "unchanged processes",
+			old:      []int{3, 1, 2},
+			pids:     []int{1, 2, 3},
+			expected: []int{3, 1, 2},
+		},
+		{
+			name:     "new process replaces a gone one",
+			old:      []int{1, 2, 3},
+			pids:     []int{3, 4, 1},
+			expected: []int{1, 4, 3},
+		},
+		{
+			name:     "new processes appended",
+			old:      []int{1, 2},
+			pids:     []int{5, 4, 2, 1},
+			expected: []int{1, 2, 4, 5},
+		},
+		{
+			name:     "last processes fill gaps",
+			old:      []int{1, 2, 3, 4, 5},
+			pids:     []int{5, 3},
+			expected: []int{5, 3},
+		},
+		{
+			name:     "all processes gone",
+			old:      []int{1, 2},
+			expected: []int{},
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			targets := assignTargets(tc.old, tc.pids)
+			if !equalPids(targets, tc.expected) {
+				t.Errorf("expected targets %v, got %v", tc.expected, targets)
+			}
+		})
+	}
+}
+
+func TestSamplePages(t *testing.T) {
+	pageSize := uint64(4096)
+	pages := []page{}
+	for i := uint64(0); i < 10; i++ {
+		pages = append(pages, page{addr: 0x10000 + i*pageSize})
+	}
+
+	tcases := []struct {
+		name     string
+		stride   uint64
+		expected []uint64
+	}{
+		{
+			name:     "no sampling",
+			stride:   1,
+			expected: []uint64{0x10000, 0x11000, 0x12000, 0x13000, 0x14000, 0x15000, 0x16000, 0x17000, 0x18000, 0x19000},
+		},
+		{
+			name:     "every 2nd page",
+			stride:   2,
+			expected: []uint64{0x10000, 0x12000, 0x14000, 0x16000, 0x18000},
+		},
+		{
+			name:     "every 3rd page",
+			stride:   3,
+			expected: []uint64{0x12000, 0x15000, 0x18000},
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			checkPageAddrs(t, "sample", samplePages(pages, tc.stride, pageSize), tc.expected)
+		})
+	}
+}
+
+func TestUpdateMover(t *testing.T) {
+	tr := newTierer(&migration{})
+	tr.pageMoveInterval = config.Duration(time.Hour)
+
+	done := make(chan struct{})
+	go func() {
+		// the mover only picks up plans between moves, so none of these may block
+		for i := 0; i < 5; i++ {
+			tr.updateMover("test", &tieringPlan{})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("passing tiering plans to a mover blocked")
+	}
+
+	tr.stopMovers()
+	if len(tr.movers) != 0 {
+		t.Errorf("expected all movers to be stopped, got %d", len(tr.movers))
+	}
+}
@@ -0,0 +1,503 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pagemigrate
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/intel/cri-resource-manager/pkg/sysfs"
+)
+
+const (
+	// sysfs idle page tracking bitmap
+	idlePageBitmapPath = "kernel/mm/page_idle/bitmap"
+	// sysfs DAMON admin directory
+	damonAdminPath = "kernel/mm/damon/admin"
+	// DAMON sampling interval in microseconds
+	damonSampleInterval = 5000
+	// unlimited upper bound for DAMON access pattern ranges
+	damonUnlimited = "18446744073709551615"
+)
+
+// pageTracker tracks accesses to the memory pages of processes.
+type pageTracker interface {
+	// Name returns the name of the tracker.
+	Name() string
+	// Track starts or updates tracking of the given processes.
+	Track(pids []int) error
+	// Accessed returns which of the given pages of a process have been accessed
+	// since the previous call. It returns nil if there is no information yet.
+	Accessed(pid int, pages []page) ([]bool, error)
+	// Stop stops tracking all processes.
+	Stop()
+}
+
+// newPageTracker creates a page tracker of the given kind.
+func newPageTracker(kind string, interval time.Duration) (pageTracker, error) {
+	bitmap := filepath.Join("/", sysfs.SysRoot(), "sys", idlePageBitmapPath)
+	damon := filepath.Join("/", sysfs.SysRoot(), "sys", damonAdminPath)
+
+	switch kind {
+	case IdlePageTracker:
+		return newIdlePageTracker(bitmap)
+	case DamonTracker:
+		return newDamonTracker(damon, interval)
+	case AutoTracker:
+		if t, err := newDamonTracker(damon, interval); err == nil {
+			return t, nil
+		}
+		if t, err := newIdlePageTracker(bitmap); err == nil {
+			return t, nil
+		}
+		return nil, migrationError("neither DAMON nor idle page tracking is available")
+	}
+
+	return nil, migrationError("unknown page tracker %q", kind)
+}
+
+// idlePageTracker tracks page accesses using the kernel idle page tracking bitmap.
+type idlePageTracker struct {
+	bitmap string       // path to the idle page bitmap
+	seen   map[int]bool // processes with pages already marked idle
+}
+
+// newIdlePageTracker creates an idle page tracker for the given bitmap.
+func newIdlePageTracker(bitmap string) (*idlePageTracker, error) {
+	if _, err := os.Stat(bitmap); err != nil {
+		return nil, migrationError("idle page tracking not available: %v", err)
+	}
+	return &idlePageTracker{
+		bitmap: bitmap,
+		seen:   make(map[int]bool),
+	}, nil
+}
+
+// Name returns the name of the tracker.
+func (t *idlePageTracker) Name() string {
+	return IdlePageTracker
+}
+
+// Track forgets about processes which are not tracked any more.
+func (t *idlePageTracker) Track(pids []int) error {
+	tracked := make(map[int]bool)
+	for _, pid := range pids {
+		if t.seen[pid] {
+			tracked[pid] = true
+		}
+	}
+	t.seen = tracked
+	return nil
+}
+
+// Accessed checks and then sets the idle bits of the page frames of the given pages.
+func (t *idlePageTracker) Accessed(pid int, pages []page) ([]bool, error) {
+	f, err := os.OpenFile(t.bitmap, os.O_RDWR, 0)
+	if err != nil {
+		return nil, migrationError("failed to open idle page bitmap: %v", err)
+	}
+	defer f.Close()
+
+	accessed := make([]bool, len(pages))
+	words := make(map[uint64]uint64) // bitmap words read
+	idle := make(map[uint64]uint64)  // bits to mark idle, per bitmap word
+	buf := make([]byte, 8)
+	known := 0
+
+	for i, p := range pages {
+		if p.pfn == 0 {
+			accessed[i] = true
+			continue
+		}
+		known++
+		idx, bit := p.pfn/64, uint64(1)<<(p.pfn%64)
+		word, ok := words[idx]
+		if !ok {
+			if _, err := f.ReadAt(buf, int64(idx*8)); err != nil {
+				return nil, migrationError("failed to read idle page bitmap: %v", err)
+			}
+			word = binary.LittleEndian.Uint64(buf)
+			words[idx] = word
+		}
+		accessed[i] = word&bit == 0
+		idle[idx] |= bit
+	}
+
+	if known == 0 && len(pages) > 0 {
+		return nil, migrationError("page frame numbers of process %d not available", pid)
+	}
+
+	for idx, bits := range idle {
+		binary.LittleEndian.PutUint64(buf, bits)
+		if _, err := f.WriteAt(buf, int64(idx*8)); err != nil {
+			return nil, migrationError("failed to write idle page bitmap: %v", err)
+		}
+	}
+
+	if !t.seen[pid] {
+		// pages have not been marked idle before, so we know nothing yet
+		t.seen[pid] = true
+		return nil, nil
+	}
+
+	return accessed, nil
+}
+
+// Stop stops tracking all processes.
+func (t *idlePageTracker) Stop() {
+	t.seen = make(map[int]bool)
+}
+
+// damonTracker tracks page accesses using a single kdamond, with a DAMON
+// monitoring target and a stat scheme limited to that target per process.
+// DAMON sysfs has no notion of ownership, so the tracker refuses to start if
+// there are kdamonds it did not create, and it removes its kdamond when stopped.
+type damonTracker struct {
+	root     string                // DAMON sysfs admin directory
+	interval time.Duration         // DAMON aggregation interval
+	running  bool                  // whether our kdamond is running
+	targets  []int                 // processes tracked, in DAMON target order
+	started  map[int]bool          // processes with monitoring results available
+	regions  map[int][]damonRegion // regions of the last monitoring results
+}
+
+// damonEntry is a value to write to a DAMON sysfs entry.
+type damonEntry struct {
+	dir   string
+	entry string
+	value string
+}
+
+// damonRegion is a memory region with its DAMON access frequency.
+type damonRegion struct {
+	start      uint64
+	end        uint64
+	nrAccesses uint64
+}
+
+// newDamonTracker creates a DAMON tracker for the given DAMON sysfs admin directory.
+func newDamonTracker(root string, interval time.Duration) (*damonTracker, error) {
+	t := &damonTracker{
+		root:     root,
+		interval: interval,
+		started:  make(map[int]bool),
+		regions:  make(map[int][]damonRegion),
+	}
+	if err := t.checkUnused(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// checkUnused checks that DAMON is available and not used by anyone else.
+func (t *damonTracker) checkUnused() error {
+	nr := uint64(0)
+	if err := readEntry(filepath.Join(t.root, "kdamonds"), "nr_kdamonds", &nr); err != nil {
+		return migrationError("DAMON not available: %v", err)
+	}
+	if nr != 0 {
+		return migrationError("DAMON already in use by %d kdamond(s)", nr)
+	}
+	return nil
+}
+
+// Name returns the name of the tracker.
+func (t *damonTracker) Name() string {
+	return DamonTracker
+}
+
+// Track updates the monitoring targets of our kdamond if the set of processes
+// has changed, and collects the monitoring results of already tracked ones.
+func (t *damonTracker) Track(pids []int) error {
+	targets := assignTargets(t.targets, pids)
+	if len(targets) == 0 {
+		t.Stop()
+		return nil
+	}
+
+	// processes keeping their target have results from the last aggregation
+	known := make(map[int]bool)
+	if t.running {
+		for idx, pid := range targets {
+			if idx < len(t.targets) && t.targets[idx] == pid {
+				known[pid] = true
+			}
+		}
+	}
+
+	if !t.running || !equalPids(targets, t.targets) {
+		if err := t.configure(targets); err != nil {
+			t.Stop()
+			return err
+		}
+	}
+
+	return t.collect(known)
+}
+
+// assignTargets assigns DAMON targets to processes. Processes already tracked
+// keep their target, so their monitoring goes on undisturbed. New processes
+// take the targets of gone ones, and the remaining gaps are filled by moving
+// the last targets.
+func assignTargets(old, pids []int) []int {
+	wanted := make(map[int]bool)
+	for _, pid := range pids {
+		wanted[pid] = true
+	}
+
+	targets := make([]int, 0, len(pids))
+	free := []int{}
+	for _, pid := range old {
+		if wanted[pid] {
+			delete(wanted, pid)
+			targets = append(targets, pid)
+		} else {
+			free = append(free, len(targets))
+			targets = append(targets, -1)
+		}
+	}
+
+	added := make([]int, 0, len(wanted))
+	for pid := range wanted {
+		added = append(added, pid)
+	}
+	sort.Ints(added)
+	for _, pid := range added {
+		if len(free) > 0 {
+			targets[free[0]] = pid
+			free = free[1:]
+		} else {
+			targets = append(targets, pid)
+		}
+	}
+
+	for len(free) > 0 {
+		hole, last := free[0], len(targets)-1
+		switch {
+		case hole > last:
+			free = free[1:]
+		case targets[last] < 0:
+			targets = targets[:last]
+		default:
+			targets[hole] = targets[last]
+			targets = targets[:last]
+			free = free[1:]
+		}
+	}
+
+	return targets
+}
+
+// configure starts our kdamond for the given targets, or commits the new
+// targets to it if it is already running.
+func (t *damonTracker) configure(targets []int) error {
+	kdamonds := filepath.Join(t.root, "kdamonds")
+	kdamond := filepath.Join(kdamonds, "0")
+	ctx := filepath.Join(kdamond, "contexts", "0")
+
+	state := "commit"
+	if !t.running {
+		if err := t.checkUnused(); err != nil {
+			return err
+		}
+		if err := writeEntry(kdamonds, "nr_kdamonds", "1"); err != nil {
+			return err
+		}
+		t.running = true
+		state = "on"
+
+		aggr := strconv.FormatInt(t.interval.Microseconds(), 10)
+		for _, e := range []damonEntry{
+			{kdamond, "contexts/nr_contexts", "1"},
+			{ctx, "operations", "vaddr"},
+			{ctx, "monitoring_attrs/intervals/sample_us", strconv.Itoa(damonSampleInterval)},
+			{ctx, "monitoring_attrs/intervals/aggr_us", aggr},
+			{ctx, "monitoring_attrs/intervals/update_us", aggr},
+		} {
+			if err := writeEntry(e.dir, e.entry, e.value); err != nil {
+				return err
+			}
+		}
+	}
+
+	entries := []damonEntry{
+		{ctx, "targets/nr_targets", strconv.Itoa(len(targets))},
+		{ctx, "schemes/nr_schemes", strconv.Itoa(len(targets))},
+	}
+	for idx, pid := range targets {
+		target := filepath.Join(ctx, "targets", strconv.Itoa(idx))
+		scheme := filepath.Join(ctx, "schemes", strconv.Itoa(idx))
+		pattern := filepath.Join(scheme, "access_pattern")
+		filter := filepath.Join(scheme, "filters", "0")
+		entries = append(entries, []damonEntry{
+			{target, "pid_target", strconv.Itoa(pid)},
+			{scheme, "action", "stat"},
+			{pattern, "sz/min", "0"},
+			{pattern, "sz/max", damonUnlimited},
+			{pattern, "nr_accesses/min", "0"},
+			{pattern, "nr_accesses/max", damonUnlimited},
+			{pattern, "age/min", "0"},
+			{pattern, "age/max", damonUnlimited},
+			// filter out regions of other targets, so the regions tried by
+			// the scheme are the regions of this target
+			{scheme, "filters/nr_filters", "1"},
+			{filter, "type", "target"},
+			{filter, "target_idx", strconv.Itoa(idx)},
+			{filter, "matching", "N"},
+		}...)
+	}
+	entries = append(entries, damonEntry{kdamond, "state", state})
+
+	for _, e := range entries {
+		if err := writeEntry(e.dir, e.entry, e.value); err != nil {
+			return err
+		}
+	}
+
+	t.targets = targets
+	log.Info("DAMON monitoring processes %v", targets)
+
+	return nil
+}
+
+// collect collects the latest monitoring results of the given processes.
+func (t *damonTracker) collect(pids map[int]bool) error {
+	t.started = make(map[int]bool)
+	t.regions = make(map[int][]damonRegion)
+	if len(pids) == 0 {
+		return nil
+	}
+
+	kdamond := filepath.Join(t.root, "kdamonds", "0")
+	if err := writeEntry(kdamond, "state", "update_schemes_tried_regions"); err != nil {
+		return err
+	}
+
+	for idx, pid := range t.targets {
+		if !pids[pid] {
+			continue
+		}
+		regions, err := t.readRegions(idx)
+		if err != nil {
+			log.Debug("failed to read DAMON regions of process %d: %v", pid, err)
+			continue
+		}
+		t.regions[pid] = regions
+		t.started[pid] = true
+	}
+
+	return nil
+}
+
+// readRegions reads the latest monitoring results of a target.
+func (t *damonTracker) readRegions(idx int) ([]damonRegion, error) {
+	scheme := filepath.Join(t.root, "kdamonds", "0", "contexts", "0", "schemes", strconv.Itoa(idx))
+	dirs, _ := filepath.Glob(filepath.Join(scheme, "tried_regions", "[0-9]*"))
+	regions := make([]damonRegion, 0, len(dirs))
+	for _, dir := range dirs {
+		r := damonRegion{}
+		for entry, ptr := range map[string]*uint64{
+			"start":       &r.start,
+			"end":         &r.end,
+			"nr_accesses": &r.nrAccesses,
+		} {
+			if err := readEntry(dir, entry, ptr); err != nil {
+				return nil, err
+			}
+		}
+		regions = append(regions, r)
+	}
+	sort.Slice(regions, func(i, j int) bool { return regions[i].start < regions[j].start })
+
+	return regions, nil
+}
+
+// Accessed checks the given pages against the latest monitoring results.
+func (t *damonTracker) Accessed(pid int, pages []page) ([]bool, error) {
+	if !t.started[pid] {
+		return nil, nil
+	}
+
+	regions := t.regions[pid]
+	accessed := make([]bool, len(pages))
+	for i, p := range pages {
+		idx := sort.Search(len(regions), func(j int) bool { return regions[j].end > p.addr })
+		if idx < len(regions) && regions[idx].start <= p.addr {
+			accessed[i] = regions[idx].nrAccesses > 0
+		}
+	}
+
+	return accessed, nil
+}
+
+// Stop stops and removes our kdamond.
+func (t *damonTracker) Stop() {
+	if t.running {
+		kdamonds := filepath.Join(t.root, "kdamonds")
+		if err := writeEntry(filepath.Join(kdamonds, "0"), "state", "off"); err != nil {
+			log.Warn("failed to stop kdamond: %v", err)
+		}
+		if err := writeEntry(kdamonds, "nr_kdamonds", "0"); err != nil {
+			log.Warn("failed to remove kdamond: %v", err)
+		}
+	}
+	t.running = false
+	t.targets = nil
+	t.started = make(map[int]bool)
+	t.regions = make(map[int][]damonRegion)
+}
+
+// equalPids checks if two slices of pids are equal.
+func equalPids(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// writeEntry writes a value to a sysfs entry.
+func writeEntry(dir, entry, value string) error {
+	path := filepath.Join(dir, entry)
+	if err := ioutil.WriteFile(path, []byte(value), 0644); err != nil {
+		return migrationError("failed to write %q to %s: %v", value, path, err)
+	}
+	return nil
+}
+
+// readEntry reads an unsigned integer value from a sysfs entry.
+func readEntry(dir, entry string, ptr *uint64) error {
+	path := filepath.Join(dir, entry)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return migrationError("failed to read %s: %v", path, err)
+	}
+	value, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return migrationError("failed to parse %s: %v", path, err)
+	}
+	*ptr = value
+	return nil
+}
@@ -17,9 +17,11 @@ package resmgr
 import (
 	"time"
 
+	"github.com/intel/cri-resource-manager/pkg/cgroupstats"
 	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/cache"
 	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/events"
 	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/metrics"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/pressure"
 	logger "github.com/intel/cri-resource-manager/pkg/log"
 )
 
@@ -32,6 +34,7 @@ func (m *resmgr) setupEventProcessing() error {
 
 	m.events = make(chan interface{}, 8)
 	m.stop = make(chan interface{})
+	cgroupstats.SetContainerLister(m.listCgroupStatsContainers)
 	options := metrics.Options{
 		PollInterval: opt.MetricsTimer,
 		Events:       m.events,
@@ -39,6 +42,7 @@ func (m *resmgr) setupEventProcessing() error {
 	if m.metrics, err = metrics.NewMetrics(options); err != nil {
 		return resmgrError("failed to create metrics (pre)processor: %v", err)
 	}
+	m.pressure = pressure.NewMonitor(m.listPressureTargets, m.SendEvent)
 
 	return nil
 }
@@ -48,6 +52,9 @@ func (m *resmgr) startEventProcessing() error {
 	if err := m.metrics.Start(); err != nil {
 		return resmgrError("failed to start metrics (pre)processor: %v", err)
 	}
+	if err := m.pressure.Start(); err != nil {
+		return resmgrError("failed to start pressure monitor: %v", err)
+	}
 
 	stop := m.stop
 	go func() {
@@ -86,8 +93,10 @@ func (m *resmgr) stopEventProcessing() {
 	if m.stop != nil {
 		close(m.stop)
 		m.metrics.Stop()
+		m.pressure.Stop()
 		m.stop = nil
 	}
+	m.idle.Wake()
 }
 
 // SendEvent injects the given event to the resource manager's event processing loop.
@@ -114,6 +123,8 @@ func (m *resmgr) processEvent(e interface{}) {
 		m.processAvx(event.Avx)
 	case *events.Policy:
 		m.DeliverPolicyEvent(event)
+	case *events.IdleCPUs:
+		m.parkIdleCPUs()
 	default:
 		evtlog.Warn("event of unexpected type %T...", e)
 	}
@@ -149,6 +160,61 @@ func (m *resmgr) processAvx(e *events.Avx) bool {
 	return changes
 }
 
+// parkIdleCPUs parks CPUs which have stayed idle long enough.
+func (m *resmgr) parkIdleCPUs() {
+	m.Lock()
+	defer m.Unlock()
+
+	m.idle.Park()
+}
+
+// listCgroupStatsContainers lists containers for cgroup statistics collection.
+func (m *resmgr) listCgroupStatsContainers() []cgroupstats.ContainerInfo {
+	m.RLock()
+	defer m.RUnlock()
+
+	containers := []cgroupstats.ContainerInfo{}
+	for _, c := range m.cache.GetContainers() {
+		dir := c.GetCgroupDir()
+		if dir == "" {
+			continue
+		}
+		info := cgroupstats.ContainerInfo{
+			Namespace: c.GetNamespace(),
+			Container: c.GetName(),
+			ID:        c.GetID(),
+			CgroupDir: dir,
+		}
+		if pod, ok := c.GetPod(); ok {
+			info.Pod = pod.GetName()
+		}
+		containers = append(containers, info)
+	}
+	return containers
+}
+
+// listPressureTargets lists running containers for pressure monitoring.
+func (m *resmgr) listPressureTargets() []pressure.Target {
+	m.RLock()
+	defer m.RUnlock()
+
+	targets := []pressure.Target{}
+	for _, c := range m.cache.GetContainers() {
+		if c.GetState() != cache.ContainerStateRunning {
+			continue
+		}
+		dir := c.GetCgroupDir()
+		if dir == "" {
+			continue
+		}
+		targets = append(targets, pressure.Target{
+			ID:        c.GetCacheID(),
+			CgroupDir: dir,
+		})
+	}
+	return targets
+}
+
 // resolveCgroupPath resolves a cgroup path to a container.
 func (m *resmgr) resolveCgroupPath(path string) (cache.Container, bool) {
 	return m.cache.LookupContainerByCgroup(path)
@@ -26,6 +26,9 @@ type Avx struct {
 	Updates map[string]bool
 }
 
+// IdleCPUs is a request to park CPUs which have stayed idle long enough.
+type IdleCPUs struct{}
+
 // Policy is a policy-specific event to be handled by the active policy.
 type Policy struct {
 	// Event is the policy-specific type of this event.
@@ -36,7 +39,22 @@ type Policy struct {
 	Data interface{}
 }
 
+// Pressure describes resource pressure (PSI) detected for a container.
+type Pressure struct {
+	// ContainerID is the cache ID of the container under pressure.
+	ContainerID string
+	// Resource is the resource under pressure, "cpu" or "memory".
+	Resource string
+	// Avg10 is the 10-second average of some-stall time, in percent.
+	Avg10 float64
+	// Threshold is the configured threshold that was crossed, in percent.
+	Threshold float64
+}
+
 const (
 	// ContainerStarted is delivered to policies when a StartContainer request succeeds.
 	ContainerStarted = "container-started"
+	// ContainerPressure is delivered to policies when a container's CPU or memory
+	// stall time crosses the configured pressure threshold. Data is *Pressure.
+	ContainerPressure = "container-pressure"
 )
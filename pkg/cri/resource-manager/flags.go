@@ -19,6 +19,7 @@ import (
 	"time"
 
 	"github.com/intel/cri-resource-manager/pkg/cri/relay"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/cache"
 	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/sockets"
 	"github.com/intel/cri-resource-manager/pkg/pidfile"
 )
@@ -41,6 +42,8 @@ type options struct {
 	DisablePolicySwitch   bool
 	ResetPolicy           bool
 	ResetConfig           bool
+	ConfigHistory         int
+	DumpTopology          string
 	MetricsTimer          time.Duration
 	RebalanceTimer        time.Duration
 	DisableUI             bool
@@ -83,9 +86,13 @@ func init() {
 		"Signal used to reload forced configuration.")
 	flag.BoolVar(&opt.ResetConfig, "reset-config", false,
 		"Remove configuration (from the agent) stored in the cache, then exit.")
+	flag.IntVar(&opt.ConfigHistory, "config-history", cache.DefaultConfigHistory,
+		"Number of applied configurations to keep in the history for rollback.")
 
 	flag.BoolVar(&opt.ResetPolicy, "reset-policy", false,
 		"Reset policy data stored in the cache, then exit.")
+	flag.StringVar(&opt.DumpTopology, "dump-topology", "",
+		"Archive system topology details into the given file, then exit.")
 	flag.BoolVar(&opt.DisablePolicySwitch, "disable-policy-switch", false,
 		"Disable switching policies during startup.")
 
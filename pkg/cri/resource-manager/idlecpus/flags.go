@@ -0,0 +1,73 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package idlecpus
+
+import (
+	"time"
+
+	"github.com/intel/cri-resource-manager/pkg/config"
+)
+
+const (
+	// ConfigPath is the configuration path for idle CPU power management.
+	ConfigPath = "resource-manager.idle-cpus"
+	// ConfigDescription is the description for idle CPU power management.
+	ConfigDescription = "idle CPU power management"
+
+	// defaultGracePeriod is the default time a CPU stays idle before getting parked.
+	defaultGracePeriod = time.Minute
+)
+
+// options captures our configurable parameters.
+type options struct {
+	// IdleCPUClass is the CPU class idle CPUs are parked in.
+	IdleCPUClass string
+	// ActiveCPUClass is the CPU class CPUs without a class before they got
+	// parked in IdleCPUClass are assigned to when woken up. If empty, such
+	// CPUs are handed back to the cpu controller, which assigns them to its
+	// default class or restores their original settings.
+	ActiveCPUClass string
+	// Offline parks idle CPUs by taking them offline instead of using
+	// IdleCPUClass.
+	Offline bool
+	// GracePeriod is the time a CPU needs to stay idle before it is parked.
+	GracePeriod config.Duration
+	// MinIdleCPUs is the number of idle CPUs to keep unparked, ready for
+	// allocation.
+	MinIdleCPUs int
+}
+
+// Our runtime configuration.
+var opt = defaultOptions().(*options)
+
+// enabled returns true if idle CPUs should be parked.
+func (o *options) enabled() bool {
+	if o.Offline {
+		return true
+	}
+	return o.IdleCPUClass != ""
+}
+
+// defaultOptions returns a new options instance, all initialized to defaults.
+func defaultOptions() interface{} {
+	return &options{
+		GracePeriod: config.Duration(defaultGracePeriod),
+	}
+}
+
+// Register us for configuration handling.
+func init() {
+	config.Register(ConfigPath, ConfigDescription, opt, defaultOptions)
+}
@@ -0,0 +1,343 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package idlecpus
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
+
+	"github.com/intel/cri-resource-manager/pkg/cpuallocator"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/cache"
+	cpucontrol "github.com/intel/cri-resource-manager/pkg/cri/resource-manager/control/cpu"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/events"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/introspect"
+	logger "github.com/intel/cri-resource-manager/pkg/log"
+	"github.com/intel/cri-resource-manager/pkg/sysfs"
+	idset "github.com/intel/goresctrl/pkg/utils"
+)
+
+// Manager parks CPUs not used by any container, to save power.
+//
+// CPUs which are part of some policy pool but are not assigned to any
+// container are considered idle. CPUs which stay idle for the configured
+// grace period are parked, either by assigning them to a low-power CPU
+// class or by taking them offline. Parked CPUs are woken up once the
+// policy takes them into use, by calling Update() with the new policy
+// state before the CPUs get assigned to any container. CPUs which stay
+// idle keep the time they became idle, so they stay parked.
+//
+// Manager is not safe for concurrent use, it expects to be called with
+// the resource manager lock held. Parking is triggered by sending an
+// events.IdleCPUs event, which should be handled by calling Park().
+type Manager struct {
+	cache     cache.Cache                                        // resource manager cache
+	send      func(interface{}) error                            // send park events
+	sys       sysfs.System                                       // system, for offlining CPUs
+	allocator cpuallocator.CPUAllocator                          // for picking CPUs to keep unparked
+	discover  bool                                               // discover system when needed
+	idle      map[int]time.Time                                  // idle CPUs, time they became idle
+	parked    cpuset.CPUSet                                      // parked CPUs
+	classes   map[int]string                                     // CPU classes of CPUs before parking
+	offline   bool                                               // whether parked CPUs are offline
+	timer     *time.Timer                                        // timer for next parking
+	now       func() time.Time                                   // current time
+	setParked func(cpus cpuset.CPUSet, park, offline bool) error // (un)park CPUs
+}
+
+// Our logger instance.
+var log = logger.NewLogger("idlecpus")
+
+// NewManager creates a new idle CPU manager.
+func NewManager(cache cache.Cache, send func(interface{}) error) *Manager {
+	m := &Manager{
+		cache:    cache,
+		send:     send,
+		discover: true,
+		idle:     make(map[int]time.Time),
+		parked:   cpuset.NewCPUSet(),
+		classes:  make(map[int]string),
+		now:      time.Now,
+	}
+	m.setParked = m.setParkedCPUs
+	return m
+}
+
+// Update updates the set of idle CPUs according to the given policy state.
+func (m *Manager) Update(state *introspect.State) {
+	if !opt.enabled() {
+		m.Wake()
+		m.idle = make(map[int]time.Time)
+		m.stopTimer()
+		return
+	}
+
+	idle := IdleCPUs(state)
+	if opt.Offline {
+		// CPU #0 usually can't be taken offline.
+		idle = idle.Difference(cpuset.NewCPUSet(0))
+	}
+
+	now := m.now()
+	for id := range m.idle {
+		if !idle.Contains(id) {
+			delete(m.idle, id)
+		}
+	}
+	for _, id := range idle.ToSlice() {
+		if _, ok := m.idle[id]; !ok {
+			m.idle[id] = now
+		}
+	}
+
+	if busy := m.parked.Difference(idle); !busy.IsEmpty() {
+		log.Info("parked CPUs %s taken into use, waking them up", busy)
+		m.unpark(busy)
+	}
+
+	m.schedule(time.Time{})
+}
+
+// Wake unparks all parked CPUs. CPUs which stay idle keep the time they
+// became idle, so they get parked again by the next Park() after Update().
+func (m *Manager) Wake() {
+	if m.parked.IsEmpty() {
+		return
+	}
+
+	log.Info("waking up parked CPUs %s", m.parked)
+	m.unpark(m.parked)
+}
+
+// WakeOffline brings parked CPUs back online, if they were taken offline.
+// This should be called before (re)starting a policy, since a policy may
+// leave offline CPUs out of its pools. CPUs parked in IdleCPUClass are
+// left parked.
+func (m *Manager) WakeOffline() {
+	if m.offline {
+		m.Wake()
+	}
+}
+
+// Park parks CPUs which have been idle for at least the grace period.
+func (m *Manager) Park() {
+	if !opt.enabled() {
+		return
+	}
+
+	now := m.now()
+	grace := time.Duration(opt.GracePeriod)
+	awake := []int{}
+	due := []int{}
+	for id, since := range m.idle {
+		if m.parked.Contains(id) {
+			continue
+		}
+		awake = append(awake, id)
+		if !now.Before(since.Add(grace)) {
+			due = append(due, id)
+		}
+	}
+
+	cpus := cpuset.NewCPUSet(due...)
+	if cnt := opt.MinIdleCPUs - (len(awake) - len(due)); cnt > 0 && !cpus.IsEmpty() {
+		keep := m.pickCPUs(cpus, cnt)
+		log.Debug("keeping idle CPUs %s unparked", keep)
+		cpus = cpus.Difference(keep)
+	}
+
+	if !cpus.IsEmpty() {
+		m.park(cpus)
+	}
+
+	m.schedule(now)
+}
+
+// Parked returns the currently parked CPUs.
+func (m *Manager) Parked() cpuset.CPUSet {
+	return m.parked.Clone()
+}
+
+// park parks the given CPUs.
+func (m *Manager) park(cpus cpuset.CPUSet) {
+	if !m.parked.IsEmpty() && m.offline != opt.Offline {
+		m.unpark(m.parked)
+	}
+
+	log.Info("parking idle CPUs %s", cpus)
+	if err := m.setParked(cpus, true, opt.Offline); err != nil {
+		log.Error("failed to park CPUs %s: %v", cpus, err)
+		return
+	}
+	m.parked = m.parked.Union(cpus)
+	m.offline = opt.Offline
+}
+
+// unpark unparks the given CPUs.
+func (m *Manager) unpark(cpus cpuset.CPUSet) {
+	if err := m.setParked(cpus, false, m.offline); err != nil {
+		log.Error("failed to wake up CPUs %s: %v", cpus, err)
+	}
+	m.parked = m.parked.Difference(cpus)
+}
+
+// schedule sets up a timer for the next idle CPU to become due after the given time.
+func (m *Manager) schedule(after time.Time) {
+	m.stopTimer()
+
+	grace := time.Duration(opt.GracePeriod)
+	next := time.Time{}
+	for id, since := range m.idle {
+		if m.parked.Contains(id) {
+			continue
+		}
+		due := since.Add(grace)
+		if !due.After(after) {
+			continue
+		}
+		if next.IsZero() || due.Before(next) {
+			next = due
+		}
+	}
+	if next.IsZero() {
+		return
+	}
+
+	delay := next.Sub(m.now())
+	if delay < 0 {
+		delay = 0
+	}
+	m.timer = time.AfterFunc(delay, func() {
+		if err := m.send(&events.IdleCPUs{}); err != nil {
+			log.Error("failed to send idle CPU event: %v", err)
+		}
+	})
+}
+
+// stopTimer stops any pending parking timer.
+func (m *Manager) stopTimer() {
+	if m.timer != nil {
+		m.timer.Stop()
+		m.timer = nil
+	}
+}
+
+// pickCPUs picks cnt CPUs from the given set, topologically close to each other if possible.
+func (m *Manager) pickCPUs(from cpuset.CPUSet, cnt int) cpuset.CPUSet {
+	if cnt >= from.Size() {
+		return from.Clone()
+	}
+	if m.getSystem() != nil {
+		cpus := from.Clone()
+		picked, err := m.allocator.AllocateCpus(&cpus, cnt, cpuallocator.PriorityNormal)
+		if err == nil {
+			return picked
+		}
+		log.Warn("failed to pick %d CPUs from %s: %v", cnt, from, err)
+	}
+	return cpuset.NewCPUSet(from.ToSlice()[:cnt]...)
+}
+
+// getSystem returns the discovered system, discovering it if necessary.
+func (m *Manager) getSystem() sysfs.System {
+	if m.sys == nil && m.discover {
+		m.discover = false
+		sys, err := sysfs.DiscoverSystem()
+		if err != nil {
+			log.Error("failed to discover system topology: %v", err)
+			return nil
+		}
+		m.sys = sys
+		m.allocator = cpuallocator.NewCPUAllocator(sys)
+	}
+	return m.sys
+}
+
+// setParkedCPUs parks or unparks CPUs by CPU class or by taking them offline.
+func (m *Manager) setParkedCPUs(cpus cpuset.CPUSet, park, offline bool) error {
+	if offline {
+		sys := m.getSystem()
+		if sys == nil {
+			return fmt.Errorf("system topology not available")
+		}
+		ids := idset.NewIDSet(cpus.ToSlice()...)
+		if _, err := sys.SetCpusOnline(!park, ids); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	if park {
+		for _, id := range cpus.ToSlice() {
+			if _, ok := m.classes[id]; !ok {
+				m.classes[id] = cpucontrol.GetCPUClass(m.cache, id)
+			}
+		}
+		return cpucontrol.Assign(m.cache, opt.IdleCPUClass, cpus.ToSlice()...)
+	}
+
+	// Restore the class each CPU had before parking. CPUs without a class
+	// get ActiveCPUClass, if set, or are handed back to the cpu controller.
+	restore := map[string][]int{}
+	for _, id := range cpus.ToSlice() {
+		class := m.classes[id]
+		if class == "" {
+			class = opt.ActiveCPUClass
+		}
+		restore[class] = append(restore[class], id)
+		delete(m.classes, id)
+	}
+	for class, ids := range restore {
+		if class == "" {
+			cpucontrol.Release(m.cache, ids...)
+			continue
+		}
+		if err := cpucontrol.Assign(m.cache, class, ids...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IdleCPUs returns the CPUs in policy pools which are neither reserved
+// nor used by any container.
+func IdleCPUs(state *introspect.State) cpuset.CPUSet {
+	pooled := cpuset.NewCPUSet()
+	used := cpuset.NewCPUSet()
+	if state == nil {
+		return pooled
+	}
+
+	for _, pool := range state.Pools {
+		pooled = pooled.Union(parseCPUs(pool.CPUs))
+	}
+	for _, a := range state.Assignments {
+		used = used.Union(parseCPUs(a.SharedCPUs)).Union(parseCPUs(a.ExclusiveCPUs))
+	}
+	used = used.Union(parseCPUs(state.Reserved))
+
+	return pooled.Difference(used)
+}
+
+// parseCPUs parses a cpuset string, returning an empty set on errors.
+func parseCPUs(cpus string) cpuset.CPUSet {
+	cset, err := cpuset.Parse(cpus)
+	if err != nil {
+		log.Warn("failed to parse cpuset %q: %v", cpus, err)
+		return cpuset.NewCPUSet()
+	}
+	return cset
+}
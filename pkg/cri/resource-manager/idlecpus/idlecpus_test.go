@@ -0,0 +1,279 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package idlecpus
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
+
+	"github.com/intel/cri-resource-manager/pkg/config"
+	"github.com/intel/cri-resource-manager/pkg/cpuallocator"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/cache"
+	cpucontrol "github.com/intel/cri-resource-manager/pkg/cri/resource-manager/control/cpu"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/introspect"
+	"github.com/intel/cri-resource-manager/pkg/sysfs"
+	"github.com/intel/cri-resource-manager/pkg/testutils"
+)
+
+func TestIdleCPUs(t *testing.T) {
+	state := &introspect.State{
+		Pools: map[string]*introspect.Pool{
+			"system": {Name: "system", CPUs: "0-1"},
+			"pool0":  {Name: "pool0", CPUs: "2-7"},
+			"pool1":  {Name: "pool1", CPUs: "8-15"},
+		},
+		Assignments: map[string]*introspect.Assignment{
+			"c1": {ContainerID: "c1", SharedCPUs: "2-5"},
+			"c2": {ContainerID: "c2", SharedCPUs: "2-5", ExclusiveCPUs: "8-9"},
+		},
+		Reserved: "0-1,15",
+	}
+	expected := "6-7,10-14"
+	if idle := IdleCPUs(state).String(); idle != expected {
+		t.Errorf("expected idle CPUs %s, got %s", expected, idle)
+	}
+}
+
+func TestParkAndWake(t *testing.T) {
+	saved := *opt
+	defer func() { *opt = saved }()
+	opt.IdleCPUClass = "idle"
+	opt.ActiveCPUClass = "active"
+	opt.Offline = false
+	opt.GracePeriod = config.Duration(time.Minute)
+	opt.MinIdleCPUs = 2
+
+	now := time.Now()
+	parked := cpuset.NewCPUSet()
+	m := &Manager{
+		send:   func(interface{}) error { return nil },
+		idle:   map[int]time.Time{},
+		parked: cpuset.NewCPUSet(),
+		now:    func() time.Time { return now },
+		setParked: func(cpus cpuset.CPUSet, park, offline bool) error {
+			if park {
+				parked = parked.Union(cpus)
+			} else {
+				parked = parked.Difference(cpus)
+			}
+			return nil
+		},
+	}
+	defer m.stopTimer()
+
+	state := func(used string) *introspect.State {
+		return &introspect.State{
+			Pools: map[string]*introspect.Pool{
+				"pool0": {Name: "pool0", CPUs: "0-7"},
+			},
+			Assignments: map[string]*introspect.Assignment{
+				"c0": {ContainerID: "c0", SharedCPUs: used},
+			},
+		}
+	}
+
+	m.Update(state("0-1"))
+	m.Park()
+	if !parked.IsEmpty() {
+		t.Errorf("expected no parked CPUs within grace period, got %s", parked)
+	}
+
+	now = now.Add(2 * time.Minute)
+	m.Park()
+	if parked.Size() != 4 || parked.Intersection(cpuset.MustParse("0-1")).Size() != 0 {
+		t.Errorf("expected 4 idle CPUs parked, got %s", parked)
+	}
+	if !m.Parked().Equals(parked) {
+		t.Errorf("expected parked CPUs %s, got %s", parked, m.Parked())
+	}
+
+	m.Wake()
+	if !parked.IsEmpty() || !m.Parked().IsEmpty() {
+		t.Errorf("expected all CPUs woken up, got %s parked", parked)
+	}
+
+	// CPUs which stay idle keep their grace period.
+	m.Update(state("0-1"))
+	m.Park()
+	if parked.Size() != 4 {
+		t.Errorf("expected 4 idle CPUs parked again, got %s", parked)
+	}
+
+	// Only CPUs taken into use get woken up.
+	taken := cpuset.NewCPUSet(parked.ToSlice()[:2]...)
+	m.Update(state(cpuset.MustParse("0-1").Union(taken).String()))
+	if !parked.Equals(m.Parked()) || parked.Size() != 2 || parked.Intersection(taken).Size() != 0 {
+		t.Errorf("expected only CPUs %s woken up, got %s parked", taken, parked)
+	}
+
+	m.Wake()
+	m.Update(state("0-5"))
+	now = now.Add(2 * time.Minute)
+	m.Park()
+	if !parked.IsEmpty() {
+		t.Errorf("expected minimum idle CPUs kept unparked, got %s parked", parked)
+	}
+
+	opt.IdleCPUClass = ""
+	m.Update(state("0-1"))
+	m.Park()
+	if !parked.IsEmpty() || len(m.idle) != 0 {
+		t.Errorf("expected no parking when disabled, got %s parked", parked)
+	}
+}
+
+func TestParkInClass(t *testing.T) {
+	saved := *opt
+	defer func() { *opt = saved }()
+	opt.IdleCPUClass = "idle"
+	opt.ActiveCPUClass = ""
+	opt.Offline = false
+	opt.GracePeriod = config.Duration(time.Minute)
+	opt.MinIdleCPUs = 0
+
+	cch, err := cache.NewCache(cache.Options{CacheDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	if err := cpucontrol.Assign(cch, "turbo", 2, 3); err != nil {
+		t.Fatalf("failed to assign CPUs to class: %v", err)
+	}
+
+	now := time.Now()
+	m := NewManager(cch, func(interface{}) error { return nil })
+	m.discover = false
+	m.now = func() time.Time { return now }
+	defer m.stopTimer()
+
+	state := func(used string) *introspect.State {
+		return &introspect.State{
+			Pools: map[string]*introspect.Pool{
+				"pool0": {Name: "pool0", CPUs: "0-5"},
+			},
+			Assignments: map[string]*introspect.Assignment{
+				"c0": {ContainerID: "c0", SharedCPUs: used},
+			},
+		}
+	}
+	// classes returns the CPU class of the given CPUs.
+	classes := func(cpus ...int) []string {
+		names := []string{}
+		for _, id := range cpus {
+			names = append(names, cpucontrol.GetCPUClass(cch, id))
+		}
+		return names
+	}
+
+	m.Update(state("0-1"))
+	now = now.Add(2 * time.Minute)
+	m.Park()
+	if names := classes(2, 3, 4, 5); !reflect.DeepEqual(names, []string{"idle", "idle", "idle", "idle"}) {
+		t.Errorf("expected parked CPUs in class idle, got classes %v", names)
+	}
+
+	// CPUs taken into use get their original class back.
+	m.Update(state("0-2,4"))
+	if names := classes(2, 3, 4, 5); !reflect.DeepEqual(names, []string{"turbo", "idle", "", "idle"}) {
+		t.Errorf("expected original classes restored, got classes %v", names)
+	}
+
+	m.Wake()
+	if names := classes(2, 3, 4, 5); !reflect.DeepEqual(names, []string{"turbo", "turbo", "", ""}) {
+		t.Errorf("expected original classes restored, got classes %v", names)
+	}
+}
+
+func TestParkOffline(t *testing.T) {
+	saved := *opt
+	defer func() { *opt = saved }()
+	opt.IdleCPUClass = ""
+	opt.ActiveCPUClass = ""
+	opt.Offline = true
+	opt.GracePeriod = config.Duration(time.Minute)
+	opt.MinIdleCPUs = 0
+
+	root := filepath.Join(testutils.CreateFakeSysfs(t, 8), "sys")
+	sys, err := sysfs.DiscoverSystemAt(root)
+	if err != nil {
+		t.Fatalf("failed to discover test system: %v", err)
+	}
+	// offlined returns the CPUs taken offline in the fake sysfs.
+	offlined := func() cpuset.CPUSet {
+		cpus := []int{}
+		for id := 0; id < 8; id++ {
+			online, err := os.ReadFile(filepath.Join(root, "devices/system/cpu", "cpu"+strconv.Itoa(id), "online"))
+			if err != nil {
+				t.Fatalf("failed to read online status of CPU #%d: %v", id, err)
+			}
+			if strings.TrimSpace(string(online)) == "0" {
+				cpus = append(cpus, id)
+			}
+		}
+		return cpuset.NewCPUSet(cpus...)
+	}
+
+	now := time.Now()
+	m := NewManager(nil, func(interface{}) error { return nil })
+	m.sys = sys
+	m.allocator = cpuallocator.NewCPUAllocator(sys)
+	m.discover = false
+	m.now = func() time.Time { return now }
+	defer m.stopTimer()
+
+	state := func(used string) *introspect.State {
+		return &introspect.State{
+			Pools: map[string]*introspect.Pool{
+				"pool0": {Name: "pool0", CPUs: "0-7"},
+			},
+			Assignments: map[string]*introspect.Assignment{
+				"c0": {ContainerID: "c0", SharedCPUs: used},
+			},
+			Reserved: "1",
+		}
+	}
+
+	m.Update(state("2-3"))
+	now = now.Add(2 * time.Minute)
+	m.Park()
+	expected := cpuset.MustParse("4-7")
+	if cpus := offlined(); !cpus.Equals(expected) {
+		t.Errorf("expected CPUs %s offline, got %s", expected, cpus)
+	}
+	if !m.Parked().Equals(expected) {
+		t.Errorf("expected parked CPUs %s, got %s", expected, m.Parked())
+	}
+	if !sys.Offlined().Equals(expected) {
+		t.Errorf("expected system to report CPUs %s offline, got %s", expected, sys.Offlined())
+	}
+
+	// CPUs taken into use get brought back online.
+	m.Update(state("2-5"))
+	expected = cpuset.MustParse("6-7")
+	if cpus := offlined(); !cpus.Equals(expected) {
+		t.Errorf("expected CPUs %s offline after taking CPUs into use, got %s", expected, cpus)
+	}
+
+	m.Wake()
+	if cpus := offlined(); !cpus.IsEmpty() || !m.Parked().IsEmpty() {
+		t.Errorf("expected all CPUs back online, got %s offline", cpus)
+	}
+}
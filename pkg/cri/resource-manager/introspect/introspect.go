@@ -51,21 +51,24 @@ type TopologyHints topology.Hints
 
 // Assignment describes resource assignments for a single container.
 type Assignment struct {
-	ContainerID   string // ID of container for this assignment
-	SharedCPUs    string // shared CPUs
-	CPUShare      int    // CPU share/weight for SharedCPUs
-	ExclusiveCPUs string // exclusive CPUs
-	Memory        string // memory controllers
-	Pool          string // pool container is assigned to
+	ContainerID   string            // ID of container for this assignment
+	SharedCPUs    string            // shared CPUs
+	CPUShare      int               // CPU share/weight for SharedCPUs
+	ExclusiveCPUs string            // exclusive CPUs
+	Memory        string            // memory controllers
+	Pool          string            // pool container is assigned to
+	Extra         map[string]string // policy-specific details
 }
 
 // Pool describes a single (resource) pool.
 type Pool struct {
-	Name     string   // pool name
-	CPUs     string   // CPUs in this pool
-	Memory   string   // memory controllers (NUMA nodes) for this pool
-	Parent   string   // parent pool
-	Children []string // child pools
+	Name       string            // pool name
+	CPUs       string            // CPUs in this pool
+	Memory     string            // memory controllers (NUMA nodes) for this pool
+	Parent     string            // parent pool
+	Children   []string          // child pools
+	Containers []string          // containers assigned to this pool
+	Extra      map[string]string // policy-specific details
 }
 
 // Socket describes a single physical CPU socket in the system.
@@ -96,6 +99,7 @@ type State struct {
 	Pools       map[string]*Pool       // pools
 	Pods        map[string]*Pod        // pods and containers
 	Assignments map[string]*Assignment // resource assignments
+	Reserved    string                 // CPUs reserved for system and kube-system containers
 	System      *System                // info about hardware/system
 	Error       string
 }
@@ -17,12 +17,15 @@ package balloons
 import (
 	"fmt"
 	"path/filepath"
+	"sort"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	resapi "k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
 
+	"github.com/intel/cri-resource-manager/pkg/apis/resmgr"
 	pkgcfg "github.com/intel/cri-resource-manager/pkg/config"
 	"github.com/intel/cri-resource-manager/pkg/cpuallocator"
 	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/cache"
@@ -32,6 +35,7 @@ import (
 	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/kubernetes"
 	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/policy"
 	policyapi "github.com/intel/cri-resource-manager/pkg/cri/resource-manager/policy"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/pressure"
 	logger "github.com/intel/cri-resource-manager/pkg/log"
 	"github.com/intel/cri-resource-manager/pkg/utils"
 	idset "github.com/intel/goresctrl/pkg/utils"
@@ -70,6 +74,12 @@ type balloons struct {
 	balloons           []*Balloon  // balloon instances: reserved, default and user-defined
 
 	cpuAllocator cpuallocator.CPUAllocator // CPU allocator used by the policy
+
+	migrations      int       // containers migrated during the current migration interval
+	migrationWindow time.Time // start of the current migration interval
+
+	fillMethods       map[string]FillMethod // fill methods used for assigning containers, by cache ID
+	pressureMilliCpus map[string]int        // extra mCPUs granted on CPU pressure, by cache ID
 }
 
 // Balloon contains attributes of a balloon instance
@@ -139,13 +149,43 @@ func (bln Balloon) MaxAvailMilliCpus(freeCpus cpuset.CPUSet) int {
 
 // CreateBalloonsPolicy creates a new policy instance.
 func CreateBalloonsPolicy(policyOptions *policy.BackendOptions) policy.Backend {
+	log.Info("creating %s policy...", PolicyName)
+	p, err := newBalloons(policyOptions)
+	if err != nil {
+		log.Fatal("%v", err)
+	}
+	// Handle policy-specific options
+	log.Debug("creating %s configuration", PolicyName)
+	if err := p.setConfig(balloonsOptions); err != nil {
+		log.Fatal("failed to create %s policy: %v", PolicyName, err)
+	}
+	log.Debug("first effective configuration:\n%s\n", utils.DumpJSON(p.bpoptions))
+	pkgcfg.GetModule(PolicyPath).AddNotify(p.configNotify)
+
+	return p
+}
+
+// validateBalloonsConfig checks the current balloons configuration against
+// the available CPUs, without creating a policy instance.
+func validateBalloonsConfig(policyOptions *policy.BackendOptions) error {
+	p, err := newBalloons(policyOptions)
+	if err != nil {
+		return err
+	}
+	return p.createBalloons(balloonsOptions)
+}
+
+// newBalloons creates a balloons instance with the allowed and reserved CPUs set up.
+func newBalloons(policyOptions *policy.BackendOptions) (*balloons, error) {
 	var err error
 	p := &balloons{
 		options:      policyOptions,
 		cch:          policyOptions.Cache,
 		cpuAllocator: cpuallocator.NewCPUAllocator(policyOptions.System),
+		fillMethods:  make(map[string]FillMethod),
+
+		pressureMilliCpus: make(map[string]int),
 	}
-	log.Info("creating %s policy...", PolicyName)
 	if p.cpuTree, err = NewCpuTreeFromSystem(); err != nil {
 		log.Errorf("creating CPU topology tree failed: %s", err)
 	}
@@ -168,24 +208,16 @@ func CreateBalloonsPolicy(policyOptions *policy.BackendOptions) policy.Backend {
 			reserveCnt := (int(v.MilliValue()) + 999) / 1000
 			cpus, err := p.cpuAllocator.AllocateCpus(&p.allowed, reserveCnt, cpuallocator.PriorityNone)
 			if err != nil {
-				log.Fatal("failed to allocate reserved CPUs: %s", err)
+				return nil, balloonsError("failed to allocate reserved CPUs: %s", err)
 			}
 			p.reserved = cpus
 			p.allowed = p.allowed.Union(cpus)
 		}
 	}
 	if p.reserved.IsEmpty() {
-		log.Fatal("%s cannot run without reserved CPUs that are also AvailableResources", PolicyName)
+		return nil, balloonsError("%s cannot run without reserved CPUs that are also AvailableResources", PolicyName)
 	}
-	// Handle policy-specific options
-	log.Debug("creating %s configuration", PolicyName)
-	if err := p.setConfig(balloonsOptions); err != nil {
-		log.Fatal("failed to create %s policy: %v", PolicyName, err)
-	}
-	log.Debug("first effective configuration:\n%s\n", utils.DumpJSON(p.bpoptions))
-	pkgcfg.GetModule(PolicyPath).AddNotify(p.configNotify)
-
-	return p
+	return p, nil
 }
 
 // Name returns the name of this policy.
@@ -252,6 +284,8 @@ func (p *balloons) AllocateResources(c cache.Container) error {
 // ReleaseResources is a resource release request for this policy.
 func (p *balloons) ReleaseResources(c cache.Container) error {
 	log.Debug("releasing container %s...", c.PrettyName())
+	delete(p.fillMethods, c.GetCacheID())
+	delete(p.pressureMilliCpus, c.GetCacheID())
 	if bln := p.balloonByContainer(c); bln != nil {
 		p.dismissContainer(c, bln)
 		if log.DebugEnabled() {
@@ -282,24 +316,211 @@ func (p *balloons) UpdateResources(c cache.Container) error {
 
 // Rebalance tries to find an optimal allocation of resources for the current containers.
 func (p *balloons) Rebalance() (bool, error) {
-	log.Debug("(not) rebalancing containers...")
-	return false, nil
+	budget := p.migrationBudget()
+	if budget <= 0 {
+		log.Debug("(not) rebalancing containers, no migration budget left...")
+		return false, nil
+	}
+	log.Debug("rebalancing containers, migration budget %d...", budget)
+	migrated := 0
+	defer func() { p.migrations += migrated }()
+	for _, blnDef := range p.bpoptions.BalloonDefs {
+		for migrated < budget {
+			count, err := p.rebalanceBalloonDef(blnDef, budget-migrated)
+			if err != nil {
+				return migrated > 0, balloonsError("rebalancing %s balloons failed: %w", blnDef.Name, err)
+			}
+			if count == 0 {
+				break
+			}
+			migrated += count
+		}
+	}
+	return migrated > 0, nil
 }
 
 // HandleEvent handles policy-specific events.
-func (p *balloons) HandleEvent(*events.Policy) (bool, error) {
-	log.Debug("(not) handling event...")
+func (p *balloons) HandleEvent(e *events.Policy) (bool, error) {
+	switch e.Type {
+	case events.ContainerPressure:
+		pe, ok := e.Data.(*events.Pressure)
+		if !ok {
+			return false, balloonsError("%s event: expecting *events.Pressure Data, got %T",
+				e.Type, e.Data)
+		}
+		return p.handlePressure(pe)
+	}
+	log.Debug("(not) handling event %s.%s...", e.Source, e.Type)
 	return false, nil
 }
 
+// handlePressure inflates the balloon of a container under CPU pressure
+// by one CPU. The extra CPU is accounted to the container: it is kept when
+// the balloon is resized and given back when the container is released or
+// migrated to another balloon. Memory pressure is not handled, since memory
+// of balloons follows their CPUs.
+func (p *balloons) handlePressure(e *events.Pressure) (bool, error) {
+	if e.Resource != pressure.CPU {
+		log.Warn("pressure: rejecting %s pressure of %s, only CPU pressure is handled",
+			e.Resource, e.ContainerID)
+		return false, nil
+	}
+	c, ok := p.cch.LookupContainer(e.ContainerID)
+	if !ok {
+		log.Debug("pressure: container %s is gone, ignoring event", e.ContainerID)
+		return false, nil
+	}
+	bln := p.balloonByContainer(c)
+	if bln == nil {
+		log.Debug("pressure: no balloon found for %s", c.PrettyName())
+		return false, nil
+	}
+	if bln.Def.MaxCpus > NoLimit && bln.Cpus.Size() >= bln.Def.MaxCpus {
+		log.Infof("pressure: %s under CPU pressure, but %s is already at max CPUs",
+			c.PrettyName(), bln)
+		return false, nil
+	}
+	if p.freeCpus.IsEmpty() {
+		log.Infof("pressure: %s under CPU pressure, but no free CPUs to inflate %s",
+			c.PrettyName(), bln)
+		return false, nil
+	}
+	if bln.Cpus.Equals(p.reserved) {
+		log.Infof("pressure: %s under CPU pressure, but %s uses fixed CPUs",
+			c.PrettyName(), bln)
+		return false, nil
+	}
+	log.Infof("pressure: %s under CPU pressure (%.2f%%), inflating %s",
+		c.PrettyName(), e.Avg10, bln)
+	cID := c.GetCacheID()
+	extra := 1000*(bln.Cpus.Size()+1) - p.requestedMilliCpus(bln)
+	p.pressureMilliCpus[cID] += extra
+	if err := p.resizeBalloon(bln, p.requestedMilliCpus(bln)); err != nil {
+		if p.pressureMilliCpus[cID] -= extra; p.pressureMilliCpus[cID] == 0 {
+			delete(p.pressureMilliCpus, cID)
+		}
+		return false, balloonsError("pressure: failed to inflate %s: %w", bln, err)
+	}
+	return true, nil
+}
+
 // ExportResourceData provides resource data to export for the container.
 func (p *balloons) ExportResourceData(c cache.Container) map[string]string {
 	return nil
 }
 
 // Introspect provides data for external introspection.
-func (p *balloons) Introspect(*introspect.State) {
-	return
+func (p *balloons) Introspect(state *introspect.State) {
+	pools := make(map[string]*introspect.Pool, len(p.balloons))
+	assignments := make(map[string]*introspect.Assignment)
+	for _, bln := range p.balloons {
+		pool := &introspect.Pool{
+			Name:   bln.PrettyName(),
+			CPUs:   bln.Cpus.String(),
+			Memory: bln.Mems.String(),
+			Extra: map[string]string{
+				"balloon-type":     bln.Def.Name,
+				"shared-idle-cpus": bln.SharedIdleCpus.String(),
+			},
+		}
+		for _, cID := range bln.ContainerIDs() {
+			c, ok := p.cch.LookupContainer(cID)
+			if !ok {
+				continue
+			}
+			pool.Containers = append(pool.Containers, c.GetID())
+			a := &introspect.Assignment{
+				ContainerID: c.GetID(),
+				SharedCPUs:  c.GetCpusetCpus(),
+				CPUShare:    p.containerRequestedMilliCpus(cID),
+				Memory:      c.GetCpusetMems(),
+				Pool:        pool.Name,
+			}
+			if fm, ok := p.fillMethods[cID]; ok {
+				a.Extra = map[string]string{"fill-method": fm.String()}
+			}
+			assignments[a.ContainerID] = a
+		}
+		sort.Strings(pool.Containers)
+		pools[pool.Name] = pool
+	}
+	state.Pools = pools
+	state.Assignments = assignments
+	state.Reserved = p.reserved.String()
+}
+
+// checkpoint is a checkpoint of the balloons and their containers.
+type checkpoint struct {
+	freeCpus          cpuset.CPUSet
+	balloons          []*Balloon
+	migrations        int
+	migrationWindow   time.Time
+	fillMethods       map[string]FillMethod
+	pressureMilliCpus map[string]int
+}
+
+// Checkpoint takes a checkpoint of the current balloons and their containers.
+func (p *balloons) Checkpoint() (policyapi.Checkpoint, error) {
+	cp := &checkpoint{
+		freeCpus:          p.freeCpus.Clone(),
+		balloons:          cloneBalloons(p.balloons),
+		migrations:        p.migrations,
+		migrationWindow:   p.migrationWindow,
+		fillMethods:       make(map[string]FillMethod, len(p.fillMethods)),
+		pressureMilliCpus: make(map[string]int, len(p.pressureMilliCpus)),
+	}
+	for cID, fm := range p.fillMethods {
+		cp.fillMethods[cID] = fm
+	}
+	for cID, extra := range p.pressureMilliCpus {
+		cp.pressureMilliCpus[cID] = extra
+	}
+	return cp, nil
+}
+
+// Restore restores the balloons and their containers from a checkpoint.
+// CPUs of the restored balloons are reconfigured according to their CPU
+// classes.
+func (p *balloons) Restore(cp policyapi.Checkpoint) error {
+	c, ok := cp.(*checkpoint)
+	if !ok {
+		return balloonsError("invalid checkpoint of type %T", cp)
+	}
+	log.Info("restoring balloons from checkpoint...")
+	p.freeCpus = c.freeCpus.Clone()
+	p.balloons = cloneBalloons(c.balloons)
+	p.migrations = c.migrations
+	p.migrationWindow = c.migrationWindow
+	p.fillMethods = make(map[string]FillMethod, len(c.fillMethods))
+	for cID, fm := range c.fillMethods {
+		p.fillMethods[cID] = fm
+	}
+	p.pressureMilliCpus = make(map[string]int, len(c.pressureMilliCpus))
+	for cID, extra := range c.pressureMilliCpus {
+		p.pressureMilliCpus[cID] = extra
+	}
+	p.resetCpuClass()
+	for _, bln := range p.balloons {
+		p.useCpuClass(bln)
+	}
+	return nil
+}
+
+// cloneBalloons returns a copy of the given balloons and their containers.
+func cloneBalloons(blns []*Balloon) []*Balloon {
+	clones := make([]*Balloon, 0, len(blns))
+	for _, bln := range blns {
+		clone := *bln
+		clone.Cpus = bln.Cpus.Clone()
+		clone.Mems = bln.Mems.Clone()
+		clone.SharedIdleCpus = bln.SharedIdleCpus.Clone()
+		clone.PodIDs = make(map[string][]string, len(bln.PodIDs))
+		for podID, ctrIDs := range bln.PodIDs {
+			clone.PodIDs[podID] = append([]string(nil), ctrIDs...)
+		}
+		clones = append(clones, &clone)
+	}
+	return clones
 }
 
 // balloonByContainer returns a balloon that contains a container.
@@ -378,6 +599,10 @@ func (p *balloons) balloonDefByName(defName string) *BalloonDef {
 	return nil
 }
 
+// chooseBalloonDef returns the balloon definition for a container.
+// The definition is chosen, in order of precedence, by the balloon
+// annotation, reserved namespaces, matching expressions and namespaces
+// of the balloon definitions, falling back to the default balloon.
 func (p *balloons) chooseBalloonDef(c cache.Container) (*BalloonDef, error) {
 	var blnDef *BalloonDef
 	// BalloonDef is defined by annotation?
@@ -395,6 +620,13 @@ func (p *balloons) chooseBalloonDef(c cache.Container) (*BalloonDef, error) {
 		return p.balloons[0].Def, nil
 	}
 
+	// BalloonDef is defined by matching expressions.
+	for _, blnDef := range p.bpoptions.BalloonDefs {
+		if expressionsMatch(blnDef.MatchExpressions, c) {
+			return blnDef, nil
+		}
+	}
+
 	// BalloonDef is defined by the namespace.
 	for _, blnDef := range append([]*BalloonDef{p.reservedBalloonDef, p.defaultBalloonDef}, p.bpoptions.BalloonDefs...) {
 		if namespaceMatches(c.GetNamespace(), blnDef.Namespaces) {
@@ -431,11 +663,12 @@ func (p *balloons) containerLimitedMilliCpus(contID string) int {
 }
 
 // requestedMilliCpus sums up and returns CPU requests of all
-// containers assigned to a balloon.
+// containers assigned to a balloon, including extra CPU granted
+// to them on CPU pressure.
 func (p *balloons) requestedMilliCpus(bln *Balloon) int {
 	cpuRequested := 0
 	for _, cID := range bln.ContainerIDs() {
-		cpuRequested += p.containerRequestedMilliCpus(cID)
+		cpuRequested += p.containerRequestedMilliCpus(cID) + p.pressureMilliCpus[cID]
 	}
 	return cpuRequested
 }
@@ -744,6 +977,20 @@ func namespaceMatches(namespace string, patterns []string) bool {
 	return false
 }
 
+// expressionsMatch returns true if expressions are given and all of
+// them evaluate true for the subject.
+func expressionsMatch(exprs []*resmgr.Expression, subject resmgr.Evaluable) bool {
+	if len(exprs) == 0 {
+		return false
+	}
+	for _, expr := range exprs {
+		if !expr.Evaluate(subject) {
+			return false
+		}
+	}
+	return true
+}
+
 // allocateBalloon returns a balloon allocated for a container.
 func (p *balloons) allocateBalloon(c cache.Container) (*Balloon, error) {
 	blnDef, err := p.chooseBalloonDef(c)
@@ -797,6 +1044,7 @@ func (p *balloons) allocateBalloonOfDef(blnDef *BalloonDef, c cache.Container) (
 			continue
 		}
 		log.Debugf("fill method %q suggests balloon instance %v", fillMethod, bln)
+		p.fillMethods[c.GetCacheID()] = fillMethod
 		return bln, nil
 	}
 	return nil, nil
@@ -1016,6 +1264,12 @@ func (p *balloons) validateConfig(bpoptions *BalloonsOptions) error {
 			return balloonsError("MinBalloons (%d) > MaxBalloons (%d) in balloon type %q",
 				blnDef.MinCpus, blnDef.MaxCpus, blnDef.Name)
 		}
+		for _, expr := range blnDef.MatchExpressions {
+			if err := expr.Validate(); err != nil {
+				return balloonsError("invalid MatchExpressions in balloon type %q: %w",
+					blnDef.Name, err)
+			}
+		}
 	}
 	return nil
 }
@@ -1025,6 +1279,29 @@ func (p *balloons) setConfig(bpoptions *BalloonsOptions) error {
 	// TODO: revert allocations (p.freeCpus) to old ones if the
 	// configuration is invalid. Currently bad configuration
 	// leaves a mess in bookkeeping.
+	if err := p.createBalloons(bpoptions); err != nil {
+		return err
+	}
+	// Finish balloon instance initialization.
+	log.Info("%s policy balloons:", PolicyName)
+	for blnIdx, bln := range p.balloons {
+		log.Info("- balloon %d: %s", blnIdx, bln)
+	}
+	// No errors in balloon creation, take new configuration into use.
+	p.bpoptions = *bpoptions
+	p.updatePinning(p.shareIdleCpus(p.freeCpus, cpuset.NewCPUSet())...)
+	// (Re)configures all CPUs in balloons.
+	p.resetCpuClass()
+	for _, bln := range p.balloons {
+		p.useCpuClass(bln)
+	}
+	return nil
+}
+
+// createBalloons validates balloon configuration and creates the
+// built-in and the minimum number of user-defined balloons for it.
+// It does not alter the system or the placement of any containers.
+func (p *balloons) createBalloons(bpoptions *BalloonsOptions) error {
 	if err := p.validateConfig(bpoptions); err != nil {
 		return balloonsError("invalid configuration: %w", err)
 	}
@@ -1079,19 +1356,6 @@ func (p *balloons) setConfig(bpoptions *BalloonsOptions) error {
 			return err
 		}
 	}
-	// Finish balloon instance initialization.
-	log.Info("%s policy balloons:", PolicyName)
-	for blnIdx, bln := range p.balloons {
-		log.Info("- balloon %d: %s", blnIdx, bln)
-	}
-	// No errors in balloon creation, take new configuration into use.
-	p.bpoptions = *bpoptions
-	p.updatePinning(p.shareIdleCpus(p.freeCpus, cpuset.NewCPUSet())...)
-	// (Re)configures all CPUs in balloons.
-	p.resetCpuClass()
-	for _, bln := range p.balloons {
-		p.useCpuClass(bln)
-	}
 	return nil
 }
 
@@ -1252,6 +1516,222 @@ func (p *balloons) shareIdleCpus(addCpus, removeCpus cpuset.CPUSet) []*Balloon {
 	return updatedBalloons
 }
 
+// migrationBudget returns the number of containers that can still be
+// migrated between balloons during the current migration interval.
+func (p *balloons) migrationBudget() int {
+	budget := p.bpoptions.RebalanceMigrationBudget
+	if budget <= 0 {
+		return 0
+	}
+	now := time.Now()
+	interval := time.Duration(p.bpoptions.RebalanceMigrationInterval)
+	if interval <= 0 || now.Sub(p.migrationWindow) >= interval {
+		p.migrationWindow = now
+		p.migrations = 0
+	}
+	return max(0, budget-p.migrations)
+}
+
+// rebalanceBalloonDef migrates containers from the most loaded balloon
+// of a balloon type to a less loaded one, if this reduces the load of
+// the most loaded balloon. Returns the number of migrated containers.
+func (p *balloons) rebalanceBalloonDef(blnDef *BalloonDef, budget int) (int, error) {
+	blns := p.balloonsByDef(blnDef)
+	if len(blns) < 2 {
+		return 0, nil
+	}
+	srcIdx, srcMilliCpus := largest(len(blns), func(i int) int {
+		return p.requestedMilliCpus(blns[i])
+	})
+	src := blns[srcIdx]
+	for _, ctrs := range p.migrationCandidates(src) {
+		if len(ctrs) > budget {
+			continue
+		}
+		reqMilliCpus := 0
+		for _, c := range ctrs {
+			reqMilliCpus += p.containerRequestedMilliCpus(c.GetCacheID())
+		}
+		if reqMilliCpus == 0 {
+			continue
+		}
+		dst := p.migrationTarget(blns, src, ctrs, srcMilliCpus, reqMilliCpus)
+		if dst == nil {
+			continue
+		}
+		if err := p.migrateContainers(ctrs, src, dst); err != nil {
+			return 0, err
+		}
+		return len(ctrs), nil
+	}
+	return 0, nil
+}
+
+// migrationCandidates returns the groups of containers in a balloon
+// that can be migrated to another balloon, largest groups first.
+// Containers of the same pod are migrated together unless the
+// balloon type prefers spreading pods.
+func (p *balloons) migrationCandidates(bln *Balloon) [][]cache.Container {
+	podIDs := make([]string, 0, len(bln.PodIDs))
+	for podID := range bln.PodIDs {
+		podIDs = append(podIDs, podID)
+	}
+	sort.Strings(podIDs)
+
+	groups := [][]cache.Container{}
+	for _, podID := range podIDs {
+		ctrIDs := append([]string{}, bln.PodIDs[podID]...)
+		sort.Strings(ctrIDs)
+		group := []cache.Container{}
+		for _, cID := range ctrIDs {
+			c, ok := p.cch.LookupContainer(cID)
+			if !ok {
+				continue
+			}
+			if bln.Def.PreferSpreadingPods {
+				groups = append(groups, []cache.Container{c})
+			} else {
+				group = append(group, c)
+			}
+		}
+		if len(group) > 0 {
+			groups = append(groups, group)
+		}
+	}
+
+	milliCpus := func(ctrs []cache.Container) int {
+		sum := 0
+		for _, c := range ctrs {
+			sum += p.containerRequestedMilliCpus(c.GetCacheID())
+		}
+		return sum
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return milliCpus(groups[i]) > milliCpus(groups[j])
+	})
+	return groups
+}
+
+// migrationTarget returns the least loaded balloon where containers
+// can be migrated from the source balloon without making the target
+// balloon as loaded as the source balloon was.
+func (p *balloons) migrationTarget(blns []*Balloon, src *Balloon, ctrs []cache.Container, srcMilliCpus, reqMilliCpus int) *Balloon {
+	var (
+		target          *Balloon
+		targetMilliCpus int
+	)
+	for _, bln := range blns {
+		if bln == src {
+			continue
+		}
+		dstMilliCpus := p.requestedMilliCpus(bln)
+		if dstMilliCpus+reqMilliCpus >= srcMilliCpus {
+			continue
+		}
+		if p.maxFreeMilliCpus(bln) < reqMilliCpus {
+			continue
+		}
+		if !p.acceptsMigration(bln, ctrs) {
+			continue
+		}
+		if target == nil || dstMilliCpus < targetMilliCpus {
+			target, targetMilliCpus = bln, dstMilliCpus
+		}
+	}
+	return target
+}
+
+// acceptsMigration returns true if migrating containers to a balloon
+// respects the pod and namespace preferences of the balloon type.
+func (p *balloons) acceptsMigration(bln *Balloon, ctrs []cache.Container) bool {
+	for _, c := range ctrs {
+		if bln.Def.PreferSpreadingPods {
+			if _, ok := bln.PodIDs[c.GetPodID()]; ok {
+				return false
+			}
+		}
+		if bln.Def.PreferPerNamespaceBalloon {
+			for podID := range bln.PodIDs {
+				pod, ok := p.cch.LookupPod(podID)
+				if ok && pod.GetNamespace() != c.GetNamespace() {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// migrateContainers moves containers from a balloon to another one.
+// If the memory nodes of the balloons differ, the memory of the
+// containers is migrated as well.
+func (p *balloons) migrateContainers(ctrs []cache.Container, src, dst *Balloon) error {
+	srcMems := src.Mems.Clone()
+	srcMilliCpus := p.requestedMilliCpus(src)
+	reqMilliCpus := p.requestedMilliCpus(dst)
+	for _, c := range ctrs {
+		reqMilliCpus += p.containerRequestedMilliCpus(c.GetCacheID())
+		p.dismissContainer(c, src)
+	}
+	// rollback puts the containers back to the source balloon.
+	rollback := func() {
+		for _, c := range ctrs {
+			p.assignContainer(c, src)
+		}
+	}
+	// Deflate the source balloon first to make its released CPUs
+	// available for inflating the target balloon.
+	if err := p.resizeBalloon(src, max(1, p.requestedMilliCpus(src))); err != nil {
+		log.Errorf("failed to deflate balloon %s for migration, keeping containers in it: %v", src, err)
+		rollback()
+		return balloonsError("failed to migrate containers from %s to %s: %w", src, dst, err)
+	}
+	if dst.AvailMilliCpus() < max(1, reqMilliCpus) {
+		if err := p.resizeBalloon(dst, max(1, reqMilliCpus)); err != nil {
+			if err := p.resizeBalloon(src, max(1, srcMilliCpus)); err != nil {
+				log.Errorf("failed to restore the size of balloon %s: %v", src, err)
+			}
+			rollback()
+			return balloonsError("failed to migrate containers from %s to %s: %w", src, dst, err)
+		}
+	}
+	for _, c := range ctrs {
+		log.Info("migrating container %s from balloon %s to %s", c.PrettyName(), src.PrettyName(), dst.PrettyName())
+		delete(p.fillMethods, c.GetCacheID())
+		delete(p.pressureMilliCpus, c.GetCacheID())
+		p.assignContainer(c, dst)
+		p.migratePages(c, srcMems, dst.Mems)
+	}
+	if src.ContainerCount() == 0 {
+		p.resizeBalloon(src, 0)
+		log.Debug("all containers migrated, free balloon allocation %s", src.PrettyName())
+		p.freeBalloon(src)
+	}
+	return nil
+}
+
+// migratePages requests migrating the memory of a container from
+// memory nodes it is no longer pinned to.
+func (p *balloons) migratePages(c cache.Container, oldMems, newMems idset.IDSet) {
+	if p.bpoptions.PinMemory != nil && !*p.bpoptions.PinMemory {
+		return
+	}
+	srcNodes := idset.NewIDSet()
+	for _, id := range oldMems.Members() {
+		if !newMems.Has(id) {
+			srcNodes.Add(id)
+		}
+	}
+	if srcNodes.Size() == 0 || newMems.Size() == 0 {
+		return
+	}
+	log.Info("  - migrating memory of %s from nodes %s to %s", c.PrettyName(), srcNodes, newMems)
+	c.SetPageMigration(&cache.PageMigrate{
+		SourceNodes: srcNodes,
+		TargetNodes: newMems.Clone(),
+	})
+}
+
 // assignContainer adds a container to a balloon
 func (p *balloons) assignContainer(c cache.Container, bln *Balloon) {
 	log.Info("assigning container %s to balloon %s", c.PrettyName(), bln)
@@ -1313,4 +1793,5 @@ func max(a, b int) int {
 // Register us as a policy implementation.
 func init() {
 	policy.Register(PolicyName, PolicyDescription, CreateBalloonsPolicy)
+	policy.RegisterValidator(PolicyName, validateBalloonsConfig)
 }
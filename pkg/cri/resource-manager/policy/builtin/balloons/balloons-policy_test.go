@@ -15,9 +15,34 @@
 package balloons
 
 import (
+	"fmt"
+	"reflect"
 	"testing"
+	"time"
+
+	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
+
+	"github.com/intel/cri-resource-manager/pkg/apis/resmgr"
+	pkgcfg "github.com/intel/cri-resource-manager/pkg/config"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/cache"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/events"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/introspect"
+	policyapi "github.com/intel/cri-resource-manager/pkg/cri/resource-manager/policy"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/pressure"
+	system "github.com/intel/cri-resource-manager/pkg/sysfs"
+	"github.com/intel/cri-resource-manager/pkg/testutils"
 )
 
+type evaluable map[string]interface{}
+
+func (e evaluable) Eval(key string) interface{} {
+	if value, ok := e[key]; ok {
+		return value
+	}
+	return fmt.Errorf("evaluable: cannot evaluate %q", key)
+}
+
 func TestChangesBalloons(t *testing.T) {
 	tcases := []struct {
 		name          string
@@ -94,3 +119,774 @@ func TestChangesBalloons(t *testing.T) {
 		})
 	}
 }
+
+func TestMigrationBudget(t *testing.T) {
+	tcases := []struct {
+		name           string
+		budget         int
+		interval       time.Duration
+		migrations     int
+		windowAge      time.Duration
+		expectedBudget int
+	}{
+		{
+			name:           "migration disabled",
+			budget:         0,
+			expectedBudget: 0,
+		},
+		{
+			name:           "budget per rebalancing pass",
+			budget:         2,
+			migrations:     2,
+			expectedBudget: 2,
+		},
+		{
+			name:           "budget partially used in interval",
+			budget:         3,
+			interval:       time.Minute,
+			migrations:     1,
+			windowAge:      time.Second,
+			expectedBudget: 2,
+		},
+		{
+			name:           "budget exhausted in interval",
+			budget:         3,
+			interval:       time.Minute,
+			migrations:     3,
+			windowAge:      time.Second,
+			expectedBudget: 0,
+		},
+		{
+			name:           "budget renewed after interval",
+			budget:         3,
+			interval:       time.Minute,
+			migrations:     3,
+			windowAge:      2 * time.Minute,
+			expectedBudget: 3,
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &balloons{
+				bpoptions: BalloonsOptions{
+					RebalanceMigrationBudget:   tc.budget,
+					RebalanceMigrationInterval: pkgcfg.Duration(tc.interval),
+				},
+				migrations:      tc.migrations,
+				migrationWindow: time.Now().Add(-tc.windowAge),
+			}
+			if budget := p.migrationBudget(); budget != tc.expectedBudget {
+				t.Errorf("Expected migration budget %d but got %d", tc.expectedBudget, budget)
+			}
+		})
+	}
+}
+
+func TestExpressionsMatch(t *testing.T) {
+	ctr := evaluable{
+		resmgr.KeyName:     "ctr",
+		resmgr.KeyImage:    "registry.example.com/db:1.0",
+		resmgr.KeyQOSClass: "Guaranteed",
+		resmgr.KeyLabels:   map[string]string{"tier": "db"},
+		resmgr.KeyPod:      evaluable{resmgr.KeyName: "db-0"},
+	}
+	tcases := []struct {
+		name          string
+		exprs         []*resmgr.Expression
+		expectedValue bool
+	}{
+		{
+			name:          "no expressions",
+			expectedValue: false,
+		},
+		{
+			name: "matching label",
+			exprs: []*resmgr.Expression{
+				{Key: "labels/tier", Op: resmgr.Equals, Values: []string{"db"}},
+			},
+			expectedValue: true,
+		},
+		{
+			name: "all expressions match",
+			exprs: []*resmgr.Expression{
+				{Key: resmgr.KeyImage, Op: resmgr.Matches, Values: []string{"*/db:*"}},
+				{Key: resmgr.KeyQOSClass, Op: resmgr.In, Values: []string{"Guaranteed", "Burstable"}},
+				{Key: "pod/name", Op: resmgr.Matches, Values: []string{"db-*"}},
+			},
+			expectedValue: true,
+		},
+		{
+			name: "one expression does not match",
+			exprs: []*resmgr.Expression{
+				{Key: "labels/tier", Op: resmgr.Equals, Values: []string{"db"}},
+				{Key: "tags/pinned", Op: resmgr.Exists},
+			},
+			expectedValue: false,
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			value := expressionsMatch(tc.exprs, ctr)
+			if value != tc.expectedValue {
+				t.Errorf("Expected return value %v but got %v", tc.expectedValue, value)
+			}
+		})
+	}
+}
+
+func TestChooseBalloonDef(t *testing.T) {
+	p := newTestBalloons(t, 8, &BalloonsOptions{
+		ReservedPoolNamespaces: []string{"monitoring"},
+		BalloonDefs: []*BalloonDef{
+			{
+				Name: "db",
+				MatchExpressions: []*resmgr.Expression{
+					{Key: "labels/tier", Op: resmgr.Equals, Values: []string{"db"}},
+				},
+			},
+			{
+				Name: "web",
+				MatchExpressions: []*resmgr.Expression{
+					{Key: resmgr.KeyImage, Op: resmgr.Matches, Values: []string{"*/nginx:*"}},
+					{Key: "pod/name", Op: resmgr.Matches, Values: []string{"web-*"}},
+				},
+			},
+			{
+				Name:       "batch",
+				Namespaces: []string{"batch"},
+			},
+		},
+	})
+
+	tcases := []struct {
+		name            string
+		namespace       string
+		podName         string
+		image           string
+		labels          map[string]string
+		annotations     map[string]string
+		expectedBalloon string
+		expectedError   bool
+	}{
+		{
+			name:            "matching label",
+			labels:          map[string]string{"tier": "db"},
+			expectedBalloon: "db",
+		},
+		{
+			name:            "all expressions match",
+			podName:         "web-0",
+			image:           "registry.example.com/nginx:1.23",
+			expectedBalloon: "web",
+		},
+		{
+			name:            "one expression does not match",
+			podName:         "api-0",
+			image:           "registry.example.com/nginx:1.23",
+			expectedBalloon: "default",
+		},
+		{
+			name:            "first matching balloon type wins",
+			podName:         "web-0",
+			image:           "registry.example.com/nginx:1.23",
+			labels:          map[string]string{"tier": "db"},
+			expectedBalloon: "db",
+		},
+		{
+			name:            "expressions take precedence over namespace",
+			namespace:       "batch",
+			labels:          map[string]string{"tier": "db"},
+			expectedBalloon: "db",
+		},
+		{
+			name:            "namespace used without matching expressions",
+			namespace:       "batch",
+			labels:          map[string]string{"tier": "frontend"},
+			expectedBalloon: "batch",
+		},
+		{
+			name:            "kube-system takes precedence over expressions",
+			namespace:       "kube-system",
+			labels:          map[string]string{"tier": "db"},
+			expectedBalloon: "reserved",
+		},
+		{
+			name:            "reserved pool namespace takes precedence over expressions",
+			namespace:       "monitoring",
+			labels:          map[string]string{"tier": "db"},
+			expectedBalloon: "reserved",
+		},
+		{
+			name:            "pod annotation takes precedence over expressions",
+			labels:          map[string]string{"tier": "db"},
+			annotations:     map[string]string{balloonKey + "/pod": "batch"},
+			expectedBalloon: "batch",
+		},
+		{
+			name:   "container annotation takes precedence over pod annotation",
+			labels: map[string]string{"tier": "db"},
+			annotations: map[string]string{
+				balloonKey + "/pod":         "batch",
+				balloonKey + "/container.c": "web",
+			},
+			expectedBalloon: "web",
+		},
+		{
+			name:          "annotation of unknown balloon type",
+			labels:        map[string]string{"tier": "db"},
+			annotations:   map[string]string{balloonKey: "nonexistent"},
+			expectedError: true,
+		},
+	}
+	for i, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			namespace := tc.namespace
+			if namespace == "" {
+				namespace = "default"
+			}
+			podName := tc.podName
+			if podName == "" {
+				podName = "pod"
+			}
+			podID := fmt.Sprintf("pod%d", i)
+			podCfg := &criv1.PodSandboxConfig{
+				Metadata:    &criv1.PodSandboxMetadata{Name: podName, Uid: podID, Namespace: namespace},
+				Annotations: tc.annotations,
+			}
+			if _, err := p.cch.InsertPod(podID, &criv1.RunPodSandboxRequest{Config: podCfg}, nil); err != nil {
+				t.Fatalf("failed to create pod: %v", err)
+			}
+			c, err := p.cch.InsertContainer(&criv1.CreateContainerRequest{
+				PodSandboxId: podID,
+				Config: &criv1.ContainerConfig{
+					Metadata: &criv1.ContainerMetadata{Name: "c"},
+					Image:    &criv1.ImageSpec{Image: tc.image},
+					Labels:   tc.labels,
+					Linux:    &criv1.LinuxContainerConfig{Resources: &criv1.LinuxContainerResources{}},
+				},
+				SandboxConfig: podCfg,
+			})
+			if err != nil {
+				t.Fatalf("failed to create container: %v", err)
+			}
+
+			blnDef, err := p.chooseBalloonDef(c)
+			if tc.expectedError {
+				if err == nil {
+					t.Errorf("expected error, got balloon type %s", blnDef.Name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if blnDef.Name != tc.expectedBalloon {
+				t.Errorf("expected balloon type %s, got %s", tc.expectedBalloon, blnDef.Name)
+			}
+		})
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	tcases := []struct {
+		name        string
+		blnDef      *BalloonDef
+		expectedErr bool
+	}{
+		{
+			name: "valid match expressions",
+			blnDef: &BalloonDef{
+				Name: "db",
+				MatchExpressions: []*resmgr.Expression{
+					{Key: "labels/tier", Op: resmgr.Equals, Values: []string{"db"}},
+				},
+			},
+		},
+		{
+			name: "invalid match expression",
+			blnDef: &BalloonDef{
+				Name: "db",
+				MatchExpressions: []*resmgr.Expression{
+					{Key: "labels/tier", Op: resmgr.Equals, Values: []string{"db", "cache"}},
+				},
+			},
+			expectedErr: true,
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &balloons{}
+			err := p.validateConfig(&BalloonsOptions{BalloonDefs: []*BalloonDef{tc.blnDef}})
+			if (err != nil) != tc.expectedErr {
+				t.Errorf("Expected error %v but got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+// newTestBalloons creates a balloons policy on a fake system with CPU 0 reserved.
+func newTestBalloons(t *testing.T, cpus int, bpoptions *BalloonsOptions) *balloons {
+	savedSysRoot := system.SysRoot()
+	t.Cleanup(func() { system.SetSysRoot(savedSysRoot) })
+	system.SetSysRoot(testutils.CreateFakeSysfs(t, cpus))
+
+	sys, err := system.DiscoverSystem()
+	if err != nil {
+		t.Fatalf("failed to discover fake system: %v", err)
+	}
+	cch, err := cache.NewCache(cache.Options{CacheDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	p, err := newBalloons(&policyapi.BackendOptions{
+		System: sys,
+		Cache:  cch,
+		Reserved: policyapi.ConstraintSet{
+			policyapi.DomainCPU: cpuset.NewCPUSet(0),
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create balloons policy: %v", err)
+	}
+	if err := p.setConfig(bpoptions); err != nil {
+		t.Fatalf("failed to configure balloons policy: %v", err)
+	}
+	return p
+}
+
+// createTestContainer adds a container requesting milliCPU to a (new) pod in the cache.
+// The runtime ID of the container is <podName>-<name>.
+func createTestContainer(t *testing.T, cch cache.Cache, podName, name, namespace string, milliCPU int) cache.Container {
+	podCfg := &criv1.PodSandboxConfig{
+		Metadata: &criv1.PodSandboxMetadata{Name: podName, Uid: podName, Namespace: namespace},
+		Linux:    &criv1.LinuxPodSandboxConfig{CgroupParent: "/kubepods.slice/kubepods-burstable.slice/" + podName},
+	}
+	if _, ok := cch.LookupPod(podName); !ok {
+		if _, err := cch.InsertPod(podName, &criv1.RunPodSandboxRequest{Config: podCfg}, nil); err != nil {
+			t.Fatalf("failed to create pod: %v", err)
+		}
+	}
+	c, err := cch.InsertContainer(&criv1.CreateContainerRequest{
+		PodSandboxId: podName,
+		Config: &criv1.ContainerConfig{
+			Metadata: &criv1.ContainerMetadata{Name: name},
+			Linux: &criv1.LinuxContainerConfig{
+				Resources: &criv1.LinuxContainerResources{
+					CpuShares: int64(cache.MilliCPUToShares(milliCPU)),
+				},
+			},
+		},
+		SandboxConfig: podCfg,
+	})
+	if err != nil {
+		t.Fatalf("failed to create container: %v", err)
+	}
+	reply := &criv1.CreateContainerResponse{ContainerId: podName + "-" + name}
+	if c, err = cch.UpdateContainerID(c.GetCacheID(), reply); err != nil {
+		t.Fatalf("failed to update container ID: %v", err)
+	}
+	return c
+}
+
+func TestRebalanceMigration(t *testing.T) {
+	type ctr struct {
+		pod       string
+		name      string
+		namespace string
+		milliCPU  int
+		balloon   int // index of the initial balloon among balloons of the type
+	}
+	tcases := []struct {
+		name             string
+		balloons         int
+		spreadPods       bool
+		perNamespace     bool
+		budget           int
+		interval         time.Duration
+		containers       []ctr
+		expectedBalloons map[string]int // expected balloon index by container name
+		expectedMigrated bool
+	}{
+		{
+			name:     "migrate to the empty balloon",
+			balloons: 2,
+			budget:   1,
+			containers: []ctr{
+				{pod: "pod0", name: "c0", milliCPU: 1000},
+				{pod: "pod1", name: "c1", milliCPU: 1000},
+			},
+			expectedBalloons: map[string]int{"c0": 1, "c1": 0},
+			expectedMigrated: true,
+		},
+		{
+			name:     "migrate to the least loaded balloon",
+			balloons: 3,
+			budget:   1,
+			containers: []ctr{
+				{pod: "pod0", name: "c0", milliCPU: 1000},
+				{pod: "pod1", name: "c1", milliCPU: 1000},
+				{pod: "pod2", name: "c2", milliCPU: 1000},
+				{pod: "pod3", name: "c3", milliCPU: 500, balloon: 1},
+			},
+			expectedBalloons: map[string]int{"c0": 2, "c1": 0, "c2": 0, "c3": 1},
+			expectedMigrated: true,
+		},
+		{
+			name:     "budget limits migrations",
+			balloons: 2,
+			budget:   1,
+			interval: time.Minute,
+			containers: []ctr{
+				{pod: "pod0", name: "c0", milliCPU: 1000},
+				{pod: "pod1", name: "c1", milliCPU: 1000},
+				{pod: "pod2", name: "c2", milliCPU: 1000},
+				{pod: "pod3", name: "c3", milliCPU: 1000},
+			},
+			expectedBalloons: map[string]int{"c0": 1, "c1": 0, "c2": 0, "c3": 0},
+			expectedMigrated: true,
+		},
+		{
+			name:     "budget allows more migrations",
+			balloons: 2,
+			budget:   3,
+			containers: []ctr{
+				{pod: "pod0", name: "c0", milliCPU: 1000},
+				{pod: "pod1", name: "c1", milliCPU: 1000},
+				{pod: "pod2", name: "c2", milliCPU: 1000},
+				{pod: "pod3", name: "c3", milliCPU: 1000},
+			},
+			expectedBalloons: map[string]int{"c0": 1, "c1": 1, "c2": 0, "c3": 0},
+			expectedMigrated: true,
+		},
+		{
+			name:     "no migration without budget",
+			balloons: 2,
+			containers: []ctr{
+				{pod: "pod0", name: "c0", milliCPU: 1000},
+				{pod: "pod1", name: "c1", milliCPU: 1000},
+			},
+			expectedBalloons: map[string]int{"c0": 0, "c1": 0},
+		},
+		{
+			name:     "no migration if the target would become as loaded",
+			balloons: 2,
+			budget:   1,
+			containers: []ctr{
+				{pod: "pod0", name: "c0", milliCPU: 1000},
+				{pod: "pod1", name: "c1", milliCPU: 1000, balloon: 1},
+			},
+			expectedBalloons: map[string]int{"c0": 0, "c1": 1},
+		},
+		{
+			name:     "pod containers kept together over budget",
+			balloons: 2,
+			budget:   1,
+			containers: []ctr{
+				{pod: "pod0", name: "c0", milliCPU: 1000},
+				{pod: "pod0", name: "c1", milliCPU: 1000},
+			},
+			expectedBalloons: map[string]int{"c0": 0, "c1": 0},
+		},
+		{
+			name:       "pod containers spread within budget",
+			balloons:   2,
+			spreadPods: true,
+			budget:     1,
+			containers: []ctr{
+				{pod: "pod0", name: "c0", milliCPU: 1000},
+				{pod: "pod0", name: "c1", milliCPU: 1000},
+			},
+			expectedBalloons: map[string]int{"c0": 1, "c1": 0},
+			expectedMigrated: true,
+		},
+		{
+			name:         "no migration to balloon of other namespace",
+			balloons:     2,
+			perNamespace: true,
+			budget:       1,
+			containers: []ctr{
+				{pod: "pod0", name: "c0", namespace: "ns0", milliCPU: 1000},
+				{pod: "pod1", name: "c1", namespace: "ns0", milliCPU: 1000},
+				{pod: "pod2", name: "c2", namespace: "ns0", milliCPU: 1000},
+				{pod: "pod3", name: "c3", namespace: "ns1", milliCPU: 500, balloon: 1},
+			},
+			expectedBalloons: map[string]int{"c0": 0, "c1": 0, "c2": 0, "c3": 1},
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			blnDef := &BalloonDef{
+				Name:                      "work",
+				Namespaces:                []string{"*"},
+				MinBalloons:               tc.balloons,
+				MaxCpus:                   4,
+				PreferSpreadingPods:       tc.spreadPods,
+				PreferPerNamespaceBalloon: tc.perNamespace,
+			}
+			p := newTestBalloons(t, 16, &BalloonsOptions{
+				RebalanceMigrationBudget:   tc.budget,
+				RebalanceMigrationInterval: pkgcfg.Duration(tc.interval),
+				BalloonDefs:                []*BalloonDef{blnDef},
+			})
+			blns := p.balloonsByDef(p.balloonDefByName("work"))
+			if len(blns) != tc.balloons {
+				t.Fatalf("expected %d balloons, got %d", tc.balloons, len(blns))
+			}
+
+			ctrs := map[string]cache.Container{}
+			for _, ctr := range tc.containers {
+				namespace := ctr.namespace
+				if namespace == "" {
+					namespace = "default"
+				}
+				c := createTestContainer(t, p.cch, ctr.pod, ctr.name, namespace, ctr.milliCPU)
+				bln := blns[ctr.balloon]
+				p.resizeBalloon(bln, p.requestedMilliCpus(bln)+ctr.milliCPU)
+				p.assignContainer(c, bln)
+				ctrs[ctr.name] = c
+			}
+
+			migrated, err := p.Rebalance()
+			if err != nil {
+				t.Fatalf("rebalancing failed: %v", err)
+			}
+			if migrated != tc.expectedMigrated {
+				t.Errorf("expected migrated %v, got %v", tc.expectedMigrated, migrated)
+			}
+			for name, idx := range tc.expectedBalloons {
+				c := ctrs[name]
+				bln := p.balloonByContainer(c)
+				if bln != blns[idx] {
+					t.Errorf("expected %s in balloon %s, got %v", name, blns[idx].PrettyName(), bln)
+					continue
+				}
+				if cpus := c.GetCpusetCpus(); cpus != bln.Cpus.Union(bln.SharedIdleCpus).String() {
+					t.Errorf("expected %s pinned to CPUs of %s (%s), got %q", name, bln.PrettyName(), bln.Cpus, cpus)
+				}
+				if p.requestedMilliCpus(bln) > bln.AvailMilliCpus() {
+					t.Errorf("expected balloon %s to fit its containers, %d mCPU requested, %d mCPU available",
+						bln.PrettyName(), p.requestedMilliCpus(bln), bln.AvailMilliCpus())
+				}
+			}
+
+			if tc.interval > 0 {
+				if migrated, _ := p.Rebalance(); migrated {
+					t.Errorf("expected no migrations once the budget of the interval is used")
+				}
+			}
+		})
+	}
+}
+
+func TestMigrateContainersRollback(t *testing.T) {
+	p := newTestBalloons(t, 8, &BalloonsOptions{
+		BalloonDefs: []*BalloonDef{
+			{
+				Name:        "work",
+				Namespaces:  []string{"*"},
+				MinBalloons: 2,
+				MaxCpus:     4,
+			},
+		},
+	})
+	blns := p.balloonsByDef(p.balloonDefByName("work"))
+	src, dst := blns[0], blns[1]
+	c0 := createTestContainer(t, p.cch, "pod0", "c0", "default", 1000)
+	c1 := createTestContainer(t, p.cch, "pod1", "c1", "default", 1000)
+	p.resizeBalloon(src, 2000)
+	p.assignContainer(c0, src)
+	p.assignContainer(c1, src)
+
+	// Make deflating the source balloon fail by giving it CPUs
+	// unknown to the CPU tree.
+	src.Cpus = cpuset.MustParse("100-101")
+	freeCpus := p.freeCpus.Clone()
+	dstCpus := dst.Cpus.Clone()
+
+	if err := p.migrateContainers([]cache.Container{c1}, src, dst); err == nil {
+		t.Fatalf("expected migration to fail")
+	}
+	for _, c := range []cache.Container{c0, c1} {
+		if bln := p.balloonByContainer(c); bln != src {
+			t.Errorf("expected %s kept in balloon %s, got %v", c.PrettyName(), src.PrettyName(), bln)
+		}
+	}
+	if !src.Cpus.Equals(cpuset.MustParse("100-101")) || !dst.Cpus.Equals(dstCpus) {
+		t.Errorf("expected balloon CPUs unchanged, got %s and %s", src.Cpus, dst.Cpus)
+	}
+	if !p.freeCpus.Equals(freeCpus) {
+		t.Errorf("expected free CPUs %s unchanged, got %s", freeCpus, p.freeCpus)
+	}
+}
+
+func TestIntrospect(t *testing.T) {
+	p := newTestBalloons(t, 8, &BalloonsOptions{
+		BalloonDefs: []*BalloonDef{
+			{
+				Name:       "work",
+				Namespaces: []string{"default"},
+				MaxCpus:    4,
+			},
+		},
+	})
+	ctrs := []cache.Container{
+		createTestContainer(t, p.cch, "sys", "c0", "kube-system", 100),
+		createTestContainer(t, p.cch, "work", "c0", "default", 1500),
+		createTestContainer(t, p.cch, "other", "c0", "other", 200),
+	}
+	for _, c := range ctrs {
+		if err := p.AllocateResources(c); err != nil {
+			t.Fatalf("failed to allocate resources for %s: %v", c.PrettyName(), err)
+		}
+	}
+
+	state := &introspect.State{}
+	p.Introspect(state)
+
+	expectedPools := map[string][]string{
+		"reserved[0]": {"sys-c0"},
+		"default[0]":  {"other-c0"},
+		"work[0]":     {"work-c0"},
+	}
+	if len(state.Pools) != len(expectedPools) {
+		t.Errorf("expected pools %v, got %v", expectedPools, state.Pools)
+	}
+	for _, bln := range p.balloons {
+		pool, ok := state.Pools[bln.PrettyName()]
+		if !ok {
+			t.Errorf("expected pool for balloon %s", bln.PrettyName())
+			continue
+		}
+		if !reflect.DeepEqual(pool.Containers, expectedPools[pool.Name]) {
+			t.Errorf("expected containers %v in pool %s, got %v", expectedPools[pool.Name], pool.Name, pool.Containers)
+		}
+		if pool.CPUs != bln.Cpus.String() {
+			t.Errorf("expected CPUs %s of pool %s, got %s", bln.Cpus, pool.Name, pool.CPUs)
+		}
+		if pool.Extra["balloon-type"] != bln.Def.Name {
+			t.Errorf("expected balloon type %s of pool %s, got %s", bln.Def.Name, pool.Name, pool.Extra["balloon-type"])
+		}
+	}
+	if cpus := state.Pools["work[0]"].CPUs; cpuset.MustParse(cpus).Size() != 2 {
+		t.Errorf("expected work balloon inflated to 2 CPUs for 1500 mCPU, got %q", cpus)
+	}
+
+	if len(state.Assignments) != len(ctrs) {
+		t.Errorf("expected %d assignments, got %d", len(ctrs), len(state.Assignments))
+	}
+	a, ok := state.Assignments["work-c0"]
+	if !ok {
+		t.Fatalf("expected assignment of work-c0")
+	}
+	if a.Pool != "work[0]" || a.CPUShare != 1500 || a.SharedCPUs != ctrs[1].GetCpusetCpus() || a.SharedCPUs == "" {
+		t.Errorf("unexpected assignment of work-c0: %+v", *a)
+	}
+	if a.Extra["fill-method"] == "" {
+		t.Errorf("expected fill method in assignment of work-c0, got %v", a.Extra)
+	}
+
+	// released containers and freed balloons disappear
+	if err := p.ReleaseResources(ctrs[1]); err != nil {
+		t.Fatalf("failed to release resources of %s: %v", ctrs[1].PrettyName(), err)
+	}
+	state = &introspect.State{}
+	p.Introspect(state)
+	if _, ok := state.Pools["work[0]"]; ok {
+		t.Errorf("expected freed work balloon not to be introspected")
+	}
+	if _, ok := state.Assignments["work-c0"]; ok {
+		t.Errorf("expected no assignment for released container")
+	}
+}
+
+func TestHandlePressure(t *testing.T) {
+	p := newTestBalloons(t, 8, &BalloonsOptions{
+		BalloonDefs: []*BalloonDef{
+			{
+				Name:        "work",
+				Namespaces:  []string{"default"},
+				MaxBalloons: 1,
+				MaxCpus:     3,
+			},
+		},
+	})
+	sys := createTestContainer(t, p.cch, "sys", "c0", "kube-system", 100)
+	ctrs := []cache.Container{
+		createTestContainer(t, p.cch, "pod0", "c0", "default", 1500),
+		createTestContainer(t, p.cch, "pod1", "c1", "default", 200),
+		createTestContainer(t, p.cch, "pod2", "c2", "default", 200),
+	}
+	for _, c := range append([]cache.Container{sys}, ctrs...) {
+		if err := p.AllocateResources(c); err != nil {
+			t.Fatalf("failed to allocate resources for %s: %v", c.PrettyName(), err)
+		}
+	}
+	bln := p.balloonByContainer(ctrs[0])
+	if bln == nil || bln.ContainerCount() != len(ctrs) {
+		t.Fatalf("expected all containers in a single work balloon, got %v", bln)
+	}
+
+	handle := func(c cache.Container, resource string) bool {
+		changed, err := p.HandleEvent(&events.Policy{
+			Type:   events.ContainerPressure,
+			Source: pressure.EventSource,
+			Data: &events.Pressure{
+				ContainerID: c.GetCacheID(),
+				Resource:    resource,
+				Avg10:       50,
+				Threshold:   20,
+			},
+		})
+		if err != nil {
+			t.Fatalf("failed to handle %s pressure of %s: %v", resource, c.PrettyName(), err)
+		}
+		return changed
+	}
+	checkSize := func(what string, cpus int) {
+		t.Helper()
+		if bln.Cpus.Size() != cpus {
+			t.Errorf("%s: expected %s to have %d CPUs, got %s", what, bln.PrettyName(), cpus, bln.Cpus)
+		}
+		if cpus := ctrs[2].GetCpusetCpus(); cpus != bln.Cpus.Union(bln.SharedIdleCpus).String() {
+			t.Errorf("%s: expected containers pinned to CPUs of %s, got %q", what, bln, cpus)
+		}
+	}
+	checkSize("initially", 2)
+
+	if handle(ctrs[0], pressure.Memory) {
+		t.Errorf("expected memory pressure to be rejected")
+	}
+	checkSize("memory pressure", 2)
+
+	if !handle(ctrs[0], pressure.CPU) {
+		t.Errorf("expected CPU pressure to inflate the balloon")
+	}
+	checkSize("CPU pressure", 3)
+
+	if handle(ctrs[0], pressure.CPU) {
+		t.Errorf("expected CPU pressure not to inflate the balloon beyond MaxCpus")
+	}
+	checkSize("CPU pressure at MaxCpus", 3)
+
+	if handle(sys, pressure.CPU) {
+		t.Errorf("expected CPU pressure not to resize the reserved balloon")
+	}
+
+	// Extra CPU is kept while the container under pressure stays...
+	if err := p.ReleaseResources(ctrs[1]); err != nil {
+		t.Fatalf("failed to release resources of %s: %v", ctrs[1].PrettyName(), err)
+	}
+	checkSize("other container released", 3)
+
+	// ...and given back once it is gone.
+	if err := p.ReleaseResources(ctrs[0]); err != nil {
+		t.Fatalf("failed to release resources of %s: %v", ctrs[0].PrettyName(), err)
+	}
+	checkSize("container under pressure released", 1)
+	if len(p.pressureMilliCpus) != 0 {
+		t.Errorf("expected no extra CPU left for released containers, got %v", p.pressureMilliCpus)
+	}
+	if p.freeCpus.Size() != 8-1-1 {
+		t.Errorf("expected extra CPUs to be freed, got free CPUs %s", p.freeCpus)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"strings"
 
 	system "github.com/intel/cri-resource-manager/pkg/sysfs"
+	idset "github.com/intel/goresctrl/pkg/utils"
 	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
 )
 
@@ -33,6 +34,8 @@ const (
 	CPUTopologyLevelPackage
 	CPUTopologyLevelDie
 	CPUTopologyLevelNuma
+	CPUTopologyLevelL3Cache
+	CPUTopologyLevelL2Cache
 	CPUTopologyLevelCore
 	CPUTopologyLevelThread
 	CPUTopologyLevelCount
@@ -98,6 +101,8 @@ var cpuTopologyLevelToString = map[CPUTopologyLevel]string{
 	CPUTopologyLevelPackage:   "package",
 	CPUTopologyLevelDie:       "die",
 	CPUTopologyLevelNuma:      "numa",
+	CPUTopologyLevelL3Cache:   "l3cache",
+	CPUTopologyLevelL2Cache:   "l2cache",
 	CPUTopologyLevelCore:      "core",
 	CPUTopologyLevelThread:    "thread",
 }
@@ -197,28 +202,49 @@ func (t *cpuTreeNode) DepthFirstWalk(handler func(*cpuTreeNode) error) error {
 }
 
 // CpuLocations returns a slice where each element contains names of
-// topology elements over which a set of CPUs spans. Example:
+// topology elements over which a set of CPUs spans. Topology levels
+// missing from the tree are left out. Example:
 // systemNode.CpuLocations(cpuset:0,99) = [["system"],["p0", "p1"], ["p0d0", "p1d0"], ...]
 func (t *cpuTreeNode) CpuLocations(cpus cpuset.CPUSet) [][]string {
 	names := make([][]string, int(CPUTopologyLevelCount)-int(t.level))
+	present := make([]bool, len(names))
 	t.DepthFirstWalk(func(tn *cpuTreeNode) error {
+		levelIndex := int(tn.level) - int(t.level)
+		present[levelIndex] = true
 		if tn.cpus.Intersection(cpus).Size() == 0 {
-			return WalkSkipChildren
+			return nil
 		}
-		levelIndex := int(tn.level) - int(t.level)
 		names[levelIndex] = append(names[levelIndex], tn.name)
 		return nil
 	})
-	return names
+	locations := make([][]string, 0, len(names))
+	for levelIndex, levelNames := range names {
+		if present[levelIndex] {
+			locations = append(locations, levelNames)
+		}
+	}
+	return locations
 }
 
 // NewCpuTreeFromSystem returns the root node of the topology tree
-// constructed from the underlying system.
+// constructed from the underlying system. If the system exposes L3
+// and L2 caches, L3 cache domains and L2 cache clusters are placed
+// between NUMA nodes and cores. A cache spanning several NUMA nodes is
+// split at NUMA node boundaries.
 func NewCpuTreeFromSystem() (*cpuTreeNode, error) {
-	sys, err := system.DiscoverSystem(system.DiscoverCPUTopology)
+	sys, err := system.DiscoverSystem(system.DiscoverCPUTopology | system.DiscoverCache)
 	if err != nil {
 		return nil, err
 	}
+	hasL3, hasL2 := false, false
+	for _, level := range sys.CacheLevels() {
+		switch level {
+		case 3:
+			hasL3 = true
+		case 2:
+			hasL2 = true
+		}
+	}
 	// TODO: split deep nested loops into functions
 	sysTree := NewCpuTree("system")
 	sysTree.level = CPUTopologyLevelSystem
@@ -236,12 +262,43 @@ func NewCpuTreeFromSystem() (*cpuTreeNode, error) {
 				nodeTree.level = CPUTopologyLevelNuma
 				dieTree.AddChild(nodeTree)
 				node := sys.Node(nodeID)
+				l3Trees := map[idset.ID]*cpuTreeNode{}
+				l2Trees := map[idset.ID]*cpuTreeNode{}
 				for _, cpuID := range node.CPUSet().ToSlice() {
+					cpu := sys.CPU(cpuID)
+					parentTree := nodeTree
+					// CPUs without details of a cache level present in the
+					// system fall back to a single L3 domain per NUMA node
+					// and an L2 cluster per core.
+					if hasL3 {
+						l3ID := cpu.CacheID(3)
+						l3Tree, ok := l3Trees[l3ID]
+						if !ok {
+							l3Tree = NewCpuTree(fmt.Sprintf("p%dd%dn%dl3c%d", packageID, dieID, nodeID, l3ID))
+							l3Tree.level = CPUTopologyLevelL3Cache
+							parentTree.AddChild(l3Tree)
+							l3Trees[l3ID] = l3Tree
+						}
+						parentTree = l3Tree
+					}
+					if hasL2 {
+						l2ID := cpu.CacheID(2)
+						if l2ID == idset.Unknown {
+							l2ID = idset.ID(cpu.ThreadCPUSet().ToSlice()[0])
+						}
+						l2Tree, ok := l2Trees[l2ID]
+						if !ok {
+							l2Tree = NewCpuTree(fmt.Sprintf("p%dd%dn%dl2c%d", packageID, dieID, nodeID, l2ID))
+							l2Tree.level = CPUTopologyLevelL2Cache
+							parentTree.AddChild(l2Tree)
+							l2Trees[l2ID] = l2Tree
+						}
+						parentTree = l2Tree
+					}
 					cpuTree := NewCpuTree(fmt.Sprintf("p%dd%dn%dcpu%d", packageID, dieID, nodeID, cpuID))
 
 					cpuTree.level = CPUTopologyLevelCore
-					nodeTree.AddChild(cpuTree)
-					cpu := sys.CPU(cpuID)
+					parentTree.AddChild(cpuTree)
 					for _, threadID := range cpu.ThreadCPUSet().ToSlice() {
 						threadTree := NewCpuTree(fmt.Sprintf("p%dd%dn%dcpu%dt%d", packageID, dieID, nodeID, cpuID, threadID))
 						threadTree.level = CPUTopologyLevelThread
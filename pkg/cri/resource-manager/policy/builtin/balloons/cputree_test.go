@@ -619,6 +619,12 @@ func TestCPUTopologyLevel(t *testing.T) {
 	if err := lvl.UnmarshalJSON([]byte("\"NUMA\"")); err != nil || lvl != CPUTopologyLevelNuma {
 		t.Errorf("unexpected outcome unmarshalling topology level: \"NUMA\", error: %s, result: %s", err, lvl)
 	}
+	if err := lvl.UnmarshalJSON([]byte("\"L3Cache\"")); err != nil || lvl != CPUTopologyLevelL3Cache {
+		t.Errorf("unexpected outcome unmarshalling topology level: \"L3Cache\", error: %s, result: %s", err, lvl)
+	}
+	if err := lvl.UnmarshalJSON([]byte("\"l2cache\"")); err != nil || lvl != CPUTopologyLevelL2Cache {
+		t.Errorf("unexpected outcome unmarshalling topology level: \"l2cache\", error: %s, result: %s", err, lvl)
+	}
 	if err := lvl.UnmarshalJSON([]byte("\"undefined\"")); err == nil {
 		t.Errorf("unexpected outcome unmarshalling topology level: \"undefined\", error: %s, result: %s", err, lvl)
 	}
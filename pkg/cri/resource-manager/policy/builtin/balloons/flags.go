@@ -19,6 +19,7 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/intel/cri-resource-manager/pkg/apis/resmgr"
 	pkgcfg "github.com/intel/cri-resource-manager/pkg/config"
 	"github.com/intel/cri-resource-manager/pkg/cpuallocator"
 )
@@ -43,6 +44,15 @@ type balloonsOptionsWrapped struct {
 	// amount of allocations. The default is false: balloons are
 	// packed tightly to optimize power efficiency.
 	AllocatorTopologyBalancing bool
+	// RebalanceMigrationBudget is the maximum number of
+	// containers that rebalancing may migrate between balloons
+	// of the same type during one RebalanceMigrationInterval.
+	// The default is 0: containers are never migrated.
+	RebalanceMigrationBudget int `json:"RebalanceMigrationBudget,omitempty"`
+	// RebalanceMigrationInterval is the period over which
+	// RebalanceMigrationBudget is accounted. The default is 0:
+	// the budget applies to every rebalancing pass separately.
+	RebalanceMigrationInterval pkgcfg.Duration `json:"RebalanceMigrationInterval,omitempty"`
 	// BallonDefs contains balloon type definitions.
 	BalloonDefs []*BalloonDef `json:"BalloonTypes,omitempty"`
 }
@@ -55,6 +65,11 @@ type BalloonDef struct {
 	// balloon instances from this definition. This is used by
 	// namespace assign methods.
 	Namespaces []string `json:"Namespaces",omitempty`
+	// MatchExpressions assign containers into balloon instances
+	// from this definition. A container matches if all of the
+	// expressions evaluate true for it. Matching expressions take
+	// precedence over Namespaces of any balloon definition.
+	MatchExpressions []*resmgr.Expression `json:"MatchExpressions,omitempty"`
 	// MaxCpus specifies the maximum number of CPUs exclusively
 	// usable by containers in a balloon. Balloon size will not be
 	// inflated larger than MaxCpus.
@@ -130,6 +145,12 @@ func (bdef *BalloonDef) DeepCopy() *BalloonDef {
 	outBdef := *bdef
 	outBdef.Namespaces = make([]string, len(bdef.Namespaces))
 	copy(outBdef.Namespaces, bdef.Namespaces)
+	if bdef.MatchExpressions != nil {
+		outBdef.MatchExpressions = make([]*resmgr.Expression, len(bdef.MatchExpressions))
+		for i, expr := range bdef.MatchExpressions {
+			outBdef.MatchExpressions[i] = expr.DeepCopy()
+		}
+	}
 	return &outBdef
 }
 
@@ -12,6 +12,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/intel/cri-resource-manager/pkg/cgroups"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/cache"
 	"github.com/intel/cri-resource-manager/pkg/sysfs"
 )
 
@@ -208,3 +210,69 @@ func getBusyTime(cts cpuTimesStat) (float64, float64) {
 	busy := total - cts.idle - cts.ioWait
 	return total, busy
 }
+
+// getContainerCpuUtilization returns the CPU demand of each container in an
+// interval, in percents of a CPU. The demand of a container is the CPU time
+// it consumed plus the time it was throttled by its CFS quota. Containers
+// whose cgroup statistics cannot be read are omitted.
+func getContainerCpuUtilization(cch cache.Cache, ids []string, interval time.Duration) (map[string]float64, error) {
+	ctx := context.Background()
+	start := time.Now()
+	cpuStat1 := getContainerCpuStats(cch, ids)
+	if err := wait(ctx, interval); err != nil {
+		return nil, err
+	}
+	cpuStat2 := getContainerCpuStats(cch, ids)
+	elapsed := time.Since(start)
+	return calculateAllContainersUtilization(cpuStat1, cpuStat2, elapsed), nil
+}
+
+// getContainerCpuStats reads cgroup CPU statistics of containers.
+func getContainerCpuStats(cch cache.Cache, ids []string) map[string]cgroups.CPUStat {
+	stats := make(map[string]cgroups.CPUStat, len(ids))
+	for _, id := range ids {
+		c, ok := cch.LookupContainer(id)
+		if !ok {
+			continue
+		}
+		dir := c.GetCgroupDir()
+		if dir == "" {
+			continue
+		}
+		stat, err := cgroups.GetCPUStat(dir)
+		if err != nil {
+			log.Debug("failed to read CPU statistics of container %s: %v", c.PrettyName(), err)
+			continue
+		}
+		stats[id] = stat
+	}
+	return stats
+}
+
+func calculateAllContainersUtilization(cs1, cs2 map[string]cgroups.CPUStat, elapsed time.Duration) map[string]float64 {
+	utilization := make(map[string]float64, len(cs2))
+	if elapsed <= 0 {
+		return utilization
+	}
+	for id, stat2 := range cs2 {
+		stat1, ok := cs1[id]
+		if !ok {
+			continue
+		}
+		utilization[id] = calculateOneContainerUtilization(stat1, stat2, elapsed)
+	}
+	return utilization
+}
+
+// calculateOneContainerUtilization returns the CPU demand of one container in an interval.
+func calculateOneContainerUtilization(cs1, cs2 cgroups.CPUStat, elapsed time.Duration) float64 {
+	usage := cs2.UsageNs - cs1.UsageNs
+	if usage < 0 {
+		usage = 0
+	}
+	throttled := int64(0)
+	if cs2.NrThrottled > cs1.NrThrottled && cs2.ThrottledNs > cs1.ThrottledNs {
+		throttled = cs2.ThrottledNs - cs1.ThrottledNs
+	}
+	return float64(usage+throttled) / float64(elapsed.Nanoseconds()) * 100
+}
@@ -17,6 +17,7 @@ package dyp
 import (
 	"fmt"
 	"path/filepath"
+	"sort"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -81,6 +82,11 @@ type DynamicPool struct {
 	// - len(PodIDs) is the number of pods in the dynamicPool.
 	// - len(PodIDs[podID]) is the number of containers of podID currently assigned to the dynamicPool.
 	PodIDs map[string][]string
+
+	load       float64   // smoothed CPU utilization
+	loadTime   time.Time // time of the latest utilization sample
+	targetCpus int       // latest calculated target number of CPUs
+	sizeReason string    // reason for targetCpus
 }
 
 var log logger.Logger = logger.NewLogger("policy")
@@ -134,10 +140,47 @@ func (dp *DynamicPool) updateRealCpuUsed(cpuInfo []float64) (float64, error) {
 	return sum, nil
 }
 
+// containerCpuUsed returns the sum of cpu demand of containers in a dynamicPool.
+func (dp *DynamicPool) containerCpuUsed(ctrInfo map[string]float64) float64 {
+	var sum float64
+	for _, cID := range dp.ContainerIDs() {
+		sum += ctrInfo[cID]
+	}
+	log.Debug("dynamic pool %s containers: %d, cpu demand: %v", dp.Def.Name, dp.ContainerCount(), sum)
+	return sum
+}
+
+// containerIDs returns IDs of containers assigned in all non-reserved dynamicPools.
+func (p *dynamicPools) containerIDs() []string {
+	cIDs := []string{}
+	for _, dp := range p.dynamicPools {
+		if dp.Def.Name == reservedDynamicPoolDefName {
+			continue
+		}
+		cIDs = append(cIDs, dp.ContainerIDs()...)
+	}
+	return cIDs
+}
+
 // calculateAllPoolWeights returns weights of all dynamicPools and the sum of weights.
-// Use dynamicPool's cpu utilization as its weight.
+// Use dynamicPool's smoothed cpu demand as its weight. The demand is measured
+// from the cgroups of its containers, or from the utilization of its CPUs if
+// configured so or if no container statistics are available.
 func (p *dynamicPools) calculateAllPoolWeights() (map[*DynamicPool]float64, float64, error) {
-	cpuInfo, _ := getCpuUtilization(time.Duration(time.Second))
+	var cpuInfo []float64
+	var ctrInfo map[string]float64
+	if p.dpoptions.LoadSource != loadSourceCpus {
+		cIDs := p.containerIDs()
+		ctrInfo, _ = getContainerCpuUtilization(p.cch, cIDs, time.Duration(time.Second))
+		if len(ctrInfo) == 0 && len(cIDs) > 0 {
+			log.Warn("no cgroup CPU statistics available for containers, using CPU utilization instead")
+			ctrInfo = nil
+		}
+	}
+	if ctrInfo == nil {
+		cpuInfo, _ = getCpuUtilization(time.Duration(time.Second))
+	}
+	now := time.Now()
 	weight := make(map[*DynamicPool]float64)
 	sumWeight := 0.0
 	for _, dp := range p.dynamicPools {
@@ -147,13 +190,20 @@ func (p *dynamicPools) calculateAllPoolWeights() (map[*DynamicPool]float64, floa
 		// If there is no container in a dynamic pool, there is no need to calculate its weight, that is, there is no need to allocate CPUs to it.
 		if dp.ContainerCount() == 0 {
 			weight[dp] = 0.0
+			dp.resetLoad()
 		} else {
-			realCpuUsed, err := dp.updateRealCpuUsed(cpuInfo)
-			if err != nil {
-				return weight, sumWeight, dynamicPoolsError("The actual cpu usage of the dynamic pool %s cannot be obtained: %w",
-					dp.PrettyName(), err)
+			var realCpuUsed float64
+			if ctrInfo != nil {
+				realCpuUsed = dp.containerCpuUsed(ctrInfo)
+			} else {
+				var err error
+				realCpuUsed, err = dp.updateRealCpuUsed(cpuInfo)
+				if err != nil {
+					return weight, sumWeight, dynamicPoolsError("The actual cpu usage of the dynamic pool %s cannot be obtained: %w",
+						dp.PrettyName(), err)
+				}
 			}
-			weight[dp] = realCpuUsed
+			weight[dp] = dp.updateLoad(realCpuUsed, now)
 			sumWeight += weight[dp]
 		}
 		log.Debug("dynamic pool: %s, weight: %v", dp, weight[dp])
@@ -164,7 +214,8 @@ func (p *dynamicPools) calculateAllPoolWeights() (map[*DynamicPool]float64, floa
 
 // calculateAllPoolRequests returns the sum of the requests of containers in each dynamicPool and remaining free cpu.
 // remainFree = allowed cpu - reserved cpu - sum(requests of containers in each dynamicPool)
-func (p *dynamicPools) calculateAllPoolRequests() (map[*DynamicPool]int, int) {
+// The requests of a dynamicPool are raised to its MinCpus, if necessary.
+func (p *dynamicPools) calculateAllPoolRequests(now time.Time) (map[*DynamicPool]int, int) {
 	requestCpu := make(map[*DynamicPool]int)
 	remainFree := p.allowed.Difference(p.reserved).Size()
 	for _, dp := range p.dynamicPools {
@@ -172,6 +223,11 @@ func (p *dynamicPools) calculateAllPoolRequests() (map[*DynamicPool]int, int) {
 			continue
 		}
 		requestCpu[dp] = (p.requestedMinMilliCpus(dp) + 999) / 1000
+		dp.sizeReason = resizeReasonRequests
+		if minCpus := dp.Def.minCpusAt(now); minCpus > requestCpu[dp] {
+			requestCpu[dp] = minCpus
+			dp.sizeReason = resizeReasonMinCpus
+		}
 		remainFree -= requestCpu[dp]
 		log.Debug("dynamic pool %s request cpu %d", dp, requestCpu[dp])
 	}
@@ -211,6 +267,9 @@ func (p *dynamicPools) calculatePoolCpuset(requestCpu map[*DynamicPool]int, rema
 			addCpu := int(float64(remainFree) * weight[dp] / sumWeight)
 			requestCpu[dp] += addCpu
 			usedCpu += addCpu
+			if addCpu > 0 {
+				dp.sizeReason = resizeReasonLoad
+			}
 		}
 		log.Info("The cpu that dynamic pool %s needs to allocate is %d, remain free cpu %d", dp, requestCpu[dp], remainFree-usedCpu)
 	}
@@ -226,18 +285,34 @@ func (p *dynamicPools) calculatePoolCpuset(requestCpu map[*DynamicPool]int, rema
 			}
 		}
 		requestCpu[tmp] += (remainFree - usedCpu)
+		tmp.sizeReason = resizeReasonLoad
 		log.Info("The cpu that dynamic pool %s needs to allocate is %d, remain free cpu %d", tmp, requestCpu[tmp], 0)
 	}
 	return requestCpu
 }
 
 // isNeedReallocate returns whether the cpus need to be reallocated.
+// Changes smaller than the ResizeThreshold of a dynamicPool are ignored
+// unless the dynamicPool has too few CPUs for its requests and MinCpus,
+// or more CPUs than its MaxCpus.
 func (p *dynamicPools) isNeedReallocate(newPoolCpu map[*DynamicPool]int) bool {
+	now := time.Now()
 	for _, dp := range p.dynamicPools {
 		if dp.Def.Name == reservedDynamicPoolDefName {
 			continue
 		}
-		if dp.Cpus.Size() != newPoolCpu[dp] {
+		size := dp.Cpus.Size()
+		delta := newPoolCpu[dp] - size
+		if delta == 0 {
+			continue
+		}
+		if delta >= dp.Def.resizeThreshold() || -delta >= dp.Def.resizeThreshold() {
+			return true
+		}
+		if size < p.requiredCpus(dp, now) || (size == 0 && dp.ContainerCount() > 0) {
+			return true
+		}
+		if dp.Def.MaxCpus > 0 && size > dp.Def.MaxCpus && newPoolCpu[dp] < size {
 			return true
 		}
 	}
@@ -246,7 +321,7 @@ func (p *dynamicPools) isNeedReallocate(newPoolCpu map[*DynamicPool]int) bool {
 
 // updatePoolCpuset updates the cpuset of the dynamicPools.
 func (p *dynamicPools) updatePoolCpuset() error {
-	requestCpu, remainFree := p.calculateAllPoolRequests()
+	requestCpu, remainFree := p.calculateAllPoolRequests(time.Now())
 	weight, sumWeight, err := p.calculateAllPoolWeights()
 	if err != nil {
 		return err
@@ -255,12 +330,17 @@ func (p *dynamicPools) updatePoolCpuset() error {
 	if remainFree >= 1 {
 		requestCpu = p.calculatePoolCpuset(requestCpu, remainFree, weight, sumWeight)
 	}
+	requestCpu = p.applyMaxCpus(requestCpu, weight)
+	p.setPoolTargets(requestCpu)
 
-	// If the number of newly allocated CPUs is the same as the number of existing CPUs in the pool,
+	// If the number of newly allocated CPUs is (close enough to) the number of existing CPUs in the pool,
 	// it means that there is no need to re-allocate
 	if !p.isNeedReallocate(requestCpu) {
-		log.Info("The number of CPUs required by the pools is the same as the number of CPUs already in the pools, so there is no need to reallocate.")
+		log.Info("The number of CPUs required by the pools is within the resize threshold of the number of CPUs already in the pools, so there is no need to reallocate.")
 		for _, dp := range p.dynamicPools {
+			if dp.targetCpus != dp.Cpus.Size() {
+				dp.sizeReason = resizeReasonHysteresis
+			}
 			p.containerPinPool(dp)
 		}
 		return nil
@@ -298,12 +378,38 @@ func (p *dynamicPools) updatePoolCpuset() error {
 
 // CreateDynamicPoolsPolicy creates a new policy instance.
 func CreateDynamicPoolsPolicy(policyOptions *policy.BackendOptions) policy.Backend {
+	log.Info("creating %s policy...", PolicyName)
+	p, err := newDynamicPools(policyOptions)
+	if err != nil {
+		log.Fatal("%v", err)
+	}
+	// Handle policy-specific options
+	log.Debug("creating %s configuration", PolicyName)
+	if err := p.setConfig(dynamicPoolsOptions); err != nil {
+		log.Fatal("failed to create %s policy: %v", PolicyName, err)
+	}
+	pkgcfg.GetModule(PolicyPath).AddNotify(p.configNotify)
+
+	return p
+}
+
+// validateDynamicPoolsConfig checks the current dynamic-pools configuration
+// against the available CPUs, without creating a policy instance.
+func validateDynamicPoolsConfig(policyOptions *policy.BackendOptions) error {
+	p, err := newDynamicPools(policyOptions)
+	if err != nil {
+		return err
+	}
+	return p.validateConfig(dynamicPoolsOptions)
+}
+
+// newDynamicPools creates a dynamicPools instance with the allowed and reserved CPUs set up.
+func newDynamicPools(policyOptions *policy.BackendOptions) (*dynamicPools, error) {
 	p := &dynamicPools{
 		options:      policyOptions,
 		cch:          policyOptions.Cache,
 		cpuAllocator: cpuallocator.NewCPUAllocator(policyOptions.System),
 	}
-	log.Info("creating %s policy...", PolicyName)
 	// Handle common policy options: AvailableResources and ReservedResources.
 	// p.allowed: CPUs available for the policy
 	if allowed, ok := policyOptions.Available[policyapi.DomainCPU]; ok {
@@ -322,23 +428,16 @@ func CreateDynamicPoolsPolicy(policyOptions *policy.BackendOptions) policy.Backe
 			reserveCnt := (int(v.MilliValue()) + 999) / 1000
 			cpus, err := p.cpuAllocator.AllocateCpus(&p.allowed, reserveCnt, cpuallocator.PriorityNone)
 			if err != nil {
-				log.Fatal("failed to allocate reserved CPUs: %s", err)
+				return nil, dynamicPoolsError("failed to allocate reserved CPUs: %s", err)
 			}
 			p.reserved = cpus
 			p.allowed = p.allowed.Union(cpus)
 		}
 	}
 	if p.reserved.IsEmpty() {
-		log.Fatal("%s cannot run without reserved CPUs that are also AvailableResources", PolicyName)
+		return nil, dynamicPoolsError("%s cannot run without reserved CPUs that are also AvailableResources", PolicyName)
 	}
-	// Handle policy-specific options
-	log.Debug("creating %s configuration", PolicyName)
-	if err := p.setConfig(dynamicPoolsOptions); err != nil {
-		log.Fatal("failed to create %s policy: %v", PolicyName, err)
-	}
-	pkgcfg.GetModule(PolicyPath).AddNotify(p.configNotify)
-
-	return p
+	return p, nil
 }
 
 // Name returns the name of this policy.
@@ -439,8 +538,89 @@ func (p *dynamicPools) ExportResourceData(c cache.Container) map[string]string {
 }
 
 // Introspect provides data for external introspection.
-func (p *dynamicPools) Introspect(*introspect.State) {
-	return
+func (p *dynamicPools) Introspect(state *introspect.State) {
+	pools := make(map[string]*introspect.Pool, len(p.dynamicPools))
+	assignments := make(map[string]*introspect.Assignment)
+	for _, dp := range p.dynamicPools {
+		pool := &introspect.Pool{
+			Name:   dp.PrettyName(),
+			CPUs:   dp.Cpus.String(),
+			Memory: dp.Mems.String(),
+			Extra: map[string]string{
+				"cpu-class":   dp.Def.CpuClass,
+				"load":        fmt.Sprintf("%.2f", dp.load),
+				"target-cpus": fmt.Sprintf("%d", dp.targetCpus),
+				"size-reason": dp.sizeReason,
+			},
+		}
+		for _, cID := range dp.ContainerIDs() {
+			c, ok := p.cch.LookupContainer(cID)
+			if !ok {
+				continue
+			}
+			pool.Containers = append(pool.Containers, c.GetID())
+			a := &introspect.Assignment{
+				ContainerID: c.GetID(),
+				SharedCPUs:  c.GetCpusetCpus(),
+				CPUShare:    p.containerRequestedMilliCpus(cID),
+				Memory:      c.GetCpusetMems(),
+				Pool:        pool.Name,
+			}
+			assignments[a.ContainerID] = a
+		}
+		sort.Strings(pool.Containers)
+		pools[pool.Name] = pool
+	}
+	state.Pools = pools
+	state.Assignments = assignments
+	state.Reserved = p.reserved.String()
+}
+
+// checkpoint is a checkpoint of the dynamic pools and their containers.
+type checkpoint struct {
+	freeCpus     cpuset.CPUSet
+	dynamicPools []*DynamicPool
+}
+
+// Checkpoint takes a checkpoint of the current dynamic pools and their containers.
+func (p *dynamicPools) Checkpoint() (policyapi.Checkpoint, error) {
+	return &checkpoint{
+		freeCpus:     p.freeCpus.Clone(),
+		dynamicPools: cloneDynamicPools(p.dynamicPools),
+	}, nil
+}
+
+// Restore restores the dynamic pools and their containers from a checkpoint.
+// CPUs of the restored dynamic pools are reconfigured according to their CPU
+// classes.
+func (p *dynamicPools) Restore(cp policyapi.Checkpoint) error {
+	c, ok := cp.(*checkpoint)
+	if !ok {
+		return dynamicPoolsError("invalid checkpoint of type %T", cp)
+	}
+	log.Info("restoring dynamic pools from checkpoint...")
+	p.freeCpus = c.freeCpus.Clone()
+	p.dynamicPools = cloneDynamicPools(c.dynamicPools)
+	for _, dp := range p.dynamicPools {
+		p.useCpuClass(dp)
+	}
+	return nil
+}
+
+// cloneDynamicPools returns a copy of the given dynamic pools and their containers.
+func cloneDynamicPools(dps []*DynamicPool) []*DynamicPool {
+	clones := make([]*DynamicPool, 0, len(dps))
+	for _, dp := range dps {
+		clone := *dp
+		clone.Cpus = dp.Cpus.Clone()
+		clone.Mems = dp.Mems.Clone()
+		clone.PodIDs = make(map[string][]string, len(dp.PodIDs))
+		for podID, ctrIDs := range dp.PodIDs {
+			clone.PodIDs[podID] = append([]string(nil), ctrIDs...)
+		}
+		clones = append(clones, &clone)
+	}
+	return clones
 }
 
 // dynamicPoolByContainer returns a dynamicPool that contains a container.
@@ -775,6 +955,11 @@ func (p *dynamicPools) applyDynamicPoolDef(dynamicPools *[]*DynamicPool, dpDef *
 		p.sharedDynamicPoolDef.AllocatorPriority = dpDef.AllocatorPriority
 		p.sharedDynamicPoolDef.CpuClass = dpDef.CpuClass
 		p.sharedDynamicPoolDef.Namespaces = dpDef.Namespaces
+		p.sharedDynamicPoolDef.MinCpus = dpDef.MinCpus
+		p.sharedDynamicPoolDef.MaxCpus = dpDef.MaxCpus
+		p.sharedDynamicPoolDef.MinCpusSchedule = dpDef.MinCpusSchedule
+		p.sharedDynamicPoolDef.LoadWindow = dpDef.LoadWindow
+		p.sharedDynamicPoolDef.ResizeThreshold = dpDef.ResizeThreshold
 	default:
 		// Case 3: create each user-defined dynamicPool without CPU.
 		newdp, err := p.newDynamicPool(dpDef, false)
@@ -786,8 +971,22 @@ func (p *dynamicPools) applyDynamicPoolDef(dynamicPools *[]*DynamicPool, dpDef *
 	return nil
 }
 
+// validateConfig checks dynamicPool configuration against the available CPUs.
+func (p *dynamicPools) validateConfig(dpoptions *DynamicPoolsOptions) error {
+	switch dpoptions.LoadSource {
+	case "", loadSourceContainers, loadSourceCpus:
+	default:
+		return dynamicPoolsError("invalid LoadSource %q, expected %q or %q",
+			dpoptions.LoadSource, loadSourceContainers, loadSourceCpus)
+	}
+	return validateDynamicPoolDefs(dpoptions.DynamicPoolDefs, p.allowed.Difference(p.reserved).Size())
+}
+
 // setConfig takes new dynamicPool configuration into use.
 func (p *dynamicPools) setConfig(dpoptions *DynamicPoolsOptions) error {
+	if err := p.validateConfig(dpoptions); err != nil {
+		return err
+	}
 	// Create the default reserved and shared dynamicPool
 	// definitions. Some properties of these definitions may be
 	// altered by user configuration.
@@ -912,4 +1111,5 @@ func removeString(strings []string, element string) []string {
 // Register us as a policy implementation.
 func init() {
 	policy.Register(PolicyName, PolicyDescription, CreateDynamicPoolsPolicy)
+	policy.RegisterValidator(PolicyName, validateDynamicPoolsConfig)
 }
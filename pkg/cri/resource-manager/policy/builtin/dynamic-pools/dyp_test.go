@@ -15,9 +15,21 @@
 package dyp
 
 import (
+	"reflect"
 	"testing"
+	"time"
 
+	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
 	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
+
+	"github.com/intel/cri-resource-manager/pkg/cgroups"
+	pkgcfg "github.com/intel/cri-resource-manager/pkg/config"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/cache"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/introspect"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/policy"
+	"github.com/intel/cri-resource-manager/pkg/sysfs"
+	"github.com/intel/cri-resource-manager/pkg/testutils"
+	idset "github.com/intel/goresctrl/pkg/utils"
 )
 
 func TestChangesDynamicPools(t *testing.T) {
@@ -349,3 +361,418 @@ func TestCalculatePoolCpuset(t *testing.T) {
 		})
 	}
 }
+
+func TestMinCpusAt(t *testing.T) {
+	dpDef := &DynamicPoolDef{
+		Name:    "pool1",
+		MinCpus: 1,
+		MinCpusSchedule: []*MinCpusWindow{
+			{Start: "08:00", End: "18:00", MinCpus: 4},
+			{Start: "22:00", End: "02:00", MinCpus: 2},
+		},
+	}
+	tcases := []struct {
+		name          string
+		time          string
+		expectedValue int
+	}{
+		{
+			name:          "inside a daytime window",
+			time:          "12:30",
+			expectedValue: 4,
+		},
+		{
+			name:          "end of a window is exclusive",
+			time:          "18:00",
+			expectedValue: 1,
+		},
+		{
+			name:          "inside a window spanning midnight, before midnight",
+			time:          "23:15",
+			expectedValue: 2,
+		},
+		{
+			name:          "inside a window spanning midnight, after midnight",
+			time:          "01:59",
+			expectedValue: 2,
+		},
+		{
+			name:          "outside all windows",
+			time:          "05:00",
+			expectedValue: 1,
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			now, err := time.Parse("15:04", tc.time)
+			if err != nil {
+				t.Fatalf("failed to parse time %q: %v", tc.time, err)
+			}
+			value := dpDef.minCpusAt(now)
+			if value != tc.expectedValue {
+				t.Errorf("Expected return value %v but got %v", tc.expectedValue, value)
+			}
+		})
+	}
+}
+
+func TestValidateDynamicPoolDefs(t *testing.T) {
+	tcases := []struct {
+		name          string
+		dpDefs        []*DynamicPoolDef
+		available     int
+		expectedError bool
+	}{
+		{
+			name: "valid bounds",
+			dpDefs: []*DynamicPoolDef{
+				{Name: "pool1", MinCpus: 2, MaxCpus: 4},
+				{Name: "pool2", MinCpus: 2},
+			},
+			available: 4,
+		},
+		{
+			name: "MinCpus exceeds MaxCpus",
+			dpDefs: []*DynamicPoolDef{
+				{Name: "pool1", MinCpus: 4, MaxCpus: 2},
+			},
+			available:     8,
+			expectedError: true,
+		},
+		{
+			name: "scheduled MinCpus exceeds available CPUs",
+			dpDefs: []*DynamicPoolDef{
+				{Name: "pool1", MinCpus: 2},
+				{Name: "pool2", MinCpusSchedule: []*MinCpusWindow{
+					{Start: "08:00", End: "18:00", MinCpus: 7},
+				}},
+			},
+			available:     8,
+			expectedError: true,
+		},
+		{
+			name: "invalid time of day",
+			dpDefs: []*DynamicPoolDef{
+				{Name: "pool1", MinCpusSchedule: []*MinCpusWindow{
+					{Start: "8am", End: "18:00", MinCpus: 1},
+				}},
+			},
+			available:     8,
+			expectedError: true,
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateDynamicPoolDefs(tc.dpDefs, tc.available)
+			if (err != nil) != tc.expectedError {
+				t.Errorf("Expected error %v but got %v", tc.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestValidateDynamicPoolsConfig(t *testing.T) {
+	sys, err := sysfs.DiscoverSystemAt(testutils.CreateFakeSysfs(t, 8) + "/sys")
+	if err != nil {
+		t.Fatalf("failed to discover fake system: %v", err)
+	}
+	saved := dynamicPoolsOptions
+	t.Cleanup(func() { dynamicPoolsOptions = saved })
+
+	tcases := []struct {
+		name          string
+		reserved      policy.ConstraintSet
+		options       *DynamicPoolsOptions
+		expectedError bool
+	}{
+		{
+			name:     "valid configuration",
+			reserved: policy.ConstraintSet{policy.DomainCPU: cpuset.MustParse("0")},
+			options: &DynamicPoolsOptions{
+				DynamicPoolDefs: []*DynamicPoolDef{{Name: "pool1", MinCpus: 7}},
+			},
+		},
+		{
+			name: "no reserved CPUs",
+			options: &DynamicPoolsOptions{
+				DynamicPoolDefs: []*DynamicPoolDef{{Name: "pool1", MinCpus: 1}},
+			},
+			expectedError: true,
+		},
+		{
+			name:     "MinCpus exceeds available CPUs",
+			reserved: policy.ConstraintSet{policy.DomainCPU: cpuset.MustParse("0-1")},
+			options: &DynamicPoolsOptions{
+				DynamicPoolDefs: []*DynamicPoolDef{{Name: "pool1", MinCpus: 7}},
+			},
+			expectedError: true,
+		},
+		{
+			name:     "invalid LoadSource",
+			reserved: policy.ConstraintSet{policy.DomainCPU: cpuset.MustParse("0")},
+			options: &DynamicPoolsOptions{
+				LoadSource: "pressure",
+			},
+			expectedError: true,
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			dynamicPoolsOptions = tc.options
+			err := validateDynamicPoolsConfig(&policy.BackendOptions{
+				System:   sys,
+				Reserved: tc.reserved,
+			})
+			if (err != nil) != tc.expectedError {
+				t.Errorf("Expected error %v but got %v", tc.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestUpdateLoad(t *testing.T) {
+	now := time.Now()
+	dp := &DynamicPool{
+		Def: &DynamicPoolDef{
+			Name:       "pool1",
+			LoadWindow: pkgcfg.Duration(10 * time.Second),
+		},
+	}
+	if load := dp.updateLoad(100.0, now); load != 100.0 {
+		t.Errorf("Expected the first sample 100 as load but got %v", load)
+	}
+	load := dp.updateLoad(0.0, now.Add(10*time.Second))
+	if load < 36.0 || load > 37.0 {
+		t.Errorf("Expected load decayed to 100/e but got %v", load)
+	}
+	dp.Def.LoadWindow = 0
+	if load := dp.updateLoad(50.0, now.Add(11*time.Second)); load != 50.0 {
+		t.Errorf("Expected unsmoothed load 50 but got %v", load)
+	}
+}
+
+func TestResizeHysteresis(t *testing.T) {
+	p := &dynamicPools{
+		dynamicPools: []*DynamicPool{
+			{
+				Def: &DynamicPoolDef{
+					Name: reservedDynamicPoolDefName,
+				},
+				Cpus: cpuset.NewCPUSet(0),
+			},
+			{
+				Def: &DynamicPoolDef{
+					Name:            sharedDynamicPoolDefName,
+					ResizeThreshold: 2,
+				},
+				Cpus: cpuset.NewCPUSet(1, 2, 3),
+			},
+			{
+				Def: &DynamicPoolDef{
+					Name:            "pool1",
+					ResizeThreshold: 2,
+					MinCpus:         4,
+				},
+				Cpus: cpuset.NewCPUSet(4, 5, 6),
+			},
+		},
+	}
+	tcases := []struct {
+		name          string
+		newPoolCpu    map[*DynamicPool]int
+		expectedValue bool
+	}{
+		{
+			name: "change below threshold",
+			newPoolCpu: map[*DynamicPool]int{
+				p.dynamicPools[1]: 4,
+				p.dynamicPools[2]: 3,
+			},
+			expectedValue: false,
+		},
+		{
+			name: "change reaches threshold",
+			newPoolCpu: map[*DynamicPool]int{
+				p.dynamicPools[1]: 1,
+				p.dynamicPools[2]: 5,
+			},
+			expectedValue: true,
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			value := p.isNeedReallocate(tc.newPoolCpu)
+			if value != tc.expectedValue {
+				t.Errorf("Expected return value %v but got %v", tc.expectedValue, value)
+			}
+		})
+	}
+}
+
+func TestCalculateOneContainerUtilization(t *testing.T) {
+	tcases := []struct {
+		name          string
+		cs1           cgroups.CPUStat
+		cs2           cgroups.CPUStat
+		elapsed       time.Duration
+		expectedValue float64
+	}{
+		{
+			name:          "half a CPU, no throttling",
+			cs1:           cgroups.CPUStat{UsageNs: 1000000000},
+			cs2:           cgroups.CPUStat{UsageNs: 1500000000},
+			elapsed:       time.Second,
+			expectedValue: 50.0,
+		},
+		{
+			name:          "two CPUs, no throttling",
+			cs1:           cgroups.CPUStat{UsageNs: 0},
+			cs2:           cgroups.CPUStat{UsageNs: 4000000000},
+			elapsed:       2 * time.Second,
+			expectedValue: 200.0,
+		},
+		{
+			name:          "throttled time adds to demand",
+			cs1:           cgroups.CPUStat{UsageNs: 0, NrPeriods: 10, NrThrottled: 1, ThrottledNs: 100000000},
+			cs2:           cgroups.CPUStat{UsageNs: 1000000000, NrPeriods: 20, NrThrottled: 6, ThrottledNs: 600000000},
+			elapsed:       time.Second,
+			expectedValue: 150.0,
+		},
+		{
+			name:          "counter reset",
+			cs1:           cgroups.CPUStat{UsageNs: 1000000000},
+			cs2:           cgroups.CPUStat{UsageNs: 0},
+			elapsed:       time.Second,
+			expectedValue: 0.0,
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			value := calculateOneContainerUtilization(tc.cs1, tc.cs2, tc.elapsed)
+			if value != tc.expectedValue {
+				t.Errorf("Expected return value %v but got %v", tc.expectedValue, value)
+			}
+		})
+	}
+}
+
+// createTestContainer adds a container requesting milliCPU to a new pod in the cache.
+// The runtime ID of the container is <podName>-ctr.
+func createTestContainer(t *testing.T, cch cache.Cache, podName string, milliCPU int) cache.Container {
+	podCfg := &criv1.PodSandboxConfig{
+		Metadata: &criv1.PodSandboxMetadata{Name: podName, Uid: podName, Namespace: "default"},
+		Linux:    &criv1.LinuxPodSandboxConfig{CgroupParent: "/kubepods.slice/kubepods-burstable.slice/" + podName},
+	}
+	if _, err := cch.InsertPod(podName, &criv1.RunPodSandboxRequest{Config: podCfg}, nil); err != nil {
+		t.Fatalf("failed to create pod: %v", err)
+	}
+	c, err := cch.InsertContainer(&criv1.CreateContainerRequest{
+		PodSandboxId: podName,
+		Config: &criv1.ContainerConfig{
+			Metadata: &criv1.ContainerMetadata{Name: "ctr"},
+			Linux: &criv1.LinuxContainerConfig{
+				Resources: &criv1.LinuxContainerResources{
+					CpuShares: int64(cache.MilliCPUToShares(milliCPU)),
+				},
+			},
+		},
+		SandboxConfig: podCfg,
+	})
+	if err != nil {
+		t.Fatalf("failed to create container: %v", err)
+	}
+	reply := &criv1.CreateContainerResponse{ContainerId: podName + "-ctr"}
+	if c, err = cch.UpdateContainerID(c.GetCacheID(), reply); err != nil {
+		t.Fatalf("failed to update container ID: %v", err)
+	}
+	return c
+}
+
+func TestIntrospect(t *testing.T) {
+	cch, err := cache.NewCache(cache.Options{CacheDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	reserved := &DynamicPool{
+		Def:    &DynamicPoolDef{Name: reservedDynamicPoolDefName},
+		Cpus:   cpuset.NewCPUSet(0),
+		Mems:   idset.NewIDSet(0),
+		PodIDs: map[string][]string{},
+	}
+	shared := &DynamicPool{
+		Def:        &DynamicPoolDef{Name: sharedDynamicPoolDefName},
+		Cpus:       cpuset.NewCPUSet(1, 2),
+		Mems:       idset.NewIDSet(0),
+		PodIDs:     map[string][]string{},
+		load:       0.5,
+		targetCpus: 2,
+		sizeReason: resizeReasonRequests,
+	}
+	pool := &DynamicPool{
+		Def:        &DynamicPoolDef{Name: "pool", CpuClass: "turbo"},
+		Cpus:       cpuset.NewCPUSet(3, 4, 5),
+		Mems:       idset.NewIDSet(0),
+		PodIDs:     map[string][]string{},
+		load:       2.25,
+		targetCpus: 4,
+		sizeReason: resizeReasonHysteresis,
+	}
+	p := &dynamicPools{
+		cch:          cch,
+		dynamicPools: []*DynamicPool{reserved, shared, pool},
+	}
+	assigned := map[string]*DynamicPool{
+		"pod0": pool,
+		"pod1": pool,
+		"pod2": shared,
+	}
+	for podName, dp := range assigned {
+		p.assignContainer(createTestContainer(t, cch, podName, 500), dp)
+	}
+
+	state := &introspect.State{}
+	p.Introspect(state)
+
+	if len(state.Pools) != 3 {
+		t.Errorf("expected 3 pools, got %d", len(state.Pools))
+	}
+	ipool, ok := state.Pools["pool"]
+	if !ok {
+		t.Fatalf("expected pool %q, got %v", "pool", state.Pools)
+	}
+	if ipool.CPUs != "3-5" || ipool.Memory != "0" {
+		t.Errorf("expected pool CPUs 3-5 and memory 0, got %q and %q", ipool.CPUs, ipool.Memory)
+	}
+	if !reflect.DeepEqual(ipool.Containers, []string{"pod0-ctr", "pod1-ctr"}) {
+		t.Errorf("expected sorted containers pod0-ctr, pod1-ctr in pool, got %v", ipool.Containers)
+	}
+	expectedExtra := map[string]string{
+		"cpu-class":   "turbo",
+		"load":        "2.25",
+		"target-cpus": "4",
+		"size-reason": resizeReasonHysteresis,
+	}
+	if !reflect.DeepEqual(ipool.Extra, expectedExtra) {
+		t.Errorf("expected pool details %v, got %v", expectedExtra, ipool.Extra)
+	}
+	if containers := state.Pools[reservedDynamicPoolDefName].Containers; len(containers) != 0 {
+		t.Errorf("expected no containers in the reserved pool, got %v", containers)
+	}
+
+	if len(state.Assignments) != len(assigned) {
+		t.Errorf("expected %d assignments, got %d", len(assigned), len(state.Assignments))
+	}
+	expected := &introspect.Assignment{
+		ContainerID: "pod2-ctr",
+		SharedCPUs:  "1-2",
+		CPUShare:    500,
+		Memory:      "0",
+		Pool:        sharedDynamicPoolDefName,
+	}
+	if a := state.Assignments["pod2-ctr"]; !reflect.DeepEqual(a, expected) {
+		t.Errorf("expected assignment %+v, got %+v", expected, a)
+	}
+	if a := state.Assignments["pod0-ctr"]; a == nil || a.Pool != "pool" || a.SharedCPUs != "3-5" {
+		t.Errorf("expected pod0-ctr assigned to pool with CPUs 3-5, got %+v", a)
+	}
+}
@@ -36,8 +36,20 @@ type dynamicPoolsOptionsWrapped struct {
 	ReservedPoolNamespaces []string `json:"ReservedPoolNamespaces,omitempty"`
 	// DynamicPoolDefs contains dynamicPool type definitions.
 	DynamicPoolDefs []*DynamicPoolDef `json:"DynamicPoolTypes,omitempty"`
+	// LoadSource selects how the load of dynamicPools is measured:
+	// "containers" (default) sums up cgroup CPU usage and throttling
+	// of the containers in a dynamicPool, "cpus" sums up host-wide
+	// utilization of the CPUs of a dynamicPool.
+	LoadSource string `json:"LoadSource,omitempty"`
 }
 
+const (
+	// loadSourceContainers measures dynamicPool load from container cgroups.
+	loadSourceContainers = "containers"
+	// loadSourceCpus measures dynamicPool load from /proc/stat.
+	loadSourceCpus = "cpus"
+)
+
 // DynamicPoolDef contains a dynamicPool definition.
 type DynamicPoolDef struct {
 	// Name of the dynamicPool definition.
@@ -49,6 +61,36 @@ type DynamicPoolDef struct {
 	// resizing a dynamicPool. At init, dynamicPools with highest priority
 	// CPUs are allocated first.
 	AllocatorPriority cpuallocator.CPUPriority `json:"AllocatorPriority"`
+	// MinCpus is the number of CPUs always kept in a dynamicPool,
+	// regardless of its load and the requests of its containers.
+	MinCpus int `json:"MinCpus,omitempty"`
+	// MaxCpus is the maximum number of CPUs given to a dynamicPool
+	// based on its load. Requests of containers are always honored.
+	// 0 means no limit.
+	MaxCpus int `json:"MaxCpus,omitempty"`
+	// MinCpusSchedule overrides MinCpus during given times of day.
+	// The first matching window is used.
+	MinCpusSchedule []*MinCpusWindow `json:"MinCpusSchedule,omitempty"`
+	// LoadWindow is the time constant of the exponentially
+	// weighted moving average of the dynamicPool load. 0 disables
+	// smoothing and the latest utilization sample is used as is.
+	LoadWindow pkgcfg.Duration `json:"LoadWindow,omitempty"`
+	// ResizeThreshold is the minimum change in the number of CPUs
+	// that triggers resizing a dynamicPool due to a change in its
+	// load. 0 and 1 resize on every change.
+	ResizeThreshold int `json:"ResizeThreshold,omitempty"`
+}
+
+// MinCpusWindow sets the minimum number of CPUs of a dynamicPool
+// during a time of day.
+type MinCpusWindow struct {
+	// Start of the window in local time, "HH:MM".
+	Start string `json:"Start"`
+	// End of the window in local time, "HH:MM". If End is before
+	// Start, the window spans over midnight.
+	End string `json:"End"`
+	// MinCpus is the minimum number of CPUs during the window.
+	MinCpus int `json:"MinCpus"`
 }
 
 var defaultPinCPU bool = true
@@ -76,6 +118,13 @@ func (bdef *DynamicPoolDef) DeepCopy() *DynamicPoolDef {
 	outBdef := *bdef
 	outBdef.Namespaces = make([]string, len(bdef.Namespaces))
 	copy(outBdef.Namespaces, bdef.Namespaces)
+	if bdef.MinCpusSchedule != nil {
+		outBdef.MinCpusSchedule = make([]*MinCpusWindow, len(bdef.MinCpusSchedule))
+		for i, w := range bdef.MinCpusSchedule {
+			outW := *w
+			outBdef.MinCpusSchedule[i] = &outW
+		}
+	}
 	return &outBdef
 }
 
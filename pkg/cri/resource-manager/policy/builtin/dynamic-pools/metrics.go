@@ -27,6 +27,8 @@ import (
 // Prometheus Metric descriptor indices and descriptor table
 const (
 	dynamicPoolsDesc = iota
+	dynamicPoolSizeDesc
+	dynamicPoolLoadDesc
 )
 
 var descriptors = []*prometheus.Desc{
@@ -44,6 +46,22 @@ var descriptors = []*prometheus.Desc{
 			"tot_limit_millicpu",
 		}, nil,
 	),
+	dynamicPoolSizeDesc: prometheus.NewDesc(
+		"DynamicPoolSize",
+		"Target and actual number of CPUs of a dynamic pool, and the reason for the target",
+		[]string{
+			"dynamicPool",
+			"size",
+			"reason",
+		}, nil,
+	),
+	dynamicPoolLoadDesc: prometheus.NewDesc(
+		"DynamicPoolLoad",
+		"Smoothed CPU utilization of a dynamic pool, in percents of a CPU",
+		[]string{
+			"dynamicPool",
+		}, nil,
+	),
 }
 
 // Metrics defines the dynamicPools-specific metrics from policy level.
@@ -63,6 +81,9 @@ type DynamicPoolMetrics struct {
 	ContainerNames          string
 	ContainerReqMilliCpus   int
 	ContainerLimitMilliCpus int
+	TargetCpus              int
+	SizeReason              string
+	Load                    float64
 }
 
 // DescribeMetrics generates policy-specific prometheus metrics data
@@ -83,6 +104,9 @@ func (p *dynamicPools) PollMetrics() policy.Metrics {
 		dm.PrettyName = dp.PrettyName()
 		dm.Cpus = dp.Cpus
 		dm.Mems = dp.Mems.String()
+		dm.TargetCpus = dp.targetCpus
+		dm.SizeReason = dp.sizeReason
+		dm.Load = dp.load
 		cNames := []string{}
 		// Get container names, total requested milliCPUs and total limit milliCPUs.
 		for _, containerIDs := range dp.PodIDs {
@@ -108,9 +132,9 @@ func (p *dynamicPools) CollectMetrics(m policy.Metrics) ([]prometheus.Metric, er
 	if !ok {
 		return nil, dynamicPoolsError("type mismatch in dynamicPools metrics")
 	}
-	promMetrics := make([]prometheus.Metric, len(metrics.DynamicPools))
-	for index, dm := range metrics.DynamicPools {
-		promMetrics[index] = prometheus.MustNewConstMetric(
+	promMetrics := make([]prometheus.Metric, 0, 4*len(metrics.DynamicPools))
+	for _, dm := range metrics.DynamicPools {
+		promMetrics = append(promMetrics, prometheus.MustNewConstMetric(
 			descriptors[dynamicPoolsDesc],
 			prometheus.GaugeValue,
 			float64(dm.Cpus.Size()),
@@ -121,7 +145,26 @@ func (p *dynamicPools) CollectMetrics(m policy.Metrics) ([]prometheus.Metric, er
 			dm.Mems,
 			dm.ContainerNames,
 			strconv.Itoa(dm.ContainerReqMilliCpus),
-			strconv.Itoa(dm.ContainerLimitMilliCpus))
+			strconv.Itoa(dm.ContainerLimitMilliCpus)))
+		promMetrics = append(promMetrics, prometheus.MustNewConstMetric(
+			descriptors[dynamicPoolSizeDesc],
+			prometheus.GaugeValue,
+			float64(dm.TargetCpus),
+			dm.PrettyName,
+			"target",
+			dm.SizeReason))
+		promMetrics = append(promMetrics, prometheus.MustNewConstMetric(
+			descriptors[dynamicPoolSizeDesc],
+			prometheus.GaugeValue,
+			float64(dm.Cpus.Size()),
+			dm.PrettyName,
+			"actual",
+			dm.SizeReason))
+		promMetrics = append(promMetrics, prometheus.MustNewConstMetric(
+			descriptors[dynamicPoolLoadDesc],
+			prometheus.GaugeValue,
+			dm.Load,
+			dm.PrettyName))
 	}
 	return promMetrics, nil
 }
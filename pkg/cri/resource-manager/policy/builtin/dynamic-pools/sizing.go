@@ -0,0 +1,238 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dyp
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Reasons for the target size of a dynamicPool.
+const (
+	// resizeReasonReserved: the reserved dynamicPool is never resized.
+	resizeReasonReserved = "reserved"
+	// resizeReasonRequests: the size is driven by container requests.
+	resizeReasonRequests = "requests"
+	// resizeReasonLoad: the size is driven by the load of the dynamicPool.
+	resizeReasonLoad = "load"
+	// resizeReasonMinCpus: the size is raised to MinCpus.
+	resizeReasonMinCpus = "min-cpus"
+	// resizeReasonMaxCpus: the size is capped to MaxCpus.
+	resizeReasonMaxCpus = "max-cpus"
+	// resizeReasonHysteresis: the change is too small to resize.
+	resizeReasonHysteresis = "hysteresis"
+)
+
+// parseTimeOfDay parses "HH:MM" into a duration since midnight.
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, dynamicPoolsError("invalid time of day %q: %w", s, err)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// contains returns true if the time of day of t is within the window.
+func (w *MinCpusWindow) contains(t time.Time) bool {
+	start, err := parseTimeOfDay(w.Start)
+	if err != nil {
+		return false
+	}
+	end, err := parseTimeOfDay(w.End)
+	if err != nil {
+		return false
+	}
+	tod := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second
+	if start <= end {
+		return start <= tod && tod < end
+	}
+	return start <= tod || tod < end
+}
+
+// minCpusAt returns the minimum number of CPUs of a dynamicPool at time t.
+func (dpDef *DynamicPoolDef) minCpusAt(t time.Time) int {
+	for _, w := range dpDef.MinCpusSchedule {
+		if w.contains(t) {
+			return w.MinCpus
+		}
+	}
+	return dpDef.MinCpus
+}
+
+// maxMinCpus returns the largest minimum number of CPUs of a dynamicPool
+// at any time of day.
+func (dpDef *DynamicPoolDef) maxMinCpus() int {
+	minCpus := dpDef.MinCpus
+	for _, w := range dpDef.MinCpusSchedule {
+		if w.MinCpus > minCpus {
+			minCpus = w.MinCpus
+		}
+	}
+	return minCpus
+}
+
+// validate checks the sizing parameters of a dynamicPool definition.
+func (dpDef *DynamicPoolDef) validate() error {
+	if dpDef.MinCpus < 0 || dpDef.MaxCpus < 0 || dpDef.ResizeThreshold < 0 || dpDef.LoadWindow < 0 {
+		return dynamicPoolsError("dynamicPool %s: negative MinCpus, MaxCpus, ResizeThreshold or LoadWindow", dpDef.Name)
+	}
+	for _, w := range dpDef.MinCpusSchedule {
+		if _, err := parseTimeOfDay(w.Start); err != nil {
+			return dynamicPoolsError("dynamicPool %s: MinCpusSchedule: %w", dpDef.Name, err)
+		}
+		if _, err := parseTimeOfDay(w.End); err != nil {
+			return dynamicPoolsError("dynamicPool %s: MinCpusSchedule: %w", dpDef.Name, err)
+		}
+		if w.MinCpus < 0 {
+			return dynamicPoolsError("dynamicPool %s: MinCpusSchedule: negative MinCpus", dpDef.Name)
+		}
+	}
+	if dpDef.MaxCpus > 0 && dpDef.maxMinCpus() > dpDef.MaxCpus {
+		return dynamicPoolsError("dynamicPool %s: MinCpus %d exceeds MaxCpus %d",
+			dpDef.Name, dpDef.maxMinCpus(), dpDef.MaxCpus)
+	}
+	return nil
+}
+
+// resizeThreshold returns the effective ResizeThreshold of a dynamicPool.
+func (dpDef *DynamicPoolDef) resizeThreshold() int {
+	if dpDef.ResizeThreshold < 1 {
+		return 1
+	}
+	return dpDef.ResizeThreshold
+}
+
+// validateDynamicPoolDefs checks that the dynamicPool definitions
+// are consistent and their minimum sizes fit in available CPUs.
+func validateDynamicPoolDefs(dpDefs []*DynamicPoolDef, available int) error {
+	sumMinCpus := 0
+	for _, dpDef := range dpDefs {
+		if err := dpDef.validate(); err != nil {
+			return err
+		}
+		if dpDef.Name != reservedDynamicPoolDefName {
+			sumMinCpus += dpDef.maxMinCpus()
+		}
+	}
+	if sumMinCpus > available {
+		return dynamicPoolsError("sum of MinCpus of dynamicPools (%d) exceeds available CPUs (%d)",
+			sumMinCpus, available)
+	}
+	return nil
+}
+
+// updateLoad folds a utilization sample into the exponentially weighted
+// moving average of the load of a dynamicPool and returns the average.
+func (dp *DynamicPool) updateLoad(sample float64, now time.Time) float64 {
+	window := time.Duration(dp.Def.LoadWindow)
+	if window <= 0 || dp.loadTime.IsZero() {
+		dp.load = sample
+	} else {
+		alpha := 1 - math.Exp(-float64(now.Sub(dp.loadTime))/float64(window))
+		dp.load += alpha * (sample - dp.load)
+	}
+	dp.loadTime = now
+	return dp.load
+}
+
+// resetLoad forgets the load history of a dynamicPool.
+func (dp *DynamicPool) resetLoad() {
+	dp.load = 0
+	dp.loadTime = time.Time{}
+}
+
+// requiredCpus returns the number of CPUs a dynamicPool must have
+// to satisfy the requests of its containers and its MinCpus.
+func (p *dynamicPools) requiredCpus(dp *DynamicPool, now time.Time) int {
+	required := (p.requestedMinMilliCpus(dp) + 999) / 1000
+	if minCpus := dp.Def.minCpusAt(now); minCpus > required {
+		required = minCpus
+	}
+	return required
+}
+
+// applyMaxCpus caps the number of CPUs of dynamicPools to their MaxCpus
+// and hands out the excess CPUs to other dynamicPools with containers in
+// the order of their weights. CPUs that no dynamicPool can take are left
+// unallocated.
+func (p *dynamicPools) applyMaxCpus(requestCpu map[*DynamicPool]int, weight map[*DynamicPool]float64) map[*DynamicPool]int {
+	excess := 0
+	capped := map[*DynamicPool]struct{}{}
+	for _, dp := range p.dynamicPools {
+		if dp.Def.Name == reservedDynamicPoolDefName || dp.Def.MaxCpus == 0 {
+			continue
+		}
+		maxCpus := dp.Def.MaxCpus
+		if reqCpus := (p.requestedMinMilliCpus(dp) + 999) / 1000; reqCpus > maxCpus {
+			maxCpus = reqCpus
+		}
+		if requestCpu[dp] > maxCpus {
+			excess += requestCpu[dp] - maxCpus
+			requestCpu[dp] = maxCpus
+			dp.sizeReason = resizeReasonMaxCpus
+		}
+		if requestCpu[dp] >= maxCpus {
+			capped[dp] = struct{}{}
+		}
+	}
+	if excess == 0 {
+		return requestCpu
+	}
+	receivers := []*DynamicPool{}
+	for _, dp := range p.dynamicPools {
+		if _, ok := capped[dp]; ok || dp.Def.Name == reservedDynamicPoolDefName || dp.ContainerCount() == 0 {
+			continue
+		}
+		receivers = append(receivers, dp)
+	}
+	sort.SliceStable(receivers, func(i, j int) bool {
+		return weight[receivers[i]] > weight[receivers[j]]
+	})
+	for _, dp := range receivers {
+		if excess == 0 {
+			break
+		}
+		addCpu := excess
+		if dp.Def.MaxCpus > 0 && requestCpu[dp]+addCpu > dp.Def.MaxCpus {
+			addCpu = dp.Def.MaxCpus - requestCpu[dp]
+		}
+		if addCpu <= 0 {
+			continue
+		}
+		requestCpu[dp] += addCpu
+		excess -= addCpu
+		dp.sizeReason = resizeReasonLoad
+	}
+	if excess > 0 {
+		log.Info("%d CPUs left unallocated due to MaxCpus of dynamic pools", excess)
+	}
+	return requestCpu
+}
+
+// setPoolTargets records the target number of CPUs of each dynamicPool.
+func (p *dynamicPools) setPoolTargets(requestCpu map[*DynamicPool]int) {
+	for _, dp := range p.dynamicPools {
+		if dp.Def.Name == reservedDynamicPoolDefName {
+			dp.targetCpus = dp.Cpus.Size()
+			dp.sizeReason = resizeReasonReserved
+			continue
+		}
+		dp.targetCpus = requestCpu[dp]
+		log.Debug("dynamic pool %s: target %d CPUs, actual %d CPUs, reason %s",
+			dp.PrettyName(), dp.targetCpus, dp.Cpus.Size(), dp.sizeReason)
+	}
+}
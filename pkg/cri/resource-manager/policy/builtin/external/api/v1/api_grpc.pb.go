@@ -0,0 +1,321 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.20.1
+// source: pkg/cri/resource-manager/policy/builtin/external/api/v1/api.proto
+
+package v1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// PolicyClient is the client API for Policy service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PolicyClient interface {
+	Start(ctx context.Context, in *StartRequest, opts ...grpc.CallOption) (*PolicyReply, error)
+	Sync(ctx context.Context, in *SyncRequest, opts ...grpc.CallOption) (*PolicyReply, error)
+	AllocateResources(ctx context.Context, in *ContainerRequest, opts ...grpc.CallOption) (*PolicyReply, error)
+	ReleaseResources(ctx context.Context, in *ContainerRequest, opts ...grpc.CallOption) (*PolicyReply, error)
+	UpdateResources(ctx context.Context, in *ContainerRequest, opts ...grpc.CallOption) (*PolicyReply, error)
+	Rebalance(ctx context.Context, in *RebalanceRequest, opts ...grpc.CallOption) (*PolicyReply, error)
+	HandleEvent(ctx context.Context, in *EventRequest, opts ...grpc.CallOption) (*PolicyReply, error)
+}
+
+type policyClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPolicyClient(cc grpc.ClientConnInterface) PolicyClient {
+	return &policyClient{cc}
+}
+
+func (c *policyClient) Start(ctx context.Context, in *StartRequest, opts ...grpc.CallOption) (*PolicyReply, error) {
+	out := new(PolicyReply)
+	err := c.cc.Invoke(ctx, "/v1.Policy/Start", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *policyClient) Sync(ctx context.Context, in *SyncRequest, opts ...grpc.CallOption) (*PolicyReply, error) {
+	out := new(PolicyReply)
+	err := c.cc.Invoke(ctx, "/v1.Policy/Sync", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *policyClient) AllocateResources(ctx context.Context, in *ContainerRequest, opts ...grpc.CallOption) (*PolicyReply, error) {
+	out := new(PolicyReply)
+	err := c.cc.Invoke(ctx, "/v1.Policy/AllocateResources", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *policyClient) ReleaseResources(ctx context.Context, in *ContainerRequest, opts ...grpc.CallOption) (*PolicyReply, error) {
+	out := new(PolicyReply)
+	err := c.cc.Invoke(ctx, "/v1.Policy/ReleaseResources", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *policyClient) UpdateResources(ctx context.Context, in *ContainerRequest, opts ...grpc.CallOption) (*PolicyReply, error) {
+	out := new(PolicyReply)
+	err := c.cc.Invoke(ctx, "/v1.Policy/UpdateResources", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *policyClient) Rebalance(ctx context.Context, in *RebalanceRequest, opts ...grpc.CallOption) (*PolicyReply, error) {
+	out := new(PolicyReply)
+	err := c.cc.Invoke(ctx, "/v1.Policy/Rebalance", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *policyClient) HandleEvent(ctx context.Context, in *EventRequest, opts ...grpc.CallOption) (*PolicyReply, error) {
+	out := new(PolicyReply)
+	err := c.cc.Invoke(ctx, "/v1.Policy/HandleEvent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PolicyServer is the server API for Policy service.
+// All implementations must embed UnimplementedPolicyServer
+// for forward compatibility
+type PolicyServer interface {
+	Start(context.Context, *StartRequest) (*PolicyReply, error)
+	Sync(context.Context, *SyncRequest) (*PolicyReply, error)
+	AllocateResources(context.Context, *ContainerRequest) (*PolicyReply, error)
+	ReleaseResources(context.Context, *ContainerRequest) (*PolicyReply, error)
+	UpdateResources(context.Context, *ContainerRequest) (*PolicyReply, error)
+	Rebalance(context.Context, *RebalanceRequest) (*PolicyReply, error)
+	HandleEvent(context.Context, *EventRequest) (*PolicyReply, error)
+	mustEmbedUnimplementedPolicyServer()
+}
+
+// UnimplementedPolicyServer must be embedded to have forward compatible implementations.
+type UnimplementedPolicyServer struct {
+}
+
+func (UnimplementedPolicyServer) Start(context.Context, *StartRequest) (*PolicyReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Start not implemented")
+}
+func (UnimplementedPolicyServer) Sync(context.Context, *SyncRequest) (*PolicyReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Sync not implemented")
+}
+func (UnimplementedPolicyServer) AllocateResources(context.Context, *ContainerRequest) (*PolicyReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AllocateResources not implemented")
+}
+func (UnimplementedPolicyServer) ReleaseResources(context.Context, *ContainerRequest) (*PolicyReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReleaseResources not implemented")
+}
+func (UnimplementedPolicyServer) UpdateResources(context.Context, *ContainerRequest) (*PolicyReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateResources not implemented")
+}
+func (UnimplementedPolicyServer) Rebalance(context.Context, *RebalanceRequest) (*PolicyReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Rebalance not implemented")
+}
+func (UnimplementedPolicyServer) HandleEvent(context.Context, *EventRequest) (*PolicyReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HandleEvent not implemented")
+}
+func (UnimplementedPolicyServer) mustEmbedUnimplementedPolicyServer() {}
+
+// UnsafePolicyServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PolicyServer will
+// result in compilation errors.
+type UnsafePolicyServer interface {
+	mustEmbedUnimplementedPolicyServer()
+}
+
+func RegisterPolicyServer(s grpc.ServiceRegistrar, srv PolicyServer) {
+	s.RegisterService(&Policy_ServiceDesc, srv)
+}
+
+func _Policy_Start_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PolicyServer).Start(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.Policy/Start",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PolicyServer).Start(ctx, req.(*StartRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Policy_Sync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SyncRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PolicyServer).Sync(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.Policy/Sync",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PolicyServer).Sync(ctx, req.(*SyncRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Policy_AllocateResources_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ContainerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PolicyServer).AllocateResources(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.Policy/AllocateResources",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PolicyServer).AllocateResources(ctx, req.(*ContainerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Policy_ReleaseResources_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ContainerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PolicyServer).ReleaseResources(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.Policy/ReleaseResources",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PolicyServer).ReleaseResources(ctx, req.(*ContainerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Policy_UpdateResources_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ContainerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PolicyServer).UpdateResources(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.Policy/UpdateResources",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PolicyServer).UpdateResources(ctx, req.(*ContainerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Policy_Rebalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RebalanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PolicyServer).Rebalance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.Policy/Rebalance",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PolicyServer).Rebalance(ctx, req.(*RebalanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Policy_HandleEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PolicyServer).HandleEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.Policy/HandleEvent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PolicyServer).HandleEvent(ctx, req.(*EventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Policy_ServiceDesc is the grpc.ServiceDesc for Policy service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Policy_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "v1.Policy",
+	HandlerType: (*PolicyServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Start",
+			Handler:    _Policy_Start_Handler,
+		},
+		{
+			MethodName: "Sync",
+			Handler:    _Policy_Sync_Handler,
+		},
+		{
+			MethodName: "AllocateResources",
+			Handler:    _Policy_AllocateResources_Handler,
+		},
+		{
+			MethodName: "ReleaseResources",
+			Handler:    _Policy_ReleaseResources_Handler,
+		},
+		{
+			MethodName: "UpdateResources",
+			Handler:    _Policy_UpdateResources_Handler,
+		},
+		{
+			MethodName: "Rebalance",
+			Handler:    _Policy_Rebalance_Handler,
+		},
+		{
+			MethodName: "HandleEvent",
+			Handler:    _Policy_HandleEvent_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pkg/cri/resource-manager/policy/builtin/external/api/v1/api.proto",
+}
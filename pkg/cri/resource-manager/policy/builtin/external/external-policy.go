@@ -0,0 +1,556 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package external
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/cache"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/events"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/introspect"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/policy"
+	v1 "github.com/intel/cri-resource-manager/pkg/cri/resource-manager/policy/builtin/external/api/v1"
+	logger "github.com/intel/cri-resource-manager/pkg/log"
+	system "github.com/intel/cri-resource-manager/pkg/sysfs"
+)
+
+const (
+	// PolicyName is the name used to activate this policy implementation.
+	PolicyName = "external"
+	// PolicyDescription is a short description of this policy.
+	PolicyDescription = "Out-of-process policy plugin, connected to over gRPC."
+	// PolicyPath is the path of this policy in the configuration hierarchy.
+	PolicyPath = "policy." + PolicyName
+)
+
+var (
+	// minRetryInterval is the initial interval of reconnecting to the plugin.
+	minRetryInterval = 1 * time.Second
+	// maxRetryInterval is the longest interval of reconnecting to the plugin.
+	maxRetryInterval = 1 * time.Minute
+)
+
+// external is a policy backend forwarding requests to a policy plugin.
+type external struct {
+	logger.Logger
+	options  *policy.BackendOptions // options common to all policies
+	cch      cache.Cache            // cri-resmgr cache
+	socket   string                 // plugin socket
+	conn     *grpc.ClientConn       // plugin connection
+	client   v1.PolicyClient        // plugin client
+	fallback policy.Backend         // built-in policy, once fallen back to
+	retry    time.Duration          // current interval of reconnecting
+	retryAt  time.Time              // time of next reconnection attempt
+}
+
+var _ policy.Backend = &external{}
+
+// CreateExternalPolicy creates a new policy instance.
+func CreateExternalPolicy(opts *policy.BackendOptions) policy.Backend {
+	p := &external{
+		Logger:  logger.NewLogger(PolicyName),
+		options: opts,
+		cch:     opts.Cache,
+		socket:  externalOptions.Socket,
+	}
+
+	p.Info("creating policy, using plugin at %s...", p.socket)
+
+	if err := p.connect(); err != nil {
+		p.Error("%v", err)
+	}
+
+	return p
+}
+
+// Name returns the name of this policy.
+func (p *external) Name() string {
+	return PolicyName
+}
+
+// Description returns the description for this policy.
+func (p *external) Description() string {
+	return PolicyDescription
+}
+
+// Start prepares this policy for accepting allocation/release requests.
+func (p *external) Start(add []cache.Container, del []cache.Container) error {
+	req := &v1.StartRequest{
+		System:     p.system(),
+		Containers: p.containers(),
+		Add:        toContainers(add),
+		Del:        toContainers(del),
+	}
+	_, err := p.call("Start", nil,
+		func(ctx context.Context) (*v1.PolicyReply, error) {
+			return p.client.Start(ctx, req)
+		},
+		func() (bool, error) {
+			return false, p.fallback.Start(add, del)
+		})
+	return err
+}
+
+// Sync synchronizes the active policy state.
+func (p *external) Sync(add []cache.Container, del []cache.Container) error {
+	req := &v1.SyncRequest{
+		Containers: p.containers(),
+		Add:        toContainers(add),
+		Del:        toContainers(del),
+	}
+	_, err := p.call("Sync", nil,
+		func(ctx context.Context) (*v1.PolicyReply, error) {
+			return p.client.Sync(ctx, req)
+		},
+		func() (bool, error) {
+			return false, p.fallback.Sync(add, del)
+		})
+	return err
+}
+
+// AllocateResources is a resource allocation request for this policy.
+func (p *external) AllocateResources(c cache.Container) error {
+	req := &v1.ContainerRequest{
+		Container:  toContainer(c),
+		Containers: p.containers(),
+	}
+	_, err := p.call("AllocateResources", c,
+		func(ctx context.Context) (*v1.PolicyReply, error) {
+			return p.client.AllocateResources(ctx, req)
+		},
+		func() (bool, error) {
+			return false, p.fallback.AllocateResources(c)
+		})
+	return err
+}
+
+// ReleaseResources is a resource release request for this policy.
+func (p *external) ReleaseResources(c cache.Container) error {
+	req := &v1.ContainerRequest{
+		Container:  toContainer(c),
+		Containers: p.containers(),
+	}
+	_, err := p.call("ReleaseResources", nil,
+		func(ctx context.Context) (*v1.PolicyReply, error) {
+			return p.client.ReleaseResources(ctx, req)
+		},
+		func() (bool, error) {
+			return false, p.fallback.ReleaseResources(c)
+		})
+	return err
+}
+
+// UpdateResources is a resource allocation update request for this policy.
+func (p *external) UpdateResources(c cache.Container) error {
+	req := &v1.ContainerRequest{
+		Container:  toContainer(c),
+		Containers: p.containers(),
+	}
+	_, err := p.call("UpdateResources", nil,
+		func(ctx context.Context) (*v1.PolicyReply, error) {
+			return p.client.UpdateResources(ctx, req)
+		},
+		func() (bool, error) {
+			return false, p.fallback.UpdateResources(c)
+		})
+	return err
+}
+
+// Rebalance tries to find an optimal allocation of resources for the current containers.
+func (p *external) Rebalance() (bool, error) {
+	req := &v1.RebalanceRequest{
+		Containers: p.containers(),
+	}
+	return p.call("Rebalance", nil,
+		func(ctx context.Context) (*v1.PolicyReply, error) {
+			return p.client.Rebalance(ctx, req)
+		},
+		func() (bool, error) {
+			return p.fallback.Rebalance()
+		})
+}
+
+// HandleEvent handles policy-specific events.
+func (p *external) HandleEvent(e *events.Policy) (bool, error) {
+	req := &v1.EventRequest{
+		Type:       e.Type,
+		Source:     e.Source,
+		Containers: p.containers(),
+	}
+	if e.Data != nil {
+		data, err := json.Marshal(e.Data)
+		if err != nil {
+			p.Warn("failed to marshal data of event %s: %v", e.Type, err)
+		} else {
+			req.Data = string(data)
+		}
+	}
+	return p.call("HandleEvent", nil,
+		func(ctx context.Context) (*v1.PolicyReply, error) {
+			return p.client.HandleEvent(ctx, req)
+		},
+		func() (bool, error) {
+			return p.fallback.HandleEvent(e)
+		})
+}
+
+// ExportResourceData provides resource data to export for the container.
+func (p *external) ExportResourceData(c cache.Container) map[string]string {
+	if p.fallback != nil {
+		return p.fallback.ExportResourceData(c)
+	}
+	return nil
+}
+
+// Introspect provides data for external introspection.
+func (p *external) Introspect(state *introspect.State) {
+	if p.fallback != nil {
+		p.fallback.Introspect(state)
+	}
+}
+
+// PollMetrics provides policy metrics for monitoring.
+func (p *external) PollMetrics() policy.Metrics {
+	if p.fallback != nil {
+		return p.fallback.PollMetrics()
+	}
+	return nil
+}
+
+// DescribeMetrics generates policy-specific prometheus metrics data descriptors.
+func (p *external) DescribeMetrics() []*prometheus.Desc {
+	if p.fallback != nil {
+		return p.fallback.DescribeMetrics()
+	}
+	return nil
+}
+
+// CollectMetrics generates prometheus metrics from cached/polled policy-specific metrics data.
+func (p *external) CollectMetrics(m policy.Metrics) ([]prometheus.Metric, error) {
+	if p.fallback != nil {
+		return p.fallback.CollectMetrics(m)
+	}
+	return nil, nil
+}
+
+// call invokes a plugin method, falling back to the built-in policy if the
+// plugin is unreachable. pending is the container being allocated, if any.
+func (p *external) call(method string, pending cache.Container,
+	invoke func(context.Context) (*v1.PolicyReply, error), fallback func() (bool, error)) (bool, error) {
+	if p.fallback != nil && !time.Now().Before(p.retryAt) {
+		p.reconnect()
+	}
+
+	if p.fallback == nil && p.client != nil {
+		reply, err := p.invoke(invoke)
+		if err == nil {
+			return reply.Changed, p.applyUpdates(reply.Updates)
+		}
+		if !isUnreachable(err) {
+			return false, policyError("plugin %s request failed: %v", method, err)
+		}
+		p.Error("policy plugin at %s is unreachable: %v", p.socket, err)
+	}
+
+	if p.fallback == nil {
+		if err := p.activateFallback(method == "Start", pending); err != nil {
+			return false, err
+		}
+	}
+
+	return fallback()
+}
+
+// invoke invokes a plugin method with the configured timeout.
+func (p *external) invoke(invoke func(context.Context) (*v1.PolicyReply, error)) (*v1.PolicyReply, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(externalOptions.Timeout))
+	defer cancel()
+	return invoke(ctx)
+}
+
+// isUnreachable checks if a plugin request failed because the plugin is unreachable.
+func isUnreachable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
+
+// connect sets up a connection to the plugin.
+func (p *external) connect() error {
+	conn, err := grpc.Dial(p.socket, grpc.WithInsecure(),
+		grpc.WithContextDialer(func(ctx context.Context, socket string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		}))
+	if err != nil {
+		return policyError("failed to connect to policy plugin at %s: %v", p.socket, err)
+	}
+	p.conn = conn
+	p.client = v1.NewPolicyClient(conn)
+	return nil
+}
+
+// disconnect closes the connection to the plugin.
+func (p *external) disconnect() {
+	if p.conn != nil {
+		p.conn.Close()
+		p.conn = nil
+		p.client = nil
+	}
+}
+
+// reconnect tries to switch back from the fallback policy to the plugin,
+// restarting the plugin with all existing containers. If this fails, the
+// next attempt is scheduled with an exponentially increasing interval.
+func (p *external) reconnect() {
+	p.Info("trying to reconnect to policy plugin at %s...", p.socket)
+
+	err := p.connect()
+	if err == nil {
+		req := &v1.StartRequest{
+			System:     p.system(),
+			Containers: p.containers(),
+			Add:        p.containers(),
+			Del:        []*v1.Container{},
+		}
+		var reply *v1.PolicyReply
+		reply, err = p.invoke(func(ctx context.Context) (*v1.PolicyReply, error) {
+			return p.client.Start(ctx, req)
+		})
+		if err == nil {
+			err = p.applyUpdates(reply.Updates)
+		}
+	}
+
+	if err != nil {
+		p.disconnect()
+		p.retry *= 2
+		if p.retry > maxRetryInterval {
+			p.retry = maxRetryInterval
+		}
+		p.retryAt = time.Now().Add(p.retry)
+		p.Warn("failed to reconnect to policy plugin, retrying in %v: %v", p.retry, err)
+		return
+	}
+
+	p.Info("reconnected to policy plugin, dropping fallback policy %s", p.fallback.Name())
+	p.fallback = nil
+}
+
+// activateFallback creates the built-in policy to fall back to and, unless the
+// policy is being started, starts it with all but the pending container.
+func (p *external) activateFallback(starting bool, pending cache.Container) error {
+	name := externalOptions.Fallback
+	if name == "" || name == PolicyName {
+		return policyError("invalid fallback policy %q", name)
+	}
+
+	p.Warn("falling back to built-in policy %s...", name)
+
+	fallback, err := policy.CreateBackend(name, p.options)
+	if err != nil {
+		return policyError("failed to create fallback policy: %v", err)
+	}
+	p.fallback = fallback
+	p.retry = minRetryInterval
+	p.retryAt = time.Now().Add(p.retry)
+
+	p.disconnect()
+
+	if starting {
+		return nil
+	}
+
+	add := []cache.Container{}
+	for _, c := range p.cch.GetContainers() {
+		if pending == nil || c.GetCacheID() != pending.GetCacheID() {
+			add = append(add, c)
+		}
+	}
+	if err := p.fallback.Start(add, []cache.Container{}); err != nil {
+		return policyError("failed to start fallback policy %s: %v", name, err)
+	}
+
+	return nil
+}
+
+// applyUpdates applies container resource updates from the plugin.
+func (p *external) applyUpdates(updates []*v1.ContainerUpdate) error {
+	for _, u := range updates {
+		c, ok := p.cch.LookupContainer(u.Id)
+		if !ok {
+			return policyError("plugin update for unknown container %s", u.Id)
+		}
+		r := u.Resources
+		if r == nil {
+			continue
+		}
+
+		p.Debug("%s: applying plugin update %s", c.PrettyName(), r.String())
+
+		if r.CpusetCpus != nil {
+			c.SetCpusetCpus(r.GetCpusetCpus())
+		}
+		if r.CpusetMems != nil {
+			c.SetCpusetMems(r.GetCpusetMems())
+		}
+		if r.CpuShares != nil {
+			c.SetCPUShares(r.GetCpuShares())
+		}
+		if r.CpuQuota != nil {
+			c.SetCPUQuota(r.GetCpuQuota())
+		}
+		if r.CpuPeriod != nil {
+			c.SetCPUPeriod(r.GetCpuPeriod())
+		}
+		if r.MemoryLimit != nil {
+			c.SetMemoryLimit(r.GetMemoryLimit())
+		}
+		if r.RdtClass != nil {
+			c.SetRDTClass(r.GetRdtClass())
+		}
+		if r.BlockioClass != nil {
+			c.SetBlockIOClass(r.GetBlockioClass())
+		}
+	}
+	return nil
+}
+
+// system returns the system details passed to the plugin.
+func (p *external) system() *v1.System {
+	sys := p.options.System
+	s := &v1.System{
+		Available: map[string]string{},
+		Reserved:  map[string]string{},
+	}
+	if sys != nil {
+		s.OnlineCpus = sys.CPUSet().Difference(sys.Offlined()).String()
+		s.IsolatedCpus = sys.Isolated().String()
+		for _, id := range sys.NodeIDs() {
+			node := sys.Node(id)
+			s.Nodes = append(s.Nodes, &v1.Node{
+				Id:         int64(id),
+				PackageId:  int64(node.PackageID()),
+				Cpus:       node.CPUSet().String(),
+				MemoryType: memoryTypes[node.GetMemoryType()],
+			})
+		}
+	}
+	for domain, constraint := range p.options.Available {
+		s.Available[string(domain)] = policy.ConstraintToString(constraint)
+	}
+	for domain, constraint := range p.options.Reserved {
+		s.Reserved[string(domain)] = policy.ConstraintToString(constraint)
+	}
+	return s
+}
+
+// containers returns all containers in the cache, for passing to the plugin.
+func (p *external) containers() []*v1.Container {
+	return toContainers(p.cch.GetContainers())
+}
+
+// memoryTypes are the names of memory types passed to the plugin.
+var memoryTypes = map[system.MemoryType]string{
+	system.MemoryTypeDRAM: "DRAM",
+	system.MemoryTypePMEM: "PMEM",
+	system.MemoryTypeHBM:  "HBM",
+	system.MemoryTypeCXL:  "CXL",
+}
+
+// containerStates are the names of container states passed to the plugin.
+var containerStates = map[cache.ContainerState]string{
+	cache.ContainerStateCreating: "creating",
+	cache.ContainerStateCreated:  "created",
+	cache.ContainerStateRunning:  "running",
+	cache.ContainerStateExited:   "exited",
+	cache.ContainerStateUnknown:  "unknown",
+	cache.ContainerStateStale:    "stale",
+}
+
+// toContainers converts the given containers for passing to the plugin.
+func toContainers(containers []cache.Container) []*v1.Container {
+	result := make([]*v1.Container, 0, len(containers))
+	for _, c := range containers {
+		result = append(result, toContainer(c))
+	}
+	return result
+}
+
+// toContainer converts the given container for passing to the plugin.
+func toContainer(c cache.Container) *v1.Container {
+	container := &v1.Container{
+		Id:          c.GetCacheID(),
+		ContainerId: c.GetID(),
+		PodId:       c.GetPodID(),
+		Name:        c.GetName(),
+		Namespace:   c.GetNamespace(),
+		QosClass:    string(c.GetQOSClass()),
+		State:       containerStates[c.GetState()],
+		Labels:      c.GetLabels(),
+		Annotations: c.GetAnnotations(),
+		Resources: &v1.Resources{
+			CpusetCpus:   proto.String(c.GetCpusetCpus()),
+			CpusetMems:   proto.String(c.GetCpusetMems()),
+			CpuShares:    proto.Int64(c.GetCPUShares()),
+			CpuQuota:     proto.Int64(c.GetCPUQuota()),
+			CpuPeriod:    proto.Int64(c.GetCPUPeriod()),
+			MemoryLimit:  proto.Int64(c.GetMemoryLimit()),
+			RdtClass:     proto.String(c.GetRDTClass()),
+			BlockioClass: proto.String(c.GetBlockIOClass()),
+		},
+	}
+	if pod, ok := c.GetPod(); ok {
+		container.PodName = pod.GetName()
+	}
+
+	resources := c.GetResourceRequirements()
+	if qty, ok := resources.Requests[corev1.ResourceCPU]; ok {
+		container.CpuRequest = qty.MilliValue()
+	}
+	if qty, ok := resources.Limits[corev1.ResourceCPU]; ok {
+		container.CpuLimit = qty.MilliValue()
+	}
+	if qty, ok := resources.Requests[corev1.ResourceMemory]; ok {
+		container.MemoryRequest = qty.Value()
+	}
+	if qty, ok := resources.Limits[corev1.ResourceMemory]; ok {
+		container.MemoryLimit = qty.Value()
+	}
+
+	return container
+}
+
+// policyError creates a formatted policy-specific error.
+func policyError(format string, args ...interface{}) error {
+	return fmt.Errorf(PolicyName+": "+format, args...)
+}
+
+// Register us as a policy implementation.
+func init() {
+	policy.Register(PolicyName, PolicyDescription, CreateExternalPolicy)
+}
@@ -0,0 +1,296 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package external
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+	kubetypes "k8s.io/kubernetes/pkg/kubelet/types"
+
+	pkgcfg "github.com/intel/cri-resource-manager/pkg/config"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/cache"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/policy"
+	v1 "github.com/intel/cri-resource-manager/pkg/cri/resource-manager/policy/builtin/external/api/v1"
+	_ "github.com/intel/cri-resource-manager/pkg/cri/resource-manager/policy/builtin/none"
+	"github.com/intel/cri-resource-manager/pkg/testutils"
+)
+
+// fakePlugin is a policy plugin allocating a fixed cpuset to all containers.
+type fakePlugin struct {
+	v1.UnimplementedPolicyServer
+	cpus      string
+	resources *v1.Resources
+	delay     time.Duration
+	err       error
+	request   *v1.ContainerRequest
+	started   int
+}
+
+func (f *fakePlugin) Start(ctx context.Context, req *v1.StartRequest) (*v1.PolicyReply, error) {
+	f.started++
+	return &v1.PolicyReply{}, nil
+}
+
+func (f *fakePlugin) AllocateResources(ctx context.Context, req *v1.ContainerRequest) (*v1.PolicyReply, error) {
+	f.request = req
+	time.Sleep(f.delay)
+	if f.err != nil {
+		return nil, f.err
+	}
+	resources := f.resources
+	if resources == nil {
+		resources = &v1.Resources{CpusetCpus: proto.String(f.cpus)}
+	}
+	return &v1.PolicyReply{
+		Updates: []*v1.ContainerUpdate{
+			{
+				Id:        req.Container.Id,
+				Resources: resources,
+			},
+		},
+	}, nil
+}
+
+func startFakePlugin(t *testing.T, socket string, plugin *fakePlugin) *grpc.Server {
+	lis, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("failed to listen on %s: %v", socket, err)
+	}
+	srv := grpc.NewServer()
+	v1.RegisterPolicyServer(srv, plugin)
+	go srv.Serve(lis)
+	return srv
+}
+
+func createContainer(t *testing.T, cch cache.Cache) cache.Container {
+	podReq := &criv1.RunPodSandboxRequest{
+		Config: &criv1.PodSandboxConfig{
+			Metadata: &criv1.PodSandboxMetadata{
+				Name:      "pod0",
+				Uid:       "pod0-uid",
+				Namespace: "default",
+			},
+			Labels: map[string]string{kubetypes.KubernetesPodUIDLabel: "pod0-uid"},
+			Linux:  &criv1.LinuxPodSandboxConfig{},
+		},
+	}
+	if _, err := cch.InsertPod("pod0", podReq, nil); err != nil {
+		t.Fatalf("failed to create pod: %v", err)
+	}
+	c, err := cch.InsertContainer(&criv1.CreateContainerRequest{
+		PodSandboxId: "pod0",
+		Config: &criv1.ContainerConfig{
+			Metadata: &criv1.ContainerMetadata{Name: "ctr0"},
+			Linux: &criv1.LinuxContainerConfig{
+				Resources: &criv1.LinuxContainerResources{},
+			},
+		},
+		SandboxConfig: podReq.Config,
+	})
+	if err != nil {
+		t.Fatalf("failed to create container: %v", err)
+	}
+	return c
+}
+
+func TestAllocateResources(t *testing.T) {
+	tcases := []struct {
+		name             string
+		plugin           *fakePlugin
+		expectedCpus     string
+		expectedFallback bool
+		expectedError    bool
+	}{
+		{
+			name:         "plugin allocates resources",
+			plugin:       &fakePlugin{cpus: "1-2"},
+			expectedCpus: "1-2",
+		},
+		{
+			name:          "plugin fails",
+			plugin:        &fakePlugin{cpus: "1-2", err: status.Error(codes.Internal, "failed")},
+			expectedError: true,
+		},
+		{
+			name:             "plugin times out",
+			plugin:           &fakePlugin{cpus: "1-2", delay: 500 * time.Millisecond},
+			expectedFallback: true,
+		},
+		{
+			name:             "plugin unreachable",
+			expectedFallback: true,
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			socket := filepath.Join(dir, "plugin.sock")
+			if tc.plugin != nil {
+				srv := startFakePlugin(t, socket, tc.plugin)
+				defer srv.Stop()
+			}
+
+			savedOptions := *externalOptions
+			defer func() { *externalOptions = savedOptions }()
+			externalOptions.Socket = socket
+			externalOptions.Timeout = pkgcfg.Duration(100 * time.Millisecond)
+
+			cch, err := cache.NewCache(cache.Options{CacheDir: dir})
+			testutils.VerifyError(t, err, 0, nil)
+
+			p := CreateExternalPolicy(&policy.BackendOptions{Cache: cch}).(*external)
+			testutils.VerifyError(t, p.Start([]cache.Container{}, []cache.Container{}), 0, nil)
+
+			c := createContainer(t, cch)
+			err = p.AllocateResources(c)
+			if tc.expectedError {
+				if err == nil {
+					t.Errorf("expected error, got none")
+				}
+			} else {
+				testutils.VerifyError(t, err, 0, nil)
+			}
+
+			if tc.plugin != nil && tc.plugin.request != nil {
+				if tc.plugin.request.Container.Name != "ctr0" {
+					t.Errorf("expected request for container ctr0, got %q", tc.plugin.request.Container.Name)
+				}
+				if len(tc.plugin.request.Containers) != 1 {
+					t.Errorf("expected 1 container in request, got %d", len(tc.plugin.request.Containers))
+				}
+			}
+			if cpus := c.GetCpusetCpus(); cpus != tc.expectedCpus {
+				t.Errorf("expected cpuset %q, got %q", tc.expectedCpus, cpus)
+			}
+			if fallback := p.fallback != nil; fallback != tc.expectedFallback {
+				t.Errorf("expected fallback %v, got %v", tc.expectedFallback, fallback)
+			}
+			if tc.expectedFallback && p.fallback.Name() != DefaultFallback {
+				t.Errorf("expected fallback policy %s, got %s", DefaultFallback, p.fallback.Name())
+			}
+		})
+	}
+}
+
+func TestApplyZeroUpdates(t *testing.T) {
+	dir := t.TempDir()
+	socket := filepath.Join(dir, "plugin.sock")
+	plugin := &fakePlugin{
+		resources: &v1.Resources{
+			CpusetMems: proto.String(""),
+			CpuQuota:   proto.Int64(0),
+		},
+	}
+	srv := startFakePlugin(t, socket, plugin)
+	defer srv.Stop()
+
+	savedOptions := *externalOptions
+	defer func() { *externalOptions = savedOptions }()
+	externalOptions.Socket = socket
+
+	cch, err := cache.NewCache(cache.Options{CacheDir: dir})
+	testutils.VerifyError(t, err, 0, nil)
+
+	p := CreateExternalPolicy(&policy.BackendOptions{Cache: cch}).(*external)
+	testutils.VerifyError(t, p.Start([]cache.Container{}, []cache.Container{}), 0, nil)
+
+	c := createContainer(t, cch)
+	c.SetCpusetCpus("0-3")
+	c.SetCpusetMems("0")
+	c.SetCPUQuota(100000)
+	c.SetCPUPeriod(100000)
+
+	testutils.VerifyError(t, p.AllocateResources(c), 0, nil)
+
+	if cpus := c.GetCpusetCpus(); cpus != "0-3" {
+		t.Errorf("expected absent cpuset to be left intact, got %q", cpus)
+	}
+	if mems := c.GetCpusetMems(); mems != "" {
+		t.Errorf("expected memset to be reset, got %q", mems)
+	}
+	if quota := c.GetCPUQuota(); quota != 0 {
+		t.Errorf("expected CPU quota to be reset, got %d", quota)
+	}
+	if period := c.GetCPUPeriod(); period != 100000 {
+		t.Errorf("expected absent CPU period to be left intact, got %d", period)
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	dir := t.TempDir()
+	socket := filepath.Join(dir, "plugin.sock")
+
+	savedOptions := *externalOptions
+	savedMin, savedMax := minRetryInterval, maxRetryInterval
+	defer func() {
+		*externalOptions = savedOptions
+		minRetryInterval, maxRetryInterval = savedMin, savedMax
+	}()
+	externalOptions.Socket = socket
+	externalOptions.Timeout = pkgcfg.Duration(100 * time.Millisecond)
+	minRetryInterval = 10 * time.Millisecond
+	maxRetryInterval = 20 * time.Millisecond
+
+	cch, err := cache.NewCache(cache.Options{CacheDir: dir})
+	testutils.VerifyError(t, err, 0, nil)
+
+	p := CreateExternalPolicy(&policy.BackendOptions{Cache: cch}).(*external)
+	testutils.VerifyError(t, p.Start([]cache.Container{}, []cache.Container{}), 0, nil)
+	if p.fallback == nil {
+		t.Fatalf("expected fallback with unreachable plugin")
+	}
+
+	// Reconnection attempts fail and back off while the plugin is unreachable.
+	c := createContainer(t, cch)
+	time.Sleep(minRetryInterval)
+	testutils.VerifyError(t, p.AllocateResources(c), 0, nil)
+	if p.fallback == nil {
+		t.Fatalf("expected fallback with unreachable plugin")
+	}
+	if p.retry != 2*minRetryInterval {
+		t.Errorf("expected retry interval %v, got %v", 2*minRetryInterval, p.retry)
+	}
+	time.Sleep(p.retry)
+	testutils.VerifyError(t, p.UpdateResources(c), 0, nil)
+	if p.retry != maxRetryInterval {
+		t.Errorf("expected retry interval capped at %v, got %v", maxRetryInterval, p.retry)
+	}
+
+	// Once the plugin is back, it is restarted with all containers and used.
+	plugin := &fakePlugin{cpus: "1-2"}
+	srv := startFakePlugin(t, socket, plugin)
+	defer srv.Stop()
+
+	time.Sleep(p.retry)
+	testutils.VerifyError(t, p.AllocateResources(c), 0, nil)
+	if p.fallback != nil {
+		t.Errorf("expected fallback to be dropped after reconnecting")
+	}
+	if plugin.started != 1 {
+		t.Errorf("expected plugin to be restarted once, got %d", plugin.started)
+	}
+	if cpus := c.GetCpusetCpus(); cpus != "1-2" {
+		t.Errorf("expected cpuset %q from plugin, got %q", "1-2", cpus)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package external
+
+import (
+	"time"
+
+	pkgcfg "github.com/intel/cri-resource-manager/pkg/config"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/policy"
+)
+
+const (
+	// DefaultSocket is the default socket of the policy plugin.
+	DefaultSocket = "/var/run/cri-resmgr/policy-plugin.sock"
+	// DefaultTimeout is the default timeout for requests to the policy plugin.
+	DefaultTimeout = 2 * time.Second
+	// DefaultFallback is the default built-in policy to fall back to.
+	DefaultFallback = policy.NonePolicy
+)
+
+// ExternalOptions contains configuration options specific to this policy.
+type ExternalOptions struct {
+	// Socket is the unix domain socket of the policy plugin.
+	Socket string `json:"Socket,omitempty"`
+	// Timeout is the timeout for requests to the policy plugin.
+	Timeout pkgcfg.Duration `json:"Timeout,omitempty"`
+	// Fallback is the built-in policy to use if the plugin is unreachable.
+	Fallback string `json:"Fallback,omitempty"`
+}
+
+// defaultExternalOptions returns a new ExternalOptions instance, all initialized to defaults.
+func defaultExternalOptions() interface{} {
+	return &ExternalOptions{
+		Socket:   DefaultSocket,
+		Timeout:  pkgcfg.Duration(DefaultTimeout),
+		Fallback: DefaultFallback,
+	}
+}
+
+// Our runtime configuration.
+var externalOptions = defaultExternalOptions().(*ExternalOptions)
+
+// Register us for configuration handling.
+func init() {
+	pkgcfg.Register(PolicyPath, PolicyDescription, externalOptions, defaultExternalOptions)
+}
@@ -112,6 +112,34 @@ func (pool Pool) PrettyName() string {
 
 // CreatePodpoolsPolicy creates a new policy instance.
 func CreatePodpoolsPolicy(policyOptions *policy.BackendOptions) policy.Backend {
+	log.Info("creating %s policy...", PolicyName)
+	p, err := newPodpools(policyOptions)
+	if err != nil {
+		log.Fatal("%v", err)
+	}
+	// Handle policy-specific options
+	log.Debug("creating %s configuration", PolicyName)
+	if err := p.setConfig(podpoolsOptions); err != nil {
+		log.Fatal("failed to create %s policy: %v", PolicyName, err)
+	}
+
+	pkgcfg.GetModule(PolicyPath).AddNotify(p.configNotify)
+
+	return p
+}
+
+// validatePodpoolsConfig checks the current podpools configuration against
+// the available CPUs, without creating a policy instance.
+func validatePodpoolsConfig(policyOptions *policy.BackendOptions) error {
+	p, err := newPodpools(policyOptions)
+	if err != nil {
+		return err
+	}
+	return p.setConfig(podpoolsOptions)
+}
+
+// newPodpools creates a podpools instance with the allowed and reserved CPUs set up.
+func newPodpools(policyOptions *policy.BackendOptions) (*podpools, error) {
 	p := &podpools{
 		options: policyOptions,
 		cch:     policyOptions.Cache,
@@ -126,7 +154,6 @@ func CreatePodpoolsPolicy(policyOptions *policy.BackendOptions) policy.Backend {
 		podMaxMilliCPU: make(map[string]int64),
 		cpuAllocator:   cpuallocator.NewCPUAllocator(policyOptions.System),
 	}
-	log.Info("creating %s policy...", PolicyName)
 	// Handle common policy options: AvailableResources and ReservedResources.
 	// p.allowed: CPUs available for the policy
 	if allowed, ok := policyOptions.Available[policyapi.DomainCPU]; ok {
@@ -145,24 +172,16 @@ func CreatePodpoolsPolicy(policyOptions *policy.BackendOptions) policy.Backend {
 			reserveCnt := (int(v.MilliValue()) + 999) / 1000
 			cpus, err := p.cpuAllocator.AllocateCpus(&p.allowed, reserveCnt, cpuallocator.PriorityNone)
 			if err != nil {
-				log.Fatal("failed to allocate reserved CPUs: %s", err)
+				return nil, podpoolsError("failed to allocate reserved CPUs: %s", err)
 			}
 			p.reserved = cpus
 			p.allowed = p.allowed.Union(cpus)
 		}
 	}
 	if p.reserved.IsEmpty() {
-		log.Fatal("%s cannot run without reserved CPUs that are also AvailableResources", PolicyName)
+		return nil, podpoolsError("%s cannot run without reserved CPUs that are also AvailableResources", PolicyName)
 	}
-	// Handle policy-specific options
-	log.Debug("creating %s configuration", PolicyName)
-	if err := p.setConfig(podpoolsOptions); err != nil {
-		log.Fatal("failed to create %s policy: %v", PolicyName, err)
-	}
-
-	pkgcfg.GetModule(PolicyPath).AddNotify(p.configNotify)
-
-	return p
+	return p, nil
 }
 
 // Name returns the name of this policy.
@@ -261,8 +280,94 @@ func (p *podpools) ExportResourceData(c cache.Container) map[string]string {
 }
 
 // Introspect provides data for external introspection.
-func (p *podpools) Introspect(*introspect.State) {
-	return
+func (p *podpools) Introspect(state *introspect.State) {
+	pools := make(map[string]*introspect.Pool, len(p.pools))
+	assignments := make(map[string]*introspect.Assignment)
+	for _, pool := range p.pools {
+		ipool := &introspect.Pool{
+			Name:   pool.PrettyName(),
+			CPUs:   pool.CPUs.String(),
+			Memory: pool.Mems.String(),
+			Extra: map[string]string{
+				"pool-type":  pool.Def.Name,
+				"fill-order": pool.Def.FillOrder.String(),
+				"pods":       fmt.Sprintf("%d/%d", len(pool.PodIDs), pool.Def.MaxPods),
+			},
+		}
+		for _, cIDs := range pool.PodIDs {
+			for _, cID := range cIDs {
+				c, ok := p.cch.LookupContainer(cID)
+				if !ok {
+					continue
+				}
+				ipool.Containers = append(ipool.Containers, c.GetID())
+				a := &introspect.Assignment{
+					ContainerID: c.GetID(),
+					SharedCPUs:  c.GetCpusetCpus(),
+					Memory:      c.GetCpusetMems(),
+					Pool:        ipool.Name,
+				}
+				if reqCpu, ok := c.GetResourceRequirements().Requests[corev1.ResourceCPU]; ok {
+					a.CPUShare = int(reqCpu.MilliValue())
+				}
+				assignments[a.ContainerID] = a
+			}
+		}
+		sort.Strings(ipool.Containers)
+		pools[ipool.Name] = ipool
+	}
+	state.Pools = pools
+	state.Assignments = assignments
+	state.Reserved = p.reserved.String()
+}
+
+// checkpoint is a checkpoint of the pools and their pods.
+type checkpoint struct {
+	pools          []*Pool
+	podMaxMilliCPU map[string]int64
+}
+
+// Checkpoint takes a checkpoint of the current pools and their pods.
+func (p *podpools) Checkpoint() (policy.Checkpoint, error) {
+	cp := &checkpoint{
+		pools:          clonePools(p.pools),
+		podMaxMilliCPU: make(map[string]int64, len(p.podMaxMilliCPU)),
+	}
+	for podID, mcpu := range p.podMaxMilliCPU {
+		cp.podMaxMilliCPU[podID] = mcpu
+	}
+	return cp, nil
+}
+
+// Restore restores the pools and their pods from a checkpoint.
+func (p *podpools) Restore(cp policy.Checkpoint) error {
+	c, ok := cp.(*checkpoint)
+	if !ok {
+		return podpoolsError("invalid checkpoint of type %T", cp)
+	}
+	log.Info("restoring pools from checkpoint...")
+	p.pools = clonePools(c.pools)
+	p.podMaxMilliCPU = make(map[string]int64, len(c.podMaxMilliCPU))
+	for podID, mcpu := range c.podMaxMilliCPU {
+		p.podMaxMilliCPU[podID] = mcpu
+	}
+	return nil
+}
+
+// clonePools returns a copy of the given pools and their pods.
+func clonePools(pools []*Pool) []*Pool {
+	clones := make([]*Pool, 0, len(pools))
+	for _, pool := range pools {
+		clone := *pool
+		clone.CPUs = pool.CPUs.Clone()
+		clone.Mems = pool.Mems.Clone()
+		clone.PodIDs = make(map[string][]string, len(pool.PodIDs))
+		for podID, contIDs := range pool.PodIDs {
+			clone.PodIDs[podID] = append([]string(nil), contIDs...)
+		}
+		clones = append(clones, &clone)
+	}
+	return clones
 }
 
 // allocatedPool returns a pool already allocated for a pod.
@@ -759,4 +864,5 @@ func removeString(strings []string, element string) []string {
 // Register us as a policy implementation.
 func init() {
 	policy.Register(PolicyName, PolicyDescription, CreatePodpoolsPolicy)
+	policy.RegisterValidator(PolicyName, validatePodpoolsConfig)
 }
@@ -16,12 +16,17 @@ package podpools
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 
+	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
 	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
 
 	"github.com/intel/cri-resource-manager/pkg/cpuallocator"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/cache"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/introspect"
+	idset "github.com/intel/goresctrl/pkg/utils"
 )
 
 func validateError(t *testing.T, expectedError string, err error) bool {
@@ -578,3 +583,123 @@ func TestParseInstancesCPUs(t *testing.T) {
 		})
 	}
 }
+
+// createTestContainer adds a container requesting milliCPU to a (new) pod in the cache.
+// The runtime ID of the container is <podName>-<name>.
+func createTestContainer(t *testing.T, cch cache.Cache, podName, name string, milliCPU int) cache.Container {
+	podCfg := &criv1.PodSandboxConfig{
+		Metadata: &criv1.PodSandboxMetadata{Name: podName, Uid: podName, Namespace: "default"},
+		Linux:    &criv1.LinuxPodSandboxConfig{CgroupParent: "/kubepods.slice/kubepods-burstable.slice/" + podName},
+	}
+	if _, ok := cch.LookupPod(podName); !ok {
+		if _, err := cch.InsertPod(podName, &criv1.RunPodSandboxRequest{Config: podCfg}, nil); err != nil {
+			t.Fatalf("failed to create pod: %v", err)
+		}
+	}
+	c, err := cch.InsertContainer(&criv1.CreateContainerRequest{
+		PodSandboxId: podName,
+		Config: &criv1.ContainerConfig{
+			Metadata: &criv1.ContainerMetadata{Name: name},
+			Linux: &criv1.LinuxContainerConfig{
+				Resources: &criv1.LinuxContainerResources{
+					CpuShares: int64(cache.MilliCPUToShares(milliCPU)),
+				},
+			},
+		},
+		SandboxConfig: podCfg,
+	})
+	if err != nil {
+		t.Fatalf("failed to create container: %v", err)
+	}
+	reply := &criv1.CreateContainerResponse{ContainerId: podName + "-" + name}
+	if c, err = cch.UpdateContainerID(c.GetCacheID(), reply); err != nil {
+		t.Fatalf("failed to update container ID: %v", err)
+	}
+	return c
+}
+
+func TestIntrospect(t *testing.T) {
+	cch, err := cache.NewCache(cache.Options{CacheDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	dualcpu := &PoolDef{Name: "dualcpu", MaxPods: 2, FillOrder: FillPacked}
+	pools := []*Pool{
+		{
+			Def:    &PoolDef{Name: reservedPoolDefName, MaxPods: 0},
+			CPUs:   cpuset.NewCPUSet(0),
+			Mems:   idset.NewIDSet(0),
+			PodIDs: map[string][]string{},
+		},
+		{
+			Def:    &PoolDef{Name: defaultPoolDefName, MaxPods: 0},
+			CPUs:   cpuset.NewCPUSet(1),
+			Mems:   idset.NewIDSet(0),
+			PodIDs: map[string][]string{},
+		},
+		{
+			Def:    dualcpu,
+			CPUs:   cpuset.NewCPUSet(2, 3),
+			Mems:   idset.NewIDSet(0),
+			PodIDs: map[string][]string{},
+		},
+		{
+			Def:      dualcpu,
+			Instance: 1,
+			CPUs:     cpuset.NewCPUSet(4, 5),
+			Mems:     idset.NewIDSet(0),
+			PodIDs:   map[string][]string{},
+		},
+	}
+	p := &podpools{
+		cch:       cch,
+		pools:     pools,
+		ppoptions: PodpoolsOptions{PinCPU: true, PinMemory: true},
+	}
+	p.assignContainer(createTestContainer(t, cch, "pod0", "c0", 500), pools[2])
+	p.assignContainer(createTestContainer(t, cch, "pod0", "c1", 250), pools[2])
+	p.assignContainer(createTestContainer(t, cch, "pod1", "c0", 1000), pools[2])
+	p.assignContainer(createTestContainer(t, cch, "pod2", "c0", 100), pools[1])
+
+	state := &introspect.State{}
+	p.Introspect(state)
+
+	if len(state.Pools) != len(pools) {
+		t.Errorf("expected %d pools, got %d", len(pools), len(state.Pools))
+	}
+	ipool, ok := state.Pools["dualcpu[0]"]
+	if !ok {
+		t.Fatalf("expected pool dualcpu[0], got %v", state.Pools)
+	}
+	if !reflect.DeepEqual(ipool.Containers, []string{"pod0-c0", "pod0-c1", "pod1-c0"}) {
+		t.Errorf("expected sorted containers of pod0 and pod1 in dualcpu[0], got %v", ipool.Containers)
+	}
+	expectedExtra := map[string]string{
+		"pool-type":  "dualcpu",
+		"fill-order": FillPacked.String(),
+		"pods":       "2/2",
+	}
+	if ipool.CPUs != "2-3" || !reflect.DeepEqual(ipool.Extra, expectedExtra) {
+		t.Errorf("expected dualcpu[0] CPUs 2-3 and details %v, got %q and %v", expectedExtra, ipool.CPUs, ipool.Extra)
+	}
+	if ipool := state.Pools["dualcpu[1]"]; ipool == nil || len(ipool.Containers) != 0 || ipool.Extra["pods"] != "0/2" {
+		t.Errorf("expected empty dualcpu[1] pool, got %+v", ipool)
+	}
+
+	if len(state.Assignments) != 4 {
+		t.Errorf("expected 4 assignments, got %d", len(state.Assignments))
+	}
+	expected := &introspect.Assignment{
+		ContainerID: "pod0-c1",
+		SharedCPUs:  "2-3",
+		CPUShare:    250,
+		Memory:      "0",
+		Pool:        "dualcpu[0]",
+	}
+	if a := state.Assignments["pod0-c1"]; !reflect.DeepEqual(a, expected) {
+		t.Errorf("expected assignment %+v, got %+v", expected, a)
+	}
+	if a := state.Assignments["pod2-c0"]; a == nil || a.Pool != defaultPoolDefName+"[0]" || a.SharedCPUs != "1" {
+		t.Errorf("expected pod2-c0 assigned to the default pool, got %+v", a)
+	}
+}
@@ -17,6 +17,7 @@ package staticplus
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strconv"
 
 	corev1 "k8s.io/api/core/v1"
@@ -57,6 +58,18 @@ type Assignment struct {
 // Allocations track all resources allocations by the static+ policy.
 type Allocations map[string]*Assignment
 
+// clone returns a copy of the allocations.
+func (a Allocations) clone() Allocations {
+	o := make(Allocations, len(a))
+	for id, assignment := range a {
+		o[id] = &Assignment{
+			exclusive: assignment.exclusive.Clone(),
+			shared:    assignment.shared,
+		}
+	}
+	return o
+}
+
 // static-plus policy runtime state.
 type staticplus struct {
 	logger.Logger
@@ -74,6 +87,9 @@ type staticplus struct {
 // Make sure staticplus implements the policy backend interface.
 var _ policy.Backend = &staticplus{}
 
+// Make sure staticplus can checkpoint and restore its allocations.
+var _ policy.Checkpointer = &staticplus{}
+
 // CreateStaticPlusPolicy creates a new policy instance.
 func CreateStaticPlusPolicy(opts *policy.BackendOptions) policy.Backend {
 	p := &staticplus{
@@ -211,8 +227,50 @@ func (p *staticplus) ExportResourceData(c cache.Container) map[string]string {
 }
 
 // Introspect provides data for external introspection.
-func (p *staticplus) Introspect(*introspect.State) {
-	return
+func (p *staticplus) Introspect(state *introspect.State) {
+	reserved := &introspect.Pool{Name: "reserved", CPUs: p.reserved.String()}
+	shared := &introspect.Pool{Name: "shared", CPUs: p.shared.String()}
+	isolated := &introspect.Pool{Name: "isolated"}
+	isolatedCpus := p.isolated
+
+	assignments := make(map[string]*introspect.Assignment, len(p.allocations))
+	for id, ca := range p.allocations {
+		c, ok := p.cache.LookupContainer(id)
+		if !ok {
+			continue
+		}
+		a := &introspect.Assignment{
+			ContainerID:   c.GetID(),
+			CPUShare:      ca.shared,
+			ExclusiveCPUs: ca.exclusive.String(),
+			Memory:        c.GetCpusetMems(),
+		}
+		pool := shared
+		switch {
+		case c.GetNamespace() == metav1.NamespaceSystem:
+			pool = reserved
+			a.SharedCPUs = reserved.CPUs
+		case !ca.exclusive.Intersection(p.sys.Isolated()).IsEmpty():
+			pool = isolated
+			isolatedCpus = isolatedCpus.Union(ca.exclusive)
+		}
+		if pool != reserved && (ca.shared != 0 || ca.exclusive.IsEmpty()) {
+			a.SharedCPUs = shared.CPUs
+		}
+		a.Pool = pool.Name
+		pool.Containers = append(pool.Containers, a.ContainerID)
+		assignments[a.ContainerID] = a
+	}
+	isolated.CPUs = isolatedCpus.String()
+	isolated.Extra = map[string]string{"free-cpus": p.isolated.String()}
+
+	state.Pools = make(map[string]*introspect.Pool, 3)
+	for _, pool := range []*introspect.Pool{reserved, shared, isolated} {
+		sort.Strings(pool.Containers)
+		state.Pools[pool.Name] = pool
+	}
+	state.Assignments = assignments
+	state.Reserved = reserved.CPUs
 }
 
 // DescribeMetrics generates policy-specific prometheus metrics data descriptors.
@@ -230,6 +288,42 @@ func (p *staticplus) CollectMetrics(policy.Metrics) ([]prometheus.Metric, error)
 	return nil, nil
 }
 
+// checkpoint is a checkpoint of our pools and allocations.
+type checkpoint struct {
+	isolated    cpuset.CPUSet
+	shared      cpuset.CPUSet
+	allocations Allocations
+}
+
+// Checkpoint takes a checkpoint of the current pools and allocations.
+func (p *staticplus) Checkpoint() (policy.Checkpoint, error) {
+	return &checkpoint{
+		isolated:    p.isolated.Clone(),
+		shared:      p.shared.Clone(),
+		allocations: p.allocations.clone(),
+	}, nil
+}
+
+// Restore restores the pools and allocations from a checkpoint.
+func (p *staticplus) Restore(cp policy.Checkpoint) error {
+	c, ok := cp.(*checkpoint)
+	if !ok {
+		return policyError("invalid checkpoint of type %T", cp)
+	}
+
+	p.Info("restoring allocations from checkpoint...")
+
+	p.isolated = c.isolated.Clone()
+	p.shared = c.shared.Clone()
+	p.allocations = c.allocations.clone()
+
+	p.cache.SetPolicyEntry(keySharedPool, p.shared)
+	p.cache.SetPolicyEntry(keyAllocations,
+		cache.Cachable(&cachedAllocations{a: p.allocations}))
+
+	return nil
+}
+
 // policyError creates a formatted policy-specific error.
 func policyError(format string, args ...interface{}) error {
 	return fmt.Errorf(PolicyName+": "+format, args...)
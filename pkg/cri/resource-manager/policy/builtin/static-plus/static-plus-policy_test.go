@@ -0,0 +1,175 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package staticplus
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
+
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/cache"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/introspect"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/policy"
+	"github.com/intel/cri-resource-manager/pkg/sysfs"
+	"github.com/intel/cri-resource-manager/pkg/testutils"
+)
+
+// createTestContainer adds a pod with a single container requesting milliCPU to the cache.
+// The runtime ID of the container is <podName>-ctr.
+func createTestContainer(t *testing.T, cch cache.Cache, podName, namespace string, milliCPU int) cache.Container {
+	podCfg := &criv1.PodSandboxConfig{
+		Metadata: &criv1.PodSandboxMetadata{Name: podName, Uid: podName, Namespace: namespace},
+		Linux:    &criv1.LinuxPodSandboxConfig{CgroupParent: "/kubepods.slice/kubepods-burstable.slice/" + podName},
+	}
+	if _, err := cch.InsertPod(podName, &criv1.RunPodSandboxRequest{Config: podCfg}, nil); err != nil {
+		t.Fatalf("failed to create pod: %v", err)
+	}
+	c, err := cch.InsertContainer(&criv1.CreateContainerRequest{
+		PodSandboxId: podName,
+		Config: &criv1.ContainerConfig{
+			Metadata: &criv1.ContainerMetadata{Name: "ctr"},
+			Linux: &criv1.LinuxContainerConfig{
+				Resources: &criv1.LinuxContainerResources{
+					CpuShares: int64(cache.MilliCPUToShares(milliCPU)),
+				},
+			},
+		},
+		SandboxConfig: podCfg,
+	})
+	if err != nil {
+		t.Fatalf("failed to create container: %v", err)
+	}
+	reply := &criv1.CreateContainerResponse{ContainerId: podName + "-ctr"}
+	if c, err = cch.UpdateContainerID(c.GetCacheID(), reply); err != nil {
+		t.Fatalf("failed to update container ID: %v", err)
+	}
+	return c
+}
+
+func TestIntrospect(t *testing.T) {
+	root := testutils.CreateFakeSysfs(t, 8)
+	isolated := filepath.Join(root, "sys/devices/system/cpu/isolated")
+	if err := os.WriteFile(isolated, []byte("6-7\n"), 0644); err != nil {
+		t.Fatalf("failed to isolate CPUs: %v", err)
+	}
+	sys, err := sysfs.DiscoverSystemAt(filepath.Join(root, "sys"))
+	if err != nil {
+		t.Fatalf("failed to discover fake system: %v", err)
+	}
+	cch, err := cache.NewCache(cache.Options{CacheDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	p := CreateStaticPlusPolicy(&policy.BackendOptions{
+		System: sys,
+		Cache:  cch,
+		Reserved: policy.ConstraintSet{
+			policy.DomainCPU: cpuset.NewCPUSet(0),
+		},
+	}).(*staticplus)
+	if err := p.Start(nil, nil); err != nil {
+		t.Fatalf("failed to start policy: %v", err)
+	}
+
+	ctrs := []cache.Container{
+		createTestContainer(t, cch, "system", "kube-system", 100),
+		createTestContainer(t, cch, "isolated", "default", 2000),
+		createTestContainer(t, cch, "mixed", "default", 1500),
+		createTestContainer(t, cch, "shared", "default", 250),
+	}
+	for _, c := range ctrs {
+		if err := p.AllocateResources(c); err != nil {
+			t.Fatalf("failed to allocate resources for %s: %v", c.PrettyName(), err)
+		}
+	}
+
+	state := &introspect.State{}
+	p.Introspect(state)
+
+	mixedCpus := p.allocations[ctrs[2].GetCacheID()].exclusive
+	if mixedCpus.Size() != 1 || !mixedCpus.Intersection(cpuset.NewCPUSet(0, 6, 7)).IsEmpty() {
+		t.Fatalf("expected a single exclusive CPU sliced off the shared pool, got %s", mixedCpus)
+	}
+	sharedCpus := cpuset.NewCPUSet(1, 2, 3, 4, 5).Difference(mixedCpus)
+
+	expectedPools := map[string]*introspect.Pool{
+		"reserved": {
+			Name:       "reserved",
+			CPUs:       "0",
+			Containers: []string{"system-ctr"},
+		},
+		"shared": {
+			Name:       "shared",
+			CPUs:       sharedCpus.String(),
+			Containers: []string{"mixed-ctr", "shared-ctr"},
+		},
+		"isolated": {
+			Name:       "isolated",
+			CPUs:       "6-7",
+			Containers: []string{"isolated-ctr"},
+			Extra:      map[string]string{"free-cpus": ""},
+		},
+	}
+	if !reflect.DeepEqual(state.Pools, expectedPools) {
+		t.Errorf("expected pools %v, got %v", expectedPools, state.Pools)
+	}
+
+	expectedAssignments := map[string]*introspect.Assignment{
+		"system-ctr": {
+			ContainerID: "system-ctr",
+			SharedCPUs:  "0",
+			CPUShare:    100,
+			Pool:        "reserved",
+		},
+		"isolated-ctr": {
+			ContainerID:   "isolated-ctr",
+			ExclusiveCPUs: "6-7",
+			Pool:          "isolated",
+		},
+		"mixed-ctr": {
+			ContainerID:   "mixed-ctr",
+			SharedCPUs:    sharedCpus.String(),
+			CPUShare:      500,
+			ExclusiveCPUs: mixedCpus.String(),
+			Pool:          "shared",
+		},
+		"shared-ctr": {
+			ContainerID: "shared-ctr",
+			SharedCPUs:  sharedCpus.String(),
+			CPUShare:    250,
+			Pool:        "shared",
+		},
+	}
+	if !reflect.DeepEqual(state.Assignments, expectedAssignments) {
+		t.Errorf("expected assignments %v, got %v", expectedAssignments, state.Assignments)
+	}
+
+	// released isolated CPUs are reported free
+	if err := p.ReleaseResources(ctrs[1]); err != nil {
+		t.Fatalf("failed to release resources of %s: %v", ctrs[1].PrettyName(), err)
+	}
+	state = &introspect.State{}
+	p.Introspect(state)
+	if pool := state.Pools["isolated"]; pool.CPUs != "6-7" || pool.Extra["free-cpus"] != "6-7" || len(pool.Containers) != 0 {
+		t.Errorf("expected free isolated pool with CPUs 6-7, got %+v", *pool)
+	}
+	if _, ok := state.Assignments["isolated-ctr"]; ok {
+		t.Errorf("expected no assignment for released container")
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"math/rand"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -75,6 +76,7 @@ type stp struct {
 }
 
 var _ policy.Backend = &stp{}
+var _ policy.Checkpointer = &stp{}
 
 //
 // Policy backend implementation
@@ -248,8 +250,64 @@ func (stp *stp) ExportResourceData(c cache.Container) map[string]string {
 }
 
 // Introspect provides data for external introspection.
-func (stp *stp) Introspect(*introspect.State) {
-	return
+func (stp *stp) Introspect(state *introspect.State) {
+	pools := make(map[string]*introspect.Pool)
+	assignments := make(map[string]*introspect.Assignment)
+	if stp.conf == nil {
+		state.Pools = pools
+		state.Assignments = assignments
+		return
+	}
+
+	for name, pc := range stp.conf.Pools {
+		pool := &introspect.Pool{
+			Name:  name,
+			CPUs:  pc.cpuSet(),
+			Extra: map[string]string{"exclusive": strconv.FormatBool(pc.Exclusive)},
+		}
+		for i, cl := range pc.CPULists {
+			list := &introspect.Pool{
+				Name:   fmt.Sprintf("%s[%d]", name, i),
+				CPUs:   cl.Cpuset,
+				Parent: name,
+				Extra:  map[string]string{"socket": strconv.FormatUint(cl.Socket, 10)},
+			}
+			for _, id := range cl.getContainers() {
+				if c, ok := stp.state.LookupContainer(id); ok {
+					list.Containers = append(list.Containers, c.GetID())
+				}
+			}
+			sort.Strings(list.Containers)
+			pool.Children = append(pool.Children, list.Name)
+			pool.Containers = append(pool.Containers, list.Containers...)
+			pools[list.Name] = list
+		}
+		sort.Strings(pool.Containers)
+		pools[pool.Name] = pool
+	}
+
+	for id, cs := range *stp.getContainerRegistry() {
+		c, ok := stp.state.LookupContainer(id)
+		if !ok {
+			continue
+		}
+		a := &introspect.Assignment{
+			ContainerID: c.GetID(),
+			Memory:      c.GetCpusetMems(),
+			Pool:        cs.Pool,
+			Extra:       map[string]string{"no-affinity": strconv.FormatBool(cs.NoAffinity)},
+		}
+		cpus, _ := c.GetEnv(CmkEnvAssigned)
+		if pc, ok := stp.conf.Pools[cs.Pool]; ok && pc.Exclusive {
+			a.ExclusiveCPUs = cpus
+		} else {
+			a.SharedCPUs = cpus
+		}
+		assignments[a.ContainerID] = a
+	}
+
+	state.Pools = pools
+	state.Assignments = assignments
 }
 
 // DescribeMetrics generates policy-specific prometheus metrics data descriptors.
@@ -267,6 +325,52 @@ func (p *stp) CollectMetrics(policy.Metrics) ([]prometheus.Metric, error) {
 	return nil, nil
 }
 
+// checkpoint is a checkpoint of our container registry and cpu list usage.
+type checkpoint struct {
+	registry stpContainerCache
+	cpuLists map[string][]map[string]struct{}
+}
+
+// Checkpoint takes a checkpoint of the current container registry and cpu list usage.
+func (stp *stp) Checkpoint() (policy.Checkpoint, error) {
+	cp := &checkpoint{
+		registry: stp.getContainerRegistry().clone(),
+		cpuLists: make(map[string][]map[string]struct{}),
+	}
+	for name, pool := range stp.conf.Pools {
+		for _, cl := range pool.CPULists {
+			cp.cpuLists[name] = append(cp.cpuLists[name], copyContainerSet(cl.containers))
+		}
+	}
+	return cp, nil
+}
+
+// Restore restores the container registry and cpu list usage from a checkpoint.
+func (stp *stp) Restore(cp policy.Checkpoint) error {
+	c, ok := cp.(*checkpoint)
+	if !ok {
+		return stpError("invalid checkpoint of type %T", cp)
+	}
+
+	stp.Info("restoring container registry from checkpoint...")
+
+	for name, pool := range stp.conf.Pools {
+		if len(c.cpuLists[name]) != len(pool.CPULists) {
+			return stpError("failed to restore checkpoint, cpu lists of pool %q changed", name)
+		}
+	}
+	for name, pool := range stp.conf.Pools {
+		for i, cl := range pool.CPULists {
+			cl.containers = copyContainerSet(c.cpuLists[name][i])
+		}
+	}
+
+	ccr := c.registry.clone()
+	stp.setContainerRegistry(&ccr)
+
+	return nil
+}
+
 func (stp *stp) configNotify(event pkgcfg.Event, source pkgcfg.Source) error {
 	stp.Info("configuration %s", event)
 
@@ -280,7 +384,26 @@ func (stp *stp) configNotify(event pkgcfg.Event, source pkgcfg.Source) error {
 }
 
 func (stp *stp) setConfig(cfg *config) error {
-	// Read legacy pools configuration if the given config has no pools configured
+	stp.readLegacyConfig(cfg)
+
+	if err := stp.verifyConfig(cfg); err != nil {
+		return err
+	}
+
+	stp.conf = cfg
+	stp.Debug("policy configuration:\n%s", utils.DumpJSON(stp.conf))
+
+	stp.nodeUpdater.update(*stp.conf)
+
+	return nil
+}
+
+//
+// Helper functions for STP policy backend
+//
+
+// readLegacyConfig reads legacy pools configuration if the given config has no pools configured.
+func (stp *stp) readLegacyConfig(cfg *config) {
 	if cfg.Pools == nil || len(cfg.Pools) == 0 {
 		if len(cfg.ConfDirPath) > 0 {
 			stp.Debug("Reading legacy configuration directory tree %q", cfg.ConfDirPath)
@@ -305,23 +428,34 @@ func (stp *stp) setConfig(cfg *config) error {
 			}
 		}
 	}
+}
 
-	if err := stp.verifyConfig(cfg); err != nil {
-		return err
-	}
-
-	stp.conf = cfg
-	stp.Debug("policy configuration:\n%s", utils.DumpJSON(stp.conf))
-
-	stp.nodeUpdater.update(*stp.conf)
+// validateStpConfig checks the static-pools configuration without creating the policy.
+func validateStpConfig(opts *policy.BackendOptions) error {
+	cfg := *conf
+	stp := &stp{Logger: logger.NewLogger(PolicyName)}
+	stp.readLegacyConfig(&cfg)
+	return validatePools(cfg.Pools)
+}
 
+// validatePools checks that some pools are configured and their cpu lists look like cpusets.
+func validatePools(p pools) error {
+	if len(p) == 0 {
+		return stpError("invalid config, no pools configured")
+	}
+	for name, pool := range p {
+		for _, cl := range pool.CPULists {
+			if cl == nil {
+				return stpError("invalid config, pool %q has an empty cpu list", name)
+			}
+			if err := validateCPUList(cl.Cpuset); err != nil {
+				return stpError("invalid config, pool %q: %v", name, err)
+			}
+		}
+	}
 	return nil
 }
 
-//
-// Helper functions for STP policy backend
-//
-
 func stpError(format string, args ...interface{}) error {
 	return fmt.Errorf(PolicyName+": "+format, args...)
 }
@@ -343,9 +477,12 @@ func (stp *stp) initializeState() error {
 // Verify configuration against the existing set of containers
 func (stp *stp) verifyConfig(cfg *config) error {
 	//  Sanity check for config
-	if cfg == nil || cfg.Pools == nil || len(cfg.Pools) == 0 {
+	if cfg == nil {
 		return stpError("invalid config, no pools configured")
 	}
+	if err := validatePools(cfg.Pools); err != nil {
+		return err
+	}
 
 	// Loop through all existing containers
 	ccr := stp.getContainerRegistry()
@@ -587,6 +724,28 @@ type stpContainerStatus struct {
 // stpContainerCache contains STP-specific data of containers
 type stpContainerCache map[string]stpContainerStatus
 
+// clone returns a copy of the container registry.
+func (c *stpContainerCache) clone() stpContainerCache {
+	o := make(stpContainerCache, len(*c))
+	for id, cs := range *c {
+		cs.Cpusets = append([]string(nil), cs.Cpusets...)
+		o[id] = cs
+	}
+	return o
+}
+
+// copyContainerSet returns a copy of the given set of container IDs.
+func copyContainerSet(containers map[string]struct{}) map[string]struct{} {
+	if containers == nil {
+		return nil
+	}
+	o := make(map[string]struct{}, len(containers))
+	for id := range containers {
+		o[id] = struct{}{}
+	}
+	return o
+}
+
 // Set the value of cached cachableContainerRegistry object
 func (c *stpContainerCache) Set(value interface{}) {
 	switch value.(type) {
@@ -622,4 +781,5 @@ func (stp *stp) setContainerRegistry(ccr *stpContainerCache) {
 // Register us as a policy implementation.
 func init() {
 	policy.Register(PolicyName, PolicyDescription, CreateStpPolicy)
+	policy.RegisterValidator(PolicyName, validateStpConfig)
 }
@@ -22,6 +22,8 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/cache"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/introspect"
 	logger "github.com/intel/cri-resource-manager/pkg/log"
 )
 
@@ -91,3 +93,112 @@ func TestCachableData(t *testing.T) {
 		t.Errorf("Exptected %v but got %v", *ccr, *ccr2)
 	}
 }
+
+func TestIntrospect(t *testing.T) {
+	cch, err := cache.NewCache(cache.Options{CacheDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	stp := &stp{
+		Logger: logger.NewLogger(PolicyName + "-test"),
+		state:  cch,
+		conf: &config{
+			Pools: pools{
+				"exclusive": poolConfig{
+					Exclusive: true,
+					CPULists: []*cpuList{
+						{Socket: 0, Cpuset: "2"},
+						{Socket: 1, Cpuset: "3"},
+					},
+				},
+				"shared": poolConfig{
+					CPULists: []*cpuList{
+						{Socket: 0, Cpuset: "0-1"},
+					},
+				},
+			},
+		},
+	}
+	stp.setContainerRegistry(&stpContainerCache{})
+
+	state := &introspect.State{}
+	stp.Introspect(state)
+
+	expected := map[string]*introspect.Pool{
+		"exclusive": {
+			Name:     "exclusive",
+			CPUs:     "2,3",
+			Children: []string{"exclusive[0]", "exclusive[1]"},
+			Extra:    map[string]string{"exclusive": "true"},
+		},
+		"exclusive[0]": {
+			Name:   "exclusive[0]",
+			CPUs:   "2",
+			Parent: "exclusive",
+			Extra:  map[string]string{"socket": "0"},
+		},
+		"exclusive[1]": {
+			Name:   "exclusive[1]",
+			CPUs:   "3",
+			Parent: "exclusive",
+			Extra:  map[string]string{"socket": "1"},
+		},
+		"shared": {
+			Name:     "shared",
+			CPUs:     "0-1",
+			Children: []string{"shared[0]"},
+			Extra:    map[string]string{"exclusive": "false"},
+		},
+		"shared[0]": {
+			Name:   "shared[0]",
+			CPUs:   "0-1",
+			Parent: "shared",
+			Extra:  map[string]string{"socket": "0"},
+		},
+	}
+	if !cmp.Equal(expected, state.Pools) {
+		t.Errorf("unexpected pools: %s", cmp.Diff(expected, state.Pools))
+	}
+	if len(state.Assignments) != 0 {
+		t.Errorf("Expected no assignments but got %v", state.Assignments)
+	}
+}
+
+func TestValidateStpConfig(t *testing.T) {
+	saved := conf
+	defer func() { conf = saved }()
+
+	tcases := []struct {
+		name          string
+		pools         pools
+		expectedError bool
+	}{
+		{
+			name: "valid pools",
+			pools: pools{
+				"exclusive": {Exclusive: true, CPULists: []*cpuList{{Cpuset: "0,1"}, {Cpuset: "2-3"}}},
+				"shared":    {CPULists: []*cpuList{{Cpuset: "4-7"}}},
+			},
+		},
+		{
+			name:          "no pools",
+			expectedError: true,
+		},
+		{
+			name: "invalid cpu list",
+			pools: pools{
+				"shared": {CPULists: []*cpuList{{Cpuset: "4-"}}},
+			},
+			expectedError: true,
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			conf = &config{Pools: tc.pools}
+			err := validateStpConfig(nil)
+			if (err != nil) != tc.expectedError {
+				t.Errorf("Expected error %v but got %v", tc.expectedError, err)
+			}
+		})
+	}
+}
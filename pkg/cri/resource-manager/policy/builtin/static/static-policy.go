@@ -16,6 +16,7 @@ package static
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 
 	corev1 "k8s.io/api/core/v1"
@@ -62,6 +63,9 @@ type static struct {
 // Make sure static implements the policy backend interface.
 var _ policy.Backend = &static{}
 
+// Make sure static can checkpoint and restore its allocations.
+var _ policy.Checkpointer = &static{}
+
 const (
 	// keyPreferIsolated is the annotation used to mark pods preferring isolated CPUs.
 	keyPreferIsolated = "prefer-isolated-cpus"
@@ -200,8 +204,52 @@ func (s *static) ExportResourceData(c cache.Container) map[string]string {
 }
 
 // Introspect provides data for external introspection.
-func (s *static) Introspect(*introspect.State) {
-	return
+func (s *static) Introspect(state *introspect.State) {
+	shared := &introspect.Pool{
+		Name:  "shared",
+		CPUs:  s.GetDefaultCPUSet().String(),
+		Extra: map[string]string{"reserved-cpus": s.reservedCpus.String()},
+	}
+	exclusive := &introspect.Pool{
+		Name:  "exclusive",
+		Extra: map[string]string{"free-isolated-cpus": s.isolatedCpus.String()},
+	}
+	exclusiveCpus := cpuset.NewCPUSet()
+
+	assignments := make(map[string]*introspect.Assignment)
+	for _, c := range s.state.GetContainers() {
+		a := &introspect.Assignment{
+			ContainerID: c.GetID(),
+			Memory:      c.GetCpusetMems(),
+		}
+		if cset, ok := s.GetCPUSet(c.GetCacheID()); ok {
+			a.ExclusiveCPUs = cset.String()
+			a.Pool = exclusive.Name
+			exclusive.Containers = append(exclusive.Containers, a.ContainerID)
+			exclusiveCpus = exclusiveCpus.Union(cset)
+			if isolated := cset.Intersection(s.sys.Isolated()); !isolated.IsEmpty() {
+				a.Extra = map[string]string{"isolated-cpus": isolated.String()}
+			}
+		} else {
+			a.SharedCPUs = shared.CPUs
+			a.Pool = shared.Name
+			shared.Containers = append(shared.Containers, a.ContainerID)
+			if req, ok := c.GetResourceRequirements().Requests[corev1.ResourceCPU]; ok {
+				a.CPUShare = int(req.MilliValue())
+			}
+		}
+		assignments[a.ContainerID] = a
+	}
+	exclusive.CPUs = exclusiveCpus.String()
+	sort.Strings(shared.Containers)
+	sort.Strings(exclusive.Containers)
+
+	state.Pools = map[string]*introspect.Pool{
+		shared.Name:    shared,
+		exclusive.Name: exclusive,
+	}
+	state.Assignments = assignments
+	state.Reserved = s.reservedCpus.String()
 }
 
 // DescribeMetrics generates policy-specific prometheus metrics data descriptors.
@@ -219,6 +267,35 @@ func (p *static) CollectMetrics(policy.Metrics) ([]prometheus.Metric, error) {
 	return nil, nil
 }
 
+// checkpoint is a checkpoint of our CPU assignments.
+type checkpoint struct {
+	assignments ContainerCPUAssignments
+	defaultCPUs cpuset.CPUSet
+}
+
+// Checkpoint takes a checkpoint of the current CPU assignments.
+func (s *static) Checkpoint() (policy.Checkpoint, error) {
+	return &checkpoint{
+		assignments: s.GetCPUAssignments().clone(),
+		defaultCPUs: s.GetDefaultCPUSet().Clone(),
+	}, nil
+}
+
+// Restore restores the CPU assignments from a checkpoint.
+func (s *static) Restore(cp policy.Checkpoint) error {
+	c, ok := cp.(*checkpoint)
+	if !ok {
+		return policyError("invalid checkpoint of type %T", cp)
+	}
+
+	s.Info("restoring CPU assignments from checkpoint...")
+
+	s.SetCPUAssignments(c.assignments.clone())
+	s.state.SetPolicyEntry(keyDefaultCPUs, c.defaultCPUs.Clone())
+
+	return nil
+}
+
 func (s *static) configNotify(event config.Event, source config.Source) error {
 	s.Info("configuration %s", event)
 
@@ -568,6 +645,15 @@ func policyError(format string, args ...interface{}) error {
 // ContainerCPUAssignments assigns CPU sets per container id.
 type ContainerCPUAssignments map[string]cpuset.CPUSet
 
+// clone returns a copy of the assignments.
+func (ca ContainerCPUAssignments) clone() ContainerCPUAssignments {
+	o := make(ContainerCPUAssignments, len(ca))
+	for id, cset := range ca {
+		o[id] = cset.Clone()
+	}
+	return o
+}
+
 //
 // Cache keys for storing the default cpuset (one for containers
 // without exclusive allocations) and static assignments (cpusets
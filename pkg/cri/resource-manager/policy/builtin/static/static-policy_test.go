@@ -0,0 +1,154 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package static
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
+
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/cache"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/introspect"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/policy"
+	"github.com/intel/cri-resource-manager/pkg/sysfs"
+	"github.com/intel/cri-resource-manager/pkg/testutils"
+)
+
+// createTestContainer adds a pod with a single container to the cache.
+// The runtime ID of the container is <podName>-ctr.
+func createTestContainer(t *testing.T, cch cache.Cache, podName, cgroupParent string, resources criv1.LinuxContainerResources) cache.Container {
+	podCfg := &criv1.PodSandboxConfig{
+		Metadata: &criv1.PodSandboxMetadata{Name: podName, Uid: podName, Namespace: "default"},
+		Linux:    &criv1.LinuxPodSandboxConfig{CgroupParent: cgroupParent},
+	}
+	if _, err := cch.InsertPod(podName, &criv1.RunPodSandboxRequest{Config: podCfg}, nil); err != nil {
+		t.Fatalf("failed to create pod: %v", err)
+	}
+	c, err := cch.InsertContainer(&criv1.CreateContainerRequest{
+		PodSandboxId: podName,
+		Config: &criv1.ContainerConfig{
+			Metadata: &criv1.ContainerMetadata{Name: "ctr"},
+			Linux:    &criv1.LinuxContainerConfig{Resources: &resources},
+		},
+		SandboxConfig: podCfg,
+	})
+	if err != nil {
+		t.Fatalf("failed to create container: %v", err)
+	}
+	reply := &criv1.CreateContainerResponse{ContainerId: podName + "-ctr"}
+	if c, err = cch.UpdateContainerID(c.GetCacheID(), reply); err != nil {
+		t.Fatalf("failed to update container ID: %v", err)
+	}
+	return c
+}
+
+func TestIntrospect(t *testing.T) {
+	sys, err := sysfs.DiscoverSystemAt(filepath.Join(testutils.CreateFakeSysfs(t, 8), "sys"))
+	if err != nil {
+		t.Fatalf("failed to discover fake system: %v", err)
+	}
+	cch, err := cache.NewCache(cache.Options{CacheDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	s := NewStaticPolicy(&policy.BackendOptions{
+		System: sys,
+		Cache:  cch,
+		Reserved: policy.ConstraintSet{
+			policy.DomainCPU: cpuset.NewCPUSet(0),
+		},
+	}).(*static)
+	if err := s.Start(nil, nil); err != nil {
+		t.Fatalf("failed to start policy: %v", err)
+	}
+
+	guaranteed := createTestContainer(t, cch, "guaranteed", "/kubepods.slice/kubepods-podguaranteed",
+		criv1.LinuxContainerResources{
+			CpuShares:          2048,
+			CpuQuota:           200000,
+			CpuPeriod:          100000,
+			MemoryLimitInBytes: 1 << 30,
+		})
+	burstable := createTestContainer(t, cch, "burstable", "/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-podburstable",
+		criv1.LinuxContainerResources{CpuShares: 512})
+	for _, c := range []cache.Container{guaranteed, burstable} {
+		if err := s.AllocateResources(c); err != nil {
+			t.Fatalf("failed to allocate resources for %s: %v", c.PrettyName(), err)
+		}
+	}
+
+	state := &introspect.State{}
+	s.Introspect(state)
+
+	exclusiveCpus, ok := s.GetCPUSet(guaranteed.GetCacheID())
+	if !ok || exclusiveCpus.Size() != 2 {
+		t.Fatalf("expected 2 exclusive CPUs for %s, got %s", guaranteed.PrettyName(), exclusiveCpus)
+	}
+	sharedCpus := s.GetDefaultCPUSet()
+	if !sharedCpus.Intersection(exclusiveCpus).IsEmpty() {
+		t.Errorf("expected shared CPUs %s not to overlap exclusive CPUs %s", sharedCpus, exclusiveCpus)
+	}
+
+	expectedPools := map[string]*introspect.Pool{
+		"shared": {
+			Name:       "shared",
+			CPUs:       sharedCpus.String(),
+			Containers: []string{"burstable-ctr"},
+			Extra:      map[string]string{"reserved-cpus": "0"},
+		},
+		"exclusive": {
+			Name:       "exclusive",
+			CPUs:       exclusiveCpus.String(),
+			Containers: []string{"guaranteed-ctr"},
+			Extra:      map[string]string{"free-isolated-cpus": ""},
+		},
+	}
+	if !reflect.DeepEqual(state.Pools, expectedPools) {
+		t.Errorf("expected pools %v, got %v", expectedPools, state.Pools)
+	}
+
+	expectedAssignments := map[string]*introspect.Assignment{
+		"guaranteed-ctr": {
+			ContainerID:   "guaranteed-ctr",
+			ExclusiveCPUs: exclusiveCpus.String(),
+			Pool:          "exclusive",
+		},
+		"burstable-ctr": {
+			ContainerID: "burstable-ctr",
+			SharedCPUs:  sharedCpus.String(),
+			CPUShare:    500,
+			Pool:        "shared",
+		},
+	}
+	if !reflect.DeepEqual(state.Assignments, expectedAssignments) {
+		t.Errorf("expected assignments %v, got %v", expectedAssignments, state.Assignments)
+	}
+
+	// released exclusive CPUs return to the shared pool
+	if err := s.ReleaseResources(guaranteed); err != nil {
+		t.Fatalf("failed to release resources of %s: %v", guaranteed.PrettyName(), err)
+	}
+	state = &introspect.State{}
+	s.Introspect(state)
+	if pool := state.Pools["exclusive"]; pool.CPUs != "" || len(pool.Containers) != 0 {
+		t.Errorf("expected empty exclusive pool, got %+v", *pool)
+	}
+	if cpus := state.Pools["shared"].CPUs; cpus != sharedCpus.Union(exclusiveCpus).String() {
+		t.Errorf("expected shared pool CPUs %s, got %s", sharedCpus.Union(exclusiveCpus), cpus)
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topologyaware
+
+import (
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	resapi "k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/cache"
+	policyapi "github.com/intel/cri-resource-manager/pkg/cri/resource-manager/policy"
+	"github.com/intel/goresctrl/pkg/rdt"
+)
+
+const (
+	// tagUnthrottledRDTClass is the container tag for the RDT class of throttled containers.
+	tagUnthrottledRDTClass = "topology-aware/unthrottled-rdt-class"
+)
+
+// bandwidthSample is the last MBM sample of a container.
+type bandwidthSample struct {
+	bytes uint64    // total bytes transferred
+	time  time.Time // time of the sample
+	rate  uint64    // bandwidth since the previous sample, in bytes per second
+}
+
+// readMBMBytes reads the total MBM byte count of a container, if monitored.
+var readMBMBytes = func(c cache.Container) (uint64, bool) {
+	cls, ok := rdt.GetClass(c.GetRDTClass())
+	if !ok {
+		return 0, false
+	}
+	mg, ok := cls.GetMonGroup(c.GetID())
+	if !ok {
+		return 0, false
+	}
+
+	total, found := uint64(0), false
+	for _, leaf := range mg.GetMonData().L3 {
+		if bytes, ok := leaf["mbm_total_bytes"]; ok {
+			total += bytes
+			found = true
+		}
+	}
+	return total, found
+}
+
+// bandwidthCapacityPerNUMANode returns the configured memory bandwidth of a NUMA node.
+func (p *policy) bandwidthCapacityPerNUMANode() uint64 {
+	if c, ok := p.options.Available[policyapi.DomainMemoryBW]; ok {
+		if qty, ok := c.(resapi.Quantity); ok && qty.Sign() > 0 {
+			return uint64(qty.Value())
+		}
+	}
+	return 0
+}
+
+// bandwidthAware returns true if memory bandwidth-aware placement is enabled.
+func (p *policy) bandwidthAware() bool {
+	return p.bandwidthCapacityPerNUMANode() > 0
+}
+
+// updateMeasuredBandwidth samples the MBM counters of all containers with grants.
+// It is called when the policy is started and on every rebalancing, so with a
+// rebalancing interval set the rates are updated periodically. The first
+// sample of a container only sets a baseline, the rate is measured from the
+// next one on.
+func (p *policy) updateMeasuredBandwidth() {
+	if !opt.MemoryBandwidthMonitoring || !p.bandwidthAware() {
+		return
+	}
+	if p.bandwidth == nil {
+		p.bandwidth = make(map[string]*bandwidthSample)
+	}
+
+	now := time.Now()
+	seen := make(map[string]struct{}, len(p.allocations.grants))
+	for id, g := range p.allocations.grants {
+		bytes, ok := readMBMBytes(g.GetContainer())
+		if !ok {
+			continue
+		}
+		seen[id] = struct{}{}
+
+		s, ok := p.bandwidth[id]
+		if !ok {
+			p.bandwidth[id] = &bandwidthSample{bytes: bytes, time: now}
+			continue
+		}
+		if elapsed := now.Sub(s.time).Seconds(); elapsed > 0 && bytes >= s.bytes {
+			s.rate = uint64(float64(bytes-s.bytes) / elapsed)
+		}
+		s.bytes, s.time = bytes, now
+	}
+
+	for id := range p.bandwidth {
+		if _, ok := seen[id]; !ok {
+			delete(p.bandwidth, id)
+		}
+	}
+}
+
+// containerBandwidth returns the memory bandwidth demand of a container, the
+// larger of its annotated and measured bandwidth.
+func (p *policy) containerBandwidth(c cache.Container) uint64 {
+	demand := uint64(0)
+	if pod, ok := c.GetPod(); ok {
+		demand = memoryBandwidthPreference(pod, c)
+	}
+	if s, ok := p.bandwidth[c.GetCacheID()]; ok && s.rate > demand {
+		demand = s.rate
+	}
+	return demand
+}
+
+// nodeBandwidthCapacity returns the memory bandwidth capacity of a node.
+func (p *policy) nodeBandwidthCapacity(n Node) uint64 {
+	return p.bandwidthCapacityPerNUMANode() * uint64(n.GetMemset(memoryAll).Size())
+}
+
+// nodeBandwidthDemand returns the memory bandwidth demand of containers in the subtree of a node.
+func (p *policy) nodeBandwidthDemand(n Node) uint64 {
+	demand := uint64(0)
+	for _, g := range p.allocations.grants {
+		if isNodeInSubtree(g.GetCPUNode(), n) {
+			demand += p.containerBandwidth(g.GetContainer())
+		}
+	}
+	return demand
+}
+
+// bandwidthUtilization returns the memory bandwidth utilization of a node
+// with the given extra demand, capped by that of any of its ancestors.
+func (p *policy) bandwidthUtilization(n Node, extra uint64) float64 {
+	utilization := 0.0
+	for ; !n.IsNil(); n = n.Parent() {
+		capacity := p.nodeBandwidthCapacity(n)
+		if capacity == 0 {
+			continue
+		}
+		if u := float64(p.nodeBandwidthDemand(n)+extra) / float64(capacity); u > utilization {
+			utilization = u
+		}
+	}
+	return utilization
+}
+
+// bandwidthSaturated returns true if the given utilization saturates a node.
+func bandwidthSaturated(utilization float64) bool {
+	return utilization*100 >= float64(opt.MemoryBandwidthSaturation)
+}
+
+// updateBandwidthThrottling assigns the configured throttling RDT class to
+// best-effort containers in saturated nodes and restores the original class
+// of throttled containers once their nodes are no longer saturated.
+func (p *policy) updateBandwidthThrottling() {
+	class := opt.MemoryBandwidthThrottleClass
+
+	saturated := []Node{}
+	if class != "" && p.bandwidthAware() {
+		for _, n := range p.pools {
+			capacity := p.nodeBandwidthCapacity(n)
+			if capacity == 0 {
+				continue
+			}
+			if bandwidthSaturated(float64(p.nodeBandwidthDemand(n)) / float64(capacity)) {
+				log.Info("memory bandwidth of %s is saturated", n.Name())
+				saturated = append(saturated, n)
+			}
+		}
+	}
+
+	for _, g := range p.allocations.grants {
+		c := g.GetContainer()
+		throttle := false
+		if c.GetQOSClass() == v1.PodQOSBestEffort {
+			for _, n := range saturated {
+				if isNodeInSubtree(g.GetCPUNode(), n) || isNodeInSubtree(n, g.GetCPUNode()) {
+					throttle = true
+					break
+				}
+			}
+		}
+
+		original, throttled := c.GetTag(tagUnthrottledRDTClass)
+		switch {
+		case throttle && !throttled:
+			log.Info("%s: throttling memory bandwidth (RDT class %s)", c.PrettyName(), class)
+			c.SetTag(tagUnthrottledRDTClass, c.GetRDTClass())
+			c.SetRDTClass(class)
+		case !throttle && throttled:
+			log.Info("%s: unthrottling memory bandwidth (RDT class %s)", c.PrettyName(), original)
+			c.DeleteTag(tagUnthrottledRDTClass)
+			c.SetRDTClass(original)
+		}
+	}
+}
+
+// isNodeInSubtree returns true if node n is in the subtree rooted at the given node.
+func isNodeInSubtree(n, root Node) bool {
+	for ; !n.IsNil(); n = n.Parent() {
+		if n.NodeID() == root.NodeID() {
+			return true
+		}
+	}
+	return false
+}
@@ -105,6 +105,7 @@ type cachedGrant struct {
 	Memset      idset.IDSet
 	MemoryLimit memoryMap
 	ColdStart   time.Duration
+	HugePages   hugePageMap `json:",omitempty"`
 }
 
 func newCachedGrant(cg Grant) *cachedGrant {
@@ -125,6 +126,13 @@ func newCachedGrant(cg Grant) *cachedGrant {
 
 	ccg.ColdStart = cg.ColdStart()
 
+	if hugePages := cg.HugePages(); !hugePages.IsEmpty() {
+		ccg.HugePages = make(hugePageMap)
+		for size, amount := range hugePages {
+			ccg.HugePages[size] = amount
+		}
+	}
+
 	return ccg
 }
 
@@ -148,6 +156,7 @@ func (ccg *cachedGrant) ToGrant(policy *policy) (Grant, error) {
 		ccg.MemoryLimit,
 		ccg.ColdStart,
 	)
+	g.SetHugePages(ccg.HugePages)
 
 	if g.Memset().String() != ccg.Memset.String() {
 		log.Error("cache error: mismatch in stored/recalculated memset: %s != %s",
@@ -125,6 +125,11 @@ func TestColdStart(t *testing.T) {
 				// t.Errorf("No DRAM was expected before coldstart timer: %v", grant.MemoryType())
 			}
 
+			policy.applyGrant(grant)
+			if pm := tc.container.GetPageMigration(); pm != nil {
+				t.Errorf("Expected no page migration during coldstart, got %v", pm)
+			}
+
 			globalPolicy = policy
 
 			policy.options.SendEvent(&events.Policy{
@@ -141,6 +146,11 @@ func TestColdStart(t *testing.T) {
 			if !newMems.Has(tc.expectedPMEMSystemNodeID) || !newMems.Has(tc.expectedDRAMSystemNodeID) {
 				t.Errorf("Didn't get all expected system nodes in mems, got: %v", newMems)
 			}
+			pm := tc.container.GetPageMigration()
+			if pm == nil || !pm.SourceNodes.Has(tc.expectedDRAMSystemNodeID) || !pm.TargetNodes.Has(tc.expectedPMEMSystemNodeID) {
+				t.Errorf("Expected page migration from %v to %v after coldstart, got %v",
+					tc.expectedDRAMSystemNodeID, tc.expectedPMEMSystemNodeID, pm)
+			}
 		})
 	}
 }
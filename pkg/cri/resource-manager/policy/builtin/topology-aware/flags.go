@@ -34,8 +34,27 @@ type options struct {
 	ColocatePods bool `json:"ColocatePods"`
 	// ColocateNamespaces causes all containers in a namespace to have affinity for each other.
 	ColocateNamespaces bool `json:"ColocateNamespaces"`
+	// MemoryBandwidthMonitoring enables measuring memory bandwidth demand using RDT MBM.
+	MemoryBandwidthMonitoring bool `json:"MemoryBandwidthMonitoring,omitempty"`
+	// MemoryBandwidthSaturation is the utilization (in percent) at which a node is saturated.
+	MemoryBandwidthSaturation int `json:"MemoryBandwidthSaturation,omitempty"`
+	// MemoryBandwidthThrottleClass is the RDT class for best-effort containers on saturated nodes.
+	MemoryBandwidthThrottleClass string `json:"MemoryBandwidthThrottleClass,omitempty"`
+	// TopologyLevels lists the topology levels to materialize as pools in the pool tree.
+	TopologyLevels []string `json:"TopologyLevels,omitempty"`
 }
 
+const (
+	// SocketLevel materializes a pool for each socket, always enabled.
+	SocketLevel = "socket"
+	// DieLevel materializes a pool for each die of multi-die sockets.
+	DieLevel = "die"
+	// NumaLevel materializes a pool for each NUMA node with CPUs.
+	NumaLevel = "numa"
+	// LLCLevel materializes a pool for each last-level cache domain.
+	LLCLevel = "llc"
+)
+
 // Our runtime configuration.
 var opt = defaultOptions().(*options)
 var aliasOpt = defaultOptions().(*options)
@@ -43,11 +62,13 @@ var aliasOpt = defaultOptions().(*options)
 // defaultOptions returns a new options instance, all initialized to defaults.
 func defaultOptions() interface{} {
 	return &options{
-		PinCPU:                 true,
-		PinMemory:              true,
-		PreferIsolated:         true,
-		PreferShared:           false,
-		ReservedPoolNamespaces: []string{"kube-system"},
+		PinCPU:                    true,
+		PinMemory:                 true,
+		PreferIsolated:            true,
+		PreferShared:              false,
+		ReservedPoolNamespaces:    []string{"kube-system"},
+		MemoryBandwidthSaturation: 90,
+		TopologyLevels:            []string{SocketLevel, DieLevel, NumaLevel},
 	}
 }
 
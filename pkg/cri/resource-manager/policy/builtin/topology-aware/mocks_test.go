@@ -31,17 +31,22 @@ import (
 )
 
 type mockSystemNode struct {
-	id       idset.ID // node id
-	memFree  uint64
-	memTotal uint64
-	memType  system.MemoryType
-	distance []int
+	id        idset.ID // node id
+	memFree   uint64
+	memTotal  uint64
+	memType   system.MemoryType
+	distance  []int
+	hugePages []system.HugePages
 }
 
 func (fake *mockSystemNode) MemoryInfo() (*system.MemInfo, error) {
 	return &system.MemInfo{MemFree: fake.memFree, MemTotal: fake.memTotal}, nil
 }
 
+func (fake *mockSystemNode) HugePages() ([]system.HugePages, error) {
+	return fake.hugePages, nil
+}
+
 func (fake *mockSystemNode) PackageID() idset.ID {
 	return 0
 }
@@ -156,6 +161,12 @@ func (c *mockCPU) SetFrequencyLimits(min, max uint64) error {
 func (c *mockCPU) SstClos() int {
 	return -1
 }
+func (c *mockCPU) CacheID(int) idset.ID {
+	return idset.Unknown
+}
+func (c *mockCPU) LLCID() idset.ID {
+	return idset.Unknown
+}
 
 type mockSystem struct {
 	isolatedCPU  int
@@ -199,6 +210,24 @@ func (fake *mockSystem) Isolated() cpuset.CPUSet {
 
 	return cpuset.NewCPUSet()
 }
+func (fake *mockSystem) CacheLevels() []int {
+	return []int{}
+}
+func (fake *mockSystem) CacheIDs(int) []idset.ID {
+	return []idset.ID{}
+}
+func (fake *mockSystem) CacheCPUSet(int, idset.ID) cpuset.CPUSet {
+	return cpuset.NewCPUSet()
+}
+func (fake *mockSystem) Cache(int, idset.ID) *system.Cache {
+	return nil
+}
+func (fake *mockSystem) LLCIDs() []idset.ID {
+	return []idset.ID{}
+}
+func (fake *mockSystem) LLCCPUSet(idset.ID) cpuset.CPUSet {
+	return cpuset.NewCPUSet()
+}
 func (fake *mockSystem) CPUSet() cpuset.CPUSet {
 	return cpuset.NewCPUSet()
 }
@@ -260,6 +289,9 @@ type mockContainer struct {
 	cpuset                                cpuset.CPUSet
 	returnValueForQOSClass                v1.PodQOSClass
 	pod                                   cache.Pod
+	tags                                  map[string]string
+	pageMigration                         *cache.PageMigrate
+	rdtClass                              string
 }
 
 func (m *mockContainer) PrettyName() string {
@@ -469,11 +501,11 @@ func (m *mockContainer) CriUpdateRequest() (*criv1.UpdateContainerResourcesReque
 func (m *mockContainer) GetAffinity() ([]*cache.Affinity, error) {
 	return nil, nil
 }
-func (m *mockContainer) SetRDTClass(string) {
-	panic("unimplemented")
+func (m *mockContainer) SetRDTClass(class string) {
+	m.rdtClass = class
 }
 func (m *mockContainer) GetRDTClass() string {
-	panic("unimplemented")
+	return m.rdtClass
 }
 func (m *mockContainer) SetBlockIOClass(string) {
 	panic("unimplemented")
@@ -481,17 +513,29 @@ func (m *mockContainer) SetBlockIOClass(string) {
 func (m *mockContainer) GetBlockIOClass() string {
 	panic("unimplemented")
 }
+func (m *mockContainer) SetCPUClass(string) {
+	panic("unimplemented")
+}
+func (m *mockContainer) GetCPUClass() string {
+	panic("unimplemented")
+}
 func (m *mockContainer) SetToptierLimit(int64) {
 	panic("unimplemented")
 }
 func (m *mockContainer) GetToptierLimit() int64 {
 	panic("unimplemented")
 }
-func (m *mockContainer) SetPageMigration(*cache.PageMigrate) {
-	return
+func (m *mockContainer) SetMemoryQoS(*cache.MemoryQoS) {
+	panic("unimplemented")
+}
+func (m *mockContainer) GetMemoryQoS() *cache.MemoryQoS {
+	panic("unimplemented")
+}
+func (m *mockContainer) SetPageMigration(pm *cache.PageMigrate) {
+	m.pageMigration = pm
 }
 func (m *mockContainer) GetPageMigration() *cache.PageMigrate {
-	return nil
+	return m.pageMigration
 }
 func (m *mockContainer) SetCRIRequest(req interface{}) error {
 	panic("unimplemented")
@@ -520,14 +564,22 @@ func (m *mockContainer) HasPending(string) bool {
 func (m *mockContainer) ClearPending(string) {
 	panic("unimplemented")
 }
-func (m *mockContainer) GetTag(string) (string, bool) {
-	panic("unimplemented")
+func (m *mockContainer) GetTag(key string) (string, bool) {
+	value, ok := m.tags[key]
+	return value, ok
 }
-func (m *mockContainer) SetTag(string, string) (string, bool) {
-	panic("unimplemented")
+func (m *mockContainer) SetTag(key, value string) (string, bool) {
+	if m.tags == nil {
+		m.tags = make(map[string]string)
+	}
+	old, ok := m.tags[key]
+	m.tags[key] = value
+	return old, ok
 }
-func (m *mockContainer) DeleteTag(string) (string, bool) {
-	panic("unimplemented")
+func (m *mockContainer) DeleteTag(key string) (string, bool) {
+	old, ok := m.tags[key]
+	delete(m.tags, key)
+	return old, ok
 }
 func (m *mockContainer) String() string {
 	return "mockContainer"
@@ -729,12 +781,30 @@ func (m *mockCache) GetConfig() *config.RawConfig {
 func (m *mockCache) ResetConfig() error {
 	panic("unimplemented")
 }
+func (m *mockCache) AddConfigRevision(*config.ConfigRevision) error {
+	panic("unimplemented")
+}
+func (m *mockCache) GetConfigHistory() []*config.ConfigRevision {
+	panic("unimplemented")
+}
+func (m *mockCache) GetConfigRevision(uint64) *config.ConfigRevision {
+	panic("unimplemented")
+}
 func (m *mockCache) SetAdjustment(*config.Adjustment) (bool, map[string]error) {
 	panic("unimplemented")
 }
 func (m *mockCache) Save() error {
 	return nil
 }
+func (m *mockCache) Snapshot() ([]byte, error) {
+	panic("unimplemented")
+}
+func (m *mockCache) CheckpointResources() *cache.ResourceCheckpoint {
+	panic("unimplemented")
+}
+func (m *mockCache) RestoreResources(*cache.ResourceCheckpoint) {
+	panic("unimplemented")
+}
 func (m *mockCache) RefreshPods(*criv1.ListPodSandboxResponse, map[string]*cache.PodStatus) ([]cache.Pod, []cache.Pod, []cache.Container) {
 	panic("unimplemented")
 }
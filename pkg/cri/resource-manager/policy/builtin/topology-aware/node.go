@@ -49,6 +49,8 @@ const (
 	DieNode NodeKind = "die"
 	// NumaNode represents a NUMA node in the system.
 	NumaNode NodeKind = "numa node"
+	// LLCNode represents a last-level cache domain in the system.
+	LLCNode NodeKind = "llc"
 	// VirtualNode represents a virtual node, currently the root multi-socket setups.
 	VirtualNode NodeKind = "virtual node"
 )
@@ -102,6 +104,8 @@ type Node interface {
 	GrantedSharedCPU() int
 	// GetMemset
 	GetMemset(mtype memoryType) idset.IDSet
+	// GetHugePageMemset returns the set of memory controllers with hugepages attached to this node.
+	GetHugePageMemset() idset.IDSet
 	// AssignNUMANodes assigns the given set of NUMA nodes to this one.
 	AssignNUMANodes(ids []idset.ID)
 	// DepthFirst traverse the tree@node calling the function at each node.
@@ -136,6 +140,8 @@ type node struct {
 	mem      idset.IDSet // controllers with normal DRAM attached
 	pMem     idset.IDSet // controllers with PMEM attached
 	hbm      idset.IDSet // controllers with HBM attached
+	cxl      idset.IDSet // controllers with CXL memory attached
+	hugeMem  idset.IDSet // controllers with hugepages attached
 }
 
 // nodeself is used to 'upcast' a generic Node interface to a type-specific one.
@@ -164,6 +170,15 @@ type numanode struct {
 	sysnode system.Node // corresponding system.Node
 }
 
+// llcnode represents a last-level cache domain in the system.
+type llcnode struct {
+	node                 // common node data
+	id     idset.ID      // LLC id (lowest CPU id sharing the cache)
+	cpus   cpuset.CPUSet // CPUs sharing the cache
+	share  int           // index of our share of the memory of a split pool
+	shares int           // number of shares the memory of a split pool is divided into
+}
+
 // virtualnode represents a virtual node (ATM only the root in a multi-socket system).
 type virtualnode struct {
 	node // common node data
@@ -191,6 +206,8 @@ func (n *node) init(p *policy, name string, kind NodeKind, parent Node) {
 	n.mem = idset.NewIDSet()
 	n.pMem = idset.NewIDSet()
 	n.hbm = idset.NewIDSet()
+	n.cxl = idset.NewIDSet()
+	n.hugeMem = idset.NewIDSet()
 }
 
 // IsNil tests if a node
@@ -308,6 +325,9 @@ func (n *node) Dump(prefix string, level ...int) {
 	if n.pMem.Size() > 0 {
 		log.Debug("%s  - PMEM memory: %v", idt, n.pMem)
 	}
+	if n.cxl.Size() > 0 {
+		log.Debug("%s  - CXL memory: %v", idt, n.cxl)
+	}
 	for _, grant := range n.policy.allocations.grants {
 		cpuNodeID := grant.GetCPUNode().NodeID()
 		memNodeID := grant.GetMemoryNode().NodeID()
@@ -403,6 +423,8 @@ func (n *node) discoverSupply(assignedNUMANodes []idset.ID) Supply {
 			n.mem.Add(c.GetMemset(memoryDRAM).Members()...)
 			n.hbm.Add(c.GetMemset(memoryHBM).Members()...)
 			n.pMem.Add(c.GetMemset(memoryPMEM).Members()...)
+			n.cxl.Add(c.GetMemset(memoryCXL).Members()...)
+			n.hugeMem.Add(c.GetHugePageMemset().Members()...)
 			log.Debug("  + %s", supply.DumpCapacity())
 		}
 		log.Debug("  = %s", n.noderes.DumpCapacity())
@@ -410,6 +432,7 @@ func (n *node) discoverSupply(assignedNUMANodes []idset.ID) Supply {
 		log.Debug("%s: discovering attached/assigned resources...", n.Name())
 
 		mmap := createMemoryMap(0, 0, 0)
+		hugePages := make(hugePageMap)
 		cpus := cpuset.NewCPUSet()
 
 		for _, nodeID := range assignedNUMANodes {
@@ -438,10 +461,17 @@ func (n *node) discoverSupply(assignedNUMANodes []idset.ID) Supply {
 				mmap.AddHBM(meminfo.MemTotal)
 				log.Debug("  + assigned HBMEM NUMA node #%d (DRAM %.2fM)",
 					nodeID, float64(meminfo.MemTotal)/float64(1024*1024))
+			case system.MemoryTypeCXL:
+				n.cxl.Add(nodeID)
+				mmap.AddCXL(meminfo.MemTotal)
+				log.Debug("  + assigned CXL NUMA node #%d (DRAM %.2fM)",
+					nodeID, float64(meminfo.MemTotal)/float64(1024*1024))
 			default:
 				log.Fatal("NUMA node #%d with unknown memory type %v", node.GetMemoryType())
 			}
 
+			n.discoverHugePages(nodeID, hugePages)
+
 			allowed := nodeCPUs.Intersection(n.policy.allowed)
 			isolated := allowed.Intersection(n.policy.isolated)
 			reserved := allowed.Intersection(n.policy.reserved).Difference(isolated)
@@ -464,6 +494,7 @@ func (n *node) discoverSupply(assignedNUMANodes []idset.ID) Supply {
 		reserved := cpus.Intersection(n.policy.reserved).Difference(isolated)
 		sharable := cpus.Difference(isolated).Difference(reserved)
 		n.noderes = newSupply(n, isolated, reserved, sharable, 0, 0, mmap, nil)
+		n.noderes.AssignHugePages(hugePages)
 		log.Debug("  = %s", n.noderes.DumpCapacity())
 	}
 
@@ -471,6 +502,23 @@ func (n *node) discoverSupply(assignedNUMANodes []idset.ID) Supply {
 	return n.noderes.Clone()
 }
 
+// discoverHugePages adds the hugepages of the given NUMA node to the given map.
+func (n *node) discoverHugePages(nodeID idset.ID, hugePages hugePageMap) {
+	pages, err := n.System().Node(nodeID).HugePages()
+	if err != nil {
+		log.Error("%s: failed to get hugepages for NUMA node #%d: %v", n.Name(), nodeID, err)
+		return
+	}
+	for _, hp := range pages {
+		if hp.Total == 0 {
+			continue
+		}
+		n.hugeMem.Add(nodeID)
+		hugePages[hp.Size] += hp.Total * hp.Size
+		log.Debug("    hugepages-%s: %d pages", prettyMem(hp.Size), hp.Total)
+	}
+}
+
 // FreeSupply returns the available CPU supply of this node.
 func (n *node) FreeSupply() Supply {
 	return n.freeres
@@ -484,6 +532,14 @@ func (n *node) GetMemset(mtype memoryType) idset.IDSet {
 	return n.self.node.GetMemset(mtype)
 }
 
+// GetHugePageMemset returns the set of memory controllers with hugepages attached to this node.
+func (n *node) GetHugePageMemset() idset.IDSet {
+	if n.hugeMem == nil { // protect against &node{}-abuse by test cases...
+		return idset.NewIDSet()
+	}
+	return n.hugeMem.Clone()
+}
+
 // AssignNUMANodes assigns the given set of NUMA nodes to this one.
 func (n *node) AssignNUMANodes(ids []idset.ID) {
 	n.self.node.AssignNUMANodes(ids)
@@ -492,9 +548,10 @@ func (n *node) AssignNUMANodes(ids []idset.ID) {
 // assignNUMANodes assigns the given set of NUMA nodes to this one.
 func (n *node) assignNUMANodes(ids []idset.ID) {
 	mem := createMemoryMap(0, 0, 0)
+	hugePages := make(hugePageMap)
 
 	for _, numaNodeID := range ids {
-		if n.mem.Has(numaNodeID) || n.pMem.Has(numaNodeID) || n.hbm.Has(numaNodeID) {
+		if n.mem.Has(numaNodeID) || n.pMem.Has(numaNodeID) || n.hbm.Has(numaNodeID) || n.cxl.Has(numaNodeID) {
 			log.Warn("*** NUMA node #%d already discovered by or assigned to %s",
 				numaNodeID, n.Name())
 			continue
@@ -523,14 +580,22 @@ func (n *node) assignNUMANodes(ids []idset.ID) {
 			mem.Add(0, 0, memTotal)
 			log.Info("*** HBM NUMA node #%d assigned to pool node %q",
 				numaNodeID, n.Name())
+		case system.MemoryTypeCXL:
+			n.cxl.Add(numaNodeID)
+			mem.AddCXL(memTotal)
+			log.Info("*** CXL NUMA node #%d assigned to pool node %q",
+				numaNodeID, n.Name())
 		default:
 			log.Fatal("can't assign NUMA node #%d of type %v to pool node %q",
 				numaNodeID, numaNode.GetMemoryType())
 		}
+		n.discoverHugePages(numaNodeID, hugePages)
 	}
 
 	n.noderes.AssignMemory(mem)
 	n.freeres.AssignMemory(mem)
+	n.noderes.AssignHugePages(hugePages)
+	n.freeres.AssignHugePages(hugePages)
 }
 
 // Discover the set of memory attached to this node.
@@ -578,6 +643,9 @@ func (n *node) GetMemoryType() memoryType {
 	if n.hbm.Size() > 0 {
 		memoryMask |= memoryHBM
 	}
+	if n.cxl.Size() > 0 {
+		memoryMask |= memoryCXL
+	}
 	return memoryMask
 }
 
@@ -629,6 +697,9 @@ func (n *numanode) GetMemset(mtype memoryType) idset.IDSet {
 	if mtype&memoryPMEM != 0 {
 		mset.Add(n.pMem.Members()...)
 	}
+	if mtype&memoryCXL != 0 {
+		mset.Add(n.cxl.Members()...)
+	}
 
 	return mset
 }
@@ -710,6 +781,9 @@ func (n *dienode) GetMemset(mtype memoryType) idset.IDSet {
 	if mtype&memoryPMEM != 0 {
 		mset.Add(n.pMem.Members()...)
 	}
+	if mtype&memoryCXL != 0 {
+		mset.Add(n.cxl.Members()...)
+	}
 
 	return mset
 }
@@ -789,6 +863,9 @@ func (n *socketnode) GetMemset(mtype memoryType) idset.IDSet {
 	if mtype&memoryPMEM != 0 {
 		mset.Add(n.pMem.Members()...)
 	}
+	if mtype&memoryCXL != 0 {
+		mset.Add(n.cxl.Members()...)
+	}
 
 	return mset
 }
@@ -814,6 +891,144 @@ func (n *socketnode) HintScore(hint topology.Hint) float64 {
 	return 0.0
 }
 
+// NewLLCNode creates a node for a last-level cache domain. With shares > 0 the node
+// is a leaf splitting the CPUs of its would-be parent, and it gets the given share
+// of the memory of the NUMA nodes it is assigned.
+func (p *policy) NewLLCNode(id idset.ID, cpus cpuset.CPUSet, share, shares int, parent Node) Node {
+	n := &llcnode{}
+	n.self.node = n
+	n.node.init(p, fmt.Sprintf("LLC #%v", id), LLCNode, parent)
+	n.id = id
+	n.cpus = cpus
+	n.share = share
+	n.shares = shares
+
+	return n
+}
+
+// Dump (the LLC-specific parts of) this node.
+func (n *llcnode) dump(prefix string, level ...int) {
+	log.Debug("%s<LLC #%v, CPUs %s>", indent(prefix, level...), n.id,
+		kubernetes.ShortCPUSet(n.cpus))
+}
+
+// Get CPU supply available at this node.
+func (n *llcnode) GetSupply() Supply {
+	return n.noderes.Clone()
+}
+
+func (n *llcnode) GetPhysicalNodeIDs() []idset.ID {
+	if n.IsLeafNode() {
+		return n.mem.SortedMembers()
+	}
+	ids := make([]idset.ID, 0)
+	for _, c := range n.children {
+		cIds := c.GetPhysicalNodeIDs()
+		ids = append(ids, cIds...)
+	}
+	return ids
+}
+
+// DiscoverSupply discovers the CPU supply available at this LLC.
+func (n *llcnode) DiscoverSupply(assignedNUMANodes []idset.ID) Supply {
+	if n.noderes != nil || n.shares == 0 {
+		return n.node.discoverSupply(assignedNUMANodes)
+	}
+
+	//
+	// Notes:
+	//   A split LLC pool gets assigned the same NUMA nodes as its siblings.
+	//   We restrict its CPUs to the ones sharing the cache and account it
+	//   only its share of the memory, so that its parent ends up with the
+	//   full memory of the NUMA nodes once it cumulates its children. The
+	//   memsets of the pool cover the full NUMA nodes.
+	//
+
+	n.node.discoverSupply(assignedNUMANodes)
+
+	cs := n.noderes.(*supply)
+	cs.isolated = cs.isolated.Intersection(n.cpus)
+	cs.reserved = cs.reserved.Intersection(n.cpus)
+	cs.sharable = cs.sharable.Intersection(n.cpus)
+
+	total := uint64(0)
+	for memType, amount := range cs.mem {
+		if memType != memoryAll {
+			cs.mem[memType] = splitShare(amount, n.share, n.shares)
+			total += cs.mem[memType]
+		}
+	}
+	cs.mem[memoryAll] = total
+
+	for size, amount := range cs.hugePages {
+		cs.hugePages[size] = size * splitShare(amount/size, n.share, n.shares)
+	}
+
+	log.Debug("  = %s (LLC share %d/%d)", n.noderes.DumpCapacity(), n.share+1, n.shares)
+
+	n.freeres = n.noderes.Clone()
+	return n.noderes.Clone()
+}
+
+// splitShare returns the given share of an amount split into the given number of shares.
+func splitShare(amount uint64, share, shares int) uint64 {
+	part := amount / uint64(shares)
+	if uint64(share) < amount%uint64(shares) {
+		part++
+	}
+	return part
+}
+
+// GetMemset returns the set of memory attached to this LLC.
+func (n *llcnode) GetMemset(mtype memoryType) idset.IDSet {
+	mset := idset.NewIDSet()
+
+	if mtype&memoryDRAM != 0 {
+		mset.Add(n.mem.Members()...)
+	}
+	if mtype&memoryHBM != 0 {
+		mset.Add(n.hbm.Members()...)
+	}
+	if mtype&memoryPMEM != 0 {
+		mset.Add(n.pMem.Members()...)
+	}
+	if mtype&memoryCXL != 0 {
+		mset.Add(n.cxl.Members()...)
+	}
+
+	return mset
+}
+
+// AssignNUMANodes assigns the given NUMA nodes to this one.
+func (n *llcnode) AssignNUMANodes(ids []idset.ID) {
+	n.node.assignNUMANodes(ids)
+}
+
+// HintScore calculates the (CPU) score of the node for the given topology hint.
+func (n *llcnode) HintScore(hint topology.Hint) float64 {
+	switch {
+	case hint.CPUs != "":
+		return cpuHintScore(hint, n.cpus)
+
+	case hint.NUMAs != "":
+		if n.mem.Size() > 1 {
+			return OverfitPenalty * numaHintScore(hint, n.mem.Members()...)
+		}
+		return numaHintScore(hint, n.mem.Members()...)
+
+	case hint.Sockets != "":
+		pkgID := n.System().CPU(n.id).PackageID()
+		score := socketHintScore(hint, pkgID)
+		if score > 0.0 {
+			// penalize underfit reciprocally (inverse-proportionally) to the socket size
+			score /= float64(len(n.System().Package(pkgID).NodeIDs()))
+		}
+		return score
+	}
+
+	return 0.0
+}
+
 // NewVirtualNode creates a new virtual node.
 func (p *policy) NewVirtualNode(name string, parent Node) Node {
 	n := &virtualnode{}
@@ -851,6 +1066,9 @@ func (n *virtualnode) GetMemset(mtype memoryType) idset.IDSet {
 	if mtype&memoryPMEM != 0 {
 		mset.Add(n.pMem.Members()...)
 	}
+	if mtype&memoryCXL != 0 {
+		mset.Add(n.cxl.Members()...)
+	}
 
 	return mset
 }
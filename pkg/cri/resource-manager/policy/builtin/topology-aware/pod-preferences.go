@@ -25,6 +25,7 @@ import (
 	"sigs.k8s.io/yaml"
 
 	corev1 "k8s.io/api/core/v1"
+	resapi "k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/intel/cri-resource-manager/pkg/config"
@@ -43,6 +44,8 @@ const (
 	keyColdStartPreference = "cold-start"
 	// annotation key for reserved pools
 	keyReservedCPUsPreference = "prefer-reserved-cpus"
+	// annotation key for memory bandwidth demand
+	keyMemoryBandwidthPreference = "memory-bandwidth"
 
 	// effective annotation key for isolated CPU preference
 	preferIsolatedCPUsKey = keyIsolationPreference + "." + kubernetes.ResmgrKeyNamespace
@@ -54,6 +57,8 @@ const (
 	preferColdStartKey = keyColdStartPreference + "." + kubernetes.ResmgrKeyNamespace
 	// annotation key for reserved pools
 	preferReservedCPUsKey = keyReservedCPUsPreference + "." + kubernetes.ResmgrKeyNamespace
+	// effective annotation key for memory bandwidth demand
+	preferMemoryBandwidthKey = keyMemoryBandwidthPreference + "." + kubernetes.ResmgrKeyNamespace
 )
 
 // cpuClass is a type of CPU to allocate
@@ -75,6 +80,7 @@ var memoryNamedTypes = map[string]memoryType{
 	"dram":  memoryDRAM,
 	"pmem":  memoryPMEM,
 	"hbm":   memoryHBM,
+	"cxl":   memoryCXL,
 	"mixed": memoryAll,
 }
 
@@ -83,6 +89,7 @@ var memoryTypeNames = map[memoryType]string{
 	memoryDRAM: "DRAM",
 	memoryPMEM: "PMEM",
 	memoryHBM:  "HBM",
+	memoryCXL:  "CXL",
 }
 
 // memoryType is bitmask of types of memory to allocate
@@ -94,6 +101,7 @@ const (
 	memoryDRAM
 	memoryPMEM
 	memoryHBM
+	memoryCXL
 	memoryFirstUnusedBit
 	memoryAll = memoryFirstUnusedBit - 1
 
@@ -205,6 +213,26 @@ func coldStartPreference(pod cache.Pod, container cache.Container) (ColdStartPre
 	return preference, nil
 }
 
+// memoryBandwidthPreference returns the memory bandwidth demand (in bytes per
+// second) annotated for the container, or 0 if there is no such annotation.
+func memoryBandwidthPreference(pod cache.Pod, container cache.Container) uint64 {
+	key := preferMemoryBandwidthKey
+	value, ok := pod.GetEffectiveAnnotation(key, container.GetName())
+	if !ok {
+		return 0
+	}
+
+	qty, err := resapi.ParseQuantity(value)
+	if err != nil || qty.Sign() < 0 {
+		log.Error("invalid memory bandwidth preference (%q, %q): %v", key, value, err)
+		return 0
+	}
+
+	log.Debug("%s: effective memory bandwidth preference %s", container.PrettyName(), value)
+
+	return uint64(qty.Value())
+}
+
 // podIsolationPreference checks if containers explicitly prefers to run on multiple isolated CPUs.
 // The first return value indicates whether the container is isolated or not.
 // The second return value indicates whether that decision was explicit (true) or implicit (false).
@@ -411,6 +439,10 @@ func cpuAllocationPreferences(pod cache.Pod, container cache.Container) (int, in
 	//            - otherwise (no shared annotation):
 	//              => exclusive cores, prefer isolated only if explicitly annotated (**)
 	//
+	//   - Guaranteed QoS class containers tagged for exclusive CPUs due to CPU pressure:
+	//      - 1 full core <= CPU request, no shared preference explicitly annotated:
+	//          => exclusive (or mixed) cores, prefer isolated only if explicitly annotated
+	//
 	//   - Rationale for isolation defaults:
 	//     *)
 	//        In the single core case, a workload does not need to do anything extra to
@@ -449,6 +481,12 @@ func cpuAllocationPreferences(pod cache.Pod, container cache.Container) (int, in
 	preferIsolated, explicitIsolated := isolatedCPUsPreference(pod, container)
 	preferShared, explicitShared := sharedCPUsPreference(pod, container)
 
+	if cores > 0 && !explicitShared {
+		if _, ok := container.GetTag(tagPressureExclusive); ok {
+			return cores, fraction, preferIsolated && explicitIsolated, cpuNormal
+		}
+	}
+
 	switch {
 	// sub-core CPU request
 	case cores == 0:
@@ -539,6 +577,32 @@ func memoryAllocationPreference(pod cache.Pod, c cache.Container) (uint64, uint6
 	return req, lim, mtype
 }
 
+// hugePageAllocationPreference returns the amount of hugepages, per page size,
+// requested by the container.
+func hugePageAllocationPreference(c cache.Container) hugePageMap {
+	resources := c.GetResourceRequirements()
+	hugePages := make(hugePageMap)
+
+	for _, list := range []corev1.ResourceList{resources.Requests, resources.Limits} {
+		for name, qty := range list {
+			if !strings.HasPrefix(string(name), corev1.ResourceHugePagesPrefix) {
+				continue
+			}
+			size, err := resapi.ParseQuantity(strings.TrimPrefix(string(name), corev1.ResourceHugePagesPrefix))
+			if err != nil || size.Sign() <= 0 {
+				log.Error("%s: invalid hugepage resource %q: %v", c.PrettyName(), name, err)
+				continue
+			}
+			// hugepage requests are always equal to limits, if both are given
+			if amount := uint64(qty.Value()); amount > hugePages[uint64(size.Value())] {
+				hugePages[uint64(size.Value())] = amount
+			}
+		}
+	}
+
+	return hugePages
+}
+
 // String stringifies a cpuClass.
 func (t cpuClass) String() string {
 	if cpuClassName, ok := cpuClassNames[t]; ok {
@@ -551,7 +615,7 @@ func (t cpuClass) String() string {
 func (t memoryType) String() string {
 	str := ""
 	sep := ""
-	for _, bit := range []memoryType{memoryDRAM, memoryPMEM, memoryHBM} {
+	for _, bit := range []memoryType{memoryDRAM, memoryPMEM, memoryHBM, memoryCXL} {
 		if int(t)&int(bit) != 0 {
 			str += sep + memoryTypeNames[bit]
 			sep = ","
@@ -792,6 +792,41 @@ func TestCpuAllocationPreferences(t *testing.T) {
 			expectedFraction: 2500,
 			expectedIsolate:  false,
 		},
+		{
+			name: "guaranteed QoS with multi-core mixed request, tagged for CPU pressure",
+			container: &mockContainer{
+				returnValueForGetResourceRequirements: v1.ResourceRequirements{
+					Requests: v1.ResourceList{
+						corev1.ResourceCPU: resapi.MustParse("2500m"),
+					},
+				},
+				tags: map[string]string{tagPressureExclusive: "true"},
+			},
+			pod: &mockPod{
+				returnValueFotGetQOSClass: corev1.PodQOSGuaranteed,
+			},
+			expectedFull:     2,
+			expectedFraction: 500,
+			expectedIsolate:  false,
+		},
+		{
+			name: "guaranteed QoS with single-core request, prefer shared, tagged for CPU pressure",
+			container: &mockContainer{
+				returnValueForGetResourceRequirements: v1.ResourceRequirements{
+					Requests: v1.ResourceList{
+						corev1.ResourceCPU: resapi.MustParse("1500m"),
+					},
+				},
+				tags: map[string]string{tagPressureExclusive: "true"},
+			},
+			pod: &mockPod{
+				returnValueFotGetQOSClass: corev1.PodQOSGuaranteed,
+			},
+			preferShared:     true,
+			expectedFull:     1,
+			expectedFraction: 500,
+			expectedIsolate:  false,
+		},
 		{
 			name: "guaranteed QoS with multi-core mixed request, annotate isolated",
 			container: &mockContainer{
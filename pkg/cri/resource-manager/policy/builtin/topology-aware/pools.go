@@ -32,6 +32,13 @@ func (p *policy) buildPoolsByTopology() error {
 		return err
 	}
 
+	levels, err := topologyLevels(opt.TopologyLevels)
+	if err != nil {
+		return err
+	}
+	p.levels = levels
+	p.llcAware = p.levels[LLCLevel] && p.checkLLCTopology()
+
 	// Notes:
 	//   we never create pool nodes for PMEM-only NUMA nodes (as these
 	//   are always without any close/local set of CPUs). We instead
@@ -54,6 +61,15 @@ func (p *policy) buildPoolsByTopology() error {
 	//   per NUMA node surrogates to find both if and where resources
 	//   of omitted DRAM NUMA nodes need to get assigned to, and also
 	//   where PMEM NUMA node resources need to get assigned to.
+	//
+	//   CPU-less CXL memory expander NUMA nodes are treated the same
+	//   way as PMEM-only nodes: their memory is assigned to one of the
+	//   closest DRAM NUMA nodes.
+	//
+	//   Die and NUMA node pools are only created if the corresponding
+	//   topology level is enabled. With the LLC level enabled, NUMA nodes
+	//   sharing a last-level cache get grouped under an LLC pool, and leaf
+	//   pools spanning multiple last-level caches get split into LLC pools.
 
 	log.Debug("building topology pool tree...")
 
@@ -90,6 +106,10 @@ func (p *policy) buildPoolsByTopology() error {
 	numaDies := map[idset.ID]Node{} // created die Nodes per NUMA node id
 	for socketID, socket := range sockets {
 		dieIDs := p.sys.Package(socketID).DieIDs()
+		if !p.levels[DieLevel] {
+			log.Debug("      - omitted pool %q (die level disabled)", socket.Name()+"/die #*")
+			continue
+		}
 		if len(dieIDs) < 2 {
 			log.Debug("      - omitted pool %q (die count: %d)", socket.Name()+"/die #0",
 				len(dieIDs))
@@ -106,9 +126,10 @@ func (p *policy) buildPoolsByTopology() error {
 	}
 
 	// create pool nodes for NUMA nodes
-	pmemNodes := map[idset.ID]system.Node{} // collected PMEM-only nodes
+	memNodes := map[idset.ID]system.Node{}  // collected PMEM-only and CXL nodes
 	dramNodes := map[idset.ID]system.Node{} // collected DRAM-only nodes
 	numaSurrogates := map[idset.ID]Node{}   // surrogate leaf nodes for omitted NUMA nodes
+	llcGroups := map[idset.ID]Node{}        // LLC nodes grouping NUMA nodes, by LLC id
 	for _, numaNodeID := range p.sys.NodeIDs() {
 		var numaNode Node
 
@@ -117,9 +138,13 @@ func (p *policy) buildPoolsByTopology() error {
 		case system.MemoryTypeDRAM:
 			dramNodes[numaNodeID] = numaSysNode
 		case system.MemoryTypePMEM:
-			pmemNodes[numaNodeID] = numaSysNode
+			memNodes[numaNodeID] = numaSysNode
 			log.Debug("        - omitted pool \"NUMA node #%d\": PMEM node", numaNodeID)
 			continue // don't create pool, will assign to a closest DRAM node
+		case system.MemoryTypeCXL:
+			memNodes[numaNodeID] = numaSysNode
+			log.Debug("        - omitted pool \"NUMA node #%d\": CXL node", numaNodeID)
+			continue // don't create pool, will assign to a closest DRAM node
 		default:
 			log.Warn("        - ignored pool \"NUMA node #%d\": unhandled memory type %v",
 				numaNodeID, numaSysNode.GetMemoryType())
@@ -134,32 +159,33 @@ func (p *policy) buildPoolsByTopology() error {
 		//   any closest PMEM-only NUMA node that the original one would have received.
 		//
 
-		if die, ok := numaDies[numaNodeID]; ok {
-			if p.parentNumaNodeCountWithCPUs(numaSysNode) < 2 {
-				numaSurrogates[numaNodeID] = die
-				log.Debug("        - omitted pool \"NUMA node #%d\": using surrogate %q",
-					numaNodeID, numaSurrogates[numaNodeID].Name())
-				continue
-			}
-			numaNode = p.NewNumaNode(numaNodeID, die)
-		} else {
-			socket := sockets[p.sys.Node(numaNodeID).PackageID()]
-			if p.parentNumaNodeCountWithCPUs(numaSysNode) < 2 {
-				numaSurrogates[numaNodeID] = socket
-				log.Debug("        - omitted pool \"NUMA node #%d\": using surrogate %q",
-					numaNodeID, numaSurrogates[numaNodeID].Name())
-				continue
-			}
-			numaNode = p.NewNumaNode(numaNodeID, socket)
+		parent, ok := numaDies[numaNodeID]
+		if !ok {
+			parent = sockets[p.sys.Node(numaNodeID).PackageID()]
+		}
+		if p.llcAware {
+			parent = p.llcGroupNode(numaSysNode, parent, llcGroups)
+		}
+		if !p.levels[NumaLevel] || p.parentNumaNodeCountWithCPUs(numaSysNode) < 2 {
+			numaSurrogates[numaNodeID] = parent
+			log.Debug("        - omitted pool \"NUMA node #%d\": using surrogate %q",
+				numaNodeID, numaSurrogates[numaNodeID].Name())
+			continue
 		}
+		numaNode = p.NewNumaNode(numaNodeID, parent)
 
 		p.nodes[numaNode.Name()] = numaNode
 		numaSurrogates[numaNodeID] = numaNode
 		log.Debug("        + created pool %q", numaNode.Parent().Name()+"/"+numaNode.Name())
 	}
 
-	// set up assignment of PMEM and DRAM node resources to pool nodes and surrogates
-	assigned := p.assignNUMANodes(numaSurrogates, pmemNodes, dramNodes)
+	// set up assignment of PMEM, CXL and DRAM node resources to pool nodes and surrogates
+	assigned := p.assignNUMANodes(numaSurrogates, memNodes, dramNodes)
+
+	// split leaf pools spanning multiple LLCs
+	if p.llcAware {
+		p.splitLeavesByLLC(assigned)
+	}
 	log.Debug("NUMA node to pool assignment:")
 	for n, numaNodeIDs := range assigned {
 		log.Debug("  pool %q: NUMA nodes #%s", n.Name(), idset.NewIDSet(numaNodeIDs...))
@@ -182,13 +208,20 @@ func (p *policy) buildPoolsByTopology() error {
 		return nil
 	})
 
-	// make sure all PMEM nodes got assigned
+	p.llcSpans = map[int]int{}
+	if p.llcAware {
+		for _, n := range p.pools {
+			p.llcSpans[n.NodeID()] = p.llcSpan(n)
+		}
+	}
+
+	// make sure all PMEM and CXL nodes got assigned
 	if len(assigned) > 0 {
-		for node, pmem := range assigned {
-			log.Error("failed to assign PMEM NUMA nodes #%s (to NUMA node/surrogate %s %v)",
-				idset.NewIDSet(pmem...), node.Name(), node)
+		for node, mem := range assigned {
+			log.Error("failed to assign PMEM/CXL NUMA nodes #%s (to NUMA node/surrogate %s %v)",
+				idset.NewIDSet(mem...), node.Name(), node)
 		}
-		log.Fatal("internal error: unassigned PMEM NUMA nodes remaining")
+		log.Fatal("internal error: unassigned PMEM/CXL NUMA nodes remaining")
 	}
 
 	p.root.Dump("<pool-setup>")
@@ -196,6 +229,131 @@ func (p *policy) buildPoolsByTopology() error {
 	return nil
 }
 
+// topologyLevels returns the set of topology levels to materialize as pools.
+func topologyLevels(names []string) (map[string]bool, error) {
+	levels := map[string]bool{SocketLevel: true}
+	for _, name := range names {
+		switch name {
+		case SocketLevel, DieLevel, NumaLevel, LLCLevel:
+			levels[name] = true
+		default:
+			return nil, policyError("invalid topology level %q, expecting one of "+
+				"%s, %s, %s or %s", name, SocketLevel, DieLevel, NumaLevel, LLCLevel)
+		}
+	}
+	return levels, nil
+}
+
+// llcGroupNode returns the LLC node to use as the parent of a NUMA node, or the given parent.
+func (p *policy) llcGroupNode(numaNode system.Node, parent Node, groups map[idset.ID]Node) Node {
+	//
+	// Notes:
+	//   We only group NUMA nodes under an LLC node if all the CPUs of the NUMA
+	//   node share the same LLC, the LLC is shared by other NUMA nodes, and the
+	//   LLC is not the only one within the parent. Otherwise the LLC node would
+	//   either not group anything or be a lone child of its parent.
+	//
+
+	llcs := p.llcsOf(numaNode.CPUSet())
+	if len(llcs) != 1 {
+		return parent
+	}
+	llcID := llcs[0]
+
+	if n, ok := groups[llcID]; ok {
+		return n
+	}
+
+	llcCPUs := p.sys.LLCCPUSet(llcID)
+	if len(p.llcNumaNodeIDs(llcCPUs)) < 2 {
+		return parent
+	}
+	if len(p.llcsOf(p.parentCPUSet(parent))) < 2 {
+		return parent
+	}
+
+	n := p.NewLLCNode(llcID, llcCPUs, 0, 0, parent)
+	p.nodes[n.Name()] = n
+	groups[llcID] = n
+	log.Debug("        + created pool %q", parent.Name()+"/"+n.Name())
+
+	return n
+}
+
+// parentCPUSet returns the CPUs of the given socket or die pool node.
+func (p *policy) parentCPUSet(parent Node) cpuset.CPUSet {
+	switch n := parent.(type) {
+	case *socketnode:
+		return n.syspkg.CPUSet()
+	case *dienode:
+		return n.syspkg.DieCPUSet(n.id)
+	}
+	return cpuset.NewCPUSet()
+}
+
+// splitLeavesByLLC splits leaf pools spanning multiple LLCs into LLC pools.
+func (p *policy) splitLeavesByLLC(assigned map[Node][]idset.ID) {
+	leaves := []Node{}
+	p.root.DepthFirst(func(n Node) error {
+		if n.IsLeafNode() {
+			leaves = append(leaves, n.(*node).self.node)
+		}
+		return nil
+	})
+
+	for _, leaf := range leaves {
+		numaNodeIDs := assigned[leaf]
+		cpus := cpuset.NewCPUSet()
+		for _, id := range numaNodeIDs {
+			cpus = cpus.Union(p.sys.Node(id).CPUSet())
+		}
+		cpus = cpus.Intersection(p.allowed)
+
+		llcs := p.llcsOf(cpus)
+		if len(llcs) < 2 {
+			continue
+		}
+
+		for share, llcID := range llcs {
+			llcCPUs := p.sys.LLCCPUSet(llcID).Intersection(cpus)
+			n := p.NewLLCNode(llcID, llcCPUs, share, len(llcs), leaf)
+			p.nodes[n.Name()] = n
+			assigned[n] = numaNodeIDs
+			log.Debug("        + created pool %q (CPUs %s)", leaf.Name()+"/"+n.Name(),
+				kubernetes.ShortCPUSet(llcCPUs))
+		}
+		delete(assigned, leaf)
+	}
+}
+
+// llcsOf returns the sorted ids of the LLCs shared by any of the given CPUs.
+func (p *policy) llcsOf(cpus cpuset.CPUSet) []idset.ID {
+	ids := idset.NewIDSet()
+	for _, id := range cpus.ToSlice() {
+		if llcID := p.sys.CPU(idset.ID(id)).LLCID(); llcID != idset.Unknown {
+			ids.Add(llcID)
+		}
+	}
+	return ids.SortedMembers()
+}
+
+// llcNumaNodeIDs returns the ids of the NUMA nodes with CPUs sharing the given LLC.
+func (p *policy) llcNumaNodeIDs(llcCPUs cpuset.CPUSet) []idset.ID {
+	ids := []idset.ID{}
+	for _, id := range p.sys.NodeIDs() {
+		if !p.sys.Node(id).CPUSet().Intersection(llcCPUs).IsEmpty() {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// llcSpan returns the number of LLCs the CPUs of a pool span.
+func (p *policy) llcSpan(n Node) int {
+	s := n.GetSupply()
+	return len(p.llcsOf(s.IsolatedCPUs().Union(s.ReservedCPUs()).Union(s.SharableCPUs())))
+}
+
 // parentNumaNodeCountWithCPUs returns the number of CPU-ful NUMA nodes in the parent die/socket.
 func (p *policy) parentNumaNodeCountWithCPUs(numaNode system.Node) int {
 	socketID := numaNode.PackageID()
@@ -210,9 +368,9 @@ func (p *policy) parentNumaNodeCountWithCPUs(numaNode system.Node) int {
 	return count
 }
 
-// assignNUMANodes assigns each PMEM node to one of the closest DRAM nodes
+// assignNUMANodes assigns each PMEM or CXL node to one of the closest DRAM nodes
 func (p *policy) assignNUMANodes(surrogates map[idset.ID]Node, pmem, dram map[idset.ID]system.Node) map[Node][]idset.ID {
-	// collect the closest DRAM NUMA nodes (sorted by idset.ID) for each PMEM NUMA node.
+	// collect the closest DRAM NUMA nodes (sorted by idset.ID) for each PMEM/CXL NUMA node.
 	closest := map[idset.ID][]idset.ID{}
 	for pmemID := range pmem {
 		var min []idset.ID
@@ -236,7 +394,7 @@ func (p *policy) assignNUMANodes(surrogates map[idset.ID]Node, pmem, dram map[id
 
 	assigned := map[Node][]idset.ID{}
 
-	// assign each PMEM node to the closest DRAM surrogate with the least PMEM assigned
+	// assign each PMEM/CXL node to the closest DRAM surrogate with the least PMEM/CXL assigned
 	for pmemID, min := range closest {
 		var taker Node
 		var takerID idset.ID
@@ -253,11 +411,11 @@ func (p *policy) assignNUMANodes(surrogates map[idset.ID]Node, pmem, dram map[id
 			}
 		}
 		if taker == nil {
-			log.Panic("failed to assign CPU-less PMEM node #%d to any surrogate", pmemID)
+			log.Panic("failed to assign CPU-less PMEM/CXL node #%d to any surrogate", pmemID)
 		}
 
 		assigned[taker] = append(assigned[taker], pmemID)
-		log.Debug("        + PMEM node #%d assigned to %s with distance %v", pmemID, taker.Name(),
+		log.Debug("        + PMEM/CXL node #%d assigned to %s with distance %v", pmemID, taker.Name(),
 			p.sys.NodeDistance(pmemID, takerID))
 	}
 
@@ -332,6 +490,47 @@ func (p *policy) checkHWTopology() error {
 	return nil
 }
 
+// checkLLCTopology verifies that LLCs nest regularly with NUMA nodes, dies and sockets.
+func (p *policy) checkLLCTopology() bool {
+	llcIDs := p.sys.LLCIDs()
+	if len(llcIDs) == 0 {
+		log.Warn("LLC topology level disabled: no LLC information available")
+		return false
+	}
+
+	online := p.sys.CPUSet().Difference(p.sys.Offlined())
+	for _, llcID := range llcIDs {
+		llcCPUs := p.sys.LLCCPUSet(llcID).Intersection(online)
+
+		// An LLC should not be shared by multiple sockets or dies.
+		pkgID, dieID := p.sys.CPU(llcID).PackageID(), p.sys.CPU(llcID).DieID()
+		for _, id := range llcCPUs.ToSlice() {
+			cpu := p.sys.CPU(idset.ID(id))
+			if cpu.PackageID() != pkgID || cpu.DieID() != dieID {
+				log.Warn("LLC topology level disabled: LLC #%d shared by "+
+					"multiple sockets or dies", llcID)
+				return false
+			}
+		}
+
+		// An LLC should either contain or be contained in each NUMA node it overlaps.
+		for _, nodeID := range p.sys.NodeIDs() {
+			nodeCPUs := p.sys.Node(nodeID).CPUSet().Intersection(online)
+			if nodeCPUs.Intersection(llcCPUs).IsEmpty() {
+				continue
+			}
+			if !nodeCPUs.IsSubsetOf(llcCPUs) && !llcCPUs.IsSubsetOf(nodeCPUs) {
+				log.Warn("LLC topology level disabled: LLC #%d (CPUs %s) straddles "+
+					"NUMA node #%d (CPUs %s)", llcID, kubernetes.ShortCPUSet(llcCPUs),
+					nodeID, kubernetes.ShortCPUSet(nodeCPUs))
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
 // Pick a pool and allocate resource from it to the container.
 func (p *policy) allocatePool(container cache.Container, poolHint string) (Grant, error) {
 	var pool Node
@@ -398,11 +597,12 @@ func (p *policy) allocatePool(container cache.Container, poolHint string) (Grant
 			request, supply.DumpAllocatable(), err)
 	}
 
-	log.Debug("allocated req '%s' to memory node '%s' (memset %s,%s,%s)",
+	log.Debug("allocated req '%s' to memory node '%s' (memset %s,%s,%s,%s)",
 		container.PrettyName(), grant.GetMemoryNode().Name(),
 		grant.GetMemoryNode().GetMemset(memoryDRAM),
 		grant.GetMemoryNode().GetMemset(memoryPMEM),
-		grant.GetMemoryNode().GetMemset(memoryHBM))
+		grant.GetMemoryNode().GetMemset(memoryHBM),
+		grant.GetMemoryNode().GetMemset(memoryCXL))
 
 	// In case the workload is assigned to a memory node with multiple
 	// child nodes, there is no guarantee that the workload will
@@ -745,24 +945,45 @@ func (p *policy) setDemotionPreferences(c cache.Container, g Grant) {
 		return
 	}
 
+	// Pages are demoted from DRAM to PMEM or CXL memory.
 	memType := g.GetMemoryNode().GetMemoryType()
-	if memType&memoryDRAM == 0 || memType&memoryPMEM == 0 {
+	if memType&memoryDRAM == 0 || memType&(memoryPMEM|memoryCXL) == 0 {
 		c.SetPageMigration(nil)
 		return
 	}
 
 	dram := g.GetMemoryNode().GetMemset(memoryDRAM)
-	pmem := g.GetMemoryNode().GetMemset(memoryPMEM)
+	slow := g.GetMemoryNode().GetMemset(memoryPMEM | memoryCXL)
+
+	// During cold start the memset has no DRAM, so pages are left alone.
+	// Once cold start is over, pages allocated in slow memory during it
+	// can get promoted if they are hot.
+	if g.ColdStart() > 0 && intersectMemset(dram, g.Memset()).Size() == 0 {
+		log.Debug("%s: in cold start, not eligible for demotion", c.PrettyName())
+		c.SetPageMigration(nil)
+		return
+	}
 
 	log.Debug("%s: eligible for demotion from %s to %s NUMA node(s)",
-		c.PrettyName(), dram, pmem)
+		c.PrettyName(), dram, slow)
 
 	c.SetPageMigration(&cache.PageMigrate{
 		SourceNodes: dram,
-		TargetNodes: pmem,
+		TargetNodes: slow,
 	})
 }
 
+// intersectMemset returns the NUMA nodes present in both memsets.
+func intersectMemset(a, b idset.IDSet) idset.IDSet {
+	mems := idset.NewIDSet()
+	for _, id := range a.Members() {
+		if b.Has(id) {
+			mems.Add(id)
+		}
+	}
+	return mems
+}
+
 func (p *policy) filterInsufficientResources(req Request, originals []Node) []Node {
 	sufficient := make([]Node, 0)
 
@@ -775,6 +996,20 @@ func (p *policy) filterInsufficientResources(req Request, originals []Node) []No
 		supply := node.FreeSupply()
 		reqMemType := req.MemoryType()
 
+		hugePagesFit := true
+		for size, amount := range req.HugePages() {
+			if free := supply.AllocatableHugePages(size); free < amount {
+				log.Debug("%s: filtered out %s with insufficient hugepages-%s (%s < %s)",
+					req.GetContainer().PrettyName(), node.Name(), prettyMem(size),
+					prettyMem(free), prettyMem(amount))
+				hugePagesFit = false
+				break
+			}
+		}
+		if !hugePagesFit {
+			continue
+		}
+
 		if reqMemType == memoryUnspec {
 			// The algorithm for handling unspecified memory allocations is the same as for handling a request
 			// with memory type all.
@@ -783,7 +1018,7 @@ func (p *policy) filterInsufficientResources(req Request, originals []Node) []No
 
 		required := req.MemAmountToAllocate()
 
-		for _, memType := range []memoryType{memoryPMEM, memoryDRAM, memoryHBM} {
+		for _, memType := range []memoryType{memoryPMEM, memoryCXL, memoryDRAM, memoryHBM} {
 			if reqMemType&memType != 0 {
 				extra := supply.ExtraMemoryReservation(memType)
 				free := supply.MemoryLimit()[memType]
@@ -857,25 +1092,29 @@ func (p *policy) compareScores(request Request, pools []Node, scores map[int]Sco
 	// 1) - insufficient isolated, reserved or shared capacity loses
 	// 2) - if we have affinity, the higher affinity score wins
 	// 3) - if only one node matches the memory type request, it wins
-	// 4) - if we have topology hints
+	// 4) - for requests with memory bandwidth demand, an unsaturated node wins
+	// 5) - if we have topology hints
 	//       * better hint score wins
 	//       * for a tie, prefer the lower node then the smaller id
-	// 5) - if a node is lower in the tree it wins
-	// 6) - for reserved allocations
+	// 6) - with LLC pools enabled, if only one node stays within a single LLC, it wins
+	// 7) - if a node is lower in the tree it wins
+	// 8) - for requests with memory bandwidth demand, lower utilization wins
+	// 9) - for requests with hugepages, more remaining hugepage capacity wins
+	// 10) - for reserved allocations
 	//       * more unallocated reserved capacity per colocated container wins
-	// 7) - for (non-reserved) isolated allocations
+	// 11) - for (non-reserved) isolated allocations
 	//       * more isolated capacity wins
 	//       * for a tie, prefer the smaller id
-	// 8) - for (non-reserved) exclusive allocations
+	// 12) - for (non-reserved) exclusive allocations
 	//       * more slicable (shared) capacity wins
 	//       * for a tie, prefer the smaller id
-	// 9) - for (non-reserved) shared-only allocations
+	// 13) - for (non-reserved) shared-only allocations
 	//       * fewer colocated containers win
 	//       * for a tie prefer more shared capacity
-	// 10) - lower id wins
+	// 14) - lower id wins
 	//
 	// Before this comparison is reached, nodes with insufficient uncompressible resources
-	// (memory) have been filtered out.
+	// (memory, hugepages) have been filtered out.
 
 	// 1) a node with insufficient isolated or shared capacity loses
 	switch {
@@ -921,7 +1160,23 @@ func (p *policy) compareScores(request Request, pools []Node, scores map[int]Sco
 		log.Debug("  - memory type is a TIE")
 	}
 
-	// 4) better topology hint score wins
+	// 4) a node that does not get saturated with the requested bandwidth wins
+	bw1, bw2 := score1.BandwidthUtilization(), score2.BandwidthUtilization()
+	if request.MemoryBandwidth() > 0 {
+		saturated1, saturated2 := bandwidthSaturated(bw1), bandwidthSaturated(bw2)
+		if !saturated1 && saturated2 {
+			log.Debug("  => %s WINS on memory bandwidth saturation", node1.Name())
+			return true
+		}
+		if saturated1 && !saturated2 {
+			log.Debug("  => %s WINS on memory bandwidth saturation", node2.Name())
+			return false
+		}
+
+		log.Debug("  - memory bandwidth saturation is a TIE")
+	}
+
+	// 5) better topology hint score wins
 	hScores1 := score1.HintScores()
 	if len(hScores1) > 0 {
 		hScores2 := score2.HintScores()
@@ -970,7 +1225,22 @@ func (p *policy) compareScores(request Request, pools []Node, scores map[int]Sco
 		}
 	}
 
-	// 5) a lower node wins
+	// 6) a node within a single LLC wins
+	if p.llcAware {
+		single1, single2 := p.llcSpans[id1] == 1, p.llcSpans[id2] == 1
+		if single1 && !single2 {
+			log.Debug("  => %s WINS on staying within a single LLC", node1.Name())
+			return true
+		}
+		if !single1 && single2 {
+			log.Debug("  => %s WINS on staying within a single LLC", node2.Name())
+			return false
+		}
+
+		log.Debug("  - LLC span is a TIE")
+	}
+
+	// 7) a lower node wins
 	if depth1 > depth2 {
 		log.Debug("  => %s WINS on depth", node1.Name())
 		return true
@@ -982,8 +1252,37 @@ func (p *policy) compareScores(request Request, pools []Node, scores map[int]Sco
 
 	log.Debug("  - depth is a TIE")
 
+	// 8) lower memory bandwidth utilization wins
+	if request.MemoryBandwidth() > 0 {
+		if bw1 < bw2 {
+			log.Debug("  => %s WINS on memory bandwidth utilization", node1.Name())
+			return true
+		}
+		if bw2 < bw1 {
+			log.Debug("  => %s WINS on memory bandwidth utilization", node2.Name())
+			return false
+		}
+
+		log.Debug("  - memory bandwidth utilization is a TIE")
+	}
+
+	// 9) more remaining hugepage capacity wins
+	if !request.HugePages().IsEmpty() {
+		hp1, hp2 := score1.HugePageCapacity(), score2.HugePageCapacity()
+		if hp1 > hp2 {
+			log.Debug("  => %s WINS on hugepage capacity", node1.Name())
+			return true
+		}
+		if hp2 > hp1 {
+			log.Debug("  => %s WINS on hugepage capacity", node2.Name())
+			return false
+		}
+
+		log.Debug("  - hugepage capacity is a TIE")
+	}
+
 	if request.CPUType() == cpuReserved {
-		// 6) if requesting reserved CPUs, more reserved
+		// 10) if requesting reserved CPUs, more reserved
 		//    capacity per colocated container wins. Reserved
 		//    CPUs cannot be precisely accounted as they run
 		//    also BestEffort containers that do not carry
@@ -996,7 +1295,7 @@ func (p *policy) compareScores(request Request, pools []Node, scores map[int]Sco
 		}
 		log.Debug("  - reserved capacity is a TIE")
 	} else if request.CPUType() == cpuNormal {
-		// 7) more isolated capacity wins
+		// 11) more isolated capacity wins
 		if request.Isolate() && (isolated1 > 0 || isolated2 > 0) {
 			if isolated1 > isolated2 {
 				return true
@@ -1011,7 +1310,7 @@ func (p *policy) compareScores(request Request, pools []Node, scores map[int]Sco
 			return id1 < id2
 		}
 
-		// 8) more slicable shared capacity wins
+		// 12) more slicable shared capacity wins
 		if request.FullCPUs() > 0 && (shared1 > 0 || shared2 > 0) {
 			if shared1 > shared2 {
 				log.Debug("  => %s WINS on more slicable capacity", node1.Name())
@@ -1028,7 +1327,7 @@ func (p *policy) compareScores(request Request, pools []Node, scores map[int]Sco
 			return id1 < id2
 		}
 
-		// 9) fewer colocated containers win
+		// 13) fewer colocated containers win
 		if score1.Colocated() < score2.Colocated() {
 			log.Debug("  => %s WINS on colocation score", node1.Name())
 			return true
@@ -1051,7 +1350,7 @@ func (p *policy) compareScores(request Request, pools []Node, scores map[int]Sco
 		}
 	}
 
-	// 10) lower id wins
+	// 14) lower id wins
 	log.Debug("  => %s WINS based on lower id",
 		map[bool]string{true: node1.Name(), false: node2.Name()}[id1 < id2])
 
@@ -20,6 +20,7 @@ import (
 	"os"
 	"path"
 	"testing"
+	"time"
 
 	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/cache"
 	policyapi "github.com/intel/cri-resource-manager/pkg/cri/resource-manager/policy"
@@ -547,6 +548,612 @@ func TestWorkloadPlacement(t *testing.T) {
 	}
 }
 
+func TestMemoryBandwidthPlacement(t *testing.T) {
+
+	// Check that containers with memory bandwidth demand are spread
+	// away from leaf nodes with already high bandwidth utilization.
+
+	dir, err := ioutil.TempDir("", "cri-resource-manager-test-sysfs-")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = utils.UncompressTbz2(path.Join("testdata", "sysfs.tar.bz2"), dir)
+	if err != nil {
+		panic(err)
+	}
+
+	tcases := []struct {
+		name         string
+		memBW        uint64
+		loaded       bool
+		expectLoaded bool
+	}{
+		{
+			name:         "no bandwidth demand, no load",
+			expectLoaded: true,
+		},
+		{
+			name:         "no bandwidth demand, loaded leaf",
+			loaded:       true,
+			expectLoaded: true,
+		},
+		{
+			name:         "bandwidth demand, loaded leaf",
+			memBW:        1000000000,
+			loaded:       true,
+			expectLoaded: false,
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			sys, err := system.DiscoverSystemAt(path.Join(dir, "sysfs", "server", "sys"))
+			if err != nil {
+				panic(err)
+			}
+
+			reserved, _ := resapi.ParseQuantity("750m")
+			capacity, _ := resapi.ParseQuantity("10G")
+			policyOptions := &policyapi.BackendOptions{
+				Cache:  &mockCache{},
+				System: sys,
+				Available: policyapi.ConstraintSet{
+					policyapi.DomainMemoryBW: capacity,
+				},
+				Reserved: policyapi.ConstraintSet{
+					policyapi.DomainCPU: reserved,
+				},
+			}
+
+			policy := CreateTopologyAwarePolicy(policyOptions).(*policy)
+
+			req := &request{
+				memType:   memoryUnspec,
+				full:      1,
+				memBW:     tc.memBW,
+				container: &mockContainer{},
+			}
+
+			// load the node preferred in the absence of bandwidth demand
+			_, pools := policy.sortPoolsByScore(req, nil)
+			loaded := pools[0]
+			if tc.loaded {
+				c := &mockContainer{returnValueForGetCacheID: "loaded"}
+				policy.allocations.grants["loaded"] = newGrant(loaded, c, cpuNormal,
+					cpuset.NewCPUSet(), 0, memoryAll, nil, 0)
+				policy.bandwidth = map[string]*bandwidthSample{
+					"loaded": {rate: 8000000000},
+				}
+			}
+
+			_, pools = policy.sortPoolsByScore(req, nil)
+
+			if !pools[0].IsLeafNode() {
+				t.Errorf("expected placement in a leaf node, got %s", pools[0].Name())
+			}
+			if isLoaded := pools[0].NodeID() == loaded.NodeID(); isLoaded != tc.expectLoaded {
+				t.Errorf("expected placement in loaded node %v, got %s", tc.expectLoaded, pools[0].Name())
+			}
+		})
+	}
+}
+
+func TestHugePagePlacement(t *testing.T) {
+
+	// Check that containers requesting hugepages are only placed in pools
+	// with enough unallocated hugepages and get those in their memset.
+
+	dir, err := ioutil.TempDir("", "cri-resource-manager-test-sysfs-")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = utils.UncompressTbz2(path.Join("testdata", "sysfs.tar.bz2"), dir)
+	if err != nil {
+		panic(err)
+	}
+
+	// allocate 4 1G hugepages on NUMA node #0
+	nrHugePages := path.Join(dir, "sysfs", "server", "sys", "devices", "system", "node", "node0",
+		"hugepages", "hugepages-1048576kB", "nr_hugepages")
+	if err := ioutil.WriteFile(nrHugePages, []byte("4\n"), 0644); err != nil {
+		panic(err)
+	}
+
+	const GiB = uint64(1 << 30)
+
+	tcases := []struct {
+		name        string
+		hugePages   hugePageMap
+		granted     uint64
+		expectPools bool
+	}{
+		{
+			name:        "no hugepages requested",
+			expectPools: true,
+		},
+		{
+			name:        "hugepages requested, enough available",
+			hugePages:   hugePageMap{GiB: 2 * GiB},
+			granted:     2 * GiB,
+			expectPools: true,
+		},
+		{
+			name:        "hugepages requested, not enough available",
+			hugePages:   hugePageMap{GiB: 2 * GiB},
+			granted:     3 * GiB,
+			expectPools: false,
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			sys, err := system.DiscoverSystemAt(path.Join(dir, "sysfs", "server", "sys"))
+			if err != nil {
+				panic(err)
+			}
+
+			reserved, _ := resapi.ParseQuantity("750m")
+			policyOptions := &policyapi.BackendOptions{
+				Cache:     &mockCache{},
+				System:    sys,
+				Available: policyapi.ConstraintSet{},
+				Reserved: policyapi.ConstraintSet{
+					policyapi.DomainCPU: reserved,
+				},
+			}
+
+			policy := CreateTopologyAwarePolicy(policyOptions).(*policy)
+
+			req := &request{
+				memType:   memoryUnspec,
+				full:      1,
+				hugePages: tc.hugePages,
+				container: &mockContainer{},
+			}
+
+			_, pools := policy.sortPoolsByScore(req, nil)
+			if tc.hugePages.IsEmpty() {
+				if len(pools) != len(policy.pools) {
+					t.Errorf("expected all %d pools to fit, got %d", len(policy.pools), len(pools))
+				}
+				return
+			}
+
+			for _, pool := range pools {
+				if !pool.GetHugePageMemset().Has(0) {
+					t.Errorf("pool %s without hugepages was not filtered out", pool.Name())
+				}
+			}
+			if len(pools) == 0 || !pools[0].IsLeafNode() {
+				t.Fatalf("expected placement in a leaf node with hugepages, got %v", pools)
+			}
+
+			c := &mockContainer{returnValueForGetCacheID: "granted"}
+			g := newGrant(pools[0], c, cpuNormal, cpuset.NewCPUSet(), 0, memoryAll, nil, 0)
+			g.SetHugePages(hugePageMap{GiB: tc.granted})
+			if !g.Memset().Has(0) {
+				t.Errorf("expected granted memset %s to include hugepage node #0", g.Memset())
+			}
+			policy.allocations.grants["granted"] = g
+
+			_, pools = policy.sortPoolsByScore(req, nil)
+			if hasPools := len(pools) > 0; hasPools != tc.expectPools {
+				t.Errorf("expected pools with enough hugepages %v, got %v", tc.expectPools, pools)
+			}
+		})
+	}
+}
+
+func TestCXLPlacement(t *testing.T) {
+
+	// Check that CPU-less nodes in a slower memory tier are used as CXL memory,
+	// assigned to the closest DRAM node and used as demotion targets.
+
+	dir, err := ioutil.TempDir("", "cri-resource-manager-test-sysfs-")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = utils.UncompressTbz2(path.Join("testdata", "sysfs.tar.bz2"), dir)
+	if err != nil {
+		panic(err)
+	}
+
+	// put DRAM NUMA nodes #0-3 and CPU-less NUMA nodes #4-5 in separate memory tiers
+	tiers := path.Join(dir, "sysfs", "server", "sys", "devices", "virtual", "memory_tiering")
+	for tier, nodes := range map[string]string{"memory_tier4": "0-3\n", "memory_tier22": "4-5\n"} {
+		if err := os.MkdirAll(path.Join(tiers, tier), 0755); err != nil {
+			panic(err)
+		}
+		if err := ioutil.WriteFile(path.Join(tiers, tier, "nodelist"), []byte(nodes), 0644); err != nil {
+			panic(err)
+		}
+	}
+
+	sys, err := system.DiscoverSystemAt(path.Join(dir, "sysfs", "server", "sys"))
+	if err != nil {
+		panic(err)
+	}
+
+	reserved, _ := resapi.ParseQuantity("750m")
+	policyOptions := &policyapi.BackendOptions{
+		Cache:     &mockCache{},
+		System:    sys,
+		Available: policyapi.ConstraintSet{},
+		Reserved: policyapi.ConstraintSet{
+			policyapi.DomainCPU: reserved,
+		},
+	}
+
+	policy := CreateTopologyAwarePolicy(policyOptions).(*policy)
+
+	if cxl := policy.root.GetMemset(memoryCXL); cxl.String() != "4,5" {
+		t.Errorf("expected CXL NUMA nodes #4,5 in root pool, got %s", cxl)
+	}
+	if pmem := policy.root.GetMemset(memoryPMEM); pmem.Size() != 0 {
+		t.Errorf("expected no PMEM NUMA nodes in root pool, got %s", pmem)
+	}
+
+	tcases := []struct {
+		name        string
+		memType     memoryType
+		expectedCXL string
+	}{
+		{
+			name:        "CXL request",
+			memType:     memoryCXL,
+			expectedCXL: "4",
+		},
+		{
+			name:        "DRAM and CXL request",
+			memType:     memoryDRAM | memoryCXL,
+			expectedCXL: "4",
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := &request{
+				memReq:    10000,
+				memLim:    10000,
+				memType:   tc.memType,
+				container: &mockContainer{},
+			}
+
+			_, pools := policy.sortPoolsByScore(req, nil)
+			for _, pool := range pools {
+				if tc.memType == memoryCXL && !pool.HasMemoryType(memoryCXL) {
+					t.Errorf("pool %s without CXL memory was not filtered out", pool.Name())
+				}
+			}
+			if len(pools) == 0 || !pools[0].IsLeafNode() {
+				t.Fatalf("expected placement in a leaf node, got %v", pools)
+			}
+
+			// pick the leaf pool NUMA node #4 got assigned to
+			var pool Node
+			for _, p := range pools {
+				if p.IsLeafNode() && p.GetMemset(memoryCXL).String() == tc.expectedCXL {
+					pool = p
+					break
+				}
+			}
+			if pool == nil {
+				t.Fatalf("no leaf pool with CXL NUMA node #%s", tc.expectedCXL)
+			}
+			if dram := pool.GetMemset(memoryDRAM); dram.String() != "0" {
+				t.Errorf("expected CXL NUMA node #%s to be assigned with DRAM node #0, got %s",
+					tc.expectedCXL, dram)
+			}
+
+			c := &mockContainer{returnValueForGetCacheID: "granted"}
+			g := newGrant(pool, c, cpuNormal, cpuset.NewCPUSet(), 0, tc.memType, nil, 0)
+			policy.setDemotionPreferences(c, g)
+			pm := c.GetPageMigration()
+			if pm == nil {
+				t.Fatalf("expected demotion from DRAM to CXL memory, got none")
+			}
+			if pm.SourceNodes.String() != "0" || pm.TargetNodes.String() != tc.expectedCXL {
+				t.Errorf("expected demotion from #0 to #%s, got from #%s to #%s",
+					tc.expectedCXL, pm.SourceNodes, pm.TargetNodes)
+			}
+		})
+	}
+}
+
+func TestLLCPools(t *testing.T) {
+
+	// Check that LLC pools get created only for regular LLC layouts and that
+	// the pool tree honors the configured topology levels.
+
+	dir, err := ioutil.TempDir("", "cri-resource-manager-test-sysfs-")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = utils.UncompressTbz2(path.Join("testdata", "sysfs.tar.bz2"), dir)
+	if err != nil {
+		panic(err)
+	}
+
+	allLevels := []string{SocketLevel, DieLevel, NumaLevel, LLCLevel}
+
+	tcases := []struct {
+		name              string
+		system            string
+		llcs              []string
+		levels            []string
+		expectedPools     int
+		expectedLLCLeaves int
+		expectedLeafCPUs  int
+	}{
+		{
+			name:             "desktop without LLC level",
+			system:           "desktop",
+			llcs:             []string{"0-9", "10-19"},
+			levels:           []string{SocketLevel, DieLevel, NumaLevel},
+			expectedPools:    1,
+			expectedLeafCPUs: 20,
+		},
+		{
+			name:              "desktop with two LLCs",
+			system:            "desktop",
+			llcs:              []string{"0-9", "10-19"},
+			levels:            allLevels,
+			expectedPools:     3,
+			expectedLLCLeaves: 2,
+			expectedLeafCPUs:  10,
+		},
+		{
+			name:             "server with SNC, LLC shared by NUMA nodes of a socket",
+			system:           "server",
+			levels:           allLevels,
+			expectedPools:    7,
+			expectedLeafCPUs: 28,
+		},
+		{
+			name:             "server with NUMA level disabled",
+			system:           "server",
+			levels:           []string{SocketLevel, LLCLevel},
+			expectedPools:    3,
+			expectedLeafCPUs: 56,
+		},
+		{
+			name:             "server with LLCs straddling NUMA nodes",
+			system:           "server",
+			llcs:             []string{"0-55", "56-111"},
+			levels:           allLevels,
+			expectedPools:    7,
+			expectedLeafCPUs: 28,
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			// override LLCs, restoring the original ones once done
+			root := path.Join(dir, "sysfs", tc.system, "sys")
+			for _, llc := range tc.llcs {
+				cpus, err := cpuset.Parse(llc)
+				if err != nil {
+					panic(err)
+				}
+				for _, id := range cpus.ToSlice() {
+					entry := path.Join(root, "devices", "system", "cpu",
+						fmt.Sprintf("cpu%d", id), "cache", "index3", "shared_cpu_list")
+					orig, err := ioutil.ReadFile(entry)
+					if err != nil {
+						panic(err)
+					}
+					defer ioutil.WriteFile(entry, orig, 0644)
+					if err := ioutil.WriteFile(entry, []byte(llc+"\n"), 0644); err != nil {
+						panic(err)
+					}
+				}
+			}
+
+			sys, err := system.DiscoverSystemAt(root)
+			if err != nil {
+				panic(err)
+			}
+
+			saved := opt.TopologyLevels
+			opt.TopologyLevels = tc.levels
+			defer func() { opt.TopologyLevels = saved }()
+
+			reserved, _ := resapi.ParseQuantity("750m")
+			policyOptions := &policyapi.BackendOptions{
+				Cache:     &mockCache{},
+				System:    sys,
+				Available: policyapi.ConstraintSet{},
+				Reserved: policyapi.ConstraintSet{
+					policyapi.DomainCPU: reserved,
+				},
+			}
+
+			policy := CreateTopologyAwarePolicy(policyOptions).(*policy)
+
+			if len(policy.pools) != tc.expectedPools {
+				t.Errorf("expected %d pools, got %d: %v", tc.expectedPools, len(policy.pools), policy.pools)
+			}
+
+			llcLeaves := 0
+			leafMem := uint64(0)
+			for _, p := range policy.pools {
+				if !p.IsLeafNode() {
+					continue
+				}
+				s := p.GetSupply()
+				cpus := s.SharableCPUs().Size() + s.IsolatedCPUs().Size() + s.ReservedCPUs().Size()
+				if cpus != tc.expectedLeafCPUs {
+					t.Errorf("expected %d CPUs in leaf pool %s, got %d", tc.expectedLeafCPUs, p.Name(), cpus)
+				}
+				if p.Kind() == LLCNode {
+					llcLeaves++
+					if mems := p.GetMemset(memoryDRAM); mems.String() != "0" {
+						t.Errorf("expected LLC pool %s to use NUMA node #0, got %s", p.Name(), mems)
+					}
+				}
+				leafMem += s.MemoryLimit()[memoryAll]
+			}
+			if llcLeaves != tc.expectedLLCLeaves {
+				t.Errorf("expected %d LLC leaf pools, got %d", tc.expectedLLCLeaves, llcLeaves)
+			}
+			if rootMem := policy.root.GetSupply().MemoryLimit()[memoryAll]; rootMem != leafMem {
+				t.Errorf("expected leaf pool memory to add up to root memory %d, got %d", rootMem, leafMem)
+			}
+
+			if tc.expectedLLCLeaves > 0 {
+				req := &request{
+					full:      2,
+					memReq:    10000,
+					memLim:    10000,
+					memType:   memoryAll,
+					container: &mockContainer{},
+				}
+				_, pools := policy.sortPoolsByScore(req, nil)
+				if len(pools) == 0 || pools[0].Kind() != LLCNode {
+					t.Errorf("expected placement in an LLC pool, got %v", pools)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryBandwidthThrottling(t *testing.T) {
+
+	// Check that best-effort containers sharing a pool with a container
+	// saturating its memory bandwidth get throttled, and unthrottled once
+	// the pool is no longer saturated.
+
+	dir, err := ioutil.TempDir("", "cri-resource-manager-test-sysfs-")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = utils.UncompressTbz2(path.Join("testdata", "sysfs.tar.bz2"), dir)
+	if err != nil {
+		panic(err)
+	}
+
+	savedOpt, savedReadMBMBytes := *opt, readMBMBytes
+	defer func() {
+		*opt, readMBMBytes = savedOpt, savedReadMBMBytes
+	}()
+	opt.MemoryBandwidthMonitoring = true
+	opt.MemoryBandwidthThrottleClass = "throttled"
+
+	mbm := map[string]uint64{}
+	readMBMBytes = func(c cache.Container) (uint64, bool) {
+		bytes, ok := mbm[c.GetCacheID()]
+		return bytes, ok
+	}
+
+	sys, err := system.DiscoverSystemAt(path.Join(dir, "sysfs", "server", "sys"))
+	if err != nil {
+		panic(err)
+	}
+	reserved, _ := resapi.ParseQuantity("750m")
+	capacity, _ := resapi.ParseQuantity("10G")
+	policyOptions := &policyapi.BackendOptions{
+		Cache:  &mockCache{},
+		System: sys,
+		Available: policyapi.ConstraintSet{
+			policyapi.DomainMemoryBW: capacity,
+		},
+		Reserved: policyapi.ConstraintSet{
+			policyapi.DomainCPU: reserved,
+		},
+	}
+	policy := CreateTopologyAwarePolicy(policyOptions).(*policy)
+
+	// pick two leaf nodes with no common ancestor but the root
+	var loaded, other Node
+	for _, n := range policy.pools {
+		if !n.IsLeafNode() {
+			continue
+		}
+		if loaded == nil {
+			loaded = n
+			continue
+		}
+		if n.Parent().NodeID() != loaded.Parent().NodeID() {
+			other = n
+			break
+		}
+	}
+	if loaded == nil || other == nil {
+		t.Fatalf("failed to find leaf nodes for test")
+	}
+
+	containers := map[string]*mockContainer{
+		"heavy":      {name: "heavy", returnValueForGetCacheID: "heavy"},
+		"besteffort": {name: "besteffort", returnValueForGetCacheID: "besteffort", rdtClass: "original"},
+		"other":      {name: "other", returnValueForGetCacheID: "other", rdtClass: "original"},
+	}
+	for id, c := range containers {
+		node := loaded
+		if id == "other" {
+			node = other
+		}
+		if id != "heavy" {
+			c.returnValueForQOSClass = v1.PodQOSBestEffort
+		}
+		policy.allocations.grants[id] = newGrant(node, c, cpuNormal,
+			cpuset.NewCPUSet(), 0, memoryAll, nil, 0)
+		mbm[id] = 0
+	}
+
+	// the first sample only sets a baseline
+	policy.updateMeasuredBandwidth()
+	policy.updateBandwidthThrottling()
+	if rate := policy.bandwidth["heavy"].rate; rate != 0 {
+		t.Errorf("expected no rate from the first sample, got %d", rate)
+	}
+	for id, c := range containers {
+		if c.GetRDTClass() != "original" && c.GetRDTClass() != "" {
+			t.Errorf("expected %s not to be throttled before saturation, got class %q", id, c.GetRDTClass())
+		}
+	}
+
+	// saturate the loaded node for a second
+	for _, s := range policy.bandwidth {
+		s.time = s.time.Add(-time.Second)
+	}
+	mbm["heavy"] = policy.nodeBandwidthCapacity(loaded)
+	policy.updateMeasuredBandwidth()
+	policy.updateBandwidthThrottling()
+
+	if policy.bandwidth["heavy"].rate == 0 {
+		t.Errorf("expected a measured rate from the second sample")
+	}
+	if class := containers["besteffort"].GetRDTClass(); class != "throttled" {
+		t.Errorf("expected best-effort container in saturated node to be throttled, got class %q", class)
+	}
+	if class := containers["heavy"].GetRDTClass(); class != "" {
+		t.Errorf("expected guaranteed container not to be throttled, got class %q", class)
+	}
+	if class := containers["other"].GetRDTClass(); class != "original" {
+		t.Errorf("expected best-effort container in other node not to be throttled, got class %q", class)
+	}
+
+	// stop the load, check that the original class is restored
+	for _, s := range policy.bandwidth {
+		s.time = s.time.Add(-time.Second)
+	}
+	policy.updateMeasuredBandwidth()
+	policy.updateBandwidthThrottling()
+
+	if class := containers["besteffort"].GetRDTClass(); class != "original" {
+		t.Errorf("expected best-effort container to be unthrottled, got class %q", class)
+	}
+	if _, ok := containers["besteffort"].GetTag(tagUnthrottledRDTClass); ok {
+		t.Errorf("expected unthrottled container to have no %s tag", tagUnthrottledRDTClass)
+	}
+}
+
 func TestContainerMove(t *testing.T) {
 
 	// In case there's not enough memory to guarantee that the
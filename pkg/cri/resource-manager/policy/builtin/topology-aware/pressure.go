@@ -0,0 +1,134 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topologyaware
+
+import (
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/cache"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/events"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/pressure"
+)
+
+const (
+	// tagPressureExclusive tags containers switched to exclusive CPUs due to CPU pressure.
+	tagPressureExclusive = "pressure-exclusive"
+)
+
+// handle resource pressure detected for a container.
+func (p *policy) handlePressure(e *events.Pressure) (bool, error) {
+	c, ok := p.cache.LookupContainer(e.ContainerID)
+	if !ok {
+		log.Debug("pressure: container %s is gone, ignoring event", e.ContainerID)
+		return false, nil
+	}
+	g, ok := p.allocations.grants[c.GetCacheID()]
+	if !ok {
+		log.Debug("pressure: no grant found for %s, nothing to do...", c.PrettyName())
+		return false, nil
+	}
+	if g.CPUType() == cpuReserved {
+		log.Debug("pressure: %s uses reserved CPUs, nothing to do...", c.PrettyName())
+		return false, nil
+	}
+
+	log.Info("pressure: %s under %s pressure (%.2f%%)", c.PrettyName(), e.Resource, e.Avg10)
+
+	switch e.Resource {
+	case pressure.CPU:
+		return p.relieveCPUPressure(c, g)
+	case pressure.Memory:
+		return p.relieveMemoryPressure(c, g)
+	}
+	return false, nil
+}
+
+// switch a container under CPU pressure from shared to exclusive CPUs, if possible.
+func (p *policy) relieveCPUPressure(c cache.Container, g Grant) (bool, error) {
+	if !g.ExclusiveCPUs().IsEmpty() {
+		log.Info("pressure: %s already has exclusive CPUs", c.PrettyName())
+		return false, nil
+	}
+	if _, ok := c.GetTag(tagPressureExclusive); ok {
+		return false, nil
+	}
+
+	pod, ok := c.GetPod()
+	if !ok {
+		return false, policyError("pressure: failed to find pod for %s", c.PrettyName())
+	}
+
+	c.SetTag(tagPressureExclusive, "true")
+	if full, _, _, _ := cpuAllocationPreferences(pod, c); full == 0 {
+		log.Info("pressure: %s is not eligible for exclusive CPUs", c.PrettyName())
+		c.DeleteTag(tagPressureExclusive)
+		return false, nil
+	}
+
+	log.Info("pressure: switching %s to exclusive CPUs", c.PrettyName())
+	pool := g.GetCPUNode().Name()
+	if err := p.reallocateResources([]cache.Container{c}, map[string]string{c.GetCacheID(): pool}); err != nil {
+		c.DeleteTag(tagPressureExclusive)
+		if _, ok := p.allocations.grants[c.GetCacheID()]; !ok {
+			if e := p.reallocateResources([]cache.Container{c}, map[string]string{c.GetCacheID(): pool}); e != nil {
+				log.Error("pressure: failed to restore allocation of %s: %v", c.PrettyName(), e)
+			}
+		}
+		return true, policyError("pressure: failed to switch %s to exclusive CPUs: %v",
+			c.PrettyName(), err)
+	}
+
+	return true, nil
+}
+
+// move a container under memory pressure to a pool with disjoint memory nodes, if possible.
+func (p *policy) relieveMemoryPressure(c cache.Container, g Grant) (bool, error) {
+	current := g.Memset()
+
+	affinity, err := p.calculatePoolAffinities(c)
+	if err != nil {
+		return false, policyError("pressure: failed to calculate affinity for %s: %v",
+			c.PrettyName(), err)
+	}
+
+	_, pools := p.sortPoolsByScore(newRequest(c), affinity)
+
+	var target Node
+	for _, pool := range pools {
+		disjoint := true
+		for _, id := range pool.GetMemset(memoryAll).Members() {
+			if current.Has(id) {
+				disjoint = false
+				break
+			}
+		}
+		if disjoint {
+			target = pool
+			break
+		}
+	}
+	if target == nil {
+		log.Info("pressure: no pool with other memory nodes than %s for %s",
+			current, c.PrettyName())
+		return false, nil
+	}
+
+	log.Info("pressure: moving %s from pool %s to pool %s", c.PrettyName(),
+		g.GetCPUNode().Name(), target.Name())
+	if err := p.reallocateResources([]cache.Container{c}, map[string]string{c.GetCacheID(): target.Name()}); err != nil {
+		return true, policyError("pressure: failed to move %s to pool %s: %v",
+			c.PrettyName(), target.Name(), err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,159 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topologyaware
+
+import (
+	"path"
+	"testing"
+
+	resapi "k8s.io/apimachinery/pkg/api/resource"
+	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/cache"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/events"
+	policyapi "github.com/intel/cri-resource-manager/pkg/cri/resource-manager/policy"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/pressure"
+	system "github.com/intel/cri-resource-manager/pkg/sysfs"
+	"github.com/intel/cri-resource-manager/pkg/utils"
+)
+
+// createPressureTestContainer adds a container with the given CPU request
+// (and an equal limit) to a new Guaranteed pod in the cache.
+func createPressureTestContainer(t *testing.T, cch cache.Cache, podName, namespace string, milliCPU int) cache.Container {
+	podCfg := &criv1.PodSandboxConfig{
+		Metadata: &criv1.PodSandboxMetadata{Name: podName, Uid: podName, Namespace: namespace},
+		Linux:    &criv1.LinuxPodSandboxConfig{CgroupParent: "/kubepods.slice/kubepods-pod" + podName},
+	}
+	if _, err := cch.InsertPod(podName, &criv1.RunPodSandboxRequest{Config: podCfg}, nil); err != nil {
+		t.Fatalf("failed to create pod: %v", err)
+	}
+	c, err := cch.InsertContainer(&criv1.CreateContainerRequest{
+		PodSandboxId: podName,
+		Config: &criv1.ContainerConfig{
+			Metadata: &criv1.ContainerMetadata{Name: "ctr"},
+			Linux: &criv1.LinuxContainerConfig{
+				Resources: &criv1.LinuxContainerResources{
+					CpuShares:          int64(cache.MilliCPUToShares(milliCPU)),
+					CpuQuota:           int64(milliCPU) * 100,
+					CpuPeriod:          100000,
+					MemoryLimitInBytes: 100 << 20,
+				},
+			},
+		},
+		SandboxConfig: podCfg,
+	})
+	if err != nil {
+		t.Fatalf("failed to create container: %v", err)
+	}
+	return c
+}
+
+func TestHandlePressure(t *testing.T) {
+	dir := t.TempDir()
+	if err := utils.UncompressTbz2(path.Join("testdata", "sysfs.tar.bz2"), dir); err != nil {
+		t.Fatalf("failed to uncompress test sysfs: %v", err)
+	}
+	sys, err := system.DiscoverSystemAt(path.Join(dir, "sysfs", "server", "sys"))
+	if err != nil {
+		t.Fatalf("failed to discover test system: %v", err)
+	}
+	cch, err := cache.NewCache(cache.Options{CacheDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	p := CreateTopologyAwarePolicy(&policyapi.BackendOptions{
+		Cache:  cch,
+		System: sys,
+		Reserved: policyapi.ConstraintSet{
+			policyapi.DomainCPU: resapi.MustParse("750m"),
+		},
+	}).(*policy)
+
+	sysCtr := createPressureTestContainer(t, cch, "sys", "kube-system", 100)
+	ctr := createPressureTestContainer(t, cch, "work", "default", 2500)
+	for _, c := range []cache.Container{sysCtr, ctr} {
+		if err := p.AllocateResources(c); err != nil {
+			t.Fatalf("failed to allocate resources for %s: %v", c.PrettyName(), err)
+		}
+	}
+
+	handle := func(c cache.Container, resource string) bool {
+		changed, err := p.HandleEvent(&events.Policy{
+			Type:   events.ContainerPressure,
+			Source: pressure.EventSource,
+			Data: &events.Pressure{
+				ContainerID: c.GetCacheID(),
+				Resource:    resource,
+				Avg10:       50,
+				Threshold:   20,
+			},
+		})
+		if err != nil {
+			t.Fatalf("failed to handle %s pressure of %s: %v", resource, c.PrettyName(), err)
+		}
+		return changed
+	}
+	grant := func(c cache.Container) Grant {
+		g, ok := p.allocations.grants[c.GetCacheID()]
+		if !ok {
+			t.Fatalf("no grant found for %s", c.PrettyName())
+		}
+		return g
+	}
+
+	g := grant(ctr)
+	if !g.ExclusiveCPUs().IsEmpty() {
+		t.Fatalf("expected %s to initially get only shared CPUs, got %s", ctr.PrettyName(), g)
+	}
+	pool := g.GetCPUNode().Name()
+
+	if handle(sysCtr, pressure.CPU) || handle(sysCtr, pressure.Memory) {
+		t.Errorf("expected pressure of a container on reserved CPUs to be ignored")
+	}
+
+	// CPU pressure switches the container to exclusive CPUs in its pool.
+	if !handle(ctr, pressure.CPU) {
+		t.Errorf("expected CPU pressure to change allocations")
+	}
+	g = grant(ctr)
+	if g.ExclusiveCPUs().Size() != 2 || g.SharedPortion() != 500 {
+		t.Errorf("expected 2 exclusive CPUs and 500 mCPU shared after CPU pressure, got %s", g)
+	}
+	if g.GetCPUNode().Name() != pool {
+		t.Errorf("expected %s to stay in pool %s, got %s", ctr.PrettyName(), pool, g.GetCPUNode().Name())
+	}
+	if cpus := ctr.GetCpusetCpus(); cpus != g.ExclusiveCPUs().Union(g.SharedCPUs()).String() {
+		t.Errorf("expected %s pinned to its granted CPUs, got %q", ctr.PrettyName(), cpus)
+	}
+	if handle(ctr, pressure.CPU) {
+		t.Errorf("expected repeated CPU pressure not to change allocations")
+	}
+
+	// Memory pressure moves the container to a pool with other memory nodes.
+	memset := g.Memset()
+	if !handle(ctr, pressure.Memory) {
+		t.Errorf("expected memory pressure to change allocations")
+	}
+	g = grant(ctr)
+	for _, id := range g.Memset().Members() {
+		if memset.Has(id) {
+			t.Errorf("expected memory nodes %s disjoint from %s after memory pressure", g.Memset(), memset)
+			break
+		}
+	}
+	if mems := ctr.GetCpusetMems(); mems != g.Memset().String() {
+		t.Errorf("expected %s pinned to its granted memory %s, got %q", ctr.PrettyName(), g.Memset(), mems)
+	}
+}
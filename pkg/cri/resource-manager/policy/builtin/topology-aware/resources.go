@@ -16,6 +16,7 @@ package topologyaware
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"time"
 
@@ -51,6 +52,12 @@ type Supply interface {
 	Cumulate(Supply)
 	// AssignMemory adds extra memory to this supply (for extra NUMA nodes assigned to a pool).
 	AssignMemory(mem memoryMap)
+	// AssignHugePages adds hugepages to this supply (for NUMA nodes assigned to a pool).
+	AssignHugePages(hugePageMap)
+	// HugePages returns the amount of hugepages belonging to this supply.
+	HugePages() hugePageMap
+	// AllocatableHugePages calculates the allocatable amount of hugepages of the given size.
+	AllocatableHugePages(uint64) uint64
 	// AccountAllocateCPU accounts for (removes) allocated exclusive capacity from the supply.
 	AccountAllocateCPU(Grant)
 	// AccountReleaseCPU accounts for (reinserts) released exclusive capacity into the supply.
@@ -110,6 +117,10 @@ type Request interface {
 	MemAmountToAllocate() uint64
 	// ColdStart returns the cold start timeout.
 	ColdStart() time.Duration
+	// MemoryBandwidth returns the requested memory bandwidth in bytes per second.
+	MemoryBandwidth() uint64
+	// HugePages returns the requested amount of hugepages per page size.
+	HugePages() hugePageMap
 }
 
 // Grant represents CPU and memory capacity allocated to a container from a node.
@@ -158,6 +169,10 @@ type Grant interface {
 	// MemLimit returns the amount of memory that the container is
 	// allowed to use.
 	MemLimit() memoryMap
+	// SetHugePages sets the hugepages granted to the container.
+	SetHugePages(hugePageMap)
+	// HugePages returns the amount of hugepages granted to the container.
+	HugePages() hugePageMap
 	// String returns a printable representation of this grant.
 	String() string
 	// Release releases the grant from all the Supplys it uses.
@@ -196,12 +211,17 @@ type Score interface {
 	SharedCapacity() int
 	Colocated() int
 	HintScores() map[string]float64
+	BandwidthUtilization() float64
+	HugePageCapacity() int64
 
 	String() string
 }
 
 type memoryMap map[memoryType]uint64
 
+// hugePageMap is an amount of hugepage memory in bytes per page size.
+type hugePageMap map[uint64]uint64
+
 // supply implements our Supply interface.
 type supply struct {
 	node                 Node                // node supplying CPUs and memory
@@ -213,6 +233,7 @@ type supply struct {
 	mem                  memoryMap           // available memory for this node
 	grantedMem           memoryMap           // total memory granted
 	extraMemReservations map[Grant]memoryMap // how much memory each workload above has requested
+	hugePages            hugePageMap         // hugepages available at this node
 }
 
 var _ Supply = &supply{}
@@ -235,6 +256,10 @@ type request struct {
 	// initial memory requests are made to the PMEM memory. A value of 0
 	// indicates that cold start is not explicitly requested.
 	coldStart time.Duration
+
+	memBW uint64 // requested memory bandwidth, in bytes per second
+
+	hugePages hugePageMap // requested hugepages
 }
 
 var _ Request = &request{}
@@ -252,6 +277,7 @@ type grant struct {
 	allocatedMem   memoryMap       // memory limit
 	coldStart      time.Duration   // how long until cold start is done
 	coldStartTimer *time.Timer     // timer to trigger cold start timeout
+	hugePages      hugePageMap     // granted hugepages
 }
 
 var _ Grant = &grant{}
@@ -265,6 +291,8 @@ type score struct {
 	shared    int                // remaining shared capacity
 	colocated int                // number of colocated containers
 	hints     map[string]float64 // hint scores
+	bandwidth float64            // memory bandwidth utilization with request
+	hugePages int64              // remaining hugepage capacity with request
 }
 
 var _ Score = &score{}
@@ -288,6 +316,7 @@ func newSupply(n Node, isolated, reserved, sharable cpuset.CPUSet, grantedReserv
 		mem:                  mem,
 		grantedMem:           grantedMem,
 		extraMemReservations: make(map[Grant]memoryMap),
+		hugePages:            make(hugePageMap),
 	}
 }
 
@@ -296,6 +325,7 @@ func createMemoryMap(dram, pmem, hbm uint64) memoryMap {
 		memoryDRAM:   dram,
 		memoryPMEM:   pmem,
 		memoryHBM:    hbm,
+		memoryCXL:    0,
 		memoryAll:    dram + pmem + hbm,
 		memoryUnspec: 0,
 	}
@@ -323,11 +353,45 @@ func (m memoryMap) AddHBM(hbm uint64) {
 	m[memoryAll] += hbm
 }
 
+func (m memoryMap) AddCXL(cxl uint64) {
+	m[memoryCXL] += cxl
+	m[memoryAll] += cxl
+}
+
+// String returns a printable representation of the hugepage amounts.
+func (m hugePageMap) String() string {
+	sizes := make([]uint64, 0, len(m))
+	for size, amount := range m {
+		if amount > 0 {
+			sizes = append(sizes, size)
+		}
+	}
+	sort.Slice(sizes, func(i, j int) bool { return sizes[i] < sizes[j] })
+
+	mem, sep := "", ""
+	for _, size := range sizes {
+		mem += sep + "hugepages-" + prettyMem(size) + " " + prettyMem(m[size])
+		sep = ", "
+	}
+
+	return mem
+}
+
+// IsEmpty returns true if there are no hugepages in the map.
+func (m hugePageMap) IsEmpty() bool {
+	for _, amount := range m {
+		if amount > 0 {
+			return false
+		}
+	}
+	return true
+}
+
 func (m memoryMap) String() string {
 	mem, sep := "", ""
 
-	dram, pmem, hbm, types := m[memoryDRAM], m[memoryPMEM], m[memoryHBM], 0
-	if dram > 0 || pmem > 0 || hbm > 0 {
+	dram, pmem, hbm, cxl, types := m[memoryDRAM], m[memoryPMEM], m[memoryHBM], m[memoryCXL], 0
+	if dram > 0 || pmem > 0 || hbm > 0 || cxl > 0 {
 		if dram > 0 {
 			mem += "DRAM " + prettyMem(dram)
 			sep = ", "
@@ -340,10 +404,15 @@ func (m memoryMap) String() string {
 		}
 		if hbm > 0 {
 			mem += sep + "HBM " + prettyMem(hbm)
+			sep = ", "
+			types++
+		}
+		if cxl > 0 {
+			mem += sep + "CXL " + prettyMem(cxl)
 			types++
 		}
 		if types > 1 {
-			mem += sep + "total " + prettyMem(pmem+dram+hbm)
+			mem += sep + "total " + prettyMem(pmem+dram+hbm+cxl)
 		}
 	}
 
@@ -366,7 +435,9 @@ func (cs *supply) Clone() Supply {
 	for key, value := range cs.grantedMem {
 		grantedMem[key] = value
 	}
-	return newSupply(cs.node, cs.isolated, cs.reserved, cs.sharable, cs.grantedReserved, cs.grantedShared, mem, grantedMem)
+	clone := newSupply(cs.node, cs.isolated, cs.reserved, cs.sharable, cs.grantedReserved, cs.grantedShared, mem, grantedMem)
+	clone.AssignHugePages(cs.hugePages)
+	return clone
 }
 
 // IsolatedCpus returns the isolated CPUSet of this supply.
@@ -419,6 +490,7 @@ func (cs *supply) Cumulate(more Supply) {
 	for key, value := range mcs.grantedMem {
 		cs.grantedMem[key] += value
 	}
+	cs.AssignHugePages(mcs.hugePages)
 }
 
 // AssignMemory adds memory (for extra NUMA nodes assigned to a pool node).
@@ -428,6 +500,55 @@ func (cs *supply) AssignMemory(mem memoryMap) {
 	}
 }
 
+// AssignHugePages adds hugepages (for NUMA nodes assigned to a pool node).
+func (cs *supply) AssignHugePages(hugePages hugePageMap) {
+	for size, amount := range hugePages {
+		cs.hugePages[size] += amount
+	}
+}
+
+// HugePages returns the amount of hugepages belonging to this supply.
+func (cs *supply) HugePages() hugePageMap {
+	return cs.hugePages
+}
+
+// AllocatableHugePages calculates the allocatable amount of hugepages of the given size.
+func (cs *supply) AllocatableHugePages(size uint64) uint64 {
+	//
+	// Notes:
+	//   Hugepages granted from this node, from any node below it, or from
+	//   any of its ancestors can all end up being allocated from the NUMA
+	//   nodes of this one, so we need to take all of them into account.
+	//
+	free := cs.hugePages[size]
+	for _, g := range cs.node.Policy().allocations.grants {
+		amount := g.HugePages()[size]
+		if amount == 0 || !isOnSamePath(cs.node, g.GetMemoryNode()) {
+			continue
+		}
+		if amount >= free {
+			return 0
+		}
+		free -= amount
+	}
+	return free
+}
+
+// isOnSamePath returns true if one of the nodes is the same as or an ancestor of the other.
+func isOnSamePath(n1, n2 Node) bool {
+	for n := n1; !n.IsNil(); n = n.Parent() {
+		if n.IsSameNode(n2) {
+			return true
+		}
+	}
+	for n := n2; !n.IsNil(); n = n.Parent() {
+		if n.IsSameNode(n1) {
+			return true
+		}
+	}
+	return false
+}
+
 // AccountAllocateCPU accounts for (removes) allocated exclusive capacity from the supply.
 func (cs *supply) AccountAllocateCPU(g Grant) {
 	if cs.node.IsSameNode(g.GetCPUNode()) {
@@ -467,7 +588,7 @@ func (cs *supply) allocateMemory(r Request) (memoryMap, error) {
 
 	//
 	// Notes:
-	//   We try to allocate PMEM, then DRAM, and finally HBM, honoring
+	//   We try to allocate PMEM, then CXL, then DRAM, and finally HBM, honoring
 	//   the types allowed by the request. We don't need to care about
 	//   extra memory reservations for this node as all the nodes with
 	//   insufficient memory have been filtered out before allocation.
@@ -477,7 +598,7 @@ func (cs *supply) allocateMemory(r Request) (memoryMap, error) {
 	//   if that check fails.
 	//
 
-	for _, memType := range []memoryType{memoryPMEM, memoryDRAM, memoryHBM} {
+	for _, memType := range []memoryType{memoryPMEM, memoryCXL, memoryDRAM, memoryHBM} {
 		if remaining > 0 && (reqType&memType) != 0 {
 			available := cs.mem[memType]
 
@@ -542,6 +663,7 @@ func (cs *supply) Allocate(r Request) (Grant, error) {
 	}
 
 	grant.SetMemoryAllocation(r.MemoryType(), memory, r.ColdStart())
+	grant.SetHugePages(r.HugePages())
 
 	return grant, nil
 }
@@ -782,6 +904,13 @@ func (cs *supply) takeCPUs(from, to *cpuset.CPUSet, cnt int) (cpuset.CPUSet, err
 func (cs *supply) DumpCapacity() string {
 	cpu, mem, sep := "", cs.mem.String(), ""
 
+	if hp := cs.hugePages.String(); hp != "" {
+		if mem != "" {
+			mem += ", "
+		}
+		mem += hp
+	}
+
 	if !cs.isolated.IsEmpty() {
 		cpu = fmt.Sprintf("isolated:%s", kubernetes.ShortCPUSet(cs.isolated))
 		sep = ", "
@@ -891,7 +1020,7 @@ func prettyMem(value uint64) string {
 
 // DumpMemoryState dumps the state of the available and allocated memory.
 func (cs *supply) DumpMemoryState(prefix string) {
-	memTypes := []memoryType{memoryDRAM, memoryPMEM, memoryHBM}
+	memTypes := []memoryType{memoryDRAM, memoryPMEM, memoryHBM, memoryCXL}
 	totalFree := uint64(0)
 	totalGranted := uint64(0)
 	for _, kind := range memTypes {
@@ -928,6 +1057,11 @@ func (cs *supply) DumpMemoryState(prefix string) {
 				sep = ", "
 				total += mem
 			}
+			if mem := memMap[memoryCXL]; mem > 0 {
+				split += sep + "CXL " + prettyMem(mem)
+				sep = ", "
+				total += mem
+			}
 			if total > 0 {
 				if printHdr {
 					log.Debug(prefix + "- extra reservations:")
@@ -985,6 +1119,8 @@ func newRequest(container cache.Container) Request {
 		memLim:    lim,
 		memType:   mtype,
 		coldStart: coldStart,
+		memBW:     memoryBandwidthPreference(pod, container),
+		hugePages: hugePageAllocationPreference(container),
 	}
 }
 
@@ -995,7 +1131,11 @@ func (cr *request) GetContainer() cache.Container {
 
 // String returns aprintable representation of the CPU request.
 func (cr *request) String() string {
-	mem := "<Memory request: limit:" + prettyMem(cr.memLim) + ", req:" + prettyMem(cr.memReq) + ">"
+	mem := "<Memory request: limit:" + prettyMem(cr.memLim) + ", req:" + prettyMem(cr.memReq)
+	if !cr.hugePages.IsEmpty() {
+		mem += ", " + cr.hugePages.String()
+	}
+	mem += ">"
 	isolated := map[bool]string{false: "", true: "isolated "}[cr.isolate]
 	switch {
 	case cr.full == 0 && cr.fraction == 0:
@@ -1074,6 +1214,16 @@ func (cr *request) ColdStart() time.Duration {
 	return cr.coldStart
 }
 
+// MemoryBandwidth returns the requested memory bandwidth in bytes per second.
+func (cr *request) MemoryBandwidth() uint64 {
+	return cr.memBW
+}
+
+// HugePages returns the requested amount of hugepages per page size.
+func (cr *request) HugePages() hugePageMap {
+	return cr.hugePages
+}
+
 // Score collects data for scoring this supply wrt. the given request.
 func (cs *supply) GetScore(req Request) Score {
 	score := &score{
@@ -1115,6 +1265,16 @@ func (cs *supply) GetScore(req Request) Score {
 		}
 	}
 
+	// calculate memory bandwidth utilization
+	if p := cs.node.Policy(); cr.memBW > 0 && p.bandwidthAware() {
+		score.bandwidth = p.bandwidthUtilization(cs.node, cr.memBW)
+	}
+
+	// calculate remaining hugepage capacity
+	for size, amount := range cr.hugePages {
+		score.hugePages += int64(cs.AllocatableHugePages(size)) - int64(amount)
+	}
+
 	// calculate real hint scores
 	hints := cr.container.GetTopologyHints()
 	score.hints = make(map[string]float64, len(hints))
@@ -1209,9 +1369,17 @@ func (score *score) HintScores() map[string]float64 {
 	return score.hints
 }
 
+func (score *score) BandwidthUtilization() float64 {
+	return score.bandwidth
+}
+
+func (score *score) HugePageCapacity() int64 {
+	return score.hugePages
+}
+
 func (score *score) String() string {
-	return fmt.Sprintf("<CPU score: node %s, isolated:%d, reserved:%d, shared:%d, colocated:%d, hints: %v>",
-		score.supply.GetNode().Name(), score.isolated, score.reserved, score.shared, score.colocated, score.hints)
+	return fmt.Sprintf("<CPU score: node %s, isolated:%d, reserved:%d, shared:%d, colocated:%d, bandwidth:%.2f, hugepages:%d, hints: %v>",
+		score.supply.GetNode().Name(), score.isolated, score.reserved, score.shared, score.colocated, score.bandwidth, score.hugePages, score.hints)
 }
 
 // newGrant creates a CPU grant from the given node for the container.
@@ -1269,6 +1437,7 @@ func (cg *grant) Clone() Grant {
 		memset:       cg.Memset().Clone(),
 		allocatedMem: cg.MemLimit(),
 		coldStart:    cg.ColdStart(),
+		hugePages:    cg.HugePages(),
 	}
 }
 
@@ -1304,7 +1473,7 @@ func (cg *grant) GetMemoryNode() Node {
 
 func (cg *grant) SetMemoryNode(n Node) {
 	cg.memoryNode = n
-	cg.memset = n.GetMemset(cg.MemoryType())
+	cg.memset = cg.withHugePageMemset(n.GetMemset(cg.MemoryType()))
 }
 
 // CPUType returns the requested type of CPU for the grant.
@@ -1368,6 +1537,31 @@ func (cg *grant) MemLimit() memoryMap {
 	return cg.allocatedMem
 }
 
+// SetHugePages sets the granted hugepages, extending the memset to include them.
+func (cg *grant) SetHugePages(hugePages hugePageMap) {
+	cg.hugePages = hugePages
+	cg.memset = cg.withHugePageMemset(cg.memset)
+}
+
+// HugePages returns the granted hugepages.
+func (cg *grant) HugePages() hugePageMap {
+	return cg.hugePages
+}
+
+// withHugePageMemset adds the memory controllers with hugepages to the given memset if necessary.
+func (cg *grant) withHugePageMemset(mems idset.IDSet) idset.IDSet {
+	if cg.hugePages.IsEmpty() {
+		return mems
+	}
+	hpMems := cg.GetMemoryNode().GetHugePageMemset()
+	if hpMems.Size() == 0 || mems.Has(hpMems.Members()...) {
+		return mems
+	}
+	mems = mems.Clone()
+	mems.Add(hpMems.Members()...)
+	return mems
+}
+
 // String returns a printable representation of the CPU grant.
 func (cg *grant) String() string {
 	var cpuType, isolated, exclusive, reserved, shared string
@@ -1392,6 +1586,9 @@ func (cg *grant) String() string {
 	if mem != "" {
 		mem = ", MemLimit: " + mem
 	}
+	if hp := cg.hugePages.String(); hp != "" {
+		mem += ", HugePages: " + hp
+	}
 
 	return fmt.Sprintf("<grant for %s from %s: %s%s%s%s%s%s>",
 		cg.container.PrettyName(), cg.node.Name(), cpuType, isolated, exclusive, reserved, shared, mem)
@@ -1425,7 +1622,7 @@ func (cg *grant) AccountReleaseCPU() {
 
 func (cg *grant) RestoreMemset() {
 	mems := cg.GetMemoryNode().GetMemset(cg.memType)
-	cg.memset = mems
+	cg.memset = cg.withHugePageMemset(mems)
 	cg.GetMemoryNode().Policy().applyGrant(cg)
 }
 
@@ -1446,7 +1643,7 @@ func (cg *grant) ExpandMemset() (bool, error) {
 	}
 	// Else it doesn't fit, so move the grant up in the memory tree.
 	required := uint64(0)
-	for _, memType := range []memoryType{memoryPMEM, memoryDRAM, memoryHBM} {
+	for _, memType := range []memoryType{memoryPMEM, memoryCXL, memoryDRAM, memoryHBM} {
 		required += cg.MemLimit()[memType]
 	}
 	log.Debug("out-of-memory risk in %s: extra reservations %s > free %s -> moving up %s total memory grant from %s",
@@ -15,6 +15,9 @@
 package topologyaware
 
 import (
+	"reflect"
+	"sort"
+
 	v1 "k8s.io/api/core/v1"
 	resapi "k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
@@ -57,22 +60,26 @@ type allocations struct {
 
 // policy is our runtime state for this policy.
 type policy struct {
-	options      *policyapi.BackendOptions // options we were created or reconfigured with
-	cache        cache.Cache               // pod/container cache
-	sys          system.System             // system/HW topology info
-	allowed      cpuset.CPUSet             // bounding set of CPUs we're allowed to use
-	reserved     cpuset.CPUSet             // system-/kube-reserved CPUs
-	reserveCnt   int                       // number of CPUs to reserve if given as resource.Quantity
-	isolated     cpuset.CPUSet             // (our allowed set of) isolated CPUs
-	nodes        map[string]Node           // pool nodes by name
-	pools        []Node                    // pre-populated node slice for scoring, etc...
-	root         Node                      // root of our pool/partition tree
-	nodeCnt      int                       // number of pools
-	depth        int                       // tree depth
-	allocations  allocations               // container pool assignments
-	cpuAllocator cpuallocator.CPUAllocator // CPU allocator used by the policy
-	coldstartOff bool                      // coldstart forced off (have movable PMEM zones)
-	isAlias      bool                      // whether started by referencing AliasName
+	options      *policyapi.BackendOptions   // options we were created or reconfigured with
+	cache        cache.Cache                 // pod/container cache
+	sys          system.System               // system/HW topology info
+	allowed      cpuset.CPUSet               // bounding set of CPUs we're allowed to use
+	reserved     cpuset.CPUSet               // system-/kube-reserved CPUs
+	reserveCnt   int                         // number of CPUs to reserve if given as resource.Quantity
+	isolated     cpuset.CPUSet               // (our allowed set of) isolated CPUs
+	nodes        map[string]Node             // pool nodes by name
+	pools        []Node                      // pre-populated node slice for scoring, etc...
+	root         Node                        // root of our pool/partition tree
+	nodeCnt      int                         // number of pools
+	depth        int                         // tree depth
+	allocations  allocations                 // container pool assignments
+	cpuAllocator cpuallocator.CPUAllocator   // CPU allocator used by the policy
+	coldstartOff bool                        // coldstart forced off (have movable PMEM zones)
+	isAlias      bool                        // whether started by referencing AliasName
+	bandwidth    map[string]*bandwidthSample // measured memory bandwidth by container
+	levels       map[string]bool             // topology levels requested as pools
+	llcAware     bool                        // whether LLC pools are in use
+	llcSpans     map[int]int                 // number of LLCs spanned by pools, by pool id
 }
 
 // Make sure policy implements the policy.Backend interface.
@@ -116,6 +123,35 @@ func createPolicy(opts *policyapi.BackendOptions, isAlias bool) policyapi.Backen
 	return p
 }
 
+// validateTopologyAwareConfig checks the current configuration against the system
+// and the available and reserved resources, without creating a policy instance.
+func validateTopologyAwareConfig(opts *policyapi.BackendOptions) error {
+	return validateConfig(opts, false)
+}
+
+// validateMemtierConfig checks the current configuration of the 'memtier' alias.
+func validateMemtierConfig(opts *policyapi.BackendOptions) error {
+	return validateConfig(opts, true)
+}
+
+// validateConfig checks the current configuration by building a pool tree for it.
+func validateConfig(opts *policyapi.BackendOptions, isAlias bool) error {
+	p := &policy{
+		sys:          opts.System,
+		options:      opts,
+		cpuAllocator: cpuallocator.NewCPUAllocator(opts.System),
+		isAlias:      isAlias,
+	}
+
+	if isAlias {
+		saved := *opt
+		defer func() { *opt = saved }()
+		*opt = *aliasOpt
+	}
+
+	return p.initialize()
+}
+
 // Name returns the name of this policy.
 func (p *policy) Name() string {
 	return PolicyName
@@ -140,7 +176,14 @@ func (p *policy) Start(add []cache.Container, del []cache.Container) error {
 
 	p.root.Dump("<post-start>")
 
-	return p.Sync(add, del)
+	if err := p.Sync(add, del); err != nil {
+		return err
+	}
+
+	// Take the baseline memory bandwidth samples for the first rebalancing.
+	p.updateMeasuredBandwidth()
+
+	return nil
 }
 
 // Sync synchronizes the state of this policy.
@@ -167,6 +210,7 @@ func (p *policy) AllocateResources(container cache.Container) error {
 	}
 	p.applyGrant(grant)
 	p.updateSharedAllocations(&grant)
+	p.updateBandwidthThrottling()
 
 	p.root.Dump("<post-alloc>")
 
@@ -179,6 +223,7 @@ func (p *policy) ReleaseResources(container cache.Container) error {
 
 	if grant, found := p.releasePool(container); found {
 		p.updateSharedAllocations(&grant)
+		p.updateBandwidthThrottling()
 	}
 
 	p.root.Dump("<post-release>")
@@ -196,6 +241,9 @@ func (p *policy) UpdateResources(c cache.Container) error {
 func (p *policy) Rebalance() (bool, error) {
 	var errors error
 
+	// Sample memory bandwidth before any grants are released.
+	p.updateMeasuredBandwidth()
+
 	containers := p.cache.GetContainers()
 	movable := []cache.Container{}
 
@@ -245,6 +293,13 @@ func (p *policy) HandleEvent(e *events.Policy) (bool, error) {
 		}
 		log.Info("finishing coldstart period for %s", c.PrettyName())
 		return p.finishColdStart(c)
+	case events.ContainerPressure:
+		pe, ok := e.Data.(*events.Pressure)
+		if !ok {
+			return false, policyError("%s event: expecting *events.Pressure Data, got %T",
+				e.Type, e.Data)
+		}
+		return p.handlePressure(pe)
 	}
 	return false, nil
 }
@@ -284,8 +339,15 @@ func (p *policy) Introspect(state *introspect.State) {
 			a.SharedCPUs = g.SharedCPUs().String()
 		}
 		assignments[a.ContainerID] = a
+		if pool, ok := pools[a.Pool]; ok {
+			pool.Containers = append(pool.Containers, a.ContainerID)
+		}
+	}
+	for _, pool := range pools {
+		sort.Strings(pool.Containers)
 	}
 	state.Assignments = assignments
+	state.Reserved = p.reserved.String()
 }
 
 // DescribeMetrics generates policy-specific prometheus metrics data descriptors.
@@ -303,6 +365,48 @@ func (p *policy) CollectMetrics(policyapi.Metrics) ([]prometheus.Metric, error)
 	return nil, nil
 }
 
+// Checkpoint takes a checkpoint of the current allocations.
+func (p *policy) Checkpoint() (policyapi.Checkpoint, error) {
+	return p.allocations.clone(), nil
+}
+
+// Restore restores the allocations from a checkpoint.
+func (p *policy) Restore(checkpoint policyapi.Checkpoint) error {
+	cp, ok := checkpoint.(allocations)
+	if !ok {
+		return policyError("invalid checkpoint of type %T", checkpoint)
+	}
+
+	log.Info("restoring allocations from checkpoint...")
+
+	savedPolicy := *p
+	allocations := cp.clone()
+
+	// Rebuild our pools from scratch and reinstate the checkpointed grants.
+	if err := p.initialize(); err != nil {
+		*p = savedPolicy
+		return policyError("failed to restore checkpoint: %v", err)
+	}
+
+	for _, grant := range allocations.grants {
+		if err := grant.RefetchNodes(); err != nil {
+			*p = savedPolicy
+			return policyError("failed to restore checkpoint: %v", err)
+		}
+	}
+
+	// Unlike when restoring from the cache, never fall back to reallocating
+	// grants: the restored state must be exactly the checkpointed one.
+	if err := p.reinstateGrants(allocations.grants); err != nil {
+		*p = savedPolicy
+		return policyError("failed to restore checkpoint: %v", err)
+	}
+
+	p.saveAllocations()
+
+	return nil
+}
+
 // ExportResourceData provides resource data to export for the container.
 func (p *policy) ExportResourceData(c cache.Container) map[string]string {
 	grant, ok := p.allocations.grants[c.GetCacheID()]
@@ -329,6 +433,7 @@ func (p *policy) ExportResourceData(c cache.Container) map[string]string {
 	dram := idset.NewIDSet()
 	pmem := idset.NewIDSet()
 	hbm := idset.NewIDSet()
+	cxl := idset.NewIDSet()
 	for _, id := range mems.SortedMembers() {
 		node := p.sys.Node(id)
 		switch node.GetMemoryType() {
@@ -336,6 +441,8 @@ func (p *policy) ExportResourceData(c cache.Container) map[string]string {
 			dram.Add(id)
 		case system.MemoryTypePMEM:
 			pmem.Add(id)
+		case system.MemoryTypeCXL:
+			cxl.Add(id)
 			/*
 				case system.MemoryTypeHBM:
 					hbm.Add(id)
@@ -352,6 +459,9 @@ func (p *policy) ExportResourceData(c cache.Container) map[string]string {
 	if hbm.Size() > 0 {
 		data["HBM_MEMS"] = hbm.String()
 	}
+	if cxl.Size() > 0 {
+		data["CXL_MEMS"] = cxl.String()
+	}
 
 	return data
 }
@@ -401,10 +511,20 @@ func (p *policy) configNotify(event config.Event, source config.Source) error {
 	log.Info("  - prefer isolated CPUs: %v", opt.PreferIsolated)
 	log.Info("  - prefer shared CPUs: %v", opt.PreferShared)
 	log.Info("  - reserved pool namespaces: %v", opt.ReservedPoolNamespaces)
+	log.Info("  - topology levels: %v", opt.TopologyLevels)
 
 	var allowed, reserved cpuset.CPUSet
 	var reinit bool
 
+	levels, err := topologyLevels(opt.TopologyLevels)
+	if err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(levels, p.levels) {
+		log.Warn("topology levels changed (%v)", opt.TopologyLevels)
+		reinit = true
+	}
+
 	if cpus, ok := p.options.Available[policyapi.DomainCPU]; ok {
 		if cset, ok := cpus.(cpuset.CPUSet); ok {
 			allowed = cset
@@ -539,7 +659,8 @@ func (p *policy) checkConstraints() error {
 		cset, err := p.cpuAllocator.AllocateCpus(&p.allowed, p.reserveCnt, cpuallocator.PriorityNormal)
 		p.allowed = p.allowed.Union(cset)
 		if err != nil {
-			log.Fatal("cannot reserve %dm CPUs for ReservedResources from AvailableResources: %s", qty.MilliValue(), err)
+			return policyError("cannot reserve %dm CPUs for ReservedResources from AvailableResources: %s",
+				qty.MilliValue(), err)
 		}
 		p.reserved = cset
 	}
@@ -607,4 +728,6 @@ func (a *allocations) getContainerPoolHints() ([]cache.Container, map[string]str
 func init() {
 	policyapi.Register(PolicyName, PolicyDescription, CreateTopologyAwarePolicy)
 	policyapi.Register(AliasName, PolicyDescription, CreateMemtierPolicy)
+	policyapi.RegisterValidator(PolicyName, validateTopologyAwareConfig)
+	policyapi.RegisterValidator(AliasName, validateMemtierConfig)
 }
@@ -0,0 +1,82 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topologyaware
+
+import (
+	"testing"
+
+	resapi "k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
+
+	policyapi "github.com/intel/cri-resource-manager/pkg/cri/resource-manager/policy"
+	"github.com/intel/cri-resource-manager/pkg/sysfs"
+	"github.com/intel/cri-resource-manager/pkg/testutils"
+)
+
+func TestValidateConfig(t *testing.T) {
+	sys, err := sysfs.DiscoverSystemAt(testutils.CreateFakeSysfs(t, 4) + "/sys")
+	if err != nil {
+		t.Fatalf("failed to discover fake system: %v", err)
+	}
+
+	tcases := []struct {
+		name          string
+		available     policyapi.ConstraintSet
+		reserved      policyapi.ConstraintSet
+		expectedError bool
+	}{
+		{
+			name:     "reserved cpuset",
+			reserved: policyapi.ConstraintSet{policyapi.DomainCPU: cpuset.MustParse("0")},
+		},
+		{
+			name:     "reserved CPU quantity",
+			reserved: policyapi.ConstraintSet{policyapi.DomainCPU: resapi.MustParse("1500m")},
+		},
+		{
+			name:          "no reserved CPUs",
+			expectedError: true,
+		},
+		{
+			name:          "reserved cpuset not available",
+			available:     policyapi.ConstraintSet{policyapi.DomainCPU: cpuset.MustParse("1-3")},
+			reserved:      policyapi.ConstraintSet{policyapi.DomainCPU: cpuset.MustParse("0")},
+			expectedError: true,
+		},
+		{
+			name:          "reserved CPU quantity exceeds available CPUs",
+			reserved:      policyapi.ConstraintSet{policyapi.DomainCPU: resapi.MustParse("5")},
+			expectedError: true,
+		},
+	}
+	for _, tc := range tcases {
+		for _, isAlias := range []bool{false, true} {
+			name := tc.name
+			if isAlias {
+				name += " with " + AliasName
+			}
+			t.Run(name, func(t *testing.T) {
+				err := validateConfig(&policyapi.BackendOptions{
+					System:    sys,
+					Available: tc.available,
+					Reserved:  tc.reserved,
+				}, isAlias)
+				if (err != nil) != tc.expectedError {
+					t.Errorf("expected error %v, got %v", tc.expectedError, err)
+				}
+			})
+		}
+	}
+}
@@ -96,6 +96,17 @@ func (cs *ConstraintSet) UnmarshalJSON(raw []byte) error {
 			default:
 				return policyError("invalid CPU constraint of type %T", value)
 			}
+		case string(DomainMemoryBW):
+			switch v := value.(type) {
+			case string:
+				if err := set.parseMemoryBW(v); err != nil {
+					return err
+				}
+			case float64:
+				set[DomainMemoryBW] = *resource.NewQuantity(int64(v), resource.DecimalSI)
+			default:
+				return policyError("invalid memory bandwidth constraint of type %T", value)
+			}
 		default:
 			return policyError("internal error: unhandled ConstraintSet domain %s", name)
 		}
@@ -202,6 +213,16 @@ func (cs *ConstraintSet) parseCPUFromCgroup(dir string) error {
 	return nil
 }
 
+func (cs *ConstraintSet) parseMemoryBW(value string) error {
+	qty, err := resource.ParseQuantity(value)
+	if err != nil {
+		return policyError("failed to parse memory bandwidth constraint %q: %v",
+			value, err)
+	}
+	(*cs)[DomainMemoryBW] = qty
+	return nil
+}
+
 func (cs *ConstraintSet) setCPUMilliQuantity(value int) {
 	qty := resource.NewMilliQuantity(int64(value), resource.DecimalSI)
 	(*cs)[DomainCPU] = *qty
@@ -86,6 +86,11 @@ type BackendOptions struct {
 // CreateFn is the type for functions used to create a policy instance.
 type CreateFn func(*BackendOptions) Backend
 
+// ValidateFn is the type for functions used to check policy configuration
+// semantically, without creating a policy instance. The options carry no
+// cache and the system must not be altered.
+type ValidateFn func(*BackendOptions) error
+
 // SendEventFn is the type for a function to send events back to the resource manager.
 type SendEventFn func(interface{}) error
 
@@ -136,6 +141,19 @@ type Backend interface {
 	CollectMetrics(Metrics) ([]prometheus.Metric, error)
 }
 
+// Checkpoint is an opaque checkpoint of the allocation state of a policy backend.
+type Checkpoint interface{}
+
+// Checkpointer is implemented by backends that can checkpoint and restore
+// their allocation state. This allows the resource manager to roll back a
+// failed allocation transactionally.
+type Checkpointer interface {
+	// Checkpoint takes a checkpoint of the current allocation state.
+	Checkpoint() (Checkpoint, error)
+	// Restore restores the allocation state from a checkpoint.
+	Restore(Checkpoint) error
+}
+
 // Policy is the exposed interface for container resource allocations decision making.
 type Policy interface {
 	// Start starts up policy, prepare for serving resource management requests.
@@ -164,6 +182,10 @@ type Policy interface {
 	PollMetrics() Metrics
 	// CollectMetrics generates prometheus metrics from cached/polled policy-specific metrics data.
 	CollectMetrics(Metrics) ([]prometheus.Metric, error)
+	// Checkpoint takes a checkpoint of the allocation state of the active policy.
+	Checkpoint() (Checkpoint, error)
+	// Restore restores the allocation state of the active policy from a checkpoint.
+	Restore(Checkpoint) error
 }
 
 type Metrics interface{}
@@ -180,9 +202,10 @@ type policy struct {
 
 // backend is a registered Backend.
 type backend struct {
-	name        string   // unqiue backend name
-	description string   // verbose backend description
-	create      CreateFn // backend creation function
+	name        string     // unqiue backend name
+	description string     // verbose backend description
+	create      CreateFn   // backend creation function
+	validate    ValidateFn // optional backend configuration validation function
 }
 
 // Out logger instance.
@@ -410,6 +433,24 @@ func (p *policy) CollectMetrics(m Metrics) ([]prometheus.Metric, error) {
 	return p.active.CollectMetrics(m)
 }
 
+// Checkpoint takes a checkpoint of the allocation state of the active policy.
+func (p *policy) Checkpoint() (Checkpoint, error) {
+	cp, ok := p.active.(Checkpointer)
+	if !ok {
+		return nil, policyError("policy '%s' does not support checkpointing", p.active.Name())
+	}
+	return cp.Checkpoint()
+}
+
+// Restore restores the allocation state of the active policy from a checkpoint.
+func (p *policy) Restore(checkpoint Checkpoint) error {
+	cp, ok := p.active.(Checkpointer)
+	if !ok {
+		return policyError("policy '%s' does not support checkpointing", p.active.Name())
+	}
+	return cp.Restore(checkpoint)
+}
+
 // Register registers a policy backend.
 func Register(name, description string, create CreateFn) error {
 	log.Info("registering policy '%s'...", name)
@@ -427,6 +468,25 @@ func Register(name, description string, create CreateFn) error {
 	return nil
 }
 
+// RegisterValidator registers a configuration validation function for a policy backend.
+func RegisterValidator(name string, validate ValidateFn) error {
+	b, ok := backends[name]
+	if !ok {
+		return policyError("can't register validator for unknown policy %s", name)
+	}
+	b.validate = validate
+	return nil
+}
+
+// CreateBackend creates an instance of the registered policy backend with the given name.
+func CreateBackend(name string, opts *BackendOptions) (Backend, error) {
+	b, ok := backends[name]
+	if !ok {
+		return nil, policyError("unknown policy '%s' requested", name)
+	}
+	return b.create(opts), nil
+}
+
 // ConstraintToString returns the given constraint as a string.
 func ConstraintToString(value Constraint) string {
 	switch value.(type) {
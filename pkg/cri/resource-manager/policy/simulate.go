@@ -0,0 +1,203 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/intel/cri-resource-manager/pkg/config"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/cache"
+	system "github.com/intel/cri-resource-manager/pkg/sysfs"
+	idset "github.com/intel/goresctrl/pkg/utils"
+)
+
+// Assignment describes the resources assigned to a container.
+type Assignment struct {
+	// CPUs is the cpuset.cpus of the container.
+	CPUs string `json:"cpus,omitempty"`
+	// Mems is the cpuset.mems of the container.
+	Mems string `json:"mems,omitempty"`
+	// RDTClass is the RDT class of the container.
+	RDTClass string `json:"rdtClass,omitempty"`
+	// BlockIOClass is the block I/O class of the container.
+	BlockIOClass string `json:"blockioClass,omitempty"`
+}
+
+// AssignmentChange describes how the assignment of a container would change.
+type AssignmentChange struct {
+	// ID is the cache ID of the container.
+	ID string `json:"id"`
+	// Name is the pretty name of the container.
+	Name string `json:"name"`
+	// Current is the current assignment of the container.
+	Current Assignment `json:"current"`
+	// Simulated is the assignment of the container under the simulated configuration.
+	Simulated Assignment `json:"simulated"`
+}
+
+// Simulation is the result of a policy simulation.
+type Simulation struct {
+	// Policy is the name of the simulated policy.
+	Policy string `json:"policy"`
+	// Changes lists the containers with a different assignment.
+	Changes []*AssignmentChange `json:"changes,omitempty"`
+	// Unchanged is the number of containers with an unchanged assignment.
+	Unchanged int `json:"unchanged"`
+}
+
+// simulatedSystem is a system.System that does not alter the state of the system.
+type simulatedSystem struct {
+	system.System
+}
+
+// SetCpusOnline pretends to change the online state of the given CPUs.
+func (s *simulatedSystem) SetCpusOnline(online bool, cpus idset.IDSet) (idset.IDSet, error) {
+	return idset.NewIDSet(), nil
+}
+
+// SetCPUFrequencyLimits pretends to set the frequency limits of the given CPUs.
+func (s *simulatedSystem) SetCPUFrequencyLimits(min, max uint64, cpus idset.IDSet) error {
+	return nil
+}
+
+// Simulate runs the policy selected by the given configuration against a cache
+// snapshot and reports the resulting changes in container assignments. The
+// policy is run against the given system, which can be a fake one or one
+// discovered from a topology archive. If sys is nil the host is discovered.
+// Neither the running configuration nor the system is changed by the simulation.
+func Simulate(sys system.System, snapshot []byte, cfg map[string]string) (*Simulation, error) {
+	return simulate(sys, snapshot, func(fn func() error) error {
+		return config.EvaluateConfig(cfg, fn)
+	})
+}
+
+// SimulateFromFile is like Simulate but takes the configuration from a file.
+func SimulateFromFile(sys system.System, snapshot []byte, path string) (*Simulation, error) {
+	return simulate(sys, snapshot, func(fn func() error) error {
+		return config.EvaluateConfigFromFile(path, fn)
+	})
+}
+
+// simulate runs a simulation using the given configuration evaluation function.
+func simulate(sys system.System, snapshot []byte, evaluate func(func() error) error) (*Simulation, error) {
+	dir, err := ioutil.TempDir("", "cri-resmgr-simulate-")
+	if err != nil {
+		return nil, policyError("simulation: failed to create cache directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "cache"), snapshot, 0644); err != nil {
+		return nil, policyError("simulation: failed to write cache snapshot: %v", err)
+	}
+	cch, err := cache.NewCache(cache.Options{CacheDir: dir})
+	if err != nil {
+		return nil, policyError("simulation: failed to restore cache snapshot: %v", err)
+	}
+	if err := cch.ResetActivePolicy(); err != nil {
+		return nil, policyError("simulation: failed to reset cached policy: %v", err)
+	}
+
+	containers := cch.GetContainers()
+	cache.SortContainers(containers)
+	current := make(map[string]Assignment, len(containers))
+	for _, c := range containers {
+		current[c.GetCacheID()] = getAssignment(c)
+	}
+
+	if sys == nil {
+		if sys, err = system.DiscoverSystem(); err != nil {
+			return nil, policyError("simulation: failed to discover system topology: %v", err)
+		}
+	}
+
+	sim := &Simulation{}
+	err = evaluate(func() error {
+		active, ok := backends[opt.Policy]
+		if !ok {
+			return policyError("unknown policy '%s' requested", opt.Policy)
+		}
+		sim.Policy = active.name
+
+		log.Info("simulating '%s' policy...", active.name)
+
+		backend := active.create(&BackendOptions{
+			System:    &simulatedSystem{System: sys},
+			Cache:     cch,
+			Available: opt.Available,
+			Reserved:  opt.Reserved,
+			SendEvent: func(interface{}) error { return nil },
+		})
+		if err := backend.Start(containers, containers); err != nil {
+			return policyError("simulation: failed to start policy %s: %v", active.name, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range containers {
+		id := c.GetCacheID()
+		simulated := getAssignment(c)
+		if simulated == current[id] {
+			sim.Unchanged++
+			continue
+		}
+		sim.Changes = append(sim.Changes, &AssignmentChange{
+			ID:        id,
+			Name:      c.PrettyName(),
+			Current:   current[id],
+			Simulated: simulated,
+		})
+	}
+
+	return sim, nil
+}
+
+// getAssignment returns the current assignment of the container.
+func getAssignment(c cache.Container) Assignment {
+	return Assignment{
+		CPUs:         c.GetCpusetCpus(),
+		Mems:         c.GetCpusetMems(),
+		RDTClass:     c.GetRDTClass(),
+		BlockIOClass: c.GetBlockIOClass(),
+	}
+}
+
+// String returns the simulation result in a human-readable form.
+func (s *Simulation) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "policy %s: %d container(s) changed, %d unchanged\n",
+		s.Policy, len(s.Changes), s.Unchanged)
+	for _, c := range s.Changes {
+		fmt.Fprintf(&b, "  %s (%s):\n", c.Name, c.ID)
+		diff := func(what, cur, sim string) {
+			if cur != sim {
+				fmt.Fprintf(&b, "    %s: %q => %q\n", what, cur, sim)
+			}
+		}
+		diff("cpus", c.Current.CPUs, c.Simulated.CPUs)
+		diff("mems", c.Current.Mems, c.Simulated.Mems)
+		diff("rdt class", c.Current.RDTClass, c.Simulated.RDTClass)
+		diff("blockio class", c.Current.BlockIOClass, c.Simulated.BlockIOClass)
+	}
+
+	return b.String()
+}
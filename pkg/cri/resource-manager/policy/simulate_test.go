@@ -0,0 +1,193 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy_test
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
+
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/cache"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/policy"
+	_ "github.com/intel/cri-resource-manager/pkg/cri/resource-manager/policy/builtin/static"
+	system "github.com/intel/cri-resource-manager/pkg/sysfs"
+)
+
+// fakeSystem discovers a single NUMA node system with the given number of CPUs
+// from a fake sysfs tree.
+func fakeSystem(t *testing.T, cpus int) system.System {
+	last := strconv.Itoa(cpus - 1)
+	files := map[string]string{
+		"sys/devices/system/cpu/online":             "0-" + last + "\n",
+		"sys/devices/system/cpu/isolated":           "\n",
+		"sys/devices/system/node/has_memory":        "0\n",
+		"sys/devices/system/node/has_normal_memory": "0\n",
+		"sys/devices/system/node/node0/cpulist":     "0-" + last + "\n",
+		"sys/devices/system/node/node0/distance":    "10\n",
+		"sys/devices/system/node/node0/meminfo":     "Node 0 MemTotal:       16384000 kB\nNode 0 MemFree:        8192000 kB\nNode 0 MemUsed:        8192000 kB\n",
+	}
+	for cpu := 0; cpu < cpus; cpu++ {
+		dir := filepath.Join("sys/devices/system/cpu", "cpu"+strconv.Itoa(cpu))
+		files[filepath.Join(dir, "online")] = "1\n"
+		files[filepath.Join(dir, "topology/physical_package_id")] = "0\n"
+		files[filepath.Join(dir, "topology/die_id")] = "0\n"
+		files[filepath.Join(dir, "topology/core_id")] = strconv.Itoa(cpu) + "\n"
+		files[filepath.Join(dir, "topology/thread_siblings_list")] = strconv.Itoa(cpu) + "\n"
+	}
+
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+	for cpu := 0; cpu < cpus; cpu++ {
+		link := filepath.Join(root, "sys/devices/system/cpu", "cpu"+strconv.Itoa(cpu), "node0")
+		if err := os.Symlink("../../node/node0", link); err != nil {
+			t.Fatalf("failed to create symlink: %v", err)
+		}
+	}
+
+	sys, err := system.DiscoverSystemAt(filepath.Join(root, "sys"))
+	if err != nil {
+		t.Fatalf("failed to discover fake system: %v", err)
+	}
+	return sys
+}
+
+// fakeSnapshot creates a cache snapshot with a guaranteed container asking for
+// the given number of CPUs and a best-effort container.
+func fakeSnapshot(t *testing.T, cpus int, assigned string) []byte {
+	cch, err := cache.NewCache(cache.Options{CacheDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+
+	pods := []struct {
+		name      string
+		qos       string
+		resources criv1.LinuxContainerResources
+	}{
+		{
+			name: "guaranteed",
+			qos:  "/kubepods.slice/kubepods-podguaranteed",
+			resources: criv1.LinuxContainerResources{
+				CpuShares:          int64(cpus * 1024),
+				CpuQuota:           int64(cpus * 100000),
+				CpuPeriod:          100000,
+				MemoryLimitInBytes: 1 << 30,
+			},
+		},
+		{
+			name: "besteffort",
+			qos:  "/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-podbesteffort",
+			resources: criv1.LinuxContainerResources{
+				CpuShares: 2,
+			},
+		},
+	}
+
+	for _, p := range pods {
+		podCfg := &criv1.PodSandboxConfig{
+			Metadata: &criv1.PodSandboxMetadata{Name: p.name, Uid: p.name, Namespace: "default"},
+			Linux:    &criv1.LinuxPodSandboxConfig{CgroupParent: p.qos},
+		}
+		if _, err := cch.InsertPod(p.name, &criv1.RunPodSandboxRequest{Config: podCfg}, nil); err != nil {
+			t.Fatalf("failed to create pod: %v", err)
+		}
+		resources := p.resources
+		c, err := cch.InsertContainer(&criv1.CreateContainerRequest{
+			PodSandboxId: p.name,
+			Config: &criv1.ContainerConfig{
+				Metadata: &criv1.ContainerMetadata{Name: "ctr"},
+				Linux:    &criv1.LinuxContainerConfig{Resources: &resources},
+			},
+			SandboxConfig: podCfg,
+		})
+		if err != nil {
+			t.Fatalf("failed to create container: %v", err)
+		}
+		if p.name == "guaranteed" {
+			c.SetCpusetCpus(assigned)
+		}
+	}
+
+	snapshot, err := cch.Snapshot()
+	if err != nil {
+		t.Fatalf("failed to take cache snapshot: %v", err)
+	}
+	return snapshot
+}
+
+func TestSimulate(t *testing.T) {
+	sys := fakeSystem(t, 8)
+	cfg := map[string]string{
+		"policy": "Active: static\nReservedResources:\n  CPU: 1\n",
+	}
+
+	sim, err := policy.Simulate(sys, fakeSnapshot(t, 2, ""), cfg)
+	if err != nil {
+		t.Fatalf("simulation failed: %v", err)
+	}
+	if sim.Policy != "static" {
+		t.Errorf("expected static policy to be simulated, got %q", sim.Policy)
+	}
+
+	var exclusive *policy.AssignmentChange
+	for _, c := range sim.Changes {
+		if c.Name == "guaranteed:ctr" {
+			exclusive = c
+		}
+	}
+	if exclusive == nil {
+		t.Fatalf("expected a change for the guaranteed container, got %v", sim.Changes)
+	}
+	cpus, err := cpuset.Parse(exclusive.Simulated.CPUs)
+	if err != nil || cpus.Size() != 2 {
+		t.Fatalf("expected 2 exclusive CPUs for the guaranteed container, got %q", exclusive.Simulated.CPUs)
+	}
+	if !cpus.IsSubsetOf(sys.CPUSet()) {
+		t.Errorf("expected exclusive CPUs from the fake system, got %s", cpus)
+	}
+
+	// Simulating the same configuration against its own result changes nothing.
+	sim, err = policy.Simulate(sys, fakeSnapshot(t, 2, exclusive.Simulated.CPUs), cfg)
+	if err != nil {
+		t.Fatalf("simulation failed: %v", err)
+	}
+	for _, c := range sim.Changes {
+		if c.Name == "guaranteed:ctr" {
+			t.Errorf("expected no change for the guaranteed container, got %q => %q",
+				c.Current.CPUs, c.Simulated.CPUs)
+		}
+	}
+	if sim.Unchanged < 1 {
+		t.Errorf("expected unchanged containers, got %d", sim.Unchanged)
+	}
+
+	if _, err := policy.Simulate(sys, fakeSnapshot(t, 2, ""), map[string]string{
+		"policy": "Active: no-such-policy\n",
+	}); err == nil {
+		t.Errorf("expected simulating an unknown policy to fail")
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"github.com/intel/cri-resource-manager/pkg/config"
+	system "github.com/intel/cri-resource-manager/pkg/sysfs"
+)
+
+// ValidateConfig checks the given configuration, including the semantic checks
+// of the policy it selects, without taking it into use. Problems found are
+// returned as config.ValidationErrors.
+func ValidateConfig(cfg map[string]string) error {
+	return validate(func(fn func() error) error {
+		return config.ValidateConfig(cfg, fn)
+	})
+}
+
+// ValidateConfigFromFile is like ValidateConfig but takes the configuration from a file.
+func ValidateConfigFromFile(path string) error {
+	return validate(func(fn func() error) error {
+		return config.ValidateConfigFromFile(path, fn)
+	})
+}
+
+// validate runs the checks of the policy selected by the evaluated configuration.
+func validate(evaluate func(func() error) error) error {
+	sys, err := system.DiscoverSystem()
+	if err != nil {
+		return policyError("validation: failed to discover system topology: %v", err)
+	}
+
+	return evaluate(func() error {
+		active, ok := backends[opt.Policy]
+		if !ok {
+			return &config.ValidationError{
+				Module: ConfigPath,
+				Reason: policyError("unknown policy '%s' requested", opt.Policy).Error(),
+			}
+		}
+		if active.validate == nil {
+			log.Info("policy '%s' has no configuration validation", active.name)
+			return nil
+		}
+
+		log.Info("validating '%s' policy configuration...", active.name)
+
+		err := active.validate(&BackendOptions{
+			System:    &simulatedSystem{System: sys},
+			Available: opt.Available,
+			Reserved:  opt.Reserved,
+			SendEvent: func(interface{}) error { return nil },
+		})
+		if err != nil {
+			return config.AsValidationErrors(ConfigPath+"."+active.name, err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,116 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pressure
+
+import (
+	"sync"
+	"time"
+
+	"github.com/intel/cri-resource-manager/pkg/config"
+)
+
+const (
+	// ConfigPath is the configuration path for pressure monitoring.
+	ConfigPath = "resource-manager.pressure"
+	// ConfigDescription is the description for pressure monitoring.
+	ConfigDescription = "container resource pressure monitoring"
+
+	// defaultPollInterval is the default interval for reading pressure.
+	defaultPollInterval = 10 * time.Second
+	// defaultTriggerWindow is the default PSI trigger time window.
+	defaultTriggerWindow = 2 * time.Second
+	// defaultCooldown is the default minimum time between events.
+	defaultCooldown = time.Minute
+)
+
+// options captures our configurable parameters.
+type options struct {
+	// CPUThreshold is the 'some' CPU stall time, in percent, above which
+	// a container is considered to be under CPU pressure. 0 disables it.
+	CPUThreshold float64
+	// MemoryThreshold is the 'some' memory stall time, in percent, above
+	// which a container is considered to be under memory pressure. 0
+	// disables it.
+	MemoryThreshold float64
+	// PollInterval is the interval for rescanning containers and for
+	// reading pressure of containers without a PSI trigger.
+	PollInterval config.Duration
+	// TriggerWindow is the PSI trigger time window, between 500ms and
+	// 10s. 0 disables PSI triggers and falls back to polling.
+	TriggerWindow config.Duration
+	// Cooldown is the minimum time between two events for the same
+	// container and resource.
+	Cooldown config.Duration
+}
+
+// Our runtime configuration.
+var opt = defaultOptions().(*options)
+
+// A copy of our runtime configuration for the monitor goroutine, which
+// must not read opt while configuration updates are written into it.
+var (
+	optLock sync.Mutex
+	current = *opt
+)
+
+// configNotify takes a copy of our updated runtime configuration.
+func (o *options) configNotify(event config.Event, source config.Source) error {
+	optLock.Lock()
+	defer optLock.Unlock()
+	current = *o
+	return nil
+}
+
+// getOptions returns a copy of our current runtime configuration.
+func getOptions() *options {
+	optLock.Lock()
+	defer optLock.Unlock()
+	o := current
+	return &o
+}
+
+// threshold returns the configured threshold for the given resource.
+func (o *options) threshold(resource string) float64 {
+	switch resource {
+	case CPU:
+		return o.CPUThreshold
+	case Memory:
+		return o.MemoryThreshold
+	}
+	return 0
+}
+
+// pollInterval returns the effective polling interval.
+func (o *options) pollInterval() time.Duration {
+	if o.PollInterval <= 0 {
+		return defaultPollInterval
+	}
+	return time.Duration(o.PollInterval)
+}
+
+// defaultOptions returns a new options instance, all initialized to defaults.
+func defaultOptions() interface{} {
+	return &options{
+		PollInterval:  config.Duration(defaultPollInterval),
+		TriggerWindow: config.Duration(defaultTriggerWindow),
+		Cooldown:      config.Duration(defaultCooldown),
+	}
+}
+
+// Register us for configuration handling.
+func init() {
+	config.Register(ConfigPath, ConfigDescription, opt, defaultOptions,
+		config.WithNotify(opt.configNotify))
+}
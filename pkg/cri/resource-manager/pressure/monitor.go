@@ -0,0 +1,361 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pressure
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/intel/cri-resource-manager/pkg/cgroups"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/events"
+	logger "github.com/intel/cri-resource-manager/pkg/log"
+)
+
+const (
+	// CPU is the name of the CPU pressure resource.
+	CPU = "cpu"
+	// Memory is the name of the memory pressure resource.
+	Memory = "memory"
+	// EventSource is the source of the policy events we generate.
+	EventSource = "pressure"
+
+	// minimum and maximum PSI trigger windows accepted by the kernel
+	minTriggerWindow = 500 * time.Millisecond
+	maxTriggerWindow = 10 * time.Second
+)
+
+// resources we monitor for pressure.
+var resources = []string{CPU, Memory}
+
+// Target is a container to monitor for pressure.
+type Target struct {
+	// ID is the cache ID of the container.
+	ID string
+	// CgroupDir is the container cgroup directory relative to the cgroup v2 mount point.
+	CgroupDir string
+}
+
+// Lister returns the containers to monitor.
+type Lister func() []Target
+
+// Sender delivers an event for processing.
+type Sender func(interface{}) error
+
+// Monitor watches cgroup v2 PSI of containers and sends policy events on pressure.
+type Monitor struct {
+	list     Lister                                                     // list monitored containers
+	send     Sender                                                     // send pressure events
+	read     func(cgroupDir, resource string) (cgroups.Pressure, error) // read pressure
+	root     string                                                     // cgroup v2 mount point
+	wakeup   *os.File                                                   // write end of stop pipe
+	triggers map[key]*trigger                                           // PSI triggers
+	last     map[key]time.Time                                          // time of last event
+	lastPoll time.Time                                                  // time of last poll
+}
+
+// key identifies a monitored container resource.
+type key struct {
+	id       string
+	resource string
+}
+
+// trigger is a PSI trigger, or a failed attempt to set one up.
+type trigger struct {
+	file *os.File // PSI file with trigger, nil if not supported
+	spec string   // trigger specification
+}
+
+// Our logger instance.
+var log = logger.NewLogger("pressure")
+
+// NewMonitor creates a pressure monitor for the containers listed.
+func NewMonitor(list Lister, send Sender) *Monitor {
+	m := &Monitor{
+		list: list,
+		send: send,
+		root: cgroups.GetMountDir(),
+	}
+	m.read = m.readPressure
+	return m
+}
+
+// Start starts pressure monitoring.
+func (m *Monitor) Start() error {
+	if m.wakeup != nil {
+		return nil
+	}
+	if cgroups.DetectSystemCgroupVersion() != 2 {
+		log.Info("cgroup v2 not detected, pressure monitoring is disabled")
+		return nil
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return pressureError("failed to create stop pipe: %v", err)
+	}
+	m.wakeup = w
+	m.triggers = make(map[key]*trigger)
+	m.last = make(map[key]time.Time)
+
+	go m.run(r)
+
+	return nil
+}
+
+// Stop stops pressure monitoring.
+func (m *Monitor) Stop() {
+	if m.wakeup != nil {
+		m.wakeup.Close()
+		m.wakeup = nil
+	}
+}
+
+// run is the main loop of pressure monitoring.
+func (m *Monitor) run(stop *os.File) {
+	defer stop.Close()
+	defer m.closeTriggers()
+
+	for {
+		o := getOptions()
+		var targets []Target
+		if o.CPUThreshold > 0 || o.MemoryThreshold > 0 {
+			targets = m.list()
+		}
+		m.syncTriggers(o, targets)
+
+		interval := o.pollInterval()
+		timeout := time.Until(m.lastPoll.Add(interval))
+		fired, stopped := m.wait(stop, timeout)
+		if stopped {
+			return
+		}
+
+		now := time.Now()
+		periodic := !now.Before(m.lastPoll.Add(interval))
+		if periodic {
+			m.lastPoll = now
+		}
+		m.check(o, targets, fired, periodic, now)
+	}
+}
+
+// check checks the given containers for pressure, sending events as necessary.
+func (m *Monitor) check(o *options, targets []Target, fired map[key]bool, periodic bool, now time.Time) {
+	cooldown := time.Duration(o.Cooldown)
+	active := make(map[key]struct{})
+
+	for _, t := range targets {
+		for _, resource := range resources {
+			threshold := o.threshold(resource)
+			if threshold <= 0 {
+				continue
+			}
+			k := key{id: t.ID, resource: resource}
+			active[k] = struct{}{}
+
+			trg, hasTrigger := m.triggers[k]
+			hasTrigger = hasTrigger && trg.file != nil
+			if !fired[k] && (hasTrigger || !periodic) {
+				continue
+			}
+
+			p, err := m.read(t.CgroupDir, resource)
+			if err != nil {
+				log.Debug("failed to read %s pressure of %s: %v", resource, t.ID, err)
+				continue
+			}
+			avg10 := 0.0
+			if p.Some != nil {
+				avg10 = p.Some.Avg10
+			}
+			if !fired[k] && avg10 < threshold {
+				continue
+			}
+			if last, ok := m.last[k]; ok && now.Sub(last) < cooldown {
+				continue
+			}
+			m.last[k] = now
+
+			log.Info("%s: %s pressure %.2f%% (threshold %.2f%%)", t.ID, resource, avg10, threshold)
+			e := &events.Policy{
+				Type:   events.ContainerPressure,
+				Source: EventSource,
+				Data: &events.Pressure{
+					ContainerID: t.ID,
+					Resource:    resource,
+					Avg10:       avg10,
+					Threshold:   threshold,
+				},
+			}
+			if err := m.send(e); err != nil {
+				log.Error("failed to send %s pressure event for %s: %v", resource, t.ID, err)
+			}
+		}
+	}
+
+	for k := range m.last {
+		if _, ok := active[k]; !ok {
+			delete(m.last, k)
+		}
+	}
+}
+
+// syncTriggers sets up and tears down PSI triggers for the given containers.
+func (m *Monitor) syncTriggers(o *options, targets []Target) {
+	window := time.Duration(o.TriggerWindow)
+	if window != 0 && (window < minTriggerWindow || window > maxTriggerWindow) {
+		log.Warn("invalid PSI trigger window %v (not within [%v, %v]), using polling",
+			window, minTriggerWindow, maxTriggerWindow)
+		window = 0
+	}
+
+	wanted := make(map[key]string)
+	if window != 0 {
+		for _, t := range targets {
+			for _, resource := range resources {
+				threshold := o.threshold(resource)
+				if threshold <= 0 {
+					continue
+				}
+				wanted[key{id: t.ID, resource: resource}] = t.CgroupDir
+			}
+		}
+	}
+
+	for k, trg := range m.triggers {
+		if _, ok := wanted[k]; !ok || trg.spec != triggerSpec(o.threshold(k.resource), window) {
+			trg.close()
+			delete(m.triggers, k)
+		}
+	}
+
+	for k, dir := range wanted {
+		if _, ok := m.triggers[k]; ok {
+			continue
+		}
+		spec := triggerSpec(o.threshold(k.resource), window)
+		trg := &trigger{spec: spec}
+		path := filepath.Join(m.root, dir, k.resource+".pressure")
+		f, err := openTrigger(path, spec)
+		if err != nil {
+			log.Debug("%s: failed to set up PSI trigger, using polling: %v", k.id, err)
+		} else {
+			trg.file = f
+		}
+		m.triggers[k] = trg
+	}
+}
+
+// wait waits for PSI triggers to fire, a timeout or for a stop request.
+func (m *Monitor) wait(stop *os.File, timeout time.Duration) (map[key]bool, bool) {
+	fds := []unix.PollFd{{Fd: int32(stop.Fd()), Events: unix.POLLIN}}
+	keys := []key{{}}
+	for k, trg := range m.triggers {
+		if trg.file == nil {
+			continue
+		}
+		fds = append(fds, unix.PollFd{Fd: int32(trg.file.Fd()), Events: unix.POLLPRI})
+		keys = append(keys, k)
+	}
+
+	if timeout < 0 {
+		timeout = 0
+	}
+	_, err := unix.Poll(fds, int(timeout.Milliseconds()))
+	if err != nil {
+		if err != unix.EINTR {
+			log.Error("failed to poll PSI triggers: %v", err)
+		}
+		return nil, false
+	}
+
+	if fds[0].Revents != 0 {
+		return nil, true
+	}
+
+	fired := make(map[key]bool)
+	for i, fd := range fds[1:] {
+		k := keys[i+1]
+		switch {
+		case fd.Revents&unix.POLLERR != 0:
+			// cgroup is gone, trigger will be set up again if it comes back
+			m.triggers[k].close()
+			delete(m.triggers, k)
+		case fd.Revents&unix.POLLPRI != 0:
+			fired[k] = true
+		}
+	}
+
+	return fired, false
+}
+
+// closeTriggers closes all PSI triggers.
+func (m *Monitor) closeTriggers() {
+	for k, trg := range m.triggers {
+		trg.close()
+		delete(m.triggers, k)
+	}
+}
+
+// readPressure reads the pressure of the given resource for a container.
+func (m *Monitor) readPressure(cgroupDir, resource string) (cgroups.Pressure, error) {
+	return cgroups.GetPressure(filepath.Join(m.root, cgroupDir), resource)
+}
+
+// close closes the PSI trigger.
+func (trg *trigger) close() {
+	if trg.file != nil {
+		trg.file.Close()
+		trg.file = nil
+	}
+}
+
+// openTrigger opens the given PSI file and sets up a trigger in it.
+func openTrigger(path, spec string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Write(append([]byte(spec), 0)); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// triggerSpec returns the PSI trigger for a threshold (in percent) and window.
+func triggerSpec(threshold float64, window time.Duration) string {
+	if threshold <= 0 || window <= 0 {
+		return ""
+	}
+	if threshold > 100 {
+		threshold = 100
+	}
+	windowUs := window.Microseconds()
+	stallUs := int64(threshold * float64(windowUs) / 100)
+	if stallUs < 1 {
+		stallUs = 1
+	}
+	return fmt.Sprintf("some %d %d", stallUs, windowUs)
+}
+
+// pressureError returns a formatted package-specific error.
+func pressureError(format string, args ...interface{}) error {
+	return fmt.Errorf("pressure: "+format, args...)
+}
@@ -0,0 +1,179 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pressure
+
+import (
+	"testing"
+	"time"
+
+	"github.com/intel/cri-resource-manager/pkg/cgroups"
+	"github.com/intel/cri-resource-manager/pkg/config"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/events"
+)
+
+func TestTriggerSpec(t *testing.T) {
+	tcases := []struct {
+		name      string
+		threshold float64
+		window    time.Duration
+		expected  string
+	}{
+		{
+			name:      "disabled threshold",
+			threshold: 0,
+			window:    time.Second,
+			expected:  "",
+		},
+		{
+			name:      "disabled window",
+			threshold: 10,
+			window:    0,
+			expected:  "",
+		},
+		{
+			name:      "10% of 2s",
+			threshold: 10,
+			window:    2 * time.Second,
+			expected:  "some 200000 2000000",
+		},
+		{
+			name:      "capped at 100%",
+			threshold: 150,
+			window:    time.Second,
+			expected:  "some 1000000 1000000",
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if spec := triggerSpec(tc.threshold, tc.window); spec != tc.expected {
+				t.Errorf("expected trigger %q, got %q", tc.expected, spec)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	saved := *opt
+	defer func() { *opt = saved }()
+	opt.CPUThreshold = 20
+	opt.MemoryThreshold = 10
+	opt.Cooldown = config.Duration(time.Minute)
+
+	pressure := map[string]float64{}
+	sent := []*events.Pressure{}
+	m := &Monitor{
+		read: func(dir, resource string) (cgroups.Pressure, error) {
+			return cgroups.Pressure{
+				Some: &cgroups.PressureLine{Avg10: pressure[dir+"/"+resource]},
+			}, nil
+		},
+		send: func(e interface{}) error {
+			sent = append(sent, e.(*events.Policy).Data.(*events.Pressure))
+			return nil
+		},
+		triggers: map[key]*trigger{},
+		last:     map[key]time.Time{},
+	}
+	targets := []Target{{ID: "c1", CgroupDir: "c1"}, {ID: "c2", CgroupDir: "c2"}}
+	now := time.Now()
+
+	tcases := []struct {
+		name     string
+		pressure map[string]float64
+		fired    map[key]bool
+		periodic bool
+		after    time.Duration
+		expected []string
+	}{
+		{
+			name:     "below thresholds",
+			pressure: map[string]float64{"c1/cpu": 19.9, "c2/memory": 5},
+			periodic: true,
+		},
+		{
+			name:     "not polled",
+			pressure: map[string]float64{"c1/cpu": 50},
+			periodic: false,
+		},
+		{
+			name:     "cpu pressure",
+			pressure: map[string]float64{"c1/cpu": 50, "c2/memory": 5},
+			periodic: true,
+			expected: []string{"c1/cpu"},
+		},
+		{
+			name:     "cooldown",
+			pressure: map[string]float64{"c1/cpu": 50, "c2/memory": 15},
+			periodic: true,
+			after:    time.Second,
+			expected: []string{"c2/memory"},
+		},
+		{
+			name:     "fired trigger",
+			fired:    map[key]bool{{id: "c2", resource: CPU}: true},
+			after:    2 * time.Second,
+			expected: []string{"c2/cpu"},
+		},
+		{
+			name:     "cooldown expired",
+			pressure: map[string]float64{"c1/cpu": 50},
+			periodic: true,
+			after:    2 * time.Minute,
+			expected: []string{"c1/cpu"},
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			pressure = tc.pressure
+			sent = sent[:0]
+			now = now.Add(tc.after)
+			m.check(opt, targets, tc.fired, tc.periodic, now)
+
+			got := []string{}
+			for _, p := range sent {
+				got = append(got, p.ContainerID+"/"+p.Resource)
+			}
+			if len(got) != len(tc.expected) {
+				t.Fatalf("expected events %v, got %v", tc.expected, got)
+			}
+			for i := range got {
+				if got[i] != tc.expected[i] {
+					t.Errorf("expected events %v, got %v", tc.expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestConfigNotify(t *testing.T) {
+	saved := *opt
+	defer func() {
+		*opt = saved
+		opt.configNotify(config.UpdateEvent, config.ConfigFile)
+	}()
+
+	opt.CPUThreshold = 30
+	if err := opt.configNotify(config.UpdateEvent, config.ConfigFile); err != nil {
+		t.Fatalf("configuration notification failed: %v", err)
+	}
+	o := getOptions()
+	opt.CPUThreshold = 40
+	if o.CPUThreshold != 30 {
+		t.Errorf("expected CPU threshold 30 in options copy, got %v", o.CPUThreshold)
+	}
+	if o := getOptions(); o.CPUThreshold != 30 {
+		t.Errorf("expected options unchanged until notified, got CPU threshold %v", o.CPUThreshold)
+	}
+}
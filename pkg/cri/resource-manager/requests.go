@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
 
@@ -153,6 +154,7 @@ func (m *resmgr) startRequestProcessing() error {
 		m.policySwitch = false
 	}
 
+	m.idle.WakeOffline()
 	if err := m.policy.Start(add, del); err != nil {
 		return resmgrError("failed to start policy %s: %v", policy.ActivePolicy(), err)
 	}
@@ -418,6 +420,8 @@ func (m *resmgr) CreateContainer(ctx context.Context, method string, request int
 
 	m.Info("%s: creating container %s...", method, container.PrettyName())
 
+	checkpoint := m.checkpointAllocations(method)
+
 	if err := m.policy.AllocateResources(container); err != nil {
 		m.Error("%s: failed to allocate resources for container %s: %v",
 			method, container.PrettyName(), err)
@@ -435,10 +439,19 @@ func (m *resmgr) CreateContainer(ctx context.Context, method string, request int
 	if err := m.runPostAllocateHooks(ctx, method); err != nil {
 		m.Error("%s: failed to run post-allocate hooks for %s: %v",
 			method, container.PrettyName(), err)
-		m.policy.ReleaseResources(container)
-		m.runPostReleaseHooks(ctx, method, container)
-		m.cache.DeleteContainer(container.GetCacheID())
-		return nil, resmgrError("failed to allocate container resources: %v", err)
+		if checkpoint == nil {
+			m.policy.ReleaseResources(container)
+			m.runPostReleaseHooks(ctx, method, container)
+			m.cache.DeleteContainer(container.GetCacheID())
+			return nil, resmgrError("failed to allocate container resources: %v", err)
+		}
+		if rberr := m.rollbackAllocations(ctx, method, container, checkpoint); rberr != nil {
+			m.Error("%s: failed to roll back allocations: %v", method, rberr)
+			return nil, resmgrError("failed to allocate container resources: %v "+
+				"(rollback failed: %v)", err, rberr)
+		}
+		return nil, resmgrError("failed to allocate container resources: %v "+
+			"(allocations rolled back)", err)
 	}
 
 	container.ClearCRIRequest()
@@ -742,6 +755,8 @@ func (m *resmgr) rebalance(method string) error {
 		}
 	}
 
+	m.updateIntrospection()
+
 	return m.cache.Save()
 }
 
@@ -775,22 +790,64 @@ func (m *resmgr) DeliverPolicyEvent(e *events.Policy) error {
 		}
 	}
 
+	m.updateIntrospection()
+
 	m.cache.Save()
 	return nil
 }
 
-// setConfig activates a new configuration, either from the agent or from a file.
-func (m *resmgr) setConfig(v interface{}) error {
-	var err error
+// configRollback is a request to reactivate a configuration from the history.
+type configRollback uint64
 
+// setConfig activates a new configuration, either from the agent, from a file,
+// or from the configuration history.
+func (m *resmgr) setConfig(v interface{}) (err error) {
 	m.Lock()
 	defer m.Unlock()
 
+	m.idle.WakeOffline()
+
+	rev := &config.ConfigRevision{Timestamp: time.Now()}
+	defer func() {
+		m.recordConfig(rev, err)
+	}()
+
+	// take a snapshot for restoring the current configuration if the new one
+	// gets applied but we then fail to activate it
+	snapshot, err := pkgcfg.GetConfig()
+	if err != nil {
+		m.Error("failed to take snapshot of current configuration: %v", err)
+		return resmgrError("failed to take snapshot of current configuration: %v", err)
+	}
+
 	switch cfg := v.(type) {
 	case *config.RawConfig:
+		rev.Source = config.ConfigSourceAgent
+		rev.NodeName = cfg.NodeName
+		rev.Data = cfg.Data
 		err = pkgcfg.SetConfig(cfg.Data)
 	case string:
-		err = pkgcfg.SetConfigFromFile(cfg)
+		// files are only (re)loaded at runtime when the reload signal is received
+		rev.Source = config.ConfigSourceSignal
+		rev.Path = cfg
+		if rev.Data, err = configDataFromFile(cfg); err == nil {
+			err = pkgcfg.SetConfigFromFile(cfg)
+		}
+	case configRollback:
+		rev.Source = config.ConfigSourceRollback
+		rev.RollbackOf = uint64(cfg)
+		old := m.cache.GetConfigRevision(uint64(cfg))
+		switch {
+		case old == nil:
+			err = fmt.Errorf("revision %d not found in configuration history", cfg)
+		case !old.Applied():
+			err = fmt.Errorf("revision %d was never successfully applied", cfg)
+		default:
+			rev.NodeName = old.NodeName
+			rev.Path = old.Path
+			rev.Data = old.Data
+			err = pkgcfg.SetConfig(old.Data)
+		}
 	default:
 		err = fmt.Errorf("invalid configuration source/type %T", v)
 	}
@@ -803,18 +860,26 @@ func (m *resmgr) setConfig(v interface{}) error {
 		// synchronize state of controllers with new configuration
 		if err = m.control.StartStopControllers(m.cache, m.relay.Client()); err != nil {
 			m.Error("failed to synchronize controllers with new configuration: %v", err)
+			m.restoreConfig(snapshot)
 			return resmgrError("failed to synchronize controllers with new configuration: %v", err)
 		}
 
 		if err = m.runPostUpdateHooks(context.Background(), "setConfig"); err != nil {
 			m.Error("failed to run post-update hooks after reconfiguration: %v", err)
+			m.restoreConfig(snapshot)
 			return resmgrError("failed to run post-update hooks after reconfiguration: %v", err)
 		}
 	}
 
-	// if we managed to activate a configuration from the agent, store it in the cache
-	if cfg, ok := v.(*config.RawConfig); ok {
-		m.cache.SetConfig(cfg)
+	// if we managed to activate a configuration from the agent or the history,
+	// store it in the cache
+	switch rev.Source {
+	case config.ConfigSourceAgent, config.ConfigSourceRollback:
+		m.cache.SetConfig(&config.RawConfig{NodeName: rev.NodeName, Data: rev.Data})
+	}
+
+	if m.policy != nil {
+		m.updateIntrospection()
 	}
 
 	m.Info("successfully switched to new configuration")
@@ -822,15 +887,105 @@ func (m *resmgr) setConfig(v interface{}) error {
 	return nil
 }
 
+// restoreConfig reverts to the configuration in use before a failed update.
+func (m *resmgr) restoreConfig(snapshot pkgcfg.Data) {
+	m.Warn("restoring previous configuration...")
+
+	if err := pkgcfg.RestoreConfig(snapshot); err != nil {
+		m.Error("failed to restore previous configuration: %v", err)
+	}
+	if err := m.control.StartStopControllers(m.cache, m.relay.Client()); err != nil {
+		m.Error("failed to synchronize controllers with restored configuration: %v", err)
+	}
+	if err := m.runPostUpdateHooks(context.Background(), "setConfig"); err != nil {
+		m.Error("failed to run post-update hooks after restoring configuration: %v", err)
+	}
+
+	m.updateIntrospection()
+}
+
+// recordConfig records an attempt to apply a configuration in the history.
+func (m *resmgr) recordConfig(rev *config.ConfigRevision, err error) {
+	if err != nil {
+		rev.Error = err.Error()
+	}
+	if err := m.cache.AddConfigRevision(rev); err != nil {
+		m.Error("failed to record configuration in history: %v", err)
+	}
+}
+
+// configDataFromFile reads configuration from a file in agent/ConfigMap format.
+func configDataFromFile(path string) (map[string]string, error) {
+	data, err := pkgcfg.DataFromFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return data.StringMap()
+}
+
+// allocationCheckpoint is a checkpoint of policy and cache allocation state.
+type allocationCheckpoint struct {
+	policy policy.Checkpoint
+	cache  *cache.ResourceCheckpoint
+}
+
+// checkpointAllocations takes a checkpoint of the current allocations, if the
+// active policy supports it. Otherwise it returns nil.
+func (m *resmgr) checkpointAllocations(method string) *allocationCheckpoint {
+	cp, err := m.policy.Checkpoint()
+	if err != nil {
+		m.Debug("%s: not checkpointing allocations: %v", method, err)
+		return nil
+	}
+	return &allocationCheckpoint{
+		policy: cp,
+		cache:  m.cache.CheckpointResources(),
+	}
+}
+
+// rollbackAllocations rolls back the allocation of a container to the given
+// checkpoint, removing the container and re-enforcing the restored resource
+// assignments of all other containers.
+func (m *resmgr) rollbackAllocations(ctx context.Context, method string,
+	container cache.Container, cp *allocationCheckpoint) error {
+	m.Warn("%s: rolling back allocation of container %s...", method, container.PrettyName())
+
+	if err := m.policy.Restore(cp.policy); err != nil {
+		m.policy.ReleaseResources(container)
+		m.runPostReleaseHooks(ctx, method, container)
+		m.cache.DeleteContainer(container.GetCacheID())
+		return err
+	}
+
+	m.cache.RestoreResources(cp.cache)
+	m.cache.DeleteContainer(container.GetCacheID())
+
+	return m.runPostUpdateHooks(ctx, method)
+}
+
 // runPostAllocateHooks runs the necessary hooks after allocating resources for some containers.
+// All controller hooks are run first and the first failure is returned. CRI update requests
+// are only sent and resource data exported once all hooks have succeeded.
 func (m *resmgr) runPostAllocateHooks(ctx context.Context, method string) error {
-	for _, c := range m.cache.GetPendingContainers() {
+	m.wakeAllocatedCPUs()
+	pending := m.cache.GetPendingContainers()
+	for _, c := range pending {
 		switch c.GetState() {
 		case cache.ContainerStateRunning, cache.ContainerStateCreated:
 			if err := m.control.RunPostUpdateHooks(c); err != nil {
-				m.Warn("%s post-update hook failed for %s: %v",
+				return resmgrError("%s post-update hook failed for %s: %v",
 					method, c.PrettyName(), err)
 			}
+		case cache.ContainerStateCreating:
+			if err := m.control.RunPreCreateHooks(c); err != nil {
+				return resmgrError("%s pre-create hook failed for %s: %v",
+					method, c.PrettyName(), err)
+			}
+		}
+	}
+	for _, c := range pending {
+		switch c.GetState() {
+		case cache.ContainerStateRunning, cache.ContainerStateCreated:
 			if req, ok := c.ClearCRIRequest(); ok {
 				if _, err := m.sendCRIRequest(ctx, req); err != nil {
 					m.Warn("%s update of container %s failed: %v",
@@ -839,10 +994,6 @@ func (m *resmgr) runPostAllocateHooks(ctx context.Context, method string) error
 			}
 			m.policy.ExportResourceData(c)
 		case cache.ContainerStateCreating:
-			if err := m.control.RunPreCreateHooks(c); err != nil {
-				m.Warn("%s pre-create hook failed for %s: %v",
-					method, c.PrettyName(), err)
-			}
 			m.policy.ExportResourceData(c)
 		default:
 			m.Warn("%s: skipping container %s (in state %v)", method,
@@ -854,6 +1005,7 @@ func (m *resmgr) runPostAllocateHooks(ctx context.Context, method string) error
 
 // runPostStartHooks runs the necessary hooks after having started a container.
 func (m *resmgr) runPostStartHooks(ctx context.Context, method string, c cache.Container) error {
+	m.wakeAllocatedCPUs()
 	if err := m.control.RunPostStartHooks(c); err != nil {
 		m.Error("%s: post-start hook failed for %s: %v", method, c.PrettyName(), err)
 	}
@@ -862,6 +1014,7 @@ func (m *resmgr) runPostStartHooks(ctx context.Context, method string, c cache.C
 
 // runPostReleaseHooks runs the necessary hooks after releaseing resources of some containers
 func (m *resmgr) runPostReleaseHooks(ctx context.Context, method string, released ...cache.Container) error {
+	m.wakeAllocatedCPUs()
 	for _, c := range released {
 		if err := m.control.RunPostStopHooks(c); err != nil {
 			m.Warn("post-stop hook failed for %s: %v", c.PrettyName(), err)
@@ -899,6 +1052,7 @@ func (m *resmgr) runPostReleaseHooks(ctx context.Context, method string, release
 
 // runPostUpdateHooks runs the necessary hooks after reconcilation.
 func (m *resmgr) runPostUpdateHooks(ctx context.Context, method string) error {
+	m.wakeAllocatedCPUs()
 	for _, c := range m.cache.GetPendingContainers() {
 		switch c.GetState() {
 		case cache.ContainerStateRunning, cache.ContainerStateCreated:
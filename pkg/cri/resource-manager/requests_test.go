@@ -0,0 +1,443 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resmgr
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+
+	"github.com/intel/cri-resource-manager/pkg/config"
+	"github.com/intel/cri-resource-manager/pkg/cri/client"
+	"github.com/intel/cri-resource-manager/pkg/cri/relay"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/cache"
+	cfgapi "github.com/intel/cri-resource-manager/pkg/cri/resource-manager/config"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/idlecpus"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/introspect"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/policy"
+	logger "github.com/intel/cri-resource-manager/pkg/log"
+	"github.com/intel/cri-resource-manager/pkg/sysfs"
+	"github.com/intel/cri-resource-manager/pkg/testutils"
+	"github.com/intel/cri-resource-manager/pkg/topology"
+)
+
+// fakeControl is a controller stub failing the hooks of the given container,
+// or failing to start controllers if asked to.
+type fakeControl struct {
+	fail        string
+	failControl bool
+}
+
+func (f *fakeControl) StartStopControllers(cache.Cache, client.Client) error {
+	if f.failControl {
+		return fmt.Errorf("failing to start controllers")
+	}
+	return nil
+}
+
+func (f *fakeControl) RunPreCreateHooks(c cache.Container) error  { return f.run(c) }
+func (f *fakeControl) RunPreStartHooks(c cache.Container) error   { return f.run(c) }
+func (f *fakeControl) RunPostStartHooks(c cache.Container) error  { return f.run(c) }
+func (f *fakeControl) RunPostUpdateHooks(c cache.Container) error { return f.run(c) }
+func (f *fakeControl) RunPostStopHooks(c cache.Container) error   { return f.run(c) }
+
+func (f *fakeControl) run(c cache.Container) error {
+	if f.fail != "" && c.GetName() == f.fail {
+		return fmt.Errorf("failing hook of container %s", c.PrettyName())
+	}
+	for _, controller := range c.GetPending() {
+		c.ClearPending(controller)
+	}
+	return nil
+}
+
+// allocationState is the state compared before and after a rollback.
+type allocationState struct {
+	pools       map[string]*introspect.Pool
+	assignments map[string]*introspect.Assignment
+	cpus        map[string]string
+	mems        map[string]string
+}
+
+func getAllocationState(m *resmgr) allocationState {
+	state := m.policy.Introspect()
+	s := allocationState{
+		pools:       state.Pools,
+		assignments: state.Assignments,
+		cpus:        map[string]string{},
+		mems:        map[string]string{},
+	}
+	for _, c := range m.cache.GetContainers() {
+		s.cpus[c.PrettyName()] = c.GetCpusetCpus()
+		s.mems[c.PrettyName()] = c.GetCpusetMems()
+	}
+	return s
+}
+
+func TestCreateContainerRollback(t *testing.T) {
+	savedSysRoot := sysfs.SysRoot()
+	defer func() {
+		sysfs.SetSysRoot(savedSysRoot)
+		topology.SetSysRoot(savedSysRoot)
+	}()
+	root := testutils.CreateFakeSysfs(t, 8)
+	sysfs.SetSysRoot(root)
+	topology.SetSysRoot(root)
+
+	tcases := []struct {
+		policy string
+		config string
+	}{
+		{
+			policy: "static",
+		},
+		{
+			policy: "static-plus",
+		},
+		{
+			policy: "topology-aware",
+		},
+		{
+			policy: "balloons",
+			config: "balloons:\n" +
+				"  BalloonTypes:\n" +
+				"    - Name: exclusive\n" +
+				"      Namespaces: [default]\n" +
+				"      PreferNewBalloons: true\n",
+		},
+		{
+			policy: "dynamic-pools",
+			config: "dynamic-pools:\n" +
+				"  DynamicPoolTypes:\n" +
+				"    - Name: pool\n" +
+				"      Namespaces: [default]\n",
+		},
+		{
+			policy: "podpools",
+			config: "podpools:\n" +
+				"  Pools:\n" +
+				"    - Name: dualcpu\n" +
+				"      CPU: 2\n" +
+				"      MaxPods: 1\n" +
+				"      Instances: 3\n",
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.policy, func(t *testing.T) {
+			cfg := map[string]string{
+				"policy": "Active: " + tc.policy + "\nReservedResources:\n  CPU: 1\n" + tc.config,
+			}
+			err := config.EvaluateConfig(cfg, func() error {
+				testCreateContainerRollback(t)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("failed to evaluate configuration: %v", err)
+			}
+		})
+	}
+}
+
+func testCreateContainerRollback(t *testing.T) {
+	cch, err := cache.NewCache(cache.Options{CacheDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	p, err := policy.NewPolicy(cch, &policy.Options{
+		SendEvent: func(interface{}) error { return nil },
+	})
+	if err != nil {
+		t.Fatalf("failed to create policy: %v", err)
+	}
+
+	ctl := &fakeControl{}
+	m := &resmgr{
+		Logger:     logger.NewLogger("resource-manager"),
+		cache:      cch,
+		policy:     p,
+		control:    ctl,
+		idle:       idlecpus.NewManager(cch, func(interface{}) error { return nil }),
+		introspect: &introspect.Server{},
+	}
+
+	existing := []cache.Container{
+		createFakeContainer(t, cch, "guaranteed", "/kubepods.slice/kubepods-podguaranteed",
+			criv1.LinuxContainerResources{
+				CpuShares:          2048,
+				CpuQuota:           200000,
+				CpuPeriod:          100000,
+				MemoryLimitInBytes: 1 << 30,
+			}),
+		createFakeContainer(t, cch, "besteffort", "/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-podbesteffort",
+			criv1.LinuxContainerResources{CpuShares: 2}),
+	}
+	for i, c := range existing {
+		reply := &criv1.CreateContainerResponse{ContainerId: c.GetPodID() + "-ctr"}
+		if existing[i], err = cch.UpdateContainerID(c.GetCacheID(), reply); err != nil {
+			t.Fatalf("failed to update container ID: %v", err)
+		}
+		existing[i].UpdateState(cache.ContainerStateRunning)
+	}
+	if err := p.Start(existing, []cache.Container{}); err != nil {
+		t.Fatalf("failed to start policy: %v", err)
+	}
+	if err := m.runPostUpdateHooks(context.Background(), "test"); err != nil {
+		t.Fatalf("failed to run post-update hooks: %v", err)
+	}
+
+	before := getAllocationState(m)
+
+	podCfg := &criv1.PodSandboxConfig{
+		Metadata: &criv1.PodSandboxMetadata{Name: "new", Uid: "new", Namespace: "default"},
+		Linux:    &criv1.LinuxPodSandboxConfig{CgroupParent: "/kubepods.slice/kubepods-podnew"},
+		Annotations: map[string]string{
+			"pool.podpools.cri-resource-manager.intel.com": "dualcpu",
+		},
+	}
+	if _, err := cch.InsertPod("new", &criv1.RunPodSandboxRequest{Config: podCfg}, nil); err != nil {
+		t.Fatalf("failed to create pod: %v", err)
+	}
+	request := &criv1.CreateContainerRequest{
+		PodSandboxId: "new",
+		Config: &criv1.ContainerConfig{
+			Metadata: &criv1.ContainerMetadata{Name: "failing"},
+			Linux: &criv1.LinuxContainerConfig{
+				Resources: &criv1.LinuxContainerResources{
+					CpuShares:          2048,
+					CpuQuota:           200000,
+					CpuPeriod:          100000,
+					MemoryLimitInBytes: 1 << 30,
+				},
+			},
+		},
+		SandboxConfig: podCfg,
+	}
+	created := false
+	handler := func(context.Context, interface{}) (interface{}, error) {
+		created = true
+		return &criv1.CreateContainerResponse{ContainerId: "new-container"}, nil
+	}
+
+	ctl.fail = "failing"
+	_, err = m.CreateContainer(context.Background(), "CreateContainer", request, handler)
+	if err == nil || !strings.Contains(err.Error(), "allocations rolled back") {
+		t.Fatalf("expected container creation to fail with allocations rolled back, got %v", err)
+	}
+	if created {
+		t.Errorf("expected failed container not to be created")
+	}
+	if _, ok := cch.LookupPod("new"); !ok {
+		t.Errorf("expected pod to be kept in cache")
+	}
+	for _, c := range cch.GetContainers() {
+		if c.GetName() == "failing" {
+			t.Errorf("expected failed container %s to be removed from cache", c.PrettyName())
+		}
+	}
+	if pending := cch.GetPendingContainers(); len(pending) != 0 {
+		t.Errorf("expected no pending containers after rollback, got %d", len(pending))
+	}
+
+	after := getAllocationState(m)
+	if !reflect.DeepEqual(before.pools, after.pools) {
+		t.Errorf("expected policy pools to be rolled back, before: %s, after: %s",
+			dumpPools(before.pools), dumpPools(after.pools))
+	}
+	if !reflect.DeepEqual(before.assignments, after.assignments) {
+		t.Errorf("expected policy assignments to be rolled back, before: %v, after: %v",
+			before.assignments, after.assignments)
+	}
+	if !reflect.DeepEqual(before.cpus, after.cpus) {
+		t.Errorf("expected cpusets to be rolled back, before: %v, after: %v", before.cpus, after.cpus)
+	}
+	if !reflect.DeepEqual(before.mems, after.mems) {
+		t.Errorf("expected memsets to be rolled back, before: %v, after: %v", before.mems, after.mems)
+	}
+
+	// Once the hooks succeed, the container is created as usual.
+	ctl.fail = ""
+	if _, err := m.CreateContainer(context.Background(), "CreateContainer", request, handler); err != nil {
+		t.Fatalf("failed to create container after rollback: %v", err)
+	}
+	if !created {
+		t.Errorf("expected container to be created")
+	}
+}
+
+func dumpPools(pools map[string]*introspect.Pool) string {
+	dump := []string{}
+	for name, pool := range pools {
+		dump = append(dump, fmt.Sprintf("%s:%s%v", name, pool.CPUs, pool.Containers))
+	}
+	return strings.Join(dump, ",")
+}
+
+// fakeRelay is a CRI relay stub without a client.
+type fakeRelay struct {
+	relay.Relay
+}
+
+func (r *fakeRelay) Client() client.Client {
+	return nil
+}
+
+// rollbackTestConfig is a configuration module for telling configurations apart.
+type rollbackTestConfig struct {
+	Revision int
+}
+
+var rollbackTestOpt = &rollbackTestConfig{}
+
+func init() {
+	config.Register("rollback-test", "Configuration rollback test.", rollbackTestOpt,
+		func() interface{} { return &rollbackTestConfig{} })
+}
+
+// rollbackTestData returns configuration data for the given test revision.
+func rollbackTestData(revision int) map[string]string {
+	return map[string]string{
+		"policy":        "Active: static\nReservedResources:\n  CPU: 1\n",
+		"rollback-test": "Revision: " + strconv.Itoa(revision) + "\n",
+	}
+}
+
+func TestConfigRollback(t *testing.T) {
+	savedSysRoot := sysfs.SysRoot()
+	defer func() {
+		sysfs.SetSysRoot(savedSysRoot)
+		topology.SetSysRoot(savedSysRoot)
+	}()
+	root := testutils.CreateFakeSysfs(t, 8)
+	sysfs.SetSysRoot(root)
+	topology.SetSysRoot(root)
+
+	err := config.EvaluateConfig(rollbackTestData(0), func() error {
+		testConfigRollback(t)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to evaluate configuration: %v", err)
+	}
+}
+
+func testConfigRollback(t *testing.T) {
+	cch, err := cache.NewCache(cache.Options{CacheDir: t.TempDir(), ConfigHistory: 8})
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	p, err := policy.NewPolicy(cch, &policy.Options{
+		SendEvent: func(interface{}) error { return nil },
+	})
+	if err != nil {
+		t.Fatalf("failed to create policy: %v", err)
+	}
+	if err := p.Start([]cache.Container{}, []cache.Container{}); err != nil {
+		t.Fatalf("failed to start policy: %v", err)
+	}
+
+	ctl := &fakeControl{}
+	m := &resmgr{
+		Logger:     logger.NewLogger("resource-manager"),
+		relay:      &fakeRelay{},
+		cache:      cch,
+		policy:     p,
+		control:    ctl,
+		idle:       idlecpus.NewManager(cch, func(interface{}) error { return nil }),
+		introspect: &introspect.Server{},
+	}
+
+	// checkActive checks the active configuration and the one stored in the cache.
+	checkActive := func(revision int) {
+		t.Helper()
+		if rollbackTestOpt.Revision != revision {
+			t.Errorf("expected configuration %d to be active, got %d",
+				revision, rollbackTestOpt.Revision)
+		}
+		expected := rollbackTestData(revision)
+		if cfg := cch.GetConfig(); cfg == nil || !reflect.DeepEqual(cfg.Data, expected) {
+			t.Errorf("expected configuration %d in cache, got %v", revision, cfg)
+		}
+	}
+	// lastRevision returns the last revision in the configuration history.
+	lastRevision := func() *cfgapi.ConfigRevision {
+		t.Helper()
+		history := m.GetConfigHistory()
+		if len(history) == 0 {
+			t.Fatalf("expected configuration history")
+		}
+		return history[len(history)-1]
+	}
+
+	for i := 1; i <= 2; i++ {
+		if err := m.SetConfig(&cfgapi.RawConfig{Data: rollbackTestData(i)}); err != nil {
+			t.Fatalf("failed to set configuration %d: %v", i, err)
+		}
+		checkActive(i)
+	}
+	first := m.GetConfigHistory()[0].Revision
+
+	// successful rollback
+	if err := m.RollbackConfig(first); err != nil {
+		t.Fatalf("failed to roll back to revision %d: %v", first, err)
+	}
+	checkActive(1)
+	if rev := lastRevision(); rev.RollbackOf != first || !rev.Applied() {
+		t.Errorf("expected applied rollback of revision %d, got %+v", first, rev)
+	}
+
+	// rollback to an unknown revision
+	if err := m.RollbackConfig(first + 100); err == nil {
+		t.Errorf("expected rollback to unknown revision to fail")
+	}
+	checkActive(1)
+
+	// failing to activate a new configuration restores the previous one
+	ctl.failControl = true
+	if err := m.SetConfig(&cfgapi.RawConfig{Data: rollbackTestData(3)}); err == nil {
+		t.Errorf("expected configuration to be rejected when controllers fail")
+	}
+	checkActive(1)
+	failed := lastRevision()
+	if failed.Applied() {
+		t.Errorf("expected failed configuration in history, got %+v", failed)
+	}
+
+	// failing to activate a rolled back configuration restores the previous one
+	second := m.GetConfigHistory()[1].Revision
+	if err := m.RollbackConfig(second); err == nil {
+		t.Errorf("expected rollback to be rejected when controllers fail")
+	}
+	checkActive(1)
+	if rev := lastRevision(); rev.RollbackOf != second || rev.Applied() {
+		t.Errorf("expected failed rollback of revision %d, got %+v", second, rev)
+	}
+
+	// a failed configuration can't be rolled back to
+	ctl.failControl = false
+	if err := m.RollbackConfig(failed.Revision); err == nil {
+		t.Errorf("expected rollback to a failed revision to fail")
+	}
+	checkActive(1)
+
+	if err := m.RollbackConfig(second); err != nil {
+		t.Fatalf("failed to roll back to revision %d: %v", second, err)
+	}
+	checkActive(2)
+}
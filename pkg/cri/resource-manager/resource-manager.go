@@ -31,9 +31,11 @@ import (
 	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/cache"
 	config "github.com/intel/cri-resource-manager/pkg/cri/resource-manager/config"
 	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/control"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/idlecpus"
 	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/introspect"
 	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/metrics"
 	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/policy"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/pressure"
 	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/visualizer"
 	"github.com/intel/cri-resource-manager/pkg/instrumentation"
 	logger "github.com/intel/cri-resource-manager/pkg/log"
@@ -55,6 +57,12 @@ type ResourceManager interface {
 	SetConfig(*config.RawConfig) error
 	// SetAdjustment dynamically updates external adjustments.
 	SetAdjustment(*config.Adjustment) map[string]error
+	// GetConfigHistory returns the history of applied configurations.
+	GetConfigHistory() []*config.ConfigRevision
+	// RollbackConfig reactivates a configuration from the history.
+	RollbackConfig(uint64) error
+	// Simulate evaluates a configuration without applying it.
+	Simulate(*config.RawConfig) ([]byte, error)
 	// SendEvent sends an event to be processed by the resource manager.
 	SendEvent(event interface{}) error
 	// Add-ons for testing.
@@ -74,6 +82,8 @@ type resmgr struct {
 	agent        agent.Interface    // connection to cri-resmgr agent
 	conf         *config.RawConfig  // pending for saving in cache
 	metrics      *metrics.Metrics   // metrics collector/pre-processor
+	pressure     *pressure.Monitor  // container resource pressure monitor
+	idle         *idlecpus.Manager  // idle CPU power manager
 	events       chan interface{}   // channel for delivering events
 	stop         chan interface{}   // channel for signalling shutdown to goroutines
 	signals      chan os.Signal     // signal channel
@@ -86,12 +96,18 @@ type resmgr struct {
 func NewResourceManager() (ResourceManager, error) {
 	m := &resmgr{Logger: logger.NewLogger("resource-manager")}
 
+	sysfs.SetSysRoot(opt.HostRoot)
+	topology.SetSysRoot(opt.HostRoot)
+
+	if opt.DumpTopology != "" {
+		os.Exit(m.dumpTopology())
+	}
+
 	if err := m.setupCache(); err != nil {
 		return nil, err
 	}
 
-	sysfs.SetSysRoot(opt.HostRoot)
-	topology.SetSysRoot(opt.HostRoot)
+	m.setupIdleCPUs()
 
 	switch {
 	case opt.ResetPolicy && opt.ResetConfig:
@@ -233,6 +249,19 @@ func (m *resmgr) SetConfig(conf *config.RawConfig) error {
 	return m.setConfig(conf)
 }
 
+// GetConfigHistory returns the history of configurations applied by the resource manager.
+func (m *resmgr) GetConfigHistory() []*config.ConfigRevision {
+	m.RLock()
+	defer m.RUnlock()
+	return m.cache.GetConfigHistory()
+}
+
+// RollbackConfig reactivates a previously applied configuration from the history.
+func (m *resmgr) RollbackConfig(revision uint64) error {
+	m.Info("rolling back to configuration revision %d...", revision)
+	return m.setConfig(configRollback(revision))
+}
+
 // SetAdjustment pushes new external adjustments to the resource manager.
 func (m *resmgr) SetAdjustment(adjustment *config.Adjustment) map[string]error {
 	m.Info("applying new adjustments from agent...")
@@ -278,6 +307,28 @@ func (m *resmgr) resetCachedPolicy() int {
 	return 0
 }
 
+// dumpTopology archives the system topology details into a file.
+func (m *resmgr) dumpTopology() int {
+	m.Info("archiving system topology into %s...", opt.DumpTopology)
+	defer logger.Flush()
+
+	f, err := os.OpenFile(opt.DumpTopology, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		m.Error("failed to create topology archive: %v", err)
+		return 1
+	}
+
+	err = sysfs.DumpTopology(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		m.Error("failed to archive system topology: %v", err)
+		return 1
+	}
+	return 0
+}
+
 // resetCachedConfig resets any cached configuration.
 func (m *resmgr) resetCachedConfig() int {
 	m.Info("resetting cached configuration...")
@@ -300,7 +351,7 @@ func (m *resmgr) resetCachedConfig() int {
 func (m *resmgr) setupCache() error {
 	var err error
 
-	options := cache.Options{CacheDir: opt.RelayDir}
+	options := cache.Options{CacheDir: opt.RelayDir, ConfigHistory: opt.ConfigHistory}
 	if m.cache, err = cache.NewCache(options); err != nil {
 		return resmgrError("failed to create cache: %v", err)
 	}
@@ -309,6 +360,13 @@ func (m *resmgr) setupCache() error {
 
 }
 
+// setupIdleCPUs sets up idle CPU power management. This needs to be done
+// before any configuration gets loaded, since switching configuration
+// wakes up parked CPUs.
+func (m *resmgr) setupIdleCPUs() {
+	m.idle = idlecpus.NewManager(m.cache, m.SendEvent)
+}
+
 // setupAgentInterface sets up the connection to the node agent.
 func (m *resmgr) setupAgentInterface() error {
 	var err error
@@ -324,7 +382,8 @@ func (m *resmgr) setupAgentInterface() error {
 func (m *resmgr) setupConfigServer() error {
 	var err error
 
-	if m.configServer, err = config.NewConfigServer(m.SetConfig, m.SetAdjustment); err != nil {
+	if m.configServer, err = config.NewConfigServer(m.SetConfig, m.SetAdjustment, m.Simulate, m.ValidateConfig,
+		m.GetConfigHistory, m.RollbackConfig); err != nil {
 		return resmgrError("failed to create configuration notification server: %v", err)
 	}
 
@@ -363,7 +422,7 @@ func (m *resmgr) loadConfig() error {
 
 	if opt.ForceConfig != "" {
 		m.Info("using forced configuration %s...", opt.ForceConfig)
-		if err := pkgcfg.SetConfigFromFile(opt.ForceConfig); err != nil {
+		if err := m.loadConfigFromFile(opt.ForceConfig); err != nil {
 			return resmgrError("failed to load forced configuration %s: %v",
 				opt.ForceConfig, err)
 		}
@@ -372,7 +431,14 @@ func (m *resmgr) loadConfig() error {
 
 	m.Info("trying configuration from agent...")
 	if conf, err := m.agent.GetConfig(1 * time.Second); err == nil {
-		if err = pkgcfg.SetConfig(conf.Data); err == nil {
+		err = pkgcfg.SetConfig(conf.Data)
+		m.recordConfig(&config.ConfigRevision{
+			Timestamp: time.Now(),
+			Source:    config.ConfigSourceAgent,
+			NodeName:  conf.NodeName,
+			Data:      conf.Data,
+		}, err)
+		if err == nil {
 			m.conf = conf // schedule storing in cache if we ever manage to start up
 			return nil
 		}
@@ -390,7 +456,7 @@ func (m *resmgr) loadConfig() error {
 
 	if opt.FallbackConfig != "" {
 		m.Info("using fallback configuration %s...", opt.FallbackConfig)
-		if err := pkgcfg.SetConfigFromFile(opt.FallbackConfig); err != nil {
+		if err := m.loadConfigFromFile(opt.FallbackConfig); err != nil {
 			return resmgrError("failed to load fallback configuration %s: %v",
 				opt.FallbackConfig, err)
 		}
@@ -401,6 +467,24 @@ func (m *resmgr) loadConfig() error {
 	return nil
 }
 
+// loadConfigFromFile loads initial configuration from a file, recording it in the history.
+func (m *resmgr) loadConfigFromFile(path string) error {
+	rev := &config.ConfigRevision{
+		Timestamp: time.Now(),
+		Source:    config.ConfigSourceFile,
+		Path:      path,
+	}
+
+	data, err := configDataFromFile(path)
+	if err == nil {
+		rev.Data = data
+		err = pkgcfg.SetConfigFromFile(path)
+	}
+	m.recordConfig(rev, err)
+
+	return err
+}
+
 // setupConfigSignal sets up a signal handler for reloading forced configuration.
 func (m *resmgr) setupConfigSignal(signame string) error {
 	if signame == "" || strings.HasPrefix(strings.ToLower(signame), "disable") {
@@ -548,8 +632,17 @@ func (m *resmgr) stopIntrospection() {
 }
 
 // updateIntrospection pushes updated data for external introspection·
+// and idle CPU tracking.
 func (m *resmgr) updateIntrospection() {
-	m.introspect.Set(m.policy.Introspect())
+	state := m.policy.Introspect()
+	m.introspect.Set(state)
+	m.idle.Update(state)
+}
+
+// wakeAllocatedCPUs wakes up parked CPUs the policy has taken into use, before
+// the updated resource assignments are pushed to the affected containers.
+func (m *resmgr) wakeAllocatedCPUs() {
+	m.idle.Update(m.policy.Introspect())
 }
 
 // registerPolicyMetricsCollector registers policy metrics collector·
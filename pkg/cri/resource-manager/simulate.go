@@ -0,0 +1,137 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resmgr
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	pkgcfg "github.com/intel/cri-resource-manager/pkg/config"
+	config "github.com/intel/cri-resource-manager/pkg/cri/resource-manager/config"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/policy"
+	"github.com/intel/cri-resource-manager/pkg/sysfs"
+	"github.com/intel/cri-resource-manager/pkg/topology"
+)
+
+const (
+	// SimulateCommand is the command line command for running a simulation.
+	SimulateCommand = "simulate"
+	// SimulateJSONFlag is the command line flag for JSON simulation output.
+	SimulateJSONFlag = "simulate-json"
+	// SimulateTopologyFlag is the command line flag for simulating on an archived topology.
+	SimulateTopologyFlag = "simulate-topology"
+)
+
+// SimulateFromFile simulates the configuration in the given file against the
+// given cache file, or against the cache of the resource manager if none is
+// given. If a topology archive is given, the simulation is run against the
+// archived system instead of the host. Neither the cache nor the system is
+// modified by the simulation.
+func SimulateFromFile(configFile, cacheFile, topologyArchive string) (*policy.Simulation, error) {
+	var sys sysfs.System
+
+	if topologyArchive != "" {
+		dir, err := ioutil.TempDir("", "cri-resmgr-topology-")
+		if err != nil {
+			return nil, resmgrError("failed to create topology directory: %v", err)
+		}
+		defer os.RemoveAll(dir)
+
+		if sys, err = sysfs.DiscoverSystemFromArchive(topologyArchive, dir); err != nil {
+			return nil, resmgrError("failed to discover archived topology %q: %v",
+				topologyArchive, err)
+		}
+		sysfs.SetSysRoot(dir)
+		topology.SetSysRoot(dir)
+	} else {
+		sysfs.SetSysRoot(opt.HostRoot)
+		topology.SetSysRoot(opt.HostRoot)
+	}
+
+	if cacheFile == "" {
+		cacheFile = filepath.Join(opt.RelayDir, "cache")
+	}
+	snapshot, err := ioutil.ReadFile(cacheFile)
+	if err != nil {
+		return nil, resmgrError("failed to read cache file %q: %v", cacheFile, err)
+	}
+
+	return policy.SimulateFromFile(sys, snapshot, configFile)
+}
+
+// Simulate evaluates the given configuration against the current state of the
+// resource manager without applying it. The returned data is the simulation
+// result in JSON.
+//
+// The simulation is run in a separate process using the simulate command, to
+// protect us from any policy backend bailing out with a fatal error while
+// trying to use the configuration.
+func (m *resmgr) Simulate(conf *config.RawConfig) ([]byte, error) {
+	m.Info("simulating new configuration from agent...")
+
+	m.Lock()
+	snapshot, err := m.cache.Snapshot()
+	m.Unlock()
+	if err != nil {
+		return nil, resmgrError("failed to take cache snapshot: %v", err)
+	}
+
+	data, err := pkgcfg.DataFromStringMap(conf.Data)
+	if err != nil {
+		return nil, resmgrError("invalid configuration: %v", err)
+	}
+
+	dir, err := ioutil.TempDir("", "cri-resmgr-simulate-")
+	if err != nil {
+		return nil, resmgrError("failed to create simulation directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cacheFile := filepath.Join(dir, "cache")
+	if err := ioutil.WriteFile(cacheFile, snapshot, 0644); err != nil {
+		return nil, resmgrError("failed to write cache snapshot: %v", err)
+	}
+	configFile := filepath.Join(dir, "config.yaml")
+	if err := ioutil.WriteFile(configFile, []byte(data.String()), 0644); err != nil {
+		return nil, resmgrError("failed to write configuration: %v", err)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, resmgrError("failed to determine executable for simulation: %v", err)
+	}
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd := exec.Command(exe, "--host-root", opt.HostRoot, "--"+SimulateJSONFlag,
+		SimulateCommand, configFile, cacheFile)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return nil, resmgrError("simulation failed: %v: %s", err,
+			strings.TrimSpace(lastLine(stderr.String())))
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// lastLine returns the last non-empty line of the given output.
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return lines[len(lines)-1]
+}
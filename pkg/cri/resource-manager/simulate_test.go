@@ -0,0 +1,123 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resmgr
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
+
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/cache"
+	"github.com/intel/cri-resource-manager/pkg/sysfs"
+	"github.com/intel/cri-resource-manager/pkg/testutils"
+	"github.com/intel/cri-resource-manager/pkg/topology"
+)
+
+// createFakeContainer adds a pod with a single container to the cache.
+func createFakeContainer(t *testing.T, cch cache.Cache, name, cgroupParent string, resources criv1.LinuxContainerResources) cache.Container {
+	podCfg := &criv1.PodSandboxConfig{
+		Metadata: &criv1.PodSandboxMetadata{Name: name, Uid: name, Namespace: "default"},
+		Linux:    &criv1.LinuxPodSandboxConfig{CgroupParent: cgroupParent},
+	}
+	if _, err := cch.InsertPod(name, &criv1.RunPodSandboxRequest{Config: podCfg}, nil); err != nil {
+		t.Fatalf("failed to create pod: %v", err)
+	}
+	c, err := cch.InsertContainer(&criv1.CreateContainerRequest{
+		PodSandboxId: name,
+		Config: &criv1.ContainerConfig{
+			Metadata: &criv1.ContainerMetadata{Name: "ctr"},
+			Linux:    &criv1.LinuxContainerConfig{Resources: &resources},
+		},
+		SandboxConfig: podCfg,
+	})
+	if err != nil {
+		t.Fatalf("failed to create container: %v", err)
+	}
+	return c
+}
+
+func TestSimulateFromArchive(t *testing.T) {
+	savedSysRoot := sysfs.SysRoot()
+	defer func() {
+		sysfs.SetSysRoot(savedSysRoot)
+		topology.SetSysRoot(savedSysRoot)
+	}()
+
+	// Archive a fake 4 CPU host.
+	sysfs.SetSysRoot(testutils.CreateFakeSysfs(t, 4))
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "topology.tar.gz")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatalf("failed to create topology archive: %v", err)
+	}
+	if err := sysfs.DumpTopology(f); err != nil {
+		t.Fatalf("failed to archive topology: %v", err)
+	}
+	f.Close()
+
+	// Simulate while the host looks different, with 16 CPUs.
+	sysfs.SetSysRoot(testutils.CreateFakeSysfs(t, 16))
+
+	cch, err := cache.NewCache(cache.Options{CacheDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	createFakeContainer(t, cch, "guaranteed", "/kubepods.slice/kubepods-podguaranteed",
+		criv1.LinuxContainerResources{
+			CpuShares:          2048,
+			CpuQuota:           200000,
+			CpuPeriod:          100000,
+			MemoryLimitInBytes: 1 << 30,
+		})
+	createFakeContainer(t, cch, "besteffort", "/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-podbesteffort",
+		criv1.LinuxContainerResources{CpuShares: 2})
+	snapshot, err := cch.Snapshot()
+	if err != nil {
+		t.Fatalf("failed to take cache snapshot: %v", err)
+	}
+	cacheFile := filepath.Join(dir, "cache")
+	if err := os.WriteFile(cacheFile, snapshot, 0644); err != nil {
+		t.Fatalf("failed to write cache snapshot: %v", err)
+	}
+	configFile := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(configFile, []byte("policy:\n  Active: static\n  ReservedResources:\n    CPU: 1\n"), 0644); err != nil {
+		t.Fatalf("failed to write configuration: %v", err)
+	}
+
+	sim, err := SimulateFromFile(configFile, cacheFile, archive)
+	if err != nil {
+		t.Fatalf("simulation failed: %v", err)
+	}
+	if len(sim.Changes) != 2 {
+		t.Fatalf("expected changes for both containers, got %v", sim.Changes)
+	}
+	for _, c := range sim.Changes {
+		cpus, err := cpuset.Parse(c.Simulated.CPUs)
+		if err != nil || cpus.IsEmpty() {
+			t.Errorf("expected CPUs for %s, got %q", c.Name, c.Simulated.CPUs)
+			continue
+		}
+		if c.Name == "guaranteed:ctr" && cpus.Size() != 2 {
+			t.Errorf("expected 2 exclusive CPUs for %s, got %s", c.Name, cpus)
+		}
+		if !cpus.IsSubsetOf(cpuset.MustParse("0-3")) {
+			t.Errorf("expected CPUs of the archived host for %s, got %s", c.Name, cpus)
+		}
+	}
+}
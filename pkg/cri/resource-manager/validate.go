@@ -0,0 +1,102 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resmgr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	pkgcfg "github.com/intel/cri-resource-manager/pkg/config"
+	config "github.com/intel/cri-resource-manager/pkg/cri/resource-manager/config"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/policy"
+	"github.com/intel/cri-resource-manager/pkg/sysfs"
+	"github.com/intel/cri-resource-manager/pkg/topology"
+)
+
+const (
+	// ValidateConfigFlag is the command line flag for validating a configuration file.
+	ValidateConfigFlag = "validate-config"
+	// ValidateJSONFlag is the command line flag for JSON validation output.
+	ValidateJSONFlag = "validate-json"
+)
+
+// ValidateConfigFromFile checks the configuration in the given file, including
+// the semantic checks of the policy it selects, without taking it into use.
+func ValidateConfigFromFile(configFile string) pkgcfg.ValidationErrors {
+	sysfs.SetSysRoot(opt.HostRoot)
+	topology.SetSysRoot(opt.HostRoot)
+
+	return pkgcfg.AsValidationErrors("", policy.ValidateConfigFromFile(configFile))
+}
+
+// ValidateConfig checks the given configuration without applying it. The
+// returned validation errors are empty if the configuration is valid.
+//
+// Like simulation, validation is run in a separate process, to protect us
+// from any policy backend bailing out with a fatal error while checking the
+// configuration.
+func (m *resmgr) ValidateConfig(conf *config.RawConfig) (pkgcfg.ValidationErrors, error) {
+	m.Info("validating configuration...")
+
+	data, err := pkgcfg.DataFromStringMap(conf.Data)
+	if err != nil {
+		return pkgcfg.AsValidationErrors("", err), nil
+	}
+
+	dir, err := ioutil.TempDir("", "cri-resmgr-validate-")
+	if err != nil {
+		return nil, resmgrError("failed to create validation directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	configFile := filepath.Join(dir, "config.yaml")
+	if err := ioutil.WriteFile(configFile, []byte(data.String()), 0644); err != nil {
+		return nil, resmgrError("failed to write configuration: %v", err)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, resmgrError("failed to determine executable for validation: %v", err)
+	}
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd := exec.Command(exe, "--host-root", opt.HostRoot, "--"+ValidateJSONFlag,
+		"--"+ValidateConfigFlag, configFile)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	err = cmd.Run()
+
+	exitErr := &exec.ExitError{}
+	if err != nil && !errors.As(err, &exitErr) {
+		return nil, resmgrError("validation failed: %v", err)
+	}
+
+	verrs := pkgcfg.ValidationErrors{}
+	if jsonErr := json.Unmarshal(stdout.Bytes(), &verrs); jsonErr != nil {
+		if err != nil {
+			return nil, resmgrError("validation failed: %v: %s", err,
+				strings.TrimSpace(lastLine(stderr.String())))
+		}
+		return nil, resmgrError("failed to decode validation results: %v", jsonErr)
+	}
+
+	return verrs, nil
+}
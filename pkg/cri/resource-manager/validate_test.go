@@ -0,0 +1,152 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resmgr
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	pkgcfg "github.com/intel/cri-resource-manager/pkg/config"
+	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/config"
+	logger "github.com/intel/cri-resource-manager/pkg/log"
+	"github.com/intel/cri-resource-manager/pkg/testutils"
+)
+
+// validateHelperEnv makes the test binary act as the validation subprocess.
+const validateHelperEnv = "CRI_RESMGR_TEST_VALIDATE"
+
+func TestMain(m *testing.M) {
+	switch os.Getenv(validateHelperEnv) {
+	case "":
+		os.Exit(m.Run())
+	case "crash":
+		fmt.Fprintln(os.Stderr, "policy bailed out while validating")
+		os.Exit(2)
+	default:
+		os.Exit(validateHelper(os.Args[1:]))
+	}
+}
+
+// validateHelper validates a configuration like cri-resmgr --validate-config.
+func validateHelper(args []string) int {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	flags.StringVar(&opt.HostRoot, "host-root", "", "")
+	flags.Bool(ValidateJSONFlag, false, "")
+	configFile := flags.String(ValidateConfigFlag, "", "")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	errs := ValidateConfigFromFile(*configFile)
+	if errs == nil {
+		errs = pkgcfg.ValidationErrors{}
+	}
+	data, err := json.Marshal(errs)
+	if err != nil {
+		return 2
+	}
+	fmt.Println(string(data))
+
+	if len(errs) > 0 {
+		return 1
+	}
+	return 0
+}
+
+func TestValidateConfig(t *testing.T) {
+	savedHostRoot := opt.HostRoot
+	defer func() { opt.HostRoot = savedHostRoot }()
+	opt.HostRoot = testutils.CreateFakeSysfs(t, 4)
+
+	m := &resmgr{Logger: logger.NewLogger("resource-manager")}
+
+	tcases := []struct {
+		name          string
+		helper        string
+		config        map[string]string
+		expected      []string
+		expectedError bool
+	}{
+		{
+			name:   "valid configuration",
+			helper: "validate",
+			config: map[string]string{
+				"policy": "Active: topology-aware\nReservedResources:\n  CPU: 1\n",
+			},
+		},
+		{
+			name:   "unknown policy",
+			helper: "validate",
+			config: map[string]string{
+				"policy": "Active: no-such-policy\n",
+			},
+			expected: []string{"policy"},
+		},
+		{
+			name:   "policy validation failure",
+			helper: "validate",
+			config: map[string]string{
+				"policy": "Active: topology-aware\nReservedResources:\n  CPU: 5\n",
+			},
+			expected: []string{"policy.topology-aware"},
+		},
+		{
+			name:   "invalid policy configuration",
+			helper: "validate",
+			config: map[string]string{
+				"policy": "Active: static-pools\nstatic-pools:\n  pools:\n    shared:\n      cpuLists:\n      - Cpuset: 0-\n",
+			},
+			expected: []string{"policy.static-pools"},
+		},
+		{
+			name:   "validation subprocess failure",
+			helper: "crash",
+			config: map[string]string{
+				"policy": "Active: topology-aware\n",
+			},
+			expectedError: true,
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(validateHelperEnv, tc.helper)
+
+			errs, err := m.ValidateConfig(&config.RawConfig{Data: tc.config})
+			if tc.expectedError {
+				if err == nil {
+					t.Fatalf("expected validation to fail, got %v", errs)
+				}
+				if !strings.Contains(err.Error(), "bailed out") {
+					t.Errorf("expected the error of the subprocess, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("validation failed: %v", err)
+			}
+			modules := []string{}
+			for _, e := range errs {
+				modules = append(modules, e.Module)
+			}
+			if strings.Join(modules, ",") != strings.Join(tc.expected, ",") {
+				t.Errorf("expected errors for modules %v, got %v", tc.expected, errs)
+			}
+		})
+	}
+}
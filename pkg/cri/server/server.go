@@ -33,6 +33,7 @@ import (
 	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/sockets"
 	"github.com/intel/cri-resource-manager/pkg/dump"
 	logger "github.com/intel/cri-resource-manager/pkg/log"
+	"github.com/intel/cri-resource-manager/pkg/record"
 	"github.com/intel/cri-resource-manager/pkg/utils"
 
 	"github.com/intel/cri-resource-manager/pkg/instrumentation"
@@ -321,6 +322,7 @@ func (s *server) intercept(ctx context.Context, req interface{},
 
 	qualif := s.qualifier(req)
 	dump.RequestMessage(kind, info.FullMethod, qualif, req, sync)
+	call := record.Begin(kind, info.FullMethod, req)
 
 	if span := trace.FromContext(ctx); span != nil {
 		span.AddAttributes(trace.StringAttribute("kind", kind))
@@ -336,6 +338,7 @@ func (s *server) intercept(ctx context.Context, req interface{},
 	} else {
 		dump.ReplyMessage(kind, info.FullMethod, qualif, rpl, elapsed, false)
 	}
+	call.End(rpl, err)
 
 	s.collectStatistics(kind, name, start, send, recv, end)
 	logger.Flush()
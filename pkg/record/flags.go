@@ -0,0 +1,76 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package record
+
+import (
+	"github.com/intel/cri-resource-manager/pkg/config"
+)
+
+// Recording options configurable via pkg/config.
+type options struct {
+	File string // session file to record to, if set
+}
+
+// Our runtime configuration.
+var opt = defaultOptions().(*options)
+
+// defaultOptions returns a new options instance, initialized to defaults.
+func defaultOptions() interface{} {
+	return &options{}
+}
+
+// configNotify updates our runtime configuration.
+func (o *options) configNotify(event config.Event, source config.Source) error {
+	log.Info("session recorder configuration %v", event)
+	log.Info(" * session file: %v", o.File)
+
+	var cfg config.Data
+	if o.File != "" {
+		var err error
+		if cfg, err = config.GetConfig(); err != nil {
+			log.Error("failed to get configuration for recording: %v", err)
+		}
+	}
+	rec.configure(o.File, cfg)
+
+	return nil
+}
+
+// Register us for configuration handling.
+func init() {
+	config.Register("record", configHelp, opt, defaultOptions,
+		config.WithNotify(opt.configNotify))
+}
+
+var configHelp = `
+Record CRI gRPC method calls into a session file.
+
+If a session file is given, all CRI requests received by the relay,
+the resulting replies or errors, and all requests sent to the CRI
+runtime are recorded into the file, one JSON object per line. The
+configuration in effect is recorded too whenever it changes.
+
+Recorded sessions can be replayed against the resource manager using
+a fake CRI runtime serving the recorded runtime replies, verifying that
+the container resources set by the replayed session match the recorded
+ones. This makes it possible to turn recorded sessions into regression
+tests.
+
+Here is a sample configuration fragment to record into the file
+'/tmp/cri-session.jsonl':
+
+  record:
+    File: /tmp/cri-session.jsonl
+`
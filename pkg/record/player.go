@@ -0,0 +1,294 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package record
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"reflect"
+	"sort"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sigs.k8s.io/yaml"
+)
+
+// Methods whose runtime requests carry the container resources set by the
+// resource manager. These are verified after replaying a session.
+var verifiedMethods = []string{
+	"CreateContainer",
+	"UpdateContainerResources",
+}
+
+// Player replays a recorded session. It acts as the CRI runtime, serving
+// the recorded runtime replies, and sends the recorded requests to a CRI
+// relay.
+type Player struct {
+	sync.Mutex
+	entries  []*Entry            // recorded entries
+	replies  map[string][]*Entry // recorded runtime calls not yet replayed, by method name
+	recorded map[string][]*Entry // recorded runtime calls, by method name
+	received map[string][]*Entry // runtime calls received while replaying, by method name
+	diffs    []string            // differences found while replaying
+	server   *grpc.Server        // fake CRI runtime server
+}
+
+// NewPlayer creates a player for the given recorded entries.
+func NewPlayer(entries []*Entry) *Player {
+	p := &Player{
+		entries:  entries,
+		replies:  make(map[string][]*Entry),
+		recorded: make(map[string][]*Entry),
+		received: make(map[string][]*Entry),
+	}
+	for _, e := range entries {
+		if e.Kind == KindRuntime {
+			name := e.MethodName()
+			p.replies[name] = append(p.replies[name], e)
+			p.recorded[name] = append(p.recorded[name], e)
+		}
+	}
+	return p
+}
+
+// Config returns the first recorded configuration as YAML, with recording disabled.
+func (p *Player) Config() (string, error) {
+	for _, e := range p.entries {
+		if e.Kind != KindConfig {
+			continue
+		}
+		cfg := map[string]interface{}{}
+		if err := json.Unmarshal(e.Request, &cfg); err != nil {
+			return "", recordError("failed to decode recorded configuration: %v", err)
+		}
+		delete(cfg, "record")
+		raw, err := yaml.Marshal(cfg)
+		if err != nil {
+			return "", recordError("failed to encode recorded configuration: %v", err)
+		}
+		return string(raw), nil
+	}
+	return "", nil
+}
+
+// Start starts serving the recorded runtime replies on the given socket.
+func (p *Player) Start(socket string) error {
+	lis, err := net.Listen("unix", socket)
+	if err != nil {
+		return recordError("failed to listen on socket %s: %v", socket, err)
+	}
+
+	p.server = grpc.NewServer(grpc.UnknownServiceHandler(p.serve))
+	go p.server.Serve(lis)
+
+	return nil
+}
+
+// Stop stops serving the recorded runtime replies.
+func (p *Player) Stop() {
+	if p.server != nil {
+		p.server.Stop()
+		p.server = nil
+	}
+}
+
+// Play sends the requests received by the relay in the recorded session to
+// the relay connected to by the given client connection.
+func (p *Player) Play(ctx context.Context, cc *grpc.ClientConn) error {
+	for idx, e := range p.entries {
+		if e.Kind != KindIntercepted && e.Kind != KindPassthrough {
+			continue
+		}
+		req, err := e.DecodeRequest()
+		if err != nil {
+			return recordError("entry #%d: %v", idx, err)
+		}
+		rpl, err := decode(nil, methods[e.Method].reply)
+		if err != nil {
+			return recordError("entry #%d: %v", idx, err)
+		}
+
+		err = cc.Invoke(ctx, e.Method, req, rpl)
+		if recorded := e.Err(); (err == nil) != (recorded == nil) {
+			p.addDiff("%s #%d: recorded error %v, replayed error %v",
+				e.MethodName(), idx, recorded, err)
+		}
+	}
+	return nil
+}
+
+// Verify returns the differences between the container resources set by the
+// recorded and the replayed session. Requests are compared in order per
+// container, since the order of updates to different containers may vary.
+func (p *Player) Verify() []string {
+	p.Lock()
+	defer p.Unlock()
+
+	diffs := append([]string{}, p.diffs...)
+	for _, name := range verifiedMethods {
+		recorded, err := resourcesByContainer(p.recorded[name])
+		if err != nil {
+			diffs = append(diffs, fmt.Sprintf("%s: recorded %v", name, err))
+			continue
+		}
+		received, err := resourcesByContainer(p.received[name])
+		if err != nil {
+			diffs = append(diffs, fmt.Sprintf("%s: replayed %v", name, err))
+			continue
+		}
+
+		ids := []string{}
+		for id := range recorded {
+			ids = append(ids, id)
+		}
+		for id := range received {
+			if _, ok := recorded[id]; !ok {
+				ids = append(ids, id)
+			}
+		}
+		sort.Strings(ids)
+
+		for _, id := range ids {
+			exp, got := recorded[id], received[id]
+			if len(exp) != len(got) {
+				diffs = append(diffs, fmt.Sprintf("%s%s: %d requests recorded, %d replayed",
+					name, containerTag(id), len(exp), len(got)))
+			}
+			for i := 0; i < len(exp) && i < len(got); i++ {
+				if !reflect.DeepEqual(exp[i], got[i]) {
+					diffs = append(diffs, fmt.Sprintf("%s%s #%d: recorded %s, replayed %s",
+						name, containerTag(id), i, dumpJSON(exp[i]), dumpJSON(got[i])))
+				}
+			}
+		}
+	}
+
+	return diffs
+}
+
+// serve serves a single call to the fake CRI runtime.
+func (p *Player) serve(srv interface{}, stream grpc.ServerStream) error {
+	method, ok := grpc.MethodFromServerStream(stream)
+	if !ok {
+		return status.Error(codes.Internal, "failed to determine method")
+	}
+	types, ok := methods[method]
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "unknown method %s", method)
+	}
+
+	req, _ := decode(nil, types.request)
+	if err := stream.RecvMsg(req); err != nil {
+		return err
+	}
+
+	p.Lock()
+	name := methodName(method)
+	p.received[name] = append(p.received[name], &Entry{
+		Kind:    KindRuntime,
+		Method:  method,
+		Request: marshal(method, req),
+	})
+	var e *Entry
+	if replies := p.replies[name]; len(replies) > 0 {
+		e, p.replies[name] = replies[0], replies[1:]
+	}
+	p.Unlock()
+
+	if e == nil {
+		log.Warn("no recorded reply for %s, replying with an empty one", method)
+		rpl, _ := decode(nil, types.reply)
+		return stream.SendMsg(rpl)
+	}
+	if err := e.Err(); err != nil {
+		return err
+	}
+	rpl, err := e.DecodeReply(method)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return stream.SendMsg(rpl)
+}
+
+// addDiff adds a difference found while replaying.
+func (p *Player) addDiff(format string, args ...interface{}) {
+	p.Lock()
+	defer p.Unlock()
+	p.diffs = append(p.diffs, fmt.Sprintf(format, args...))
+}
+
+// containerResources are the container resources set in a runtime request.
+type containerResources struct {
+	ContainerID string      `json:"container_id,omitempty"`
+	Linux       interface{} `json:"linux,omitempty"`
+	Annotations interface{} `json:"annotations,omitempty"`
+}
+
+// resourcesOf extracts the container resources from a recorded runtime request.
+func resourcesOf(e *Entry) (*containerResources, error) {
+	var msg struct {
+		ContainerID string `json:"container_id"`
+		Config      *struct {
+			Linux *struct {
+				Resources interface{} `json:"resources"`
+			} `json:"linux"`
+		} `json:"config"`
+		Linux       interface{} `json:"linux"`
+		Annotations interface{} `json:"annotations"`
+	}
+	if err := json.Unmarshal(e.Request, &msg); err != nil {
+		return nil, recordError("failed to decode %s request: %v", e.MethodName(), err)
+	}
+
+	r := &containerResources{ContainerID: msg.ContainerID, Linux: msg.Linux, Annotations: msg.Annotations}
+	if msg.Config != nil && msg.Config.Linux != nil {
+		r.Linux = msg.Config.Linux.Resources
+	}
+	return r, nil
+}
+
+// resourcesByContainer extracts the container resources of requests, by container ID.
+func resourcesByContainer(entries []*Entry) (map[string][]*containerResources, error) {
+	resources := make(map[string][]*containerResources)
+	for idx, e := range entries {
+		r, err := resourcesOf(e)
+		if err != nil {
+			return nil, recordError("request #%d: %v", idx, err)
+		}
+		resources[r.ContainerID] = append(resources[r.ContainerID], r)
+	}
+	return resources, nil
+}
+
+// containerTag returns a container ID tag for differences.
+func containerTag(id string) string {
+	if id == "" {
+		return ""
+	}
+	return " (container " + id + ")"
+}
+
+// dumpJSON returns the given object as JSON.
+func dumpJSON(obj interface{}) string {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return fmt.Sprintf("<failed to marshal %T: %v>", obj, err)
+	}
+	return string(raw)
+}
@@ -0,0 +1,325 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package record
+
+//
+// This package implements recording of CRI (gRPC) method calls into a
+// structured session file and replaying such a recorded session. Each
+// line of a session file is a JSON-encoded Entry. Calls received by the
+// CRI relay are recorded with kinds 'intercepted' and 'passthrough',
+// calls sent to the CRI runtime are recorded with kind 'runtime', and
+// the configuration in effect is recorded with kind 'config'.
+//
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+	criv1alpha2 "k8s.io/cri-api/pkg/apis/runtime/v1alpha2"
+
+	logger "github.com/intel/cri-resource-manager/pkg/log"
+)
+
+const (
+	// KindIntercepted is a request received and intercepted by the relay.
+	KindIntercepted = "intercepted"
+	// KindPassthrough is a request received and passed through by the relay.
+	KindPassthrough = "passthrough"
+	// KindRuntime is a request sent to the CRI runtime.
+	KindRuntime = "runtime"
+	// KindConfig is the configuration in effect.
+	KindConfig = "config"
+)
+
+// Entry is a single recorded method call.
+type Entry struct {
+	// Time is the time the call was made.
+	Time time.Time `json:"time"`
+	// Kind is the kind of the call.
+	Kind string `json:"kind"`
+	// Method is the full gRPC method name of the call.
+	Method string `json:"method,omitempty"`
+	// Request is the request of the call.
+	Request json.RawMessage `json:"request,omitempty"`
+	// Reply is the reply of the call, if it succeeded.
+	Reply json.RawMessage `json:"reply,omitempty"`
+	// Code is the gRPC status code of the call, if it failed.
+	Code codes.Code `json:"code,omitempty"`
+	// Error is the error message of the call, if it failed.
+	Error string `json:"error,omitempty"`
+}
+
+// Call is a method call being recorded.
+type Call struct {
+	entry *Entry
+}
+
+// recorder encapsulates the runtime state of our recorder.
+type recorder struct {
+	sync.Mutex          // protect concurrent recording/reconfiguration
+	path       string   // session file path
+	file       *os.File // session file
+}
+
+// methodTypes are the request and reply types of a method.
+type methodTypes struct {
+	request reflect.Type
+	reply   reflect.Type
+}
+
+// Our global recorder instance.
+var rec = &recorder{}
+
+// Our logger instance.
+var log = logger.NewLogger("record")
+
+// Request and reply types of known methods, by full method name.
+var methods = discoverMethods(map[string]reflect.Type{
+	"runtime.v1.RuntimeService":       reflect.TypeOf((*criv1.RuntimeServiceServer)(nil)).Elem(),
+	"runtime.v1.ImageService":         reflect.TypeOf((*criv1.ImageServiceServer)(nil)).Elem(),
+	"runtime.v1alpha2.RuntimeService": reflect.TypeOf((*criv1alpha2.RuntimeServiceServer)(nil)).Elem(),
+	"runtime.v1alpha2.ImageService":   reflect.TypeOf((*criv1alpha2.ImageServiceServer)(nil)).Elem(),
+})
+
+// Enabled returns true if recording is enabled.
+func Enabled() bool {
+	rec.Lock()
+	defer rec.Unlock()
+	return rec.file != nil
+}
+
+// Begin starts recording a call received by the relay. It returns nil
+// if recording is disabled. The request is recorded immediately, so any
+// later modifications to it by interceptors are not recorded.
+func Begin(kind, method string, req interface{}) *Call {
+	if !Enabled() {
+		return nil
+	}
+
+	c := &Call{entry: &Entry{Time: time.Now(), Kind: kind, Method: method}}
+	c.entry.Request = marshal(method, req)
+
+	return c
+}
+
+// End finishes recording a call with the given reply or error.
+func (c *Call) End(rpl interface{}, err error) {
+	if c == nil {
+		return
+	}
+	c.entry.setResult(rpl, err)
+	rec.write(c.entry)
+}
+
+// UnaryClientInterceptor records calls sent to the CRI runtime.
+func UnaryClientInterceptor(ctx context.Context, method string, req, rpl interface{},
+	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	c := Begin(KindRuntime, method, req)
+	err := invoker(ctx, method, req, rpl, cc, opts...)
+	c.End(rpl, err)
+	return err
+}
+
+// Load loads recorded entries from the given session.
+func Load(r io.Reader) ([]*Entry, error) {
+	entries := []*Entry{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		e := &Entry{}
+		if err := json.Unmarshal(scanner.Bytes(), e); err != nil {
+			return nil, recordError("failed to parse line %d: %v", line, err)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, recordError("failed to read session: %v", err)
+	}
+
+	return entries, nil
+}
+
+// LoadFile loads recorded entries from the given session file.
+func LoadFile(path string) ([]*Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, recordError("failed to open session file: %v", err)
+	}
+	defer f.Close()
+
+	return Load(f)
+}
+
+// MethodName returns the name of the method of the entry without the service.
+func (e *Entry) MethodName() string {
+	return methodName(e.Method)
+}
+
+// DecodeRequest decodes the recorded request of the entry.
+func (e *Entry) DecodeRequest() (interface{}, error) {
+	types, ok := methods[e.Method]
+	if !ok {
+		return nil, recordError("unknown method %s", e.Method)
+	}
+	return decode(e.Request, types.request)
+}
+
+// DecodeReply decodes the recorded reply of the entry as a reply for the given method.
+func (e *Entry) DecodeReply(method string) (interface{}, error) {
+	types, ok := methods[method]
+	if !ok {
+		return nil, recordError("unknown method %s", method)
+	}
+	return decode(e.Reply, types.reply)
+}
+
+// Err returns the recorded error of the entry, or nil if the call succeeded.
+func (e *Entry) Err() error {
+	if e.Code == codes.OK && e.Error == "" {
+		return nil
+	}
+	code := e.Code
+	if code == codes.OK {
+		code = codes.Unknown
+	}
+	return status.Error(code, e.Error)
+}
+
+// setResult sets the reply or error of the entry.
+func (e *Entry) setResult(rpl interface{}, err error) {
+	if err != nil {
+		st := status.Convert(err)
+		e.Code = st.Code()
+		e.Error = st.Message()
+		return
+	}
+	e.Reply = marshal(e.Method, rpl)
+}
+
+// configure (re)configures the recorder.
+func (r *recorder) configure(path string, config interface{}) {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.path != path {
+		if r.file != nil {
+			log.Info("closing old session file %q...", r.path)
+			r.file.Close()
+			r.file = nil
+		}
+
+		r.path = path
+		if r.path != "" {
+			var err error
+			log.Info("opening new session file %q...", r.path)
+			r.file, err = os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+			if err != nil {
+				log.Error("failed to open file %q: %v", r.path, err)
+			}
+		}
+	}
+
+	if r.file != nil {
+		r.writeLocked(&Entry{Time: time.Now(), Kind: KindConfig, Request: marshal(KindConfig, config)})
+	}
+}
+
+// write writes an entry to the session file.
+func (r *recorder) write(e *Entry) {
+	r.Lock()
+	defer r.Unlock()
+	r.writeLocked(e)
+}
+
+// writeLocked writes an entry to the session file, with the recorder locked.
+func (r *recorder) writeLocked(e *Entry) {
+	if r.file == nil {
+		return
+	}
+	raw, err := json.Marshal(e)
+	if err != nil {
+		log.Error("failed to record %s %s: %v", e.Kind, e.Method, err)
+		return
+	}
+	if _, err := r.file.Write(append(raw, '\n')); err != nil {
+		log.Error("failed to record %s %s: %v", e.Kind, e.Method, err)
+	}
+}
+
+// marshal marshals a message, logging any errors.
+func marshal(method string, msg interface{}) json.RawMessage {
+	raw, err := json.Marshal(msg)
+	if err != nil {
+		log.Error("failed to marshal %s message %T: %v", method, msg, err)
+		return nil
+	}
+	return raw
+}
+
+// decode decodes a message of the given (pointer) type.
+func decode(raw json.RawMessage, typ reflect.Type) (interface{}, error) {
+	msg := reflect.New(typ.Elem()).Interface()
+	if len(raw) == 0 {
+		return msg, nil
+	}
+	if err := json.Unmarshal(raw, msg); err != nil {
+		return nil, recordError("failed to decode %s: %v", typ.Elem().Name(), err)
+	}
+	return msg, nil
+}
+
+// discoverMethods discovers the request and reply types of the methods of services.
+func discoverMethods(services map[string]reflect.Type) map[string]*methodTypes {
+	methods := make(map[string]*methodTypes)
+	for service, typ := range services {
+		for i := 0; i < typ.NumMethod(); i++ {
+			m := typ.Method(i)
+			if m.Type.NumIn() != 2 || m.Type.NumOut() != 2 {
+				continue
+			}
+			methods["/"+service+"/"+m.Name] = &methodTypes{
+				request: m.Type.In(1),
+				reply:   m.Type.Out(0),
+			}
+		}
+	}
+	return methods
+}
+
+// methodName returns the basename of a method.
+func methodName(method string) string {
+	return method[strings.LastIndex(method, "/")+1:]
+}
+
+// recordError produces a formatted package-specific error.
+func recordError(format string, args ...interface{}) error {
+	return fmt.Errorf("record: "+format, args...)
+}
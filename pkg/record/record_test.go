@@ -0,0 +1,161 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package record
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+
+	"github.com/intel/cri-resource-manager/pkg/testutils"
+)
+
+func TestRecordAndLoad(t *testing.T) {
+	const (
+		createMethod = "/runtime.v1.RuntimeService/CreateContainer"
+		removeMethod = "/runtime.v1.RuntimeService/RemoveContainer"
+	)
+
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	rec.configure(path, map[string]interface{}{"policy": map[string]string{"Active": "none"}})
+	defer rec.configure("", nil)
+
+	if !Enabled() {
+		t.Fatalf("recording not enabled after configuration")
+	}
+
+	req := &criv1.CreateContainerRequest{
+		PodSandboxId: "pod0",
+		Config: &criv1.ContainerConfig{
+			Metadata: &criv1.ContainerMetadata{Name: "ctr0"},
+		},
+	}
+	call := Begin(KindIntercepted, createMethod, req)
+	req.PodSandboxId = "modified-after-begin"
+	call.End(&criv1.CreateContainerResponse{ContainerId: "ctr0-id"}, nil)
+
+	call = Begin(KindPassthrough, removeMethod, &criv1.RemoveContainerRequest{ContainerId: "ctr1-id"})
+	call.End(nil, status.Error(codes.NotFound, "no such container"))
+
+	entries, err := LoadFile(path)
+	testutils.VerifyError(t, err, 0, nil)
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 recorded entries, got %d", len(entries))
+	}
+
+	tcases := []struct {
+		name    string
+		entry   *Entry
+		kind    string
+		request interface{}
+		reply   interface{}
+		err     error
+	}{
+		{
+			name:  "configuration",
+			entry: entries[0],
+			kind:  KindConfig,
+		},
+		{
+			name:  "successful call",
+			entry: entries[1],
+			kind:  KindIntercepted,
+			request: &criv1.CreateContainerRequest{
+				PodSandboxId: "pod0",
+				Config: &criv1.ContainerConfig{
+					Metadata: &criv1.ContainerMetadata{Name: "ctr0"},
+				},
+			},
+			reply: &criv1.CreateContainerResponse{ContainerId: "ctr0-id"},
+		},
+		{
+			name:    "failed call",
+			entry:   entries[2],
+			kind:    KindPassthrough,
+			request: &criv1.RemoveContainerRequest{ContainerId: "ctr1-id"},
+			err:     status.Error(codes.NotFound, "no such container"),
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.entry.Kind != tc.kind {
+				t.Errorf("expected kind %q, got %q", tc.kind, tc.entry.Kind)
+			}
+			if tc.request != nil {
+				req, err := tc.entry.DecodeRequest()
+				testutils.VerifyError(t, err, 0, nil)
+				testutils.VerifyDeepEqual(t, "request", tc.request, req)
+			}
+			if tc.reply != nil {
+				rpl, err := tc.entry.DecodeReply(tc.entry.Method)
+				testutils.VerifyError(t, err, 0, nil)
+				testutils.VerifyDeepEqual(t, "reply", tc.reply, rpl)
+			}
+			if fmt.Sprint(tc.err) != fmt.Sprint(tc.entry.Err()) {
+				t.Errorf("expected error %v, got %v", tc.err, tc.entry.Err())
+			}
+		})
+	}
+}
+
+func TestResourcesOf(t *testing.T) {
+	tcases := []struct {
+		name     string
+		entry    *Entry
+		expected *containerResources
+	}{
+		{
+			name: "CreateContainer",
+			entry: &Entry{
+				Method: "/runtime.v1.RuntimeService/CreateContainer",
+				Request: marshal("", &criv1.CreateContainerRequest{
+					Config: &criv1.ContainerConfig{
+						Linux: &criv1.LinuxContainerConfig{
+							Resources: &criv1.LinuxContainerResources{CpusetCpus: "0-1"},
+						},
+					},
+				}),
+			},
+			expected: &containerResources{
+				Linux: map[string]interface{}{"cpuset_cpus": "0-1"},
+			},
+		},
+		{
+			name: "UpdateContainerResources",
+			entry: &Entry{
+				Method: "/runtime.v1.RuntimeService/UpdateContainerResources",
+				Request: marshal("", &criv1.UpdateContainerResourcesRequest{
+					ContainerId: "ctr0",
+					Linux:       &criv1.LinuxContainerResources{CpusetMems: "1"},
+				}),
+			},
+			expected: &containerResources{
+				ContainerID: "ctr0",
+				Linux:       map[string]interface{}{"cpuset_mems": "1"},
+			},
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := resourcesOf(tc.entry)
+			testutils.VerifyError(t, err, 0, nil)
+			testutils.VerifyDeepEqual(t, "resources", tc.expected, r)
+		})
+	}
+}
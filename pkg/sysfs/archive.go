@@ -0,0 +1,313 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sysfs
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// archivedEntries are the sysfs and procfs entries captured in a topology archive,
+// as glob patterns relative to the root directory.
+var archivedEntries = []string{
+	"sys/devices/system/cpu/online",
+	"sys/devices/system/cpu/offline",
+	"sys/devices/system/cpu/present",
+	"sys/devices/system/cpu/possible",
+	"sys/devices/system/cpu/isolated",
+	"sys/devices/system/cpu/cpu[0-9]*/online",
+	"sys/devices/system/cpu/cpu[0-9]*/node[0-9]*",
+	"sys/devices/system/cpu/cpu[0-9]*/topology/*",
+	"sys/devices/system/cpu/cpu[0-9]*/cpufreq/*",
+	"sys/devices/system/cpu/cpu[0-9]*/cache/index[0-9]*/*",
+	"sys/devices/system/node/online",
+	"sys/devices/system/node/possible",
+	"sys/devices/system/node/has_*",
+	"sys/devices/system/node/node[0-9]*/cpulist",
+	"sys/devices/system/node/node[0-9]*/cpumap",
+	"sys/devices/system/node/node[0-9]*/distance",
+	"sys/devices/system/node/node[0-9]*/meminfo",
+	"sys/devices/system/node/node[0-9]*/hugepages/hugepages-*/*",
+	"sys/devices/virtual/memory_tiering/memory_tier[0-9]*/nodelist",
+	"sys/bus/nd/devices/region[0-9]*/target_node",
+	"proc/cpuinfo",
+	"proc/meminfo",
+	"proc/cmdline",
+}
+
+// DumpTopology archives the system topology details into a gzipped tarball.
+// The archive can be used to discover the same system on another host using
+// DiscoverSystemFromArchive, or by extracting it and using the extracted tree
+// as the sys root directory.
+func DumpTopology(w io.Writer) error {
+	root := filepath.Join("/", sysRoot)
+
+	zw := gzip.NewWriter(w)
+	tw := tar.NewWriter(zw)
+	dirs := map[string]struct{}{}
+
+	for _, pattern := range archivedEntries {
+		entries, err := filepath.Glob(filepath.Join(root, pattern))
+		if err != nil {
+			return sysfsError(pattern, "invalid archive pattern: %v", err)
+		}
+		for _, entry := range entries {
+			name, err := filepath.Rel(root, entry)
+			if err != nil {
+				return sysfsError(entry, "failed to archive entry: %v", err)
+			}
+			if err := archiveEntry(tw, entry, name, dirs); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return sysfsError("topology archive", "failed to close archive: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		return sysfsError("topology archive", "failed to close archive: %v", err)
+	}
+
+	return nil
+}
+
+// archiveEntry adds a single file or symbolic link, and its parent directories, to the archive.
+func archiveEntry(tw *tar.Writer, path, name string, dirs map[string]struct{}) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return sysfsError(path, "failed to archive entry: %v", err)
+	}
+
+	hdr := &tar.Header{
+		Name:    filepath.ToSlash(name),
+		Mode:    0644,
+		ModTime: time.Now(),
+	}
+	var data []byte
+
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		if hdr.Linkname, err = os.Readlink(path); err != nil {
+			return sysfsError(path, "failed to archive link: %v", err)
+		}
+		hdr.Typeflag = tar.TypeSymlink
+		hdr.Mode = 0777
+	case info.Mode().IsRegular():
+		// Sizes of sysfs entries are bogus, so we read them first. Entries
+		// we can't read (write-only or privileged ones) are simply omitted.
+		if data, err = ioutil.ReadFile(path); err != nil {
+			return nil
+		}
+		hdr.Typeflag = tar.TypeReg
+		hdr.Size = int64(len(data))
+	default:
+		return nil
+	}
+
+	if err := archiveParentDirs(tw, filepath.Dir(name), dirs); err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return sysfsError(path, "failed to archive entry: %v", err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return sysfsError(path, "failed to archive entry: %v", err)
+	}
+
+	return nil
+}
+
+// archiveParentDirs adds any missing parent directories of an entry to the archive.
+func archiveParentDirs(tw *tar.Writer, dir string, dirs map[string]struct{}) error {
+	if dir == "." || dir == "/" {
+		return nil
+	}
+	if _, ok := dirs[dir]; ok {
+		return nil
+	}
+	if err := archiveParentDirs(tw, filepath.Dir(dir), dirs); err != nil {
+		return err
+	}
+
+	hdr := &tar.Header{
+		Name:     filepath.ToSlash(dir) + "/",
+		Typeflag: tar.TypeDir,
+		Mode:     0755,
+		ModTime:  time.Now(),
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return sysfsError(dir, "failed to archive directory: %v", err)
+	}
+	dirs[dir] = struct{}{}
+
+	return nil
+}
+
+// ExtractTopology extracts a topology archive created by DumpTopology into dir.
+func ExtractTopology(r io.Reader, dir string) error {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return sysfsError("topology archive", "failed to open archive: %v", err)
+	}
+	defer zr.Close()
+
+	tr := tar.NewReader(zr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return sysfsError("topology archive", "failed to read archive: %v", err)
+		}
+
+		name := filepath.Clean(filepath.FromSlash(hdr.Name))
+		if isOutsideDir(name) {
+			return sysfsError(hdr.Name, "refusing to extract archive entry outside %s", dir)
+		}
+		if err := checkNoSymlinks(dir, name); err != nil {
+			return sysfsError(hdr.Name, "refusing to extract archive entry: %v", err)
+		}
+		path := filepath.Join(dir, name)
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(path, 0755)
+		case tar.TypeSymlink:
+			if err := checkLinkTarget(dir, name, hdr.Linkname); err != nil {
+				return sysfsError(hdr.Name, "refusing to extract link: %v", err)
+			}
+			if err = os.MkdirAll(filepath.Dir(path), 0755); err == nil {
+				err = os.Symlink(hdr.Linkname, path)
+			}
+		case tar.TypeReg:
+			if err = os.MkdirAll(filepath.Dir(path), 0755); err == nil {
+				err = extractFile(tr, path)
+			}
+		}
+		if err != nil {
+			return sysfsError(path, "failed to extract archive entry: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// isOutsideDir checks if a relative path refers to anything outside its base directory.
+func isOutsideDir(name string) bool {
+	name = filepath.Clean(name)
+	return filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator))
+}
+
+// checkNoSymlinks checks that no existing component of name under dir is a
+// symbolic link, so that extracting name can't be redirected by a link that
+// was extracted from an earlier archive entry.
+func checkNoSymlinks(dir, name string) error {
+	path := dir
+	for _, component := range strings.Split(name, string(filepath.Separator)) {
+		path = filepath.Join(path, component)
+		info, err := os.Lstat(path)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("%s is a symbolic link", path)
+		}
+	}
+	return nil
+}
+
+// checkLinkTarget checks that a symbolic link name under dir points inside
+// dir. The target is resolved one component at a time, and resolving it
+// through another symbolic link is refused.
+func checkLinkTarget(dir, name, target string) error {
+	target = filepath.FromSlash(target)
+	if filepath.IsAbs(target) {
+		return fmt.Errorf("absolute link target %s", target)
+	}
+
+	path := []string{}
+	if parent := filepath.Dir(name); parent != "." {
+		path = strings.Split(parent, string(filepath.Separator))
+	}
+	for _, component := range strings.Split(target, string(filepath.Separator)) {
+		switch component {
+		case "", ".":
+			continue
+		case "..":
+			if len(path) == 0 {
+				return fmt.Errorf("link target %s outside %s", target, dir)
+			}
+			path = path[:len(path)-1]
+		default:
+			path = append(path, component)
+			info, err := os.Lstat(filepath.Join(dir, filepath.Join(path...)))
+			if err == nil && info.Mode()&os.ModeSymlink != 0 {
+				return fmt.Errorf("link target %s goes through another link", target)
+			}
+		}
+	}
+	return nil
+}
+
+// extractFile extracts the current archive entry into the given file.
+func extractFile(r io.Reader, path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, r)
+	return err
+}
+
+// DiscoverSystemFromArchive extracts a topology archive into dir and performs
+// discovery of the archived system. The extracted tree is used for looking up
+// further details, so dir needs to be kept around as long as the system is in
+// use. Discovery of Speed Select Technology details is never performed, as it
+// is not part of the archive.
+func DiscoverSystemFromArchive(archive, dir string, args ...DiscoveryFlag) (System, error) {
+	f, err := os.Open(archive)
+	if err != nil {
+		return nil, sysfsError(archive, "failed to open topology archive: %v", err)
+	}
+	defer f.Close()
+
+	if err := ExtractTopology(f, dir); err != nil {
+		return nil, err
+	}
+
+	flags := DiscoverDefault
+	if len(args) > 0 {
+		flags = DiscoverNone
+		for _, flag := range args {
+			flags |= flag
+		}
+	}
+
+	return DiscoverSystemAt(filepath.Join(dir, "sys"), flags&^DiscoverSst)
+}
@@ -0,0 +1,222 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sysfs
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	idset "github.com/intel/goresctrl/pkg/utils"
+)
+
+// TestTopologyArchive: unit test for DumpTopology() and DiscoverSystemFromArchive()
+func TestTopologyArchive(t *testing.T) {
+	files := map[string]string{
+		"sys/devices/system/cpu/online":             "0-3\n",
+		"sys/devices/system/cpu/isolated":           "\n",
+		"sys/devices/system/node/has_memory":        "0-2\n",
+		"sys/devices/system/node/has_normal_memory": "0-2\n",
+		"sys/devices/system/node/node0/cpulist":     "0-1\n",
+		"sys/devices/system/node/node0/distance":    "10 21 17\n",
+		"sys/devices/system/node/node0/meminfo":     "Node 0 MemTotal:       16384000 kB\nNode 0 MemFree:        8192000 kB\nNode 0 MemUsed:        8192000 kB\n",
+		"sys/devices/system/node/node1/cpulist":     "2-3\n",
+		"sys/devices/system/node/node1/distance":    "21 10 28\n",
+		"sys/devices/system/node/node1/meminfo":     "Node 1 MemTotal:       16384000 kB\nNode 1 MemFree:        8192000 kB\nNode 1 MemUsed:        8192000 kB\n",
+		"sys/devices/system/node/node2/cpulist":     "\n",
+		"sys/devices/system/node/node2/distance":    "17 28 10\n",
+		"sys/devices/system/node/node2/meminfo":     "Node 2 MemTotal:       67108864 kB\nNode 2 MemFree:        67108864 kB\nNode 2 MemUsed:        0 kB\n",
+		"sys/devices/system/cpu/cpu99/unarchived":   "should not be archived\n",
+
+		"sys/devices/system/node/node0/hugepages/hugepages-2048kB/nr_hugepages":      "512\n",
+		"sys/devices/system/node/node0/hugepages/hugepages-2048kB/free_hugepages":    "500\n",
+		"sys/devices/system/node/node0/hugepages/hugepages-1048576kB/nr_hugepages":   "2\n",
+		"sys/devices/system/node/node0/hugepages/hugepages-1048576kB/free_hugepages": "2\n",
+	}
+	for cpu, node := range []string{"0", "0", "1", "1"} {
+		dir := filepath.Join("sys/devices/system/cpu", "cpu"+string(rune('0'+cpu)))
+		files[filepath.Join(dir, "online")] = "1\n"
+		files[filepath.Join(dir, "topology/physical_package_id")] = node + "\n"
+		files[filepath.Join(dir, "topology/die_id")] = "0\n"
+		files[filepath.Join(dir, "topology/core_id")] = string(rune('0'+cpu/2)) + "\n"
+		files[filepath.Join(dir, "topology/thread_siblings_list")] = string(rune('0'+cpu)) + "\n"
+	}
+
+	hostRoot := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(hostRoot, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+	for cpu, node := range []string{"0", "0", "1", "1"} {
+		link := filepath.Join(hostRoot, "sys/devices/system/cpu", "cpu"+string(rune('0'+cpu)), "node"+node)
+		if err := os.Symlink("../../node/node"+node, link); err != nil {
+			t.Fatalf("failed to create symlink: %v", err)
+		}
+	}
+
+	savedSysRoot := sysRoot
+	defer SetSysRoot(savedSysRoot)
+	SetSysRoot(hostRoot)
+
+	archive := &bytes.Buffer{}
+	if err := DumpTopology(archive); err != nil {
+		t.Fatalf("failed to dump topology: %v", err)
+	}
+	archiveFile := filepath.Join(t.TempDir(), "topology.tar.gz")
+	if err := os.WriteFile(archiveFile, archive.Bytes(), 0644); err != nil {
+		t.Fatalf("failed to write topology archive: %v", err)
+	}
+
+	dir := t.TempDir()
+	sys, err := DiscoverSystemFromArchive(archiveFile, dir)
+	if err != nil {
+		t.Fatalf("failed to discover system from archive: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "sys/devices/system/cpu/cpu99/unarchived")); !os.IsNotExist(err) {
+		t.Errorf("unexpected entry in topology archive")
+	}
+	if sys.PackageCount() != 2 {
+		t.Errorf("expected 2 packages, got %d", sys.PackageCount())
+	}
+	if sys.CPUCount() != 4 {
+		t.Errorf("expected 4 CPUs, got %d", sys.CPUCount())
+	}
+	if sys.NUMANodeCount() != 3 {
+		t.Errorf("expected 3 NUMA nodes, got %d", sys.NUMANodeCount())
+	}
+	if node := sys.CPU(idset.ID(2)).NodeID(); node != 1 {
+		t.Errorf("expected CPU #2 in NUMA node #1, got #%d", node)
+	}
+	if memType := sys.Node(idset.ID(2)).GetMemoryType(); memType != MemoryTypePMEM {
+		t.Errorf("expected PMEM in NUMA node #2, got %v", memType)
+	}
+	if dist := sys.NodeDistance(idset.ID(0), idset.ID(2)); dist != 17 {
+		t.Errorf("expected distance 17 between NUMA nodes #0 and #2, got %d", dist)
+	}
+	hugePages, err := sys.Node(idset.ID(0)).HugePages()
+	if err != nil {
+		t.Fatalf("failed to get hugepages of NUMA node #0: %v", err)
+	}
+	expected := []HugePages{
+		{Size: 2 << 20, Total: 512, Free: 500},
+		{Size: 1 << 30, Total: 2, Free: 2},
+	}
+	if !reflect.DeepEqual(hugePages, expected) {
+		t.Errorf("expected hugepages %v for NUMA node #0, got %v", expected, hugePages)
+	}
+	if hugePages, err = sys.Node(idset.ID(1)).HugePages(); err != nil || len(hugePages) != 0 {
+		t.Errorf("expected no hugepages for NUMA node #1, got %v (error: %v)", hugePages, err)
+	}
+}
+
+// TestMaliciousTopologyArchive: unit test for refusing to extract entries outside the target directory.
+func TestMaliciousTopologyArchive(t *testing.T) {
+	type entry struct {
+		name string
+		link string
+		data string
+	}
+
+	tcases := []struct {
+		name    string
+		entries []entry
+	}{
+		{
+			name:    "entry outside directory",
+			entries: []entry{{name: "../outside/file", data: "pwned"}},
+		},
+		{
+			name: "absolute link target",
+			entries: []entry{
+				{name: "sys/evil", link: "OUTSIDE"},
+				{name: "sys/evil/file", data: "pwned"},
+			},
+		},
+		{
+			name: "relative link target outside directory",
+			entries: []entry{
+				{name: "sys/evil", link: "../../outside"},
+				{name: "sys/evil/file", data: "pwned"},
+			},
+		},
+		{
+			name: "writing through a link",
+			entries: []entry{
+				{name: "sys/devices/file", data: "ok"},
+				{name: "sys/evil", link: "devices"},
+				{name: "sys/evil/file", data: "pwned"},
+			},
+		},
+		{
+			name: "link target through another link",
+			entries: []entry{
+				{name: "sys/devices/up", link: "../.."},
+				{name: "sys/evil", link: "devices/up/../outside"},
+			},
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			root := t.TempDir()
+			dir := filepath.Join(root, "extract")
+			outside := filepath.Join(root, "outside")
+			if err := os.MkdirAll(outside, 0755); err != nil {
+				t.Fatalf("failed to create directory: %v", err)
+			}
+
+			archive := &bytes.Buffer{}
+			zw := gzip.NewWriter(archive)
+			tw := tar.NewWriter(zw)
+			for _, e := range tc.entries {
+				hdr := &tar.Header{Name: e.name, Mode: 0644, Typeflag: tar.TypeReg, Size: int64(len(e.data))}
+				if e.link != "" {
+					hdr = &tar.Header{Name: e.name, Mode: 0777, Typeflag: tar.TypeSymlink}
+					hdr.Linkname = strings.ReplaceAll(e.link, "OUTSIDE", outside)
+				}
+				if err := tw.WriteHeader(hdr); err != nil {
+					t.Fatalf("failed to write archive header: %v", err)
+				}
+				if _, err := tw.Write([]byte(e.data)); err != nil {
+					t.Fatalf("failed to write archive entry: %v", err)
+				}
+			}
+			if err := tw.Close(); err != nil {
+				t.Fatalf("failed to close archive: %v", err)
+			}
+			if err := zw.Close(); err != nil {
+				t.Fatalf("failed to close archive: %v", err)
+			}
+
+			if err := ExtractTopology(archive, dir); err == nil {
+				t.Errorf("expected extracting a malicious archive to fail")
+			}
+			if files, _ := os.ReadDir(outside); len(files) != 0 {
+				t.Errorf("unexpected entries extracted outside %s: %v", dir, files)
+			}
+		})
+	}
+}
@@ -40,6 +40,10 @@ const (
 	sysfsCPUPath = "devices/system/cpu"
 	// sysfs device/node subdirectory path
 	sysfsNumaNodePath = "devices/system/node"
+	// sysfs memory tiering subdirectory path
+	sysfsMemoryTieringPath = "devices/virtual/memory_tiering"
+	// sysfs libnvdimm bus devices subdirectory path
+	sysfsNvdimmPath = "bus/nd/devices"
 )
 
 // DiscoveryFlag controls what hardware details to discover.
@@ -59,7 +63,7 @@ const (
 	// DiscoverAll requests full supported discovery.
 	DiscoverAll DiscoveryFlag = 0xffffffff
 	// DiscoverDefault is the default set of discovery flags.
-	DiscoverDefault DiscoveryFlag = (DiscoverCPUTopology | DiscoverMemTopology | DiscoverSst)
+	DiscoverDefault DiscoveryFlag = (DiscoverCPUTopology | DiscoverMemTopology | DiscoverCache | DiscoverSst)
 )
 
 // MemoryType is an enum for the Node memory
@@ -72,6 +76,8 @@ const (
 	MemoryTypePMEM
 	// MemoryTypeHBM means that the node has high bandwidth memory
 	MemoryTypeHBM
+	// MemoryTypeCXL means that the node has CXL-attached (expander) memory
+	MemoryTypeCXL
 )
 
 // System devices
@@ -94,20 +100,26 @@ type System interface {
 	CPU(id idset.ID) CPU
 	Offlined() cpuset.CPUSet
 	Isolated() cpuset.CPUSet
+	CacheLevels() []int
+	CacheIDs(level int) []idset.ID
+	CacheCPUSet(level int, id idset.ID) cpuset.CPUSet
+	Cache(level int, id idset.ID) *Cache
+	LLCIDs() []idset.ID
+	LLCCPUSet(id idset.ID) cpuset.CPUSet
 }
 
 // System devices
 type system struct {
-	logger.Logger                          // our logger instance
-	flags         DiscoveryFlag            // system discovery flags
-	path          string                   // sysfs mount point
-	packages      map[idset.ID]*cpuPackage // physical packages
-	nodes         map[idset.ID]*node       // NUMA nodes
-	cpus          map[idset.ID]*cpu        // CPUs
-	cache         map[idset.ID]*Cache      // Cache
-	offline       idset.IDSet              // offlined CPUs
-	isolated      idset.IDSet              // isolated CPUs
-	threads       int                      // hyperthreads per core
+	logger.Logger                             // our logger instance
+	flags         DiscoveryFlag               // system discovery flags
+	path          string                      // sysfs mount point
+	packages      map[idset.ID]*cpuPackage    // physical packages
+	nodes         map[idset.ID]*node          // NUMA nodes
+	cpus          map[idset.ID]*cpu           // CPUs
+	caches        map[int]map[idset.ID]*Cache // CPU caches by level and id
+	offline       idset.IDSet                 // offlined CPUs
+	isolated      idset.IDSet                 // isolated CPUs
+	threads       int                         // hyperthreads per core
 }
 
 // CPUPackage is a physical package (a collection of CPUs).
@@ -140,6 +152,7 @@ type Node interface {
 	Distance() []int
 	DistanceFrom(id idset.ID) int
 	MemoryInfo() (*MemInfo, error)
+	HugePages() ([]HugePages, error)
 	GetMemoryType() MemoryType
 	HasNormalMemory() bool
 }
@@ -170,22 +183,26 @@ type CPU interface {
 	Isolated() bool
 	SetFrequencyLimits(min, max uint64) error
 	SstClos() int
+	CacheID(level int) idset.ID
+	LLCID() idset.ID
 }
 
 type cpu struct {
-	path     string      // sysfs path
-	id       idset.ID    // CPU id
-	pkg      idset.ID    // package id
-	die      idset.ID    // die id
-	node     idset.ID    // node id
-	core     idset.ID    // core id
-	threads  idset.IDSet // sibling/hyper-threads
-	baseFreq uint64      // CPU base frequency
-	freq     CPUFreq     // CPU frequencies
-	epp      EPP         // Energy Performance Preference from cpufreq governor
-	online   bool        // whether this CPU is online
-	isolated bool        // whether this CPU is isolated
-	sstClos  int         // SST-CP CLOS the CPU is associated with
+	path     string           // sysfs path
+	id       idset.ID         // CPU id
+	pkg      idset.ID         // package id
+	die      idset.ID         // die id
+	node     idset.ID         // node id
+	core     idset.ID         // core id
+	threads  idset.IDSet      // sibling/hyper-threads
+	baseFreq uint64           // CPU base frequency
+	freq     CPUFreq          // CPU frequencies
+	epp      EPP              // Energy Performance Preference from cpufreq governor
+	online   bool             // whether this CPU is online
+	isolated bool             // whether this CPU is isolated
+	sstClos  int              // SST-CP CLOS the CPU is associated with
+	caches   map[int]idset.ID // cache ids by cache level
+	llc      idset.ID         // last-level cache id
 }
 
 // CPUFreq is a CPU frequency scaling range
@@ -213,10 +230,21 @@ type MemInfo struct {
 	MemUsed  uint64
 }
 
+// HugePages contains data read from a NUMA node hugepages directory for a page size.
+type HugePages struct {
+	Size  uint64 // page size in bytes
+	Total uint64 // number of pages allocated
+	Free  uint64 // number of free pages
+}
+
 // CPU cache.
-//   Notes: cache-discovery is forced off now (by forcibly clearing the related discovery bit)
-//      Can't seem to make sense of the cache information exposed under sysfs. The cache ids
-//      do not seem to be unique, which IIUC is contrary to the documentation.
+//
+// Notes:
+//
+//	The cache ids exposed under sysfs are only unique within a cache level
+//	and have not always been unique even then. We identify each cache by its
+//	level and the lowest numbered CPU sharing it instead. Instruction caches
+//	are ignored, so each level has a single (data or unified) cache per CPU.
 
 // CacheType specifies a cache type.
 type CacheType string
@@ -232,10 +260,10 @@ const (
 
 // Cache has details about cache.
 type Cache struct {
-	id    idset.ID    // cache id
+	id    idset.ID    // cache id, lowest id of CPUs sharing this cache
 	kind  CacheType   // cache type
 	size  uint64      // cache size
-	level uint8       // cache level
+	level int         // cache level
 	cpus  idset.IDSet // CPUs sharing this cache
 }
 
@@ -282,7 +310,7 @@ func DiscoverSystemAt(path string, args ...DiscoveryFlag) (System, error) {
 
 // Discover performs system/hardware discovery.
 func (sys *system) Discover(flags DiscoveryFlag) error {
-	sys.flags |= (flags &^ DiscoverCache)
+	sys.flags |= flags
 
 	if (sys.flags & (DiscoverCPUTopology | DiscoverCache | DiscoverSst)) != 0 {
 		if err := sys.discoverCPUs(); err != nil {
@@ -360,17 +388,20 @@ func (sys *system) Discover(flags DiscoveryFlag) error {
 			sys.Debug("  base freq: %d", cpu.baseFreq)
 			sys.Debug("       freq: %d - %d", cpu.freq.min, cpu.freq.max)
 			sys.Debug("        epp: %d", cpu.epp)
+			sys.Debug("        llc: %d", cpu.llc)
 		}
 
 		sys.Debug("offline CPUs: %s", sys.offline)
 		sys.Debug("isolated CPUs: %s", sys.isolated)
 
-		for id, cch := range sys.cache {
-			sys.Debug("cache #%d:", id)
-			sys.Debug("   type: %v", cch.kind)
-			sys.Debug("   size: %d", cch.size)
-			sys.Debug("  level: %d", cch.level)
-			sys.Debug("   CPUs: %s", cch.cpus)
+		for _, level := range sys.CacheLevels() {
+			for _, id := range sys.CacheIDs(level) {
+				cch := sys.caches[level][id]
+				sys.Debug("L%d cache #%d:", level, id)
+				sys.Debug("   type: %v", cch.kind)
+				sys.Debug("   size: %d", cch.size)
+				sys.Debug("   CPUs: %s", cch.cpus)
+			}
 		}
 	}
 
@@ -551,6 +582,60 @@ func (sys *system) Isolated() cpuset.CPUSet {
 	return CPUSetFromIDSet(sys.isolated)
 }
 
+// LLCIDs gets the ids of all last-level caches.
+func (sys *system) LLCIDs() []idset.ID {
+	return sys.CacheIDs(sys.llcLevel())
+}
+
+// LLCCPUSet gets the set of CPUs sharing the given last-level cache.
+func (sys *system) LLCCPUSet(id idset.ID) cpuset.CPUSet {
+	return sys.CacheCPUSet(sys.llcLevel(), id)
+}
+
+// llcLevel gets the level of the last-level caches, or 0 if no caches were discovered.
+func (sys *system) llcLevel() int {
+	level := 0
+	for l := range sys.caches {
+		if l > level {
+			level = l
+		}
+	}
+	return level
+}
+
+// CacheLevels gets the levels of all discovered CPU caches.
+func (sys *system) CacheLevels() []int {
+	levels := make([]int, 0, len(sys.caches))
+	for level := range sys.caches {
+		levels = append(levels, level)
+	}
+	sort.Ints(levels)
+	return levels
+}
+
+// CacheIDs gets the ids of all CPU caches at the given level.
+func (sys *system) CacheIDs(level int) []idset.ID {
+	ids := make([]idset.ID, 0, len(sys.caches[level]))
+	for id := range sys.caches[level] {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// CacheCPUSet gets the set of CPUs sharing the given cache.
+func (sys *system) CacheCPUSet(level int, id idset.ID) cpuset.CPUSet {
+	if c, ok := sys.caches[level][id]; ok {
+		return CPUSetFromIDSet(c.cpus)
+	}
+	return cpuset.NewCPUSet()
+}
+
+// Cache gets the given CPU cache, or nil if it does not exist.
+func (sys *system) Cache(level int, id idset.ID) *Cache {
+	return sys.caches[level][id]
+}
+
 // Discover Cpus present in the system.
 func (sys *system) discoverCPUs() error {
 	if sys.cpus != nil {
@@ -576,7 +661,7 @@ func (sys *system) discoverCPUs() error {
 
 // Discover details of the given CPU.
 func (sys *system) discoverCPU(path string) error {
-	cpu := &cpu{path: path, id: getEnumeratedID(path), online: true, sstClos: -1}
+	cpu := &cpu{path: path, id: getEnumeratedID(path), online: true, sstClos: -1, llc: idset.Unknown}
 
 	cpu.isolated = sys.isolated.Has(cpu.id)
 
@@ -595,6 +680,9 @@ func (sys *system) discoverCPU(path string) error {
 		if _, err := readSysfsEntry(path, "topology/thread_siblings_list", &cpu.threads, ","); err != nil {
 			return err
 		}
+		if (sys.flags & DiscoverCache) != 0 {
+			sys.discoverCaches(cpu)
+		}
 	} else {
 		sys.offline.Add(cpu.id)
 	}
@@ -626,16 +714,30 @@ func (sys *system) discoverCPU(path string) error {
 
 	sys.cpus[cpu.id] = cpu
 
-	if (sys.flags & DiscoverCache) != 0 {
-		entries, _ := filepath.Glob(filepath.Join(path, "cache/index[0-9]*"))
-		for _, entry := range entries {
-			if err := sys.discoverCache(entry); err != nil {
-				return err
-			}
+	return nil
+}
+
+// discoverCaches discovers the (data and unified) caches of the given CPU.
+// Caches with incomplete or unexpected details are ignored.
+func (sys *system) discoverCaches(cpu *cpu) {
+	entries, _ := filepath.Glob(filepath.Join(cpu.path, "cache/index[0-9]*"))
+
+	cpu.caches = make(map[int]idset.ID)
+	llcLevel := 0
+	for _, entry := range entries {
+		c, err := sys.discoverCache(entry)
+		if err != nil {
+			sys.Warn("ignoring CPU cache: %v", err)
+			continue
+		}
+		if c == nil {
+			continue
+		}
+		cpu.caches[c.level] = c.id
+		if c.level > llcLevel {
+			llcLevel, cpu.llc = c.level, c.id
 		}
 	}
-
-	return nil
 }
 
 // ID returns the id of this CPU.
@@ -693,6 +795,19 @@ func (c *cpu) Isolated() bool {
 	return c.isolated
 }
 
+// CacheID returns the id of the cache at the given level for this CPU, or idset.Unknown.
+func (c *cpu) CacheID(level int) idset.ID {
+	if id, ok := c.caches[level]; ok {
+		return id
+	}
+	return idset.Unknown
+}
+
+// LLCID returns the id of the last-level cache of this CPU, or idset.Unknown.
+func (c *cpu) LLCID() idset.ID {
+	return c.llc
+}
+
 // SstClos returns the Speed Select Core Power CLOS number assigned to the CPU
 // -1 implies that no SST prioritization is in effect
 func (c *cpu) SstClos() int {
@@ -791,19 +906,21 @@ func (sys *system) discoverNodes() error {
 	sys.Logger.Info("NUMA nodes with normal memory: %s", normalMemNodes.String())
 
 	dramNodes := memoryNodes.Intersection(cpuNodes)
-	pmemOrHbmNodes := memoryNodes.Difference(dramNodes)
+	specialNodes := memoryNodes.Difference(dramNodes)
 
 	dramNodeIds := IDSetFromCPUSet(dramNodes)
-	pmemOrHbmNodeIds := IDSetFromCPUSet(pmemOrHbmNodes)
+	specialNodeIds := IDSetFromCPUSet(specialNodes)
 
 	infos := make(map[idset.ID]*MemInfo)
 	dramAvg := uint64(0)
-	if len(pmemOrHbmNodeIds) > 0 && len(dramNodeIds) > 0 {
+	if len(specialNodeIds) > 0 && len(dramNodeIds) > 0 {
 		// There is special memory present in the system.
 
-		// FIXME assumption: if a node only has memory (and no CPUs), it's PMEM or HBM. Otherwise it's DRAM.
-		// Also, we figure out if the memory is HBM or PMEM based on the amount. If the amount of memory is
-		// smaller than the average amount of DRAM per node, it's HBM, otherwise PMEM.
+		// FIXME assumption: if a node only has memory (and no CPUs), it's PMEM, HBM or CXL.
+		// Otherwise it's DRAM. We tell these apart using memory tiers and node distances
+		// if we can. Otherwise we figure out if the memory is HBM or PMEM based on the amount.
+		// If the amount of memory is smaller than the average amount of DRAM per node, it's
+		// HBM, otherwise PMEM.
 		dramTotal := uint64(0)
 		for _, node := range sys.nodes {
 			info, err := node.MemoryInfo()
@@ -822,30 +939,138 @@ func (sys *system) discoverNodes() error {
 		}
 	}
 
+	tiers := sys.discoverMemoryTiers()
+	nvdimm := sys.discoverNvdimmNodes()
+
 	for _, node := range sys.nodes {
-		if _, ok := pmemOrHbmNodeIds[node.id]; ok {
+		if _, ok := specialNodeIds[node.id]; ok {
 			mem, ok := infos[node.id]
 			if !ok {
 				return fmt.Errorf("not able to determine system special memory types")
 			}
-			if mem.MemTotal < dramAvg {
-				sys.Logger.Info("node %d has HBM memory", node.id)
+			if mtype, ok := sys.classifyMemoryNode(node, dramNodeIds, tiers, nvdimm); ok {
+				node.memoryType = mtype
+			} else if mem.MemTotal < dramAvg {
 				node.memoryType = MemoryTypeHBM
 			} else {
-				sys.Logger.Info("node %d has PMEM memory", node.id)
 				node.memoryType = MemoryTypePMEM
 			}
+			switch node.memoryType {
+			case MemoryTypeHBM:
+				sys.Logger.Info("node %d has HBM memory", node.id)
+			case MemoryTypeCXL:
+				sys.Logger.Info("node %d has CXL memory", node.id)
+			default:
+				sys.Logger.Info("node %d has PMEM memory", node.id)
+			}
 		} else if _, ok := dramNodeIds[node.id]; ok {
 			sys.Logger.Info("node %d has DRAM memory", node.id)
 			node.memoryType = MemoryTypeDRAM
 		} else {
-			return fmt.Errorf("Unknown memory type for node %v (pmem nodes: %s, dram nodes: %s)", node, pmemOrHbmNodes, dramNodes)
+			return fmt.Errorf("Unknown memory type for node %v (special nodes: %s, dram nodes: %s)", node, specialNodes, dramNodes)
 		}
 	}
 
 	return nil
 }
 
+// discoverMemoryTiers discovers the memory tier of NUMA nodes, if the kernel exposes them.
+func (sys *system) discoverMemoryTiers() map[idset.ID]int {
+	entries, _ := filepath.Glob(filepath.Join(sys.path, sysfsMemoryTieringPath, "memory_tier[0-9]*"))
+	if len(entries) == 0 {
+		return nil
+	}
+
+	tiers := make(map[idset.ID]int)
+	for _, entry := range entries {
+		tier := int(getEnumeratedID(entry))
+		nodes := idset.NewIDSet()
+		if _, err := readSysfsEntry(entry, "nodelist", &nodes, ","); err != nil {
+			sys.Logger.Error("failed to read memory tier %d nodes: %v", tier, err)
+			continue
+		}
+		for _, id := range nodes.Members() {
+			tiers[id] = tier
+		}
+		sys.Logger.Info("memory tier %d has NUMA nodes %s", tier, nodes)
+	}
+
+	return tiers
+}
+
+// discoverNvdimmNodes discovers the NUMA nodes backed by NVDIMM (PMEM) regions.
+func (sys *system) discoverNvdimmNodes() idset.IDSet {
+	entries, _ := filepath.Glob(filepath.Join(sys.path, sysfsNvdimmPath, "region[0-9]*"))
+	if len(entries) == 0 {
+		return nil
+	}
+
+	nodes := idset.NewIDSet()
+	for _, entry := range entries {
+		var id int
+		if _, err := readSysfsEntry(entry, "target_node", &id); err != nil || id < 0 {
+			continue
+		}
+		nodes.Add(idset.ID(id))
+	}
+
+	return nodes
+}
+
+// classifyMemoryNode tries to determine the type of memory of a CPU-less NUMA node
+// using memory tiers, NVDIMM regions, and node distances. It returns false if none
+// of these tell the type of memory apart.
+func (sys *system) classifyMemoryNode(n *node, dram idset.IDSet, tiers map[idset.ID]int, nvdimm idset.IDSet) (MemoryType, bool) {
+	// memory backed by an NVDIMM region is PMEM
+	if nvdimm != nil && nvdimm.Has(n.id) {
+		return MemoryTypePMEM, true
+	}
+
+	// compare memory tiers, if known: faster than DRAM is HBM, slower is CXL
+	if tier, ok := tiers[n.id]; ok {
+		dramTier := -1
+		for _, id := range dram.Members() {
+			if t, ok := tiers[id]; ok && t > dramTier {
+				dramTier = t
+			}
+		}
+		if dramTier >= 0 {
+			switch {
+			case tier < dramTier:
+				return MemoryTypeHBM, true
+			case tier > dramTier:
+				return MemoryTypeCXL, true
+			}
+			return MemoryTypeDRAM, false
+		}
+	}
+
+	// otherwise, without any PMEM in the system, treat nodes farther away from
+	// all DRAM than DRAM nodes are from each other as CXL memory expanders. With
+	// a single DRAM node there is no remote DRAM distance to compare against.
+	if nvdimm == nil && len(dram) > 1 {
+		maxDRAM, minNode := 0, -1
+		for _, from := range dram.Members() {
+			for _, to := range dram.Members() {
+				if from == to {
+					continue
+				}
+				if d := sys.NodeDistance(from, to); d > maxDRAM {
+					maxDRAM = d
+				}
+			}
+			if d := n.DistanceFrom(from); minNode < 0 || d < minNode {
+				minNode = d
+			}
+		}
+		if minNode > maxDRAM {
+			return MemoryTypeCXL, true
+		}
+	}
+
+	return MemoryTypeDRAM, false
+}
+
 // Discover details of the given NUMA node.
 func (sys *system) discoverNode(path string) error {
 	node := &node{path: path, id: getEnumeratedID(path)}
@@ -942,6 +1167,35 @@ func (n *node) MemoryInfo() (*MemInfo, error) {
 	return buf, nil
 }
 
+// HugePages returns the hugepages allocated on this node, in increasing page size order.
+func (n *node) HugePages() ([]HugePages, error) {
+	dirs, err := filepath.Glob(filepath.Join(n.path, "hugepages", "hugepages-*kB"))
+	if err != nil {
+		return nil, sysfsError(n.path, "failed to look up hugepages: %v", err)
+	}
+
+	pages := []HugePages{}
+	for _, dir := range dirs {
+		kB := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(dir), "hugepages-"), "kB")
+		size, err := strconv.ParseUint(kB, 10, 64)
+		if err != nil {
+			return nil, sysfsError(dir, "failed to parse hugepage size: %v", err)
+		}
+		hp := HugePages{Size: size * 1024}
+		if _, err := readSysfsEntry(dir, "nr_hugepages", &hp.Total); err != nil {
+			return nil, err
+		}
+		if _, err := readSysfsEntry(dir, "free_hugepages", &hp.Free); err != nil {
+			return nil, err
+		}
+		pages = append(pages, hp)
+	}
+
+	sort.Slice(pages, func(i, j int) bool { return pages[i].Size < pages[j].Size })
+
+	return pages, nil
+}
+
 // GetMemoryType returns the memory type for this node.
 func (n *node) GetMemoryType() MemoryType {
 	return n.memoryType
@@ -1063,74 +1317,104 @@ func (p *cpuPackage) SstInfo() *sst.SstPackageInfo {
 	return p.sstInfo
 }
 
-// Discover cache associated with the given CPU.
-//
-// Notes:
-//
-//	I'm not sure how to interpret the cache information under sysfs.
-//	This code is now effectively disabled by forcing the associated
-//	discovery bit off in the discovery flags.
-func (sys *system) discoverCache(path string) error {
-	var id idset.ID
-
-	if _, err := readSysfsEntry(path, "id", &id); err != nil {
-		return sysfsError(path, "can't read cache id: %v", err)
+// Discover the cache at the given sysfs path, ignoring instruction caches.
+func (sys *system) discoverCache(path string) (*Cache, error) {
+	kind := ""
+	if _, err := readSysfsEntry(path, "type", &kind); err != nil {
+		return nil, sysfsError(path, "can't read cache type: %v", err)
 	}
-
-	if sys.cache == nil {
-		sys.cache = make(map[idset.ID]*Cache)
+	switch kind {
+	case "Data", "Unified":
+	case "Instruction":
+		return nil, nil
+	default:
+		return nil, sysfsError(path, "unknown cache type: %s", kind)
 	}
 
-	if _, found := sys.cache[id]; found {
-		return nil
+	level := 0
+	if _, err := readSysfsEntry(path, "level", &level); err != nil {
+		return nil, sysfsError(path, "can't read cache level: %v", err)
 	}
-
-	c := &Cache{id: id}
-
-	if _, err := readSysfsEntry(path, "level", &c.level); err != nil {
-		return sysfsError(path, "can't read cache level: %v", err)
+	cpus := idset.NewIDSet()
+	if _, err := readSysfsEntry(path, "shared_cpu_list", &cpus, ","); err != nil {
+		return nil, sysfsError(path, "can't read shared CPUs: %v", err)
 	}
-	if _, err := readSysfsEntry(path, "shared_cpu_list", &c.cpus, ","); err != nil {
-		return sysfsError(path, "can't read shared CPUs: %v", err)
+	if cpus.Size() == 0 {
+		return nil, sysfsError(path, "no CPUs sharing cache")
 	}
-	kind := ""
-	if _, err := readSysfsEntry(path, "type", &kind); err != nil {
-		return sysfsError(path, "can't read cache type: %v", err)
+	id := cpus.SortedMembers()[0]
+
+	if sys.caches == nil {
+		sys.caches = make(map[int]map[idset.ID]*Cache)
 	}
-	switch kind {
-	case "Data":
-		c.kind = DataCache
-	case "Instruction":
-		c.kind = InstructionCache
-	case "Unified":
-		c.kind = UnifiedCache
-	default:
-		return sysfsError(path, "unknown cache type: %s", kind)
+	if sys.caches[level] == nil {
+		sys.caches[level] = make(map[idset.ID]*Cache)
 	}
+	if c, found := sys.caches[level][id]; found {
+		return c, nil
+	}
+
+	c := &Cache{id: id, kind: CacheType(kind), level: level, cpus: cpus}
 
 	size := ""
 	if _, err := readSysfsEntry(path, "size", &size); err != nil {
-		return sysfsError(path, "can't read cache size: %v", err)
+		return nil, sysfsError(path, "can't read cache size: %v", err)
 	}
+	bytes, err := parseCacheSize(size)
+	if err != nil {
+		return nil, sysfsError(path, "%v", err)
+	}
+	c.size = bytes
 
-	base := size[0 : len(size)-1]
-	suff := size[len(size)-1]
-	unit := map[byte]uint64{'K': 1 << 10, 'M': 1 << 20, 'G': 1 << 30}
+	sys.caches[level][id] = c
 
+	return c, nil
+}
+
+// parseCacheSize parses a cache size with an optional K, M or G unit suffix.
+func parseCacheSize(size string) (uint64, error) {
+	unit := uint64(1)
+	base := size
+	if n := len(size); n > 0 {
+		switch size[n-1] {
+		case 'K':
+			unit, base = 1<<10, size[:n-1]
+		case 'M':
+			unit, base = 1<<20, size[:n-1]
+		case 'G':
+			unit, base = 1<<30, size[:n-1]
+		}
+	}
 	val, err := strconv.ParseUint(base, 10, 0)
 	if err != nil {
-		return sysfsError(path, "can't parse cache size '%s': %v", size, err)
+		return 0, fmt.Errorf("can't parse cache size '%s': %v", size, err)
 	}
+	return val * unit, nil
+}
 
-	if u, ok := unit[suff]; ok {
-		c.size = val * u
-	} else {
-		c.size = val*1000 + u - '0'
-	}
+// ID returns the id of this cache.
+func (c *Cache) ID() idset.ID {
+	return c.id
+}
 
-	sys.cache[c.id] = c
+// Type returns the type of this cache.
+func (c *Cache) Type() CacheType {
+	return c.kind
+}
 
-	return nil
+// Level returns the level of this cache.
+func (c *Cache) Level() int {
+	return c.level
+}
+
+// Size returns the size of this cache in bytes.
+func (c *Cache) Size() uint64 {
+	return c.size
+}
+
+// CPUSet returns the set of CPUs sharing this cache.
+func (c *Cache) CPUSet() cpuset.CPUSet {
+	return CPUSetFromIDSet(c.cpus)
 }
 
 // eppStrings initialized this way to better catch changes in the enum
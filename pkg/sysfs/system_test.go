@@ -0,0 +1,302 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sysfs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	idset "github.com/intel/goresctrl/pkg/utils"
+)
+
+// TestMemoryTypeDiscovery: unit test for discovering the memory type of CPU-less NUMA nodes.
+func TestMemoryTypeDiscovery(t *testing.T) {
+	const (
+		bigMem   = "Node 2 MemTotal:       67108864 kB\nNode 2 MemFree:        67108864 kB\nNode 2 MemUsed:        0 kB\n"
+		smallMem = "Node 2 MemTotal:       4194304 kB\nNode 2 MemFree:        4194304 kB\nNode 2 MemUsed:        0 kB\n"
+	)
+
+	tcases := []struct {
+		name     string
+		distance string
+		meminfo  string
+		extra    map[string]string
+		expected MemoryType
+	}{
+		{
+			name:     "close big node without tiers is PMEM",
+			distance: "17 28 10\n",
+			meminfo:  bigMem,
+			expected: MemoryTypePMEM,
+		},
+		{
+			name:     "close small node without tiers is HBM",
+			distance: "13 28 10\n",
+			meminfo:  smallMem,
+			expected: MemoryTypeHBM,
+		},
+		{
+			name:     "distant node without tiers is CXL",
+			distance: "30 40 10\n",
+			meminfo:  bigMem,
+			expected: MemoryTypeCXL,
+		},
+		{
+			name:     "distant node without tiers with NVDIMM is PMEM",
+			distance: "30 40 10\n",
+			meminfo:  bigMem,
+			extra: map[string]string{
+				"sys/bus/nd/devices/region0/target_node": "2\n",
+			},
+			expected: MemoryTypePMEM,
+		},
+		{
+			name:     "slower tier is CXL",
+			distance: "17 28 10\n",
+			meminfo:  bigMem,
+			extra: map[string]string{
+				"sys/devices/virtual/memory_tiering/memory_tier4/nodelist":  "0-1\n",
+				"sys/devices/virtual/memory_tiering/memory_tier22/nodelist": "2\n",
+			},
+			expected: MemoryTypeCXL,
+		},
+		{
+			name:     "slower tier with NVDIMM is PMEM",
+			distance: "17 28 10\n",
+			meminfo:  bigMem,
+			extra: map[string]string{
+				"sys/devices/virtual/memory_tiering/memory_tier4/nodelist":  "0-1\n",
+				"sys/devices/virtual/memory_tiering/memory_tier22/nodelist": "2\n",
+				"sys/bus/nd/devices/region0/target_node":                    "2\n",
+			},
+			expected: MemoryTypePMEM,
+		},
+		{
+			name:     "faster tier is HBM",
+			distance: "17 28 10\n",
+			meminfo:  bigMem,
+			extra: map[string]string{
+				"sys/devices/virtual/memory_tiering/memory_tier1/nodelist": "2\n",
+				"sys/devices/virtual/memory_tiering/memory_tier4/nodelist": "0-1\n",
+			},
+			expected: MemoryTypeHBM,
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			files := map[string]string{
+				"sys/devices/system/cpu/online":             "0-3\n",
+				"sys/devices/system/cpu/isolated":           "\n",
+				"sys/devices/system/node/has_memory":        "0-2\n",
+				"sys/devices/system/node/has_normal_memory": "0-1\n",
+				"sys/devices/system/node/node0/cpulist":     "0-1\n",
+				"sys/devices/system/node/node0/distance":    "10 21 17\n",
+				"sys/devices/system/node/node0/meminfo":     "Node 0 MemTotal:       16384000 kB\nNode 0 MemFree:        8192000 kB\nNode 0 MemUsed:        8192000 kB\n",
+				"sys/devices/system/node/node1/cpulist":     "2-3\n",
+				"sys/devices/system/node/node1/distance":    "21 10 28\n",
+				"sys/devices/system/node/node1/meminfo":     "Node 1 MemTotal:       16384000 kB\nNode 1 MemFree:        8192000 kB\nNode 1 MemUsed:        8192000 kB\n",
+				"sys/devices/system/node/node2/cpulist":     "\n",
+				"sys/devices/system/node/node2/distance":    tc.distance,
+				"sys/devices/system/node/node2/meminfo":     tc.meminfo,
+			}
+			for name, content := range tc.extra {
+				files[name] = content
+			}
+
+			root := createTestSysfs(t, files, []string{"0", "0", "1", "1"})
+
+			sys, err := DiscoverSystemAt(filepath.Join(root, "sys"), DiscoverCPUTopology|DiscoverMemTopology)
+			if err != nil {
+				t.Fatalf("failed to discover system: %v", err)
+			}
+			for _, id := range []idset.ID{0, 1} {
+				if memType := sys.Node(id).GetMemoryType(); memType != MemoryTypeDRAM {
+					t.Errorf("expected DRAM in NUMA node #%d, got %v", id, memType)
+				}
+			}
+			if memType := sys.Node(idset.ID(2)).GetMemoryType(); memType != tc.expected {
+				t.Errorf("expected memory type %v in NUMA node #2, got %v", tc.expected, memType)
+			}
+		})
+	}
+}
+
+// createTestSysfs writes the given files under a temporary root, adds the
+// topology of CPUs in the given NUMA nodes, and returns the root.
+func createTestSysfs(t *testing.T, files map[string]string, cpuNodes []string) string {
+	for cpu, node := range cpuNodes {
+		dir := filepath.Join("sys/devices/system/cpu", "cpu"+string(rune('0'+cpu)))
+		files[filepath.Join(dir, "online")] = "1\n"
+		files[filepath.Join(dir, "topology/physical_package_id")] = node + "\n"
+		files[filepath.Join(dir, "topology/die_id")] = "0\n"
+		files[filepath.Join(dir, "topology/core_id")] = string(rune('0'+cpu/2)) + "\n"
+		files[filepath.Join(dir, "topology/thread_siblings_list")] = string(rune('0'+cpu)) + "\n"
+	}
+
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+	for cpu, node := range cpuNodes {
+		link := filepath.Join(root, "sys/devices/system/cpu", "cpu"+string(rune('0'+cpu)), "node"+node)
+		if err := os.Symlink("../../node/node"+node, link); err != nil {
+			t.Fatalf("failed to create symlink: %v", err)
+		}
+	}
+
+	return root
+}
+
+// TestSingleDRAMNodeMemoryTypeDiscovery: unit test for discovering the memory type
+// of CPU-less NUMA nodes next to a single DRAM node.
+func TestSingleDRAMNodeMemoryTypeDiscovery(t *testing.T) {
+	tcases := []struct {
+		name     string
+		distance string
+		meminfo  string
+		expected MemoryType
+	}{
+		{
+			name:     "close big node is PMEM",
+			distance: "17",
+			meminfo:  "Node 1 MemTotal:       67108864 kB\nNode 1 MemFree:        67108864 kB\nNode 1 MemUsed:        0 kB\n",
+			expected: MemoryTypePMEM,
+		},
+		{
+			name:     "close small node is HBM",
+			distance: "13",
+			meminfo:  "Node 1 MemTotal:       4194304 kB\nNode 1 MemFree:        4194304 kB\nNode 1 MemUsed:        0 kB\n",
+			expected: MemoryTypeHBM,
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			files := map[string]string{
+				"sys/devices/system/cpu/online":             "0-3\n",
+				"sys/devices/system/cpu/isolated":           "\n",
+				"sys/devices/system/node/has_memory":        "0-1\n",
+				"sys/devices/system/node/has_normal_memory": "0\n",
+				"sys/devices/system/node/node0/cpulist":     "0-3\n",
+				"sys/devices/system/node/node0/distance":    "10 " + tc.distance + "\n",
+				"sys/devices/system/node/node0/meminfo":     "Node 0 MemTotal:       16384000 kB\nNode 0 MemFree:        8192000 kB\nNode 0 MemUsed:        8192000 kB\n",
+				"sys/devices/system/node/node1/cpulist":     "\n",
+				"sys/devices/system/node/node1/distance":    tc.distance + " 10\n",
+				"sys/devices/system/node/node1/meminfo":     tc.meminfo,
+			}
+			root := createTestSysfs(t, files, []string{"0", "0", "0", "0"})
+
+			sys, err := DiscoverSystemAt(filepath.Join(root, "sys"), DiscoverCPUTopology|DiscoverMemTopology)
+			if err != nil {
+				t.Fatalf("failed to discover system: %v", err)
+			}
+			if memType := sys.Node(idset.ID(0)).GetMemoryType(); memType != MemoryTypeDRAM {
+				t.Errorf("expected DRAM in NUMA node #0, got %v", memType)
+			}
+			if memType := sys.Node(idset.ID(1)).GetMemoryType(); memType != tc.expected {
+				t.Errorf("expected memory type %v in NUMA node #1, got %v", tc.expected, memType)
+			}
+		})
+	}
+}
+
+// TestCacheDiscovery: unit test for discovering CPU caches.
+func TestCacheDiscovery(t *testing.T) {
+	files := map[string]string{
+		"sys/devices/system/cpu/online":             "0-3\n",
+		"sys/devices/system/cpu/isolated":           "\n",
+		"sys/devices/system/node/has_memory":        "0\n",
+		"sys/devices/system/node/has_normal_memory": "0\n",
+		"sys/devices/system/node/node0/cpulist":     "0-3\n",
+		"sys/devices/system/node/node0/distance":    "10\n",
+		"sys/devices/system/node/node0/meminfo":     "Node 0 MemTotal:       16384000 kB\nNode 0 MemFree:        8192000 kB\nNode 0 MemUsed:        8192000 kB\n",
+	}
+	for cpu := 0; cpu < 4; cpu++ {
+		id := string(rune('0' + cpu))
+		dir := filepath.Join("sys/devices/system/cpu", "cpu"+id)
+		files[filepath.Join(dir, "online")] = "1\n"
+		files[filepath.Join(dir, "topology/physical_package_id")] = "0\n"
+		files[filepath.Join(dir, "topology/die_id")] = "0\n"
+		files[filepath.Join(dir, "topology/core_id")] = id + "\n"
+		files[filepath.Join(dir, "topology/thread_siblings_list")] = id + "\n"
+		for idx, cache := range []struct{ level, kind, size, cpus string }{
+			{"1", "Data", "32K", id},
+			{"1", "Instruction", "32K", id},
+			{"2", "Unified", "2048K", []string{"0-1", "2-3"}[cpu/2]},
+			{"3", "Unified", "8M", "0-3"},
+		} {
+			index := filepath.Join(dir, "cache", "index"+string(rune('0'+idx)))
+			files[filepath.Join(index, "level")] = cache.level + "\n"
+			files[filepath.Join(index, "type")] = cache.kind + "\n"
+			files[filepath.Join(index, "size")] = cache.size + "\n"
+			files[filepath.Join(index, "shared_cpu_list")] = cache.cpus + "\n"
+		}
+	}
+
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+	for cpu := 0; cpu < 4; cpu++ {
+		link := filepath.Join(root, "sys/devices/system/cpu", "cpu"+string(rune('0'+cpu)), "node0")
+		if err := os.Symlink("../../node/node0", link); err != nil {
+			t.Fatalf("failed to create symlink: %v", err)
+		}
+	}
+
+	sys, err := DiscoverSystemAt(filepath.Join(root, "sys"), DiscoverCPUTopology|DiscoverCache)
+	if err != nil {
+		t.Fatalf("failed to discover system: %v", err)
+	}
+
+	if levels := sys.CacheLevels(); len(levels) != 3 || levels[0] != 1 || levels[2] != 3 {
+		t.Errorf("expected cache levels [1 2 3], got %v", levels)
+	}
+	if ids := sys.CacheIDs(1); len(ids) != 4 {
+		t.Errorf("expected 4 L1 caches (instruction caches ignored), got %v", ids)
+	}
+	if ids := sys.CacheIDs(2); len(ids) != 2 || ids[0] != 0 || ids[1] != 2 {
+		t.Errorf("expected L2 caches [0 2], got %v", ids)
+	}
+	if cpus := sys.CacheCPUSet(2, 2); cpus.String() != "2-3" {
+		t.Errorf("expected L2 cache #2 to be shared by CPUs 2-3, got %s", cpus)
+	}
+	if id := sys.CPU(3).CacheID(2); id != 2 {
+		t.Errorf("expected CPU #3 to be in L2 cache #2, got %d", id)
+	}
+	if id := sys.CPU(3).CacheID(4); id != idset.Unknown {
+		t.Errorf("expected CPU #3 to have no L4 cache, got %d", id)
+	}
+	if c := sys.Cache(3, 0); c == nil || c.Size() != 8<<20 || c.Type() != UnifiedCache || c.CPUSet().Size() != 4 {
+		t.Errorf("unexpected L3 cache #0: %v", c)
+	}
+	if ids := sys.LLCIDs(); len(ids) != 1 || ids[0] != 0 || sys.CPU(2).LLCID() != 0 {
+		t.Errorf("expected a single last-level cache #0, got %v", ids)
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutils
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// CreateFakeSysfs creates a fake single NUMA node host root with the given
+// number of CPUs, usable as the root of sysfs.SetSysRoot.
+func CreateFakeSysfs(t *testing.T, cpus int) string {
+	last := strconv.Itoa(cpus - 1)
+	files := map[string]string{
+		"sys/devices/system/cpu/online":             "0-" + last + "\n",
+		"sys/devices/system/cpu/isolated":           "\n",
+		"sys/devices/system/node/has_memory":        "0\n",
+		"sys/devices/system/node/has_normal_memory": "0\n",
+		"sys/devices/system/node/node0/cpulist":     "0-" + last + "\n",
+		"sys/devices/system/node/node0/distance":    "10\n",
+		"sys/devices/system/node/node0/meminfo":     "Node 0 MemTotal:       16384000 kB\nNode 0 MemFree:        8192000 kB\nNode 0 MemUsed:        8192000 kB\n",
+	}
+	stat := "cpu  100 0 100 1000 0 0 0 0 0 0\n"
+	for cpu := 0; cpu < cpus; cpu++ {
+		stat += "cpu" + strconv.Itoa(cpu) + " 100 0 100 1000 0 0 0 0 0 0\n"
+	}
+	files["proc/stat"] = stat
+	for cpu := 0; cpu < cpus; cpu++ {
+		dir := filepath.Join("sys/devices/system/cpu", "cpu"+strconv.Itoa(cpu))
+		files[filepath.Join(dir, "online")] = "1\n"
+		files[filepath.Join(dir, "topology/physical_package_id")] = "0\n"
+		files[filepath.Join(dir, "topology/die_id")] = "0\n"
+		files[filepath.Join(dir, "topology/core_id")] = strconv.Itoa(cpu) + "\n"
+		files[filepath.Join(dir, "topology/thread_siblings_list")] = strconv.Itoa(cpu) + "\n"
+	}
+
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+	for cpu := 0; cpu < cpus; cpu++ {
+		link := filepath.Join(root, "sys/devices/system/cpu", "cpu"+strconv.Itoa(cpu), "node0")
+		if err := os.Symlink("../../node/node0", link); err != nil {
+			t.Fatalf("failed to create symlink: %v", err)
+		}
+	}
+
+	return root
+}
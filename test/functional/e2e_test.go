@@ -30,6 +30,7 @@ import (
 	resmgr "github.com/intel/cri-resource-manager/pkg/cri/resource-manager"
 	"github.com/intel/cri-resource-manager/pkg/cri/resource-manager/cache"
 	"github.com/intel/cri-resource-manager/pkg/dump"
+	"github.com/intel/cri-resource-manager/pkg/record"
 	"google.golang.org/grpc"
 	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
 
@@ -54,6 +55,9 @@ type testEnv struct {
 	handlers    map[string]interface{}
 	client      criv1.RuntimeServiceClient
 	forceConfig string
+	hostRoot    string
+	player      *record.Player
+	conn        *grpc.ClientConn
 	mgr         resmgr.ResourceManager
 	cache       cache.Cache
 }
@@ -91,6 +95,9 @@ func (env *testEnv) Run(name string, testFunction func(context.Context, *testEnv
 		if err := flag.Set("allow-untested-runtimes", "true"); err != nil {
 			t.Fatalf("unable to allow untested runtimes: %v", err)
 		}
+		if err := flag.Set("host-root", env.hostRoot); err != nil {
+			t.Fatalf("unable to set host-root")
+		}
 
 		if env.forceConfig != "" {
 			path := filepath.Join(tmpDir, "forcedconfig.cfg")
@@ -104,8 +111,15 @@ func (env *testEnv) Run(name string, testFunction func(context.Context, *testEnv
 
 		flag.Parse()
 
-		fakeCri := newFakeCriServer(t, filepath.Join(tmpDir, "fakecri.sock"), overriddenCriHandlers)
-		defer fakeCri.stop()
+		if env.player != nil {
+			if err := env.player.Start(filepath.Join(tmpDir, "fakecri.sock")); err != nil {
+				t.Fatalf("unable to start session player: %+v", err)
+			}
+			defer env.player.Stop()
+		} else {
+			fakeCri := newFakeCriServer(t, filepath.Join(tmpDir, "fakecri.sock"), overriddenCriHandlers)
+			defer fakeCri.stop()
+		}
 
 		resMgr, err := resmgr.NewResourceManager()
 		if err != nil {
@@ -135,6 +149,7 @@ func (env *testEnv) Run(name string, testFunction func(context.Context, *testEnv
 		client := criv1.NewRuntimeServiceClient(conn)
 
 		env.client = client
+		env.conn = conn
 		env.mgr = resMgr
 		env.cache = resMgr.GetCache()
 
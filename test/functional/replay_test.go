@@ -0,0 +1,93 @@
+// Copyright 2022 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/intel/cri-resource-manager/pkg/record"
+	"github.com/intel/cri-resource-manager/pkg/sysfs"
+)
+
+const (
+	// replayDir contains recorded sessions, one per subdirectory.
+	replayDir = "testdata/replay"
+	// sessionFile is the recorded session within a session directory.
+	sessionFile = "session.jsonl"
+	// topologyFile is the recorded topology within a session directory.
+	topologyFile = "topology.tar.gz"
+)
+
+// TestReplay replays recorded sessions and verifies that the resulting
+// container resources match the recorded ones. Each session is replayed
+// on the recorded host topology, using the recorded configuration.
+func TestReplay(t *testing.T) {
+	sessions, err := os.ReadDir(replayDir)
+	if err != nil {
+		t.Fatalf("failed to read recorded sessions: %v", err)
+	}
+
+	for _, session := range sessions {
+		if !session.IsDir() {
+			continue
+		}
+		dir := filepath.Join(replayDir, session.Name())
+
+		entries, err := record.LoadFile(filepath.Join(dir, sessionFile))
+		if err != nil {
+			t.Fatalf("failed to load session %s: %v", session.Name(), err)
+		}
+		player := record.NewPlayer(entries)
+		cfg, err := player.Config()
+		if err != nil {
+			t.Fatalf("failed to get configuration of session %s: %v", session.Name(), err)
+		}
+
+		// Notifiers of policies from earlier runs can outlive their resource
+		// manager, so extract the topology where it will not get removed.
+		hostRoot := filepath.Join(testDir, "replay", session.Name())
+		if err := os.RemoveAll(hostRoot); err != nil {
+			t.Fatalf("failed to remove old topology of session %s: %v", session.Name(), err)
+		}
+		archive, err := os.Open(filepath.Join(dir, topologyFile))
+		if err != nil {
+			t.Fatalf("failed to open topology of session %s: %v", session.Name(), err)
+		}
+		err = sysfs.ExtractTopology(archive, hostRoot)
+		archive.Close()
+		if err != nil {
+			t.Fatalf("failed to extract topology of session %s: %v", session.Name(), err)
+		}
+
+		env := &testEnv{
+			t:           t,
+			forceConfig: cfg,
+			hostRoot:    hostRoot,
+			player:      player,
+		}
+		env.Run(session.Name(), func(ctx context.Context, env *testEnv) {
+			t := env.t
+			if err := player.Play(ctx, env.conn); err != nil {
+				t.Fatalf("failed to replay session: %v", err)
+			}
+			for _, diff := range player.Verify() {
+				t.Errorf("%s", diff)
+			}
+		})
+	}
+}